@@ -4,6 +4,7 @@
 package alterations
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +17,16 @@ const (
 	ldhChars = "abcdefghijklmnopqrstuvwxyz0123456789-"
 )
 
+// The modes of the numeric alterations applied to the names.
+const (
+	// NumericFlip flips the first and last digits of the label over zero through nine
+	NumericFlip = "flip"
+	// NumericRange substitutes each run of digits with the numbers of the configured range
+	NumericRange = "range"
+	// NumericAdjacent increments and decrements the numbers found within the label
+	NumericAdjacent = "adjacent"
+)
+
 // Cache maintains counters for word usage within alteration techniques.
 type Cache struct {
 	sync.RWMutex
@@ -53,6 +64,19 @@ type State struct {
 	EditDistance   int
 	Prefixes       *Cache
 	Suffixes       *Cache
+
+	// The numeric alterations applied to the runs of digits within the names
+	NumericMode     string
+	NumericMin      int
+	NumericMax      int
+	NumericPadding  int
+	NumericDistance int
+	MaxNumericAlts  int
+
+	// The words added before and after the first label of the names, independent of the
+	// word counters used by the other word alterations
+	PrefixWords []string
+	SuffixWords []string
 }
 
 // NewState returns an initialized State.
@@ -148,6 +172,99 @@ func (s *State) secondNumberFlip(name string, minIndex int) []string {
 	return newNames
 }
 
+// NumericAlterations replaces each run of digits within the first label of the name, using the
+// numbers of the range or the numbers adjacent to the one found, depending on the NumericMode.
+// Zero-padded runs keep their width, unless the NumericPadding provides the width. The number
+// of names returned is bounded by MaxNumericAlts when it is greater than zero.
+func (s *State) NumericAlterations(name string) []string {
+	if s.NumericMode == "" || s.NumericMode == NumericFlip {
+		return s.FlipNumbers(name)
+	}
+
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) < 2 {
+		return []string{}
+	}
+	label := parts[0]
+
+	newNames := stringset.New()
+	for _, run := range digitRuns(label) {
+		digits := label[run[0]:run[1]]
+
+		found, err := strconv.Atoi(digits)
+		if err != nil {
+			continue
+		}
+
+		width := s.NumericPadding
+		if width <= 0 && len(digits) > 1 && digits[0] == '0' {
+			width = len(digits)
+		}
+
+		for _, num := range s.numericValues(found) {
+			if s.MaxNumericAlts > 0 && newNames.Len() >= s.MaxNumericAlts {
+				return newNames.Slice()
+			}
+
+			n := fmt.Sprintf("%0*d", width, num)
+			if n == digits {
+				continue
+			}
+
+			if l := label[:run[0]] + n + label[run[1]:]; len(l) <= maxDNSLabelLen {
+				newNames.Insert(l + "." + parts[1])
+			}
+		}
+	}
+
+	return newNames.Slice()
+}
+
+func (s *State) numericValues(found int) []int {
+	var nums []int
+
+	if s.NumericMode == NumericAdjacent {
+		dist := s.NumericDistance
+		if dist < 1 {
+			dist = 1
+		}
+
+		for d := 1; d <= dist; d++ {
+			if found-d >= 0 {
+				nums = append(nums, found-d)
+			}
+			nums = append(nums, found+d)
+		}
+		return nums
+	}
+
+	for n := s.NumericMin; n <= s.NumericMax; n++ {
+		nums = append(nums, n)
+	}
+	return nums
+}
+
+// digitRuns returns the start and end indices of the runs of digits within the label.
+func digitRuns(label string) [][2]int {
+	var runs [][2]int
+
+	start := -1
+	for i := 0; i <= len(label); i++ {
+		if i < len(label) && label[i] >= '0' && label[i] <= '9' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			runs = append(runs, [2]int{start, i})
+			start = -1
+		}
+	}
+
+	return runs
+}
+
 // AppendNumbers appends a number to a subdomain name.
 func (s *State) AppendNumbers(name string) []string {
 	parts := strings.SplitN(name, ".", 2)
@@ -208,6 +325,56 @@ func (s *State) AddPrefixWord(name string) []string {
 	return newNames.Slice()
 }
 
+// AddPrefixes adds each of the PrefixWords before the first label of the name, using both
+// the hyphenated and the bare concatenations.
+func (s *State) AddPrefixes(name string) []string {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) < 2 {
+		return []string{}
+	}
+
+	label := strings.Trim(parts[0], "-")
+	if label == "" {
+		return []string{}
+	}
+
+	newNames := stringset.New()
+	for _, word := range s.PrefixWords {
+		for _, l := range s.addPrefix(label, word) {
+			if len(l) <= maxDNSLabelLen {
+				newNames.Insert(l + "." + parts[1])
+			}
+		}
+	}
+
+	return newNames.Slice()
+}
+
+// AddSuffixes adds each of the SuffixWords after the first label of the name, using both
+// the hyphenated and the bare concatenations.
+func (s *State) AddSuffixes(name string) []string {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) < 2 {
+		return []string{}
+	}
+
+	parts[0] = strings.Trim(parts[0], "-")
+	if parts[0] == "" {
+		return []string{}
+	}
+
+	newNames := stringset.New()
+	for _, word := range s.SuffixWords {
+		for _, n := range s.addSuffix(parts, word) {
+			if strings.IndexByte(n, '.') <= maxDNSLabelLen {
+				newNames.Insert(n)
+			}
+		}
+	}
+
+	return newNames.Slice()
+}
+
 func (s *State) addSuffix(parts []string, suffix string) []string {
 	return []string{
 		parts[0] + suffix + "." + parts[1],
@@ -86,3 +86,77 @@ func TestAlterations(t *testing.T) {
 	})
 }
 */
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNumericAlterations(t *testing.T) {
+	for _, tt := range []struct {
+		mode     string
+		min      int
+		max      int
+		padding  int
+		distance int
+		maxAlts  int
+		name     string
+		expected []string
+	}{
+		{NumericRange, 1, 4, 0, 0, 0, "web2.owasp.org",
+			[]string{"web1.owasp.org", "web3.owasp.org", "web4.owasp.org"}},
+		{NumericRange, 1, 3, 2, 0, 0, "web2.owasp.org",
+			[]string{"web01.owasp.org", "web02.owasp.org", "web03.owasp.org"}},
+		// The zero-padded runs keep their width
+		{NumericRange, 0, 2, 0, 0, 0, "web01.owasp.org",
+			[]string{"web00.owasp.org", "web02.owasp.org"}},
+		{NumericAdjacent, 0, 9, 0, 2, 0, "web47.owasp.org",
+			[]string{"web45.owasp.org", "web46.owasp.org", "web48.owasp.org", "web49.owasp.org"}},
+		{NumericAdjacent, 0, 9, 0, 1, 0, "db0-us2.owasp.org",
+			[]string{"db1-us2.owasp.org", "db0-us1.owasp.org", "db0-us3.owasp.org"}},
+		{NumericRange, 0, 99, 0, 0, 5, "web1.owasp.org",
+			[]string{"web0.owasp.org", "web2.owasp.org", "web3.owasp.org", "web4.owasp.org", "web5.owasp.org"}},
+		{NumericRange, 0, 9, 0, 0, 0, "web.owasp.org", []string{}},
+	} {
+		s := NewState([]string{})
+		s.NumericMode = tt.mode
+		s.NumericMin = tt.min
+		s.NumericMax = tt.max
+		s.NumericPadding = tt.padding
+		s.NumericDistance = tt.distance
+		s.MaxNumericAlts = tt.maxAlts
+
+		got := s.NumericAlterations(tt.name)
+		sort.Strings(got)
+		sort.Strings(tt.expected)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("NumericAlterations(%q) in the %s mode returned %v, expected %v", tt.name, tt.mode, got, tt.expected)
+		}
+	}
+}
+
+func TestAddPrefixesAndSuffixes(t *testing.T) {
+	s := NewState([]string{})
+	s.PrefixWords = []string{"dev", "stage"}
+	s.SuffixWords = []string{"api"}
+
+	got := s.AddPrefixes("web.owasp.org")
+	expected := []string{"dev-web.owasp.org", "devweb.owasp.org", "stage-web.owasp.org", "stageweb.owasp.org"}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("AddPrefixes returned %v, expected %v", got, expected)
+	}
+
+	got = s.AddSuffixes("web.owasp.org")
+	expected = []string{"web-api.owasp.org", "webapi.owasp.org"}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("AddSuffixes returned %v, expected %v", got, expected)
+	}
+
+	// The word counters used by the other word alterations are not involved
+	if got := s.AddPrefixWord("web.owasp.org"); len(got) != 0 {
+		t.Errorf("AddPrefixWord returned %v without any counted words", got)
+	}
+}
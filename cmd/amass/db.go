@@ -5,10 +5,12 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,9 +18,11 @@ import (
 	"github.com/OWASP/Amass/v3/format"
 	"github.com/OWASP/Amass/v3/graph"
 	"github.com/OWASP/Amass/v3/graph/db"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 	"github.com/fatih/color"
+	"github.com/google/uuid"
 )
 
 const (
@@ -26,26 +30,80 @@ const (
 )
 
 type dbArgs struct {
-	Domains stringset.Set
-	Enum    int
-	Options struct {
+	ASNs         format.ParseInts
+	Domains      stringset.Set
+	Enum         int
+	EnumUUID     string
+	ExcludedTags stringset.Set
+	IncludedTags stringset.Set
+	Since        string
+	since        time.Time
+	Options      struct {
+		Canonicalize     bool
+		Confirm          bool
+		Confirmed        bool
+		Delete           bool
 		DemoMode         bool
+		Detail           bool
+		DNSRecords       bool
 		IPs              bool
 		IPv4             bool
 		IPv6             bool
 		ListEnumerations bool
+		PassiveOnly      bool
 		ASNTableSummary  bool
 		DiscoveredNames  bool
 		ShowAll          bool
+		Silent           bool
 		Sources          bool
+		Unicode          bool
 	}
 	Filepaths struct {
 		ConfigFile string
 		Directory  string
 		Domains    string
+		JSONOutput string
+		STIX       string
 	}
 }
 
+// enumFlag identifies an enumeration via either an index from the listing or the UUID.
+type enumFlag struct {
+	index *int
+	uuid  *string
+}
+
+func (e enumFlag) String() string {
+	if e.uuid != nil && *e.uuid != "" {
+		return *e.uuid
+	}
+	if e.index != nil && *e.index > 0 {
+		return strconv.Itoa(*e.index)
+	}
+	return ""
+}
+
+func (e enumFlag) Set(s string) error {
+	s = strings.TrimSpace(s)
+
+	if i, err := strconv.Atoi(s); err == nil {
+		if i <= 0 {
+			return fmt.Errorf("%d is not a valid enumeration index", i)
+		}
+		*e.index = i
+		*e.uuid = ""
+		return nil
+	}
+
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return fmt.Errorf("%s is not an enumeration index or UUID", s)
+	}
+	*e.index = 0
+	*e.uuid = id.String()
+	return nil
+}
+
 func runDBCommand(clArgs []string) {
 	var args dbArgs
 	var help1, help2 bool
@@ -55,23 +113,40 @@ func runDBCommand(clArgs []string) {
 	dbCommand.SetOutput(dbBuf)
 
 	args.Domains = stringset.New()
+	args.ExcludedTags = stringset.New()
+	args.IncludedTags = stringset.New()
 
 	dbCommand.BoolVar(&help1, "h", false, "Show the program usage message")
 	dbCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	dbCommand.Var(&args.ASNs, "asn", "Show only names with addresses announced by these ASNs (can be used multiple times)")
 	dbCommand.Var(&args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
-	dbCommand.IntVar(&args.Enum, "enum", 0, "Identify an enumeration via an index from the listing")
+	dbCommand.Var(enumFlag{index: &args.Enum, uuid: &args.EnumUUID}, "enum", "Identify an enumeration via an index from the listing or the UUID")
+	dbCommand.Var(&args.ExcludedTags, "exclude-tags", "Show only names not reported with these tags (e.g. brute,alt)")
+	dbCommand.Var(&args.IncludedTags, "include-tags", "Show only names reported with any of these tags (e.g. cert,api)")
+	dbCommand.BoolVar(&args.Options.Canonicalize, "canonicalize", false, "Merge the names stored in a non-canonical form, such as mixed case")
+	dbCommand.BoolVar(&args.Options.Confirm, "confirm", false, "Perform the removal requested by -delete")
+	dbCommand.BoolVar(&args.Options.Confirmed, "confirmed", false, "Show only names confirmed by active DNS resolution")
+	dbCommand.BoolVar(&args.Options.Delete, "delete", false, "Remove the enumeration selected by -enum, or the enumerations of the -d domains")
 	dbCommand.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
+	dbCommand.BoolVar(&args.Options.Detail, "detail", false, "Print a line per address with the netblock and ASN information")
+	dbCommand.BoolVar(&args.Options.DNSRecords, "dns", false, "Show the DNS records stored for discovered names")
 	dbCommand.BoolVar(&args.Options.IPs, "ip", false, "Show the IP addresses for discovered names")
 	dbCommand.BoolVar(&args.Options.IPv4, "ipv4", false, "Show the IPv4 addresses for discovered names")
 	dbCommand.BoolVar(&args.Options.IPv6, "ipv6", false, "Show the IPv6 addresses for discovered names")
 	dbCommand.BoolVar(&args.Options.ListEnumerations, "list", false, "Numbered list of enums filtered on provided domains")
+	dbCommand.BoolVar(&args.Options.PassiveOnly, "passive-only", false, "Show only names never confirmed by active DNS resolution")
+	dbCommand.BoolVar(&args.Options.Silent, "silent", false, "Only write the results to stdout and send all other output to stderr")
 	dbCommand.BoolVar(&args.Options.Sources, "src", false, "Print data sources for the discovered names")
+	dbCommand.BoolVar(&args.Options.Unicode, "unicode", false, "Show internationalized names in the Unicode form")
 	dbCommand.BoolVar(&args.Options.ASNTableSummary, "summary", false, "Print Just ASN Table Summary")
 	dbCommand.BoolVar(&args.Options.DiscoveredNames, "names", false, "Print Just Discovered Names")
 	dbCommand.BoolVar(&args.Options.ShowAll, "show", false, "Print the results for the enumeration index + domains provided")
+	dbCommand.StringVar(&args.Since, "since", "", "Show only names first seen after (format: "+timeFormat+")")
 	dbCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
 	dbCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
 	dbCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
+	dbCommand.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file for the names selected by -asn")
+	dbCommand.StringVar(&args.Filepaths.STIX, "stix", "", "Path to the STIX 2.1 bundle file generated for the enumeration")
 
 	if len(clArgs) < 1 {
 		commandUsage(dbUsageMsg, dbCommand, dbBuf)
@@ -86,6 +161,28 @@ func runDBCommand(clArgs []string) {
 		commandUsage(dbUsageMsg, dbCommand, dbBuf)
 		return
 	}
+	format.SetMachineMode(args.Options.Silent)
+
+	if args.Options.Confirmed && args.Options.PassiveOnly {
+		r.Fprintln(color.Error, "The -confirmed and -passive-only flags cannot be used together")
+		os.Exit(1)
+	}
+
+	for _, tags := range []stringset.Set{args.IncludedTags, args.ExcludedTags} {
+		if err := checkTags(tags); err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+	if args.Since != "" {
+		var err error
+
+		args.since, err = time.Parse(timeFormat, args.Since)
+		if err != nil {
+			r.Fprintf(color.Error, "%s is not in the correct format: %s\n", args.Since, timeFormat)
+			os.Exit(1)
+		}
+	}
 
 	if args.Filepaths.Domains != "" {
 		list, err := config.GetListFromFile(args.Filepaths.Domains)
@@ -110,6 +207,15 @@ func runDBCommand(clArgs []string) {
 		os.Exit(1)
 	}
 
+	// The removal cannot run while an enumeration is writing to the graph database
+	if args.Options.Delete {
+		if pid, active := db.ActiveWriter(args.Filepaths.Directory); active {
+			r.Fprintf(color.Error, "The graph database is being written by an enumeration (process %d)\n", pid)
+			r.Fprintf(color.Error, "Remove the %s file from the directory if the enumeration is no longer running\n", db.WriterLockFile)
+			os.Exit(1)
+		}
+	}
+
 	db := openGraphDatabase(args.Filepaths.Directory, cfg)
 	if db == nil {
 		r.Fprintln(color.Error, "Failed to connect with the database")
@@ -117,16 +223,41 @@ func runDBCommand(clArgs []string) {
 	}
 	defer db.Close()
 
+	if args.Options.Canonicalize {
+		merged, err := db.MergeCanonicalNames()
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to canonicalize the names: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(color.Output, "Merged %d names into the canonical form\n", merged)
+		return
+	}
+
+	if args.Options.Delete {
+		deleteEnumerations(&args, db)
+		return
+	}
+
 	if args.Options.ListEnumerations {
 		listEnumerations(&args, db)
 		return
 	}
 
+	if args.Filepaths.STIX != "" {
+		writeSTIXFile(&args, db)
+		return
+	}
+
 	if args.Options.ShowAll {
 		args.Options.DiscoveredNames = true
 		args.Options.ASNTableSummary = true
 	}
 
+	if len(args.ASNs) > 0 && args.Options.DiscoveredNames {
+		showASNNames(&args, db)
+		return
+	}
+
 	if args.Options.DiscoveredNames || args.Options.ASNTableSummary {
 		showEnumeration(&args, db)
 		return
@@ -167,17 +298,17 @@ func listEnumerations(args *dbArgs, db *graph.Graph) {
 	// Check if the user has requested the list of enumerations
 	for i := range enums {
 		if i != 0 {
-			g.Println()
+			g.Fprintln(format.ResultOutput())
 		}
-		g.Printf("%d) %s -> %s: ", i+1, earliest[i].Format(timeFormat), latest[i].Format(timeFormat))
+		g.Fprintf(format.ResultOutput(), "%d) %s -> %s: ", i+1, earliest[i].Format(timeFormat), latest[i].Format(timeFormat))
 		// Print out the scope for this enumeration
 		for x, domain := range db.EventDomains(enums[i]) {
 			if x != 0 {
-				g.Print(", ")
+				g.Fprint(format.ResultOutput(), ", ")
 			}
-			g.Print(domain)
+			g.Fprint(format.ResultOutput(), domain)
 		}
-		g.Println()
+		g.Fprintln(format.ResultOutput())
 	}
 }
 
@@ -186,7 +317,7 @@ func showEnumeration(args *dbArgs, db *graph.Graph) {
 	var total int
 	tags := make(map[string]int)
 	asns := make(map[int]*format.ASNSummaryData)
-	for _, out := range getEnumOutput(args.Enum, domains, db) {
+	for _, out := range getEnumOutput(args, domains, db) {
 		if len(domains) > 0 && !domainNameInScope(out.Name, domains) {
 			continue
 		}
@@ -195,32 +326,197 @@ func showEnumeration(args *dbArgs, db *graph.Graph) {
 		if len(out.Addresses) == 0 {
 			continue
 		}
+		if !format.DesiredTags(out, args.IncludedTags, args.ExcludedTags) {
+			continue
+		}
+		if !args.since.IsZero() && !out.FirstSeen.After(args.since) {
+			continue
+		}
+		if (args.Options.Confirmed && out.PassiveOnly) || (args.Options.PassiveOnly && !out.PassiveOnly) {
+			continue
+		}
 
 		total++
 		format.UpdateSummaryData(out, tags, asns)
 		source, name, ips := format.OutputLineParts(out, args.Options.Sources,
 			args.Options.IPs || args.Options.IPv4 || args.Options.IPv6, args.Options.DemoMode)
+		if args.Options.Unicode {
+			name = amassdns.UnicodeName(name)
+		}
 
 		if ips != "" {
 			ips = " " + ips
 		}
+		if args.Options.DNSRecords {
+			if records := format.RecordsString(out, args.Options.DemoMode); records != "" {
+				ips += " " + records
+			}
+		}
+		if args.Options.ShowAll && !out.FirstSeen.IsZero() {
+			ips += fmt.Sprintf(" (first seen: %s, last seen: %s)",
+				out.FirstSeen.Local().Format(timeFormat), out.LastSeen.Local().Format(timeFormat))
+		}
+
+		if !args.Options.DiscoveredNames {
+			continue
+		}
 
-		if args.Options.DiscoveredNames {
-			fmt.Fprintf(color.Output, "%s%s%s\n", blue(source), green(name), yellow(ips))
+		if args.Options.Detail {
+			for _, detail := range format.AddressDetails(out.Addresses, args.Options.DemoMode) {
+				format.PrintResult(fmt.Sprintf("%s%s %s", source, name, detail),
+					fmt.Sprintf("%s%s %s", blue(source), green(name), yellow(detail)))
+			}
+		} else {
+			format.PrintResult(fmt.Sprintf("%s%s%s", source, name, ips),
+				fmt.Sprintf("%s%s%s", blue(source), green(name), yellow(ips)))
 		}
 	}
 	if total == 0 {
-		r.Println("No names were discovered")
+		r.Fprintln(format.MessageOutput(), "No names were discovered")
 	} else if args.Options.ASNTableSummary {
 		format.PrintEnumerationSummary(total, tags, asns, args.Options.DemoMode)
 	}
 }
 
-func getEnumOutput(id int, domains []string, db *graph.Graph) []*requests.Output {
+// showASNNames prints the in scope names with addresses announced by the requested autonomous
+// systems, and writes the pairs with the covering netblock to the JSON output file.
+func showASNNames(args *dbArgs, db *graph.Graph) {
+	domains := args.Domains.Slice()
+
+	var events []string
+	if enum, selected := selectedEnumID(args, domains, db); selected {
+		if enum == "" {
+			r.Fprintln(color.Error, "No enumerations found within the provided scope")
+			return
+		}
+		events = []string{enum}
+	} else {
+		events = enumIDs(domains, db)
+		if len(events) == 0 {
+			r.Fprintln(color.Error, "No enumerations found within the provided scope")
+			return
+		}
+	}
+
+	var enc *json.Encoder
+	if args.Filepaths.JSONOutput != "" {
+		f, err := os.OpenFile(args.Filepaths.JSONOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to open the JSON output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer func() {
+			f.Sync()
+			f.Close()
+		}()
+		enc = json.NewEncoder(f)
+	}
+
+	for _, asn := range args.ASNs {
+		names, err := db.NamesByASN(asn, domains, events...)
+		if err != nil {
+			r.Fprintf(format.MessageOutput(), "%v\n", err)
+			continue
+		}
+		if len(names) == 0 {
+			r.Fprintf(format.MessageOutput(), "No names within the provided scope resolved to addresses in AS%d\n", asn)
+			continue
+		}
+
+		for _, n := range names {
+			if args.Options.Detail {
+				format.PrintResult(fmt.Sprintf("%s %s %s AS%d", n.Name, n.Address, n.Netblock, n.ASN),
+					fmt.Sprintf("%s %s %s", green(n.Name), yellow(n.Address), blue(fmt.Sprintf("%s AS%d", n.Netblock, n.ASN))))
+			} else {
+				format.PrintResult(fmt.Sprintf("%s %s", n.Name, n.Address),
+					fmt.Sprintf("%s %s", green(n.Name), yellow(n.Address)))
+			}
+
+			if enc != nil {
+				if err := enc.Encode(n); err != nil {
+					r.Fprintf(color.Error, "Failed to write the JSON output: %v\n", err)
+					return
+				}
+			}
+		}
+	}
+}
+
+func writeSTIXFile(args *dbArgs, db *graph.Graph) {
+	var uuid string
+	domains := args.Domains.Slice()
+
+	uuid, selected := selectedEnumID(args, domains, db)
+	if !selected {
+		// Get the UUID for the most recent enumeration
+		uuid = mostRecentEnumID(domains, db)
+	}
+	if uuid == "" {
+		r.Fprintln(color.Error, "No enumerations found within the provided scope")
+		os.Exit(1)
+	}
+
+	f, err := os.OpenFile(args.Filepaths.STIX, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to open the STIX bundle file: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	start, finish := db.EventDateRange(uuid)
+	if err := format.WriteSTIXBundle(f, uuid, start, finish, getUniqueDBOutput(uuid, domains, db)); err != nil {
+		r.Fprintf(color.Error, "Failed to write the STIX bundle: %v\n", err)
+		os.Exit(1)
+	}
+	f.Sync()
+}
+
+// deleteEnumerations removes the selected enumerations from the graph database, along with the
+// nodes no longer referenced by the remaining enumerations. Without the -confirm flag, only the
+// enumerations that would be removed are printed.
+func deleteEnumerations(args *dbArgs, db *graph.Graph) {
+	domains := args.Domains.Slice()
+
+	var enums []string
+	if enum, selected := selectedEnumID(args, domains, db); selected {
+		if enum != "" {
+			enums = []string{enum}
+		}
+	} else if len(domains) > 0 {
+		enums = enumIDs(domains, db)
+	} else {
+		r.Fprintln(color.Error, "The -delete flag requires the -enum or -d flag to select the enumerations")
+		os.Exit(1)
+	}
+	if len(enums) == 0 {
+		r.Fprintln(color.Error, "No enumerations found within the provided scope")
+		os.Exit(1)
+	}
+
+	if !args.Options.Confirm {
+		for _, enum := range enums {
+			start, finish := db.EventDateRange(enum)
+			fmt.Fprintf(color.Output, "%s %s -> %s: %s\n", enum, start.Format(timeFormat),
+				finish.Format(timeFormat), strings.Join(db.EventDomains(enum), ", "))
+		}
+		r.Fprintf(color.Error, "Add the -confirm flag to remove the %d enumerations listed above\n", len(enums))
+		os.Exit(1)
+	}
+
+	for _, enum := range enums {
+		removed, err := db.DeleteEvent(enum)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to remove the enumeration %s: %v\n", enum, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(color.Output, "Removed %d edges and %d nodes for enumeration %s\n", removed.Edges, removed.Nodes, enum)
+	}
+}
+
+func getEnumOutput(args *dbArgs, domains []string, db *graph.Graph) []*requests.Output {
 	var output []*requests.Output
 
-	if id > 0 {
-		enum := enumIndexToID(id, domains, db)
+	if enum, selected := selectedEnumID(args, domains, db); selected {
 		if enum == "" {
 			r.Fprintln(color.Error, "No enumerations found within the provided scope")
 			return output
@@ -274,6 +570,23 @@ func enumIndexToID(e int, domains []string, db *graph.Graph) string {
 	return ""
 }
 
+// selectedEnumID returns the UUID of the enumeration identified by the -enum flag, and false
+// when the flag was not provided. The UUID is empty when no such enumeration exists.
+func selectedEnumID(args *dbArgs, domains []string, db *graph.Graph) (string, bool) {
+	if args.EnumUUID != "" {
+		for _, enum := range db.EventList() {
+			if enum == args.EnumUUID {
+				return enum, true
+			}
+		}
+		return "", true
+	}
+	if args.Enum > 0 {
+		return enumIndexToID(args.Enum, domains, db), true
+	}
+	return "", false
+}
+
 // Get the UUID for the most recent enumeration
 func mostRecentEnumID(domains []string, db *graph.Graph) string {
 	var uuid string
@@ -331,9 +644,9 @@ func enumIDs(domains []string, db *graph.Graph) []string {
 func domainNameInScope(name string, scope []string) bool {
 	var discovered bool
 
-	n := strings.ToLower(strings.TrimSpace(name))
+	n := amassdns.Canonical(name)
 	for _, d := range scope {
-		d = strings.ToLower(d)
+		d = amassdns.Canonical(d)
 
 		if n == d || strings.HasSuffix(n, "."+d) {
 			discovered = true
@@ -31,6 +31,7 @@ const (
 type dnsArgs struct {
 	Blacklist     stringset.Set
 	Domains       stringset.Set
+	MaxDNSQPS     int
 	MaxDNSQueries int
 	Names         stringset.Set
 	RecordTypes   stringset.Set
@@ -42,6 +43,7 @@ type dnsArgs struct {
 		IPv4                bool
 		IPv6                bool
 		MonitorResolverRate bool
+		Silent              bool
 		Unresolved          bool
 		Verbose             bool
 	}
@@ -62,6 +64,7 @@ type dnsArgs struct {
 func defineDNSArgumentFlags(dnsFlags *flag.FlagSet, args *dnsArgs) {
 	dnsFlags.Var(&args.Blacklist, "bl", "Blacklist of subdomain names that will not be investigated")
 	dnsFlags.Var(&args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
+	dnsFlags.IntVar(&args.MaxDNSQPS, "max-dns-qps", 0, "Maximum number of DNS queries per second sent overall")
 	dnsFlags.IntVar(&args.MaxDNSQueries, "max-dns-queries", 0, "Maximum number of concurrent DNS queries")
 	dnsFlags.Var(&args.RecordTypes, "t", "DNS record types to be queried for (can be used multiple times)")
 	dnsFlags.Var(&args.Resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
@@ -75,6 +78,7 @@ func defineDNSOptionFlags(dnsFlags *flag.FlagSet, args *dnsArgs) {
 	dnsFlags.BoolVar(&args.Options.IPv6, "ipv6", false, "Show the IPv6 addresses for discovered names")
 	dnsFlags.BoolVar(&args.Options.MonitorResolverRate, "noresolvrate", true, "Disable resolver rate monitoring")
 	dnsFlags.BoolVar(&args.Options.Unresolved, "include-unresolvable", false, "Output DNS names that did not resolve")
+	dnsFlags.BoolVar(&args.Options.Silent, "silent", false, "Only write the results to stdout and send all other output to stderr")
 	dnsFlags.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
 }
 
@@ -124,6 +128,7 @@ func runDNSCommand(clArgs []string) {
 		commandUsage(dnsUsageMsg, dnsCommand, dnsBuf)
 		return
 	}
+	format.SetMachineMode(args.Options.Silent)
 
 	if err := processDNSInputFiles(&args); err != nil {
 		fmt.Fprintf(color.Error, "%v\n", err)
@@ -297,7 +302,8 @@ func processDNSAnswers(cfg *config.Config,
 					data += resolvers.RemoveLastDot(rec.Data)
 				}
 
-				fmt.Fprintf(color.Output, "%s%s %s\n", blue(tstr), green(req.Name), yellow(data))
+				format.PrintResult(fmt.Sprintf("%s%s %s", tstr, req.Name, data),
+					fmt.Sprintf("%s%s %s", blue(tstr), green(req.Name), yellow(data)))
 			}
 		}
 	}
@@ -350,6 +356,9 @@ func (d dnsArgs) OverrideConfig(conf *config.Config) error {
 	if d.Filepaths.Directory != "" {
 		conf.Dir = d.Filepaths.Directory
 	}
+	if d.MaxDNSQPS > 0 {
+		conf.MaxDNSQPS = d.MaxDNSQPS
+	}
 	if d.MaxDNSQueries > 0 {
 		conf.MaxDNSQueries = d.MaxDNSQueries
 	}
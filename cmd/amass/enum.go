@@ -6,6 +6,7 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -24,7 +25,8 @@ import (
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/enum"
 	"github.com/OWASP/Amass/v3/format"
-	"github.com/OWASP/Amass/v3/services"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 	"github.com/fatih/color"
 )
@@ -48,7 +50,10 @@ type enumArgs struct {
 	Blacklist         stringset.Set
 	Domains           stringset.Set
 	Excluded          stringset.Set
+	ExcludedTags      stringset.Set
 	Included          stringset.Set
+	IncludedTags      stringset.Set
+	MaxDNSQPS         int
 	MaxDNSQueries     int
 	MinForRecursive   int
 	Names             stringset.Set
@@ -59,6 +64,8 @@ type enumArgs struct {
 		Active              bool
 		BruteForcing        bool
 		DemoMode            bool
+		Detail              bool
+		DNSRecords          bool
 		IPs                 bool
 		IPv4                bool
 		IPv6                bool
@@ -67,7 +74,9 @@ type enumArgs struct {
 		NoAlts              bool
 		NoRecursive         bool
 		Passive             bool
+		Silent              bool
 		Sources             bool
+		Unicode             bool
 		Unresolved          bool
 		Verbose             bool
 	}
@@ -86,6 +95,7 @@ type enumArgs struct {
 		Names         format.ParseStrings
 		Resolvers     format.ParseStrings
 		TermOut       string
+		ZoneFiles     format.ParseStrings
 	}
 }
 
@@ -98,7 +108,10 @@ func defineEnumArgumentFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.Var(&args.BruteWordListMask, "wm", "\"hashcat-style\" wordlist masks for DNS brute forcing")
 	enumFlags.Var(&args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
 	enumFlags.Var(&args.Excluded, "exclude", "Data source names separated by commas to be excluded")
+	enumFlags.Var(&args.ExcludedTags, "exclude-tags", "Output only names not reported with these tags (e.g. brute,alt)")
 	enumFlags.Var(&args.Included, "include", "Data source names separated by commas to be included")
+	enumFlags.Var(&args.IncludedTags, "include-tags", "Output only names reported with any of these tags (e.g. cert,api)")
+	enumFlags.IntVar(&args.MaxDNSQPS, "max-dns-qps", 0, "Maximum number of DNS queries per second sent overall")
 	enumFlags.IntVar(&args.MaxDNSQueries, "max-dns-queries", 0, "Maximum number of concurrent DNS queries")
 	enumFlags.IntVar(&args.MinForRecursive, "min-for-recursive", 1, "Subdomain labels seen before recursive brute forcing")
 	enumFlags.Var(&args.Ports, "p", "Ports separated by commas (default: 443)")
@@ -110,6 +123,8 @@ func defineEnumOptionFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.BoolVar(&args.Options.Active, "active", false, "Attempt zone transfers and certificate name grabs")
 	enumFlags.BoolVar(&args.Options.BruteForcing, "brute", false, "Execute brute forcing after searches")
 	enumFlags.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
+	enumFlags.BoolVar(&args.Options.Detail, "detail", false, "Print a line per address with the netblock and ASN information")
+	enumFlags.BoolVar(&args.Options.DNSRecords, "dns", false, "Show the DNS records stored for discovered names")
 	enumFlags.BoolVar(&args.Options.IPs, "ip", false, "Show the IP addresses for discovered names")
 	enumFlags.BoolVar(&args.Options.IPv4, "ipv4", false, "Show the IPv4 addresses for discovered names")
 	enumFlags.BoolVar(&args.Options.IPv6, "ipv6", false, "Show the IPv6 addresses for discovered names")
@@ -118,7 +133,9 @@ func defineEnumOptionFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.BoolVar(&args.Options.NoAlts, "noalts", false, "Disable generation of altered names")
 	enumFlags.BoolVar(&args.Options.NoRecursive, "norecursive", false, "Turn off recursive brute forcing")
 	enumFlags.BoolVar(&args.Options.Passive, "passive", false, "Disable DNS resolution of names and dependent features")
+	enumFlags.BoolVar(&args.Options.Silent, "silent", false, "Only write the results to stdout and send all other output to stderr")
 	enumFlags.BoolVar(&args.Options.Sources, "src", false, "Print data sources for the discovered names")
+	enumFlags.BoolVar(&args.Options.Unicode, "unicode", false, "Show internationalized names in the Unicode form")
 	enumFlags.BoolVar(&args.Options.Unresolved, "include-unresolvable", false, "Output DNS names that did not resolve")
 	enumFlags.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
 }
@@ -138,6 +155,7 @@ func defineEnumFilepathFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.Var(&args.Filepaths.Names, "nf", "Path to a file providing already known subdomain names (from other tools/sources)")
 	enumFlags.Var(&args.Filepaths.Resolvers, "rf", "Path to a file providing preferred DNS resolvers")
 	enumFlags.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
+	enumFlags.Var(&args.Filepaths.ZoneFiles, "zf", "Path to a DNS zone file providing records of the target domains")
 }
 
 func runEnumCommand(clArgs []string) {
@@ -149,7 +167,9 @@ func runEnumCommand(clArgs []string) {
 		Blacklist:         stringset.New(),
 		Domains:           stringset.New(),
 		Excluded:          stringset.New(),
+		ExcludedTags:      stringset.New(),
 		Included:          stringset.New(),
+		IncludedTags:      stringset.New(),
 		Names:             stringset.New(),
 		Resolvers:         stringset.New(),
 	}
@@ -178,11 +198,12 @@ func runEnumCommand(clArgs []string) {
 		commandUsage(enumUsageMsg, enumCommand, enumBuf)
 		return
 	}
+	format.SetMachineMode(args.Options.Silent)
 
 	// Check if the user has requested the data source names
 	if args.Options.ListSources {
 		for _, name := range GetAllSourceNames() {
-			g.Println(name)
+			format.PrintResult(name, g.Sprint(name))
 		}
 		return
 	}
@@ -208,6 +229,13 @@ func runEnumCommand(clArgs []string) {
 		os.Exit(1)
 	}
 
+	for _, tags := range []stringset.Set{args.IncludedTags, args.ExcludedTags} {
+		if err := checkTags(tags); err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	if err := processEnumInputFiles(&args); err != nil {
 		fmt.Fprintf(color.Error, "%v\n", err)
 		os.Exit(1)
@@ -241,21 +269,14 @@ func runEnumCommand(clArgs []string) {
 	createOutputDirectory(cfg)
 	go writeLogsAndMessages(rLog, logfile, args.Options.Verbose)
 
-	sys, err := services.NewLocalSystem(cfg)
-	if err != nil {
-		r.Fprintf(color.Error, "%v\n", err)
-		os.Exit(1)
-	}
-
 	// Seed the default pseudo-random number generator
 	rand.Seed(time.Now().UTC().UnixNano())
 
-	e := enum.NewEnumeration(sys)
-	if e == nil {
-		r.Fprintf(color.Error, "%s\n", "No DNS resolvers passed the sanity check")
+	e, err := enum.New(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
 		os.Exit(1)
 	}
-	e.Config = cfg
 
 	processEnumOutput(e, &args)
 	//graph := sys.GraphDatabases()[0]
@@ -274,9 +295,11 @@ func processEnumOutput(e *enum.Enumeration, args *enumArgs) {
 	if args.Filepaths.JSONOutput != "" {
 		jsonfile = args.Filepaths.JSONOutput
 	}
+	summaryfile := filepath.Join(dir, "amass_summary.json")
 	if args.Filepaths.AllFilePrefix != "" {
 		txtfile = args.Filepaths.AllFilePrefix + ".txt"
 		jsonfile = args.Filepaths.AllFilePrefix + ".json"
+		summaryfile = args.Filepaths.AllFilePrefix + "_summary.json"
 	}
 
 	var outptr, jsonptr *os.File
@@ -317,25 +340,48 @@ func processEnumOutput(e *enum.Enumeration, args *enumArgs) {
 		tags := make(map[string]int)
 		asns := make(map[int]*format.ASNSummaryData)
 		// Collect all the names returned by the enumeration
-		for out := range e.Output {
+		for out := range e.Results() {
 			out.Addresses = format.DesiredAddrTypes(out.Addresses, args.Options.IPv4, args.Options.IPv6)
 			if !e.Config.Passive && len(out.Addresses) <= 0 {
 				continue
 			}
+			if !format.DesiredTags(out, args.IncludedTags, args.ExcludedTags) {
+				continue
+			}
 
 			total++
 			format.UpdateSummaryData(out, tags, asns)
 			source, name, ips := format.OutputLineParts(out, args.Options.Sources,
 				args.Options.IPs || args.Options.IPv4 || args.Options.IPv6, args.Options.DemoMode)
+			if args.Options.Unicode {
+				name = amassdns.UnicodeName(name)
+			}
 
 			if ips != "" {
 				ips = " " + ips
 			}
+			if !args.Options.DNSRecords {
+				out.Records = nil
+			} else if records := format.RecordsString(out, args.Options.DemoMode); records != "" {
+				ips += " " + records
+			}
 
-			fmt.Fprintf(color.Output, "%s%s%s\n", blue(source), green(name), yellow(ips))
-			// Handle writing the line to a specified output file
-			if outptr != nil {
-				fmt.Fprintf(outptr, "%s%s%s\n", source, name, ips)
+			if args.Options.Detail && len(out.Addresses) > 0 {
+				for _, detail := range format.AddressDetails(out.Addresses, args.Options.DemoMode) {
+					format.PrintResult(fmt.Sprintf("%s%s %s", source, name, detail),
+						fmt.Sprintf("%s%s %s", blue(source), green(name), yellow(detail)))
+					// Handle writing the line to a specified output file
+					if outptr != nil {
+						fmt.Fprintf(outptr, "%s%s %s\n", source, name, detail)
+					}
+				}
+			} else {
+				format.PrintResult(fmt.Sprintf("%s%s%s", source, name, ips),
+					fmt.Sprintf("%s%s%s", blue(source), green(name), yellow(ips)))
+				// Handle writing the line to a specified output file
+				if outptr != nil {
+					fmt.Fprintf(outptr, "%s%s%s\n", source, name, ips)
+				}
 			}
 			// Handle encoding the result as JSON
 			if jsonptr != nil {
@@ -343,21 +389,63 @@ func processEnumOutput(e *enum.Enumeration, args *enumArgs) {
 			}
 		}
 		if total == 0 {
-			r.Println("No names were discovered")
+			r.Fprintln(format.MessageOutput(), "No names were discovered")
+		} else if summary := enumInfrastructureSummary(e); len(summary) > 0 {
+			format.PrintEnumerationSummary(total, tags, nil, args.Options.DemoMode)
+			format.PrintInfrastructureSummary(summary, args.Options.DemoMode)
+			writeInfrastructureSummary(summaryfile, summary)
 		} else {
 			format.PrintEnumerationSummary(total, tags, asns, args.Options.DemoMode)
 		}
+		printEventStats(e)
 		close(finished)
 	}()
 	// Start the enumeration process
 	go signalHandler(e)
-	if err := e.Start(); err != nil {
-		r.Println(err)
+	if err := e.Start(context.Background()); err != nil {
+		r.Fprintln(color.Error, err)
 		os.Exit(1)
 	}
 	<-finished
 }
 
+// enumInfrastructureSummary returns the ASN and netblock rollup of the names discovered by the enumeration.
+func enumInfrastructureSummary(e *enum.Enumeration) []*requests.ASNSummary {
+	graphs := e.Sys.GraphDatabases()
+	if len(graphs) == 0 {
+		return nil
+	}
+
+	return graphs[0].InfrastructureSummary(e.Config.Domains(), e.Config.UUID.String())
+}
+
+// printEventStats warns about the events dropped by the event bus, since data may have gone missing.
+func printEventStats(e *enum.Enumeration) {
+	stats := e.EventStats()
+	if stats.DataDropped == 0 && stats.HousekeepingDropped == 0 {
+		return
+	}
+
+	r.Fprintf(color.Error, "\nWARNING: %d housekeeping events dropped; %d data events dropped\n",
+		stats.HousekeepingDropped, stats.DataDropped)
+	if stats.DataDropped > 0 {
+		r.Fprintln(color.Error, "Some of the discovered names and addresses may be missing from the results")
+	}
+}
+
+func writeInfrastructureSummary(path string, summary []*requests.ASNSummary) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to open the summary output file: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if err := format.WriteInfrastructureSummary(f, summary); err != nil {
+		r.Fprintf(color.Error, "Failed to write the summary output file: %v\n", err)
+	}
+}
+
 // If the user interrupts the program, print the summary information
 func signalHandler(e *enum.Enumeration) {
 	quit := make(chan os.Signal, 1)
@@ -366,7 +454,7 @@ func signalHandler(e *enum.Enumeration) {
 
 	<-quit
 	// Start final output operations
-	e.Done()
+	e.Stop()
 	<-finished
 	os.Exit(1)
 }
@@ -409,9 +497,15 @@ func writeLogsAndMessages(logs *io.PipeReader, logfile string, verbose bool) {
 		if filePtr != nil {
 			fmt.Fprintln(filePtr, line)
 		}
-		// Remove the timestamp
-		parts := strings.Split(line, " ")
-		line = strings.Join(parts[1:], " ")
+		var entry requests.LogEntry
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &entry) == nil {
+			// The JSON log entries are displayed as text
+			line = entry.String()
+		} else {
+			// Remove the timestamp
+			parts := strings.Split(line, " ")
+			line = strings.Join(parts[1:], " ")
+		}
 		// Check for the Amass average DNS names messages
 		if avg.FindString(line) != "" {
 			fgY.Fprintln(color.Error, line)
@@ -536,12 +630,18 @@ func (e enumArgs) OverrideConfig(conf *config.Config) error {
 	if e.Filepaths.Directory != "" {
 		conf.Dir = e.Filepaths.Directory
 	}
+	if e.MaxDNSQPS > 0 {
+		conf.MaxDNSQPS = e.MaxDNSQPS
+	}
 	if e.MaxDNSQueries > 0 {
 		conf.MaxDNSQueries = e.MaxDNSQueries
 	}
 	if len(e.Names) > 0 {
 		conf.ProvidedNames = e.Names.Slice()
 	}
+	if len(e.Filepaths.ZoneFiles) > 0 {
+		conf.ZoneFiles = e.Filepaths.ZoneFiles
+	}
 	if len(e.BruteWordList) > 0 {
 		conf.Wordlist = e.BruteWordList.Slice()
 	}
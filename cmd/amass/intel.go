@@ -14,6 +14,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -34,6 +35,7 @@ type intelArgs struct {
 	ASNs             format.ParseInts
 	CIDRs            format.ParseCIDRs
 	OrganizationName string
+	OrgThreshold     int
 	Domains          stringset.Set
 	Excluded         stringset.Set
 	Included         stringset.Set
@@ -41,6 +43,8 @@ type intelArgs struct {
 	Ports            format.ParseInts
 	Resolvers        stringset.Set
 	Timeout          int
+	WhoisEmails      format.ParseStrings
+	WhoisOrg         string
 	Options          struct {
 		Active              bool
 		DemoMode            bool
@@ -49,7 +53,10 @@ type intelArgs struct {
 		IPv6                bool
 		ListSources         bool
 		ReverseWhois        bool
+		Silent              bool
+		StrictOrg           bool
 		Sources             bool
+		Summary             bool
 		MonitorResolverRate bool
 		Verbose             bool
 	}
@@ -70,6 +77,7 @@ func defineIntelArgumentFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.Var(&args.ASNs, "asn", "ASNs separated by commas (can be used multiple times)")
 	intelFlags.Var(&args.CIDRs, "cidr", "CIDRs separated by commas (can be used multiple times)")
 	intelFlags.StringVar(&args.OrganizationName, "org", "", "Search string provided against AS description information")
+	intelFlags.IntVar(&args.OrgThreshold, "org-threshold", config.DefaultOrgMatchThreshold, "Minimum score (0-100) of the AS descriptions matching the -org name")
 	intelFlags.Var(&args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
 	intelFlags.Var(&args.Excluded, "exclude", "Data source names separated by commas to be excluded")
 	intelFlags.Var(&args.Included, "include", "Data source names separated by commas to be included")
@@ -77,6 +85,8 @@ func defineIntelArgumentFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.Var(&args.Ports, "p", "Ports separated by commas (default: 443)")
 	intelFlags.Var(&args.Resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
 	intelFlags.IntVar(&args.Timeout, "timeout", 0, "Number of minutes to let enumeration run before quitting")
+	intelFlags.Var(&args.WhoisEmails, "whois-email", "Registrant email addresses run through reverse whois (can be used multiple times)")
+	intelFlags.StringVar(&args.WhoisOrg, "whois-org", "", "Registrant organization run through reverse whois")
 }
 
 func defineIntelOptionFlags(intelFlags *flag.FlagSet, args *intelArgs) {
@@ -88,7 +98,10 @@ func defineIntelOptionFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.BoolVar(&args.Options.ListSources, "list", false, "Print the names of all available data sources")
 	intelFlags.BoolVar(&args.Options.MonitorResolverRate, "noresolvrate", true, "Disable resolver rate monitoring")
 	intelFlags.BoolVar(&args.Options.ReverseWhois, "whois", false, "All provided domains are run through reverse whois")
+	intelFlags.BoolVar(&args.Options.Silent, "silent", false, "Only write the results to stdout and send all other output to stderr")
+	intelFlags.BoolVar(&args.Options.StrictOrg, "strict", false, "Only match the AS descriptions containing the -org string")
 	intelFlags.BoolVar(&args.Options.Sources, "src", false, "Print data sources for the discovered names")
+	intelFlags.BoolVar(&args.Options.Summary, "summary", false, "Print the ASN and netblock summary of the stored enumerations")
 	intelFlags.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
 }
 
@@ -135,18 +148,19 @@ func runIntelCommand(clArgs []string) {
 		commandUsage(intelUsageMsg, intelCommand, intelBuf)
 		return
 	}
+	format.SetMachineMode(args.Options.Silent)
 
 	// Check if the user has requested the data source names
 	if args.Options.ListSources {
 		for _, name := range GetAllSourceNames() {
-			g.Println(name)
+			format.PrintResult(name, g.Sprint(name))
 		}
 		return
 	}
 
 	// Some input validation
-	if !args.Options.ReverseWhois && args.OrganizationName == "" &&
-		len(args.Addresses) == 0 && len(args.CIDRs) == 0 && len(args.ASNs) == 0 {
+	if !args.Options.ReverseWhois && !args.Options.Summary && args.OrganizationName == "" &&
+		len(args.WhoisEmails) == 0 && args.WhoisOrg == "" && len(args.Addresses) == 0 && len(args.CIDRs) == 0 && len(args.ASNs) == 0 {
 		commandUsage(intelUsageMsg, intelCommand, intelBuf)
 		os.Exit(1)
 	}
@@ -161,13 +175,26 @@ func runIntelCommand(clArgs []string) {
 	rand.Seed(time.Now().UTC().UnixNano())
 
 	if args.OrganizationName != "" {
-		records, err := config.LookupASNsByName(args.OrganizationName)
+		if args.Options.StrictOrg {
+			records, err := config.LookupASNsByName(args.OrganizationName)
+			if err == nil {
+				for _, a := range records {
+					fmt.Fprintf(format.ResultOutput(), "%d, %s\n", a.ASN, a.Description)
+				}
+			} else {
+				fmt.Fprintf(color.Error, "%v\n", err)
+			}
+			return
+		}
+
+		// The score is printed with each candidate, so the weaker matches can be reviewed
+		matches, err := config.LookupASNsByOrg(args.OrganizationName, args.OrgThreshold)
 		if err == nil {
-			for _, a := range records {
-				fmt.Printf("%d, %s\n", a.ASN, a.Description)
+			for _, m := range matches {
+				fmt.Fprintf(format.ResultOutput(), "%d, %s, %s (score: %d)\n", m.ASN, m.Description, m.CC, m.Score)
 			}
 		} else {
-			fmt.Printf("%v\n", err)
+			fmt.Fprintf(color.Error, "%v\n", err)
 		}
 		return
 	}
@@ -195,6 +222,12 @@ func runIntelCommand(clArgs []string) {
 		os.Exit(1)
 	}
 
+	// Check if the user has requested the summary of the stored enumerations
+	if args.Options.Summary {
+		printIntelSummary(&args, cfg)
+		return
+	}
+
 	rLog, wLog := io.Pipe()
 	cfg.Log = log.New(wLog, "", log.Lmicroseconds)
 	logfile := filepath.Join(config.OutputDirectory(cfg.Dir), "amass.log")
@@ -217,7 +250,16 @@ func runIntelCommand(clArgs []string) {
 	}
 	ic.Config = cfg
 
-	if args.Options.ReverseWhois {
+	if terms := registrantTerms(&args); len(terms) > 0 {
+		args.Options.IPs = false
+		args.Options.IPv4 = false
+		args.Options.IPv6 = false
+		go func() {
+			if err := ic.RegistrantDomains(terms); err != nil {
+				r.Fprintf(color.Error, "%v\n", err)
+			}
+		}()
+	} else if args.Options.ReverseWhois {
 		if len(ic.Config.Domains()) == 0 {
 			r.Fprintln(color.Error, "No root domain names were provided")
 			os.Exit(1)
@@ -235,6 +277,21 @@ func runIntelCommand(clArgs []string) {
 	processIntelOutput(ic, &args)
 }
 
+// registrantTerms returns the email addresses and organization provided for reverse whois.
+func registrantTerms(args *intelArgs) []string {
+	var terms []string
+
+	for _, email := range args.WhoisEmails {
+		if email != "" {
+			terms = append(terms, email)
+		}
+	}
+	if org := strings.TrimSpace(args.WhoisOrg); org != "" {
+		terms = append(terms, org)
+	}
+	return terms
+}
+
 func processIntelOutput(ic *intel.Collection, args *intelArgs) {
 	var err error
 	dir := config.OutputDirectory(ic.Config.Dir)
@@ -268,7 +325,8 @@ func processIntelOutput(ic *intel.Collection, args *intelArgs) {
 			ips = " " + ips
 		}
 
-		fmt.Fprintf(color.Output, "%s%s%s\n", blue(source), green(name), yellow(ips))
+		format.PrintResult(fmt.Sprintf("%s%s%s", source, name, ips),
+			fmt.Sprintf("%s%s%s", blue(source), green(name), yellow(ips)))
 		// Handle writing the line to a specified output file
 		if outptr != nil {
 			fmt.Fprintf(outptr, "%s%s%s\n", source, name, ips)
@@ -356,6 +414,25 @@ func processIntelInputFiles(args *intelArgs) error {
 	return nil
 }
 
+// printIntelSummary outputs the ASN and netblock rollup from the enumerations stored in the graph database.
+func printIntelSummary(args *intelArgs, cfg *config.Config) {
+	db := openGraphDatabase(args.Filepaths.Directory, cfg)
+	if db == nil {
+		r.Fprintln(color.Error, "Failed to connect with the database")
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	domains := cfg.Domains()
+	summary := db.InfrastructureSummary(domains, enumIDs(domains, db)...)
+	if len(summary) == 0 {
+		r.Fprintln(format.MessageOutput(), "No infrastructure was found for the stored enumerations")
+		return
+	}
+
+	format.PrintInfrastructureSummary(summary, args.Options.DemoMode)
+}
+
 // Setup the amass intelligence collection settings
 func (i intelArgs) OverrideConfig(conf *config.Config) error {
 	if i.Options.Active {
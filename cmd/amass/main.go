@@ -12,12 +12,14 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/format"
+	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/stringset"
 	"github.com/fatih/color"
 )
 
 const (
-	mainUsageMsg         = "intel|enum|viz|track|db|dns [options]"
+	mainUsageMsg         = "intel|enum|viz|track|db|dns|serve [options]"
 	exampleConfigFileURL = "https://github.com/OWASP/Amass/blob/master/examples/config.ini"
 	userGuideURL         = "https://github.com/OWASP/Amass/blob/master/doc/user_guide.md"
 	tutorialURL          = "https://github.com/OWASP/Amass/blob/master/doc/tutorial.md"
@@ -49,7 +51,8 @@ func commandUsage(msg string, cmdFlagSet *flag.FlagSet, errBuf *bytes.Buffer) {
 		g.Fprintf(color.Error, "\t%-11s - Visualize enumeration results\n", "amass viz")
 		g.Fprintf(color.Error, "\t%-11s - Track differences between enumerations\n", "amass track")
 		g.Fprintf(color.Error, "\t%-11s - Manipulate the Amass graph database\n", "amass db")
-		g.Fprintf(color.Error, "\t%-11s - Resolve DNS names at high performance\n\n", "amass dns")
+		g.Fprintf(color.Error, "\t%-11s - Resolve DNS names at high performance\n", "amass dns")
+		g.Fprintf(color.Error, "\t%-11s - Run enumeration jobs submitted through a gRPC API\n\n", "amass serve")
 	}
 
 	g.Fprintf(color.Error, "The user's guide can be found here: \n%s\n\n", userGuideURL)
@@ -95,6 +98,8 @@ func main() {
 		runEnumCommand(os.Args[2:])
 	case "intel":
 		runIntelCommand(os.Args[2:])
+	case "serve":
+		runServeCommand(os.Args[2:])
 	case "track":
 		runTrackCommand(os.Args[2:])
 	case "viz":
@@ -135,3 +140,16 @@ func createOutputDirectory(cfg *config.Config) {
 		os.Exit(1)
 	}
 }
+
+// checkTags returns an error when the set contains a value that is not a request tag.
+func checkTags(tags stringset.Set) error {
+	known := stringset.New(requests.ALT, requests.GUESS, requests.ARCHIVE, requests.API, requests.AXFR,
+		requests.BRUTE, requests.CERT, requests.DNS, requests.RIR, requests.EXTERNAL, requests.SCRAPE)
+
+	for _, tag := range tags.Slice() {
+		if !known.Has(tag) {
+			return fmt.Errorf("%s is not a valid tag", tag)
+		}
+	}
+	return nil
+}
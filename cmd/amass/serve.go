@@ -0,0 +1,261 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"flag"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/serve"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/fatih/color"
+)
+
+const serveUsageMsg = "serve [options]"
+
+type serveArgs struct {
+	Address     string
+	HTTPAddress string
+	HealthAddr  string
+	MaxJobs     int
+	MaxResults  int
+	Options     struct {
+		AllowActive bool
+		Verbose     bool
+	}
+	Filepaths struct {
+		Certificate string
+		ConfigFile  string
+		Directory   string
+		Key         string
+		LogFile     string
+		TokenFile   string
+	}
+}
+
+func runServeCommand(clArgs []string) {
+	var args serveArgs
+	var help1, help2 bool
+	serveCommand := flag.NewFlagSet("serve", flag.ContinueOnError)
+
+	serveBuf := new(bytes.Buffer)
+	serveCommand.SetOutput(serveBuf)
+
+	serveCommand.BoolVar(&help1, "h", false, "Show the program usage message")
+	serveCommand.BoolVar(&help2, "help", false, "Show the program usage message")
+	serveCommand.StringVar(&args.Address, "addr", "127.0.0.1:4443", "The address the gRPC API listens on (empty to disable)")
+	serveCommand.StringVar(&args.HTTPAddress, "http", "", "The address the REST API listens on (empty to disable)")
+	serveCommand.StringVar(&args.HealthAddr, "health", "", "The address the unauthenticated health endpoint listens on (empty to disable)")
+	serveCommand.BoolVar(&args.Options.AllowActive, "allow-active", false, "Allow the jobs to enable the active techniques")
+	serveCommand.IntVar(&args.MaxJobs, "max-jobs", 4, "Maximum number of enumeration jobs running at the same time (0 for no limit)")
+	serveCommand.IntVar(&args.MaxResults, "max-results", serve.DefaultMaxResults, "Maximum number of findings buffered for each job")
+	serveCommand.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
+	serveCommand.StringVar(&args.Filepaths.Certificate, "cert", "", "Path to the TLS certificate file")
+	serveCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
+	serveCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the output files")
+	serveCommand.StringVar(&args.Filepaths.Key, "key", "", "Path to the TLS private key file")
+	serveCommand.StringVar(&args.Filepaths.LogFile, "log", "", "Path to the log file where errors will be written")
+	serveCommand.StringVar(&args.Filepaths.TokenFile, "token-file", "", "Path to a file providing the token required from the clients")
+
+	if err := serveCommand.Parse(clArgs); err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	if help1 || help2 {
+		commandUsage(serveUsageMsg, serveCommand, serveBuf)
+		return
+	}
+
+	if args.Address == "" && args.HTTPAddress == "" {
+		r.Fprintln(color.Error, "The gRPC and REST APIs cannot both be disabled")
+		os.Exit(1)
+	}
+	if (args.Filepaths.Certificate == "") != (args.Filepaths.Key == "") {
+		r.Fprintln(color.Error, "The TLS certificate and private key must be provided together")
+		os.Exit(1)
+	}
+
+	var opts serve.Options
+	if args.Filepaths.Certificate != "" {
+		cert, err := tls.LoadX509KeyPair(args.Filepaths.Certificate, args.Filepaths.Key)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to load the TLS certificate: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	if args.Filepaths.TokenFile != "" {
+		data, err := ioutil.ReadFile(args.Filepaths.TokenFile)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to read the token file: %v\n", err)
+			os.Exit(1)
+		}
+
+		opts.Token = strings.TrimSpace(string(data))
+		if opts.Token == "" {
+			r.Fprintln(color.Error, "The token file is empty")
+			os.Exit(1)
+		}
+	}
+	if opts.TLSConfig == nil {
+		fgY.Fprintln(color.Error, "TLS was not configured, so the API traffic and token are not encrypted")
+	}
+
+	cfg := config.NewConfig()
+	// The configuration file provides the settings shared by all the jobs, such as
+	// the resolvers, graph databases and data source API keys
+	if err := config.AcquireConfig(args.Filepaths.Directory, args.Filepaths.ConfigFile, cfg); err != nil && args.Filepaths.ConfigFile != "" {
+		r.Fprintf(color.Error, "Failed to load the configuration file: %v\n", err)
+		os.Exit(1)
+	}
+	if args.Filepaths.Directory != "" {
+		cfg.Dir = args.Filepaths.Directory
+	}
+
+	rLog, wLog := io.Pipe()
+	cfg.Log = log.New(wLog, "", log.Lmicroseconds)
+	logfile := filepath.Join(config.OutputDirectory(cfg.Dir), "amass.log")
+	if args.Filepaths.LogFile != "" {
+		logfile = args.Filepaths.LogFile
+	}
+
+	createOutputDirectory(cfg)
+	go writeLogsAndMessages(rLog, logfile, args.Options.Verbose)
+
+	// Seed the default pseudo-random number generator
+	rand.Seed(time.Now().UTC().UnixNano())
+
+	sys, err := services.NewLocalSystem(cfg)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+
+	jobs := serve.NewJobManager(sys, args.MaxJobs, args.MaxResults)
+	jobs.AllowActive(args.Options.AllowActive)
+
+	var grpcSrv *serve.Server
+	var httpSrv *http.Server
+	stopped := make(chan struct{}, 2)
+	if args.Address != "" {
+		lis, err := net.Listen("tcp", args.Address)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to listen on %s: %v\n", args.Address, err)
+			sys.Shutdown()
+			os.Exit(1)
+		}
+
+		grpcSrv = serve.NewServer(jobs, opts)
+		g.Fprintf(color.Error, "Serving the gRPC API on %s\n", lis.Addr())
+		go func() {
+			if err := grpcSrv.Serve(lis); err != nil {
+				r.Fprintf(color.Error, "%v\n", err)
+			}
+			stopped <- struct{}{}
+		}()
+	}
+	if args.HTTPAddress != "" {
+		httpSrv = &http.Server{
+			Addr:      args.HTTPAddress,
+			Handler:   serve.NewRESTHandler(jobs, opts.Token),
+			TLSConfig: opts.TLSConfig,
+		}
+
+		g.Fprintf(color.Error, "Serving the REST API on %s\n", args.HTTPAddress)
+		go func() {
+			var err error
+			if opts.TLSConfig != nil {
+				err = httpSrv.ListenAndServeTLS("", "")
+			} else {
+				err = httpSrv.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				r.Fprintf(color.Error, "%v\n", err)
+			}
+			stopped <- struct{}{}
+		}()
+	}
+
+	var healthSrv *http.Server
+	if args.HealthAddr != "" {
+		var dms *services.DataManagerService
+		for _, srv := range sys.CoreServices() {
+			if d, ok := srv.(*services.DataManagerService); ok {
+				dms = d
+				break
+			}
+		}
+
+		if dms != nil {
+			mux := http.NewServeMux()
+			mux.Handle("/health", services.NewHealthHandler(dms))
+			healthSrv = &http.Server{
+				Addr:    args.HealthAddr,
+				Handler: mux,
+			}
+
+			g.Fprintf(color.Error, "Serving the health endpoint on %s/health\n", args.HealthAddr)
+			go func() {
+				if err := healthSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					r.Fprintf(color.Error, "%v\n", err)
+				}
+			}()
+		}
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-quit:
+	case <-stopped:
+	}
+
+	// The running jobs are drained before shutting down, unless interrupted again
+	g.Fprintln(color.Error, "Waiting for the running jobs to end, interrupt again to cancel them")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-quit
+		cancel()
+	}()
+	if err := jobs.Drain(ctx); err != nil {
+		jobs.Stop()
+		// Allow the cancelled jobs to send their remaining findings
+		dctx, dcancel := context.WithTimeout(context.Background(), 30*time.Second)
+		jobs.Drain(dctx)
+		dcancel()
+	}
+
+	if httpSrv != nil {
+		sctx, scancel := context.WithTimeout(context.Background(), 10*time.Second)
+		httpSrv.Shutdown(sctx)
+		scancel()
+	}
+	if grpcSrv != nil {
+		grpcSrv.Stop()
+	}
+	if healthSrv != nil {
+		healthSrv.Close()
+	}
+	sys.Shutdown()
+}
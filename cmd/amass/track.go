@@ -7,11 +7,13 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/format"
 	"github.com/OWASP/Amass/v3/graph"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
@@ -23,12 +25,19 @@ const (
 	trackUsageMsg = "track [options] -d domain"
 )
 
+// The exit code of the track subcommand when differences are found between the enumerations,
+// which is distinct from the exit code of the errors, so the wrappers can branch on the result.
+const trackChangesExitCode = 2
+
 type trackArgs struct {
 	Domains stringset.Set
 	Last    int
 	Since   string
 	Options struct {
-		History bool
+		DryRun         bool
+		History        bool
+		QuietNoChanges bool
+		Silent         bool
 	}
 	Filepaths struct {
 		ConfigFile string
@@ -53,6 +62,9 @@ func runTrackCommand(clArgs []string) {
 	trackCommand.IntVar(&args.Last, "last", 0, "The number of recent enumerations to include in the tracking")
 	trackCommand.StringVar(&args.Since, "since", "", "Exclude all enumerations before (format: "+timeFormat+")")
 	trackCommand.BoolVar(&args.Options.History, "history", false, "Show the difference between all enumeration pairs")
+	trackCommand.BoolVar(&args.Options.DryRun, "dry-run", false, "Print the webhook notification instead of sending it")
+	trackCommand.BoolVar(&args.Options.QuietNoChanges, "quiet-no-changes", false, "Suppress all output when no differences are found")
+	trackCommand.BoolVar(&args.Options.Silent, "silent", false, "Only write the results to stdout and send all other output to stderr")
 	trackCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
 	trackCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
 	trackCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
@@ -70,6 +82,7 @@ func runTrackCommand(clArgs []string) {
 		commandUsage(trackUsageMsg, trackCommand, trackBuf)
 		return
 	}
+	format.SetMachineMode(args.Options.Silent)
 
 	// Some input validation
 	if args.Since != "" && args.Last != 0 {
@@ -163,13 +176,96 @@ func runTrackCommand(clArgs []string) {
 	latest = latest[:end]
 
 	if args.Options.History {
-		completeHistoryOutput(args.Domains.Slice(), enums, earliest, latest, db)
+		if completeHistoryOutput(args.Domains.Slice(), enums, earliest, latest, db, args.Options.QuietNoChanges) {
+			// The deferred close is skipped by the exit
+			db.Close()
+			os.Exit(trackChangesExitCode)
+		}
 		return
 	}
-	cumulativeOutput(args.Domains.Slice(), enums, earliest, latest, db)
+
+	findings, changed := cumulativeOutput(args.Domains.Slice(), enums, earliest, latest, db, args.Options.QuietNoChanges)
+	if cfg.NotifyURL != "" {
+		if err := sendTrackNotification(cfg, findings, args.Options.DryRun); err != nil {
+			r.Fprintf(color.Error, "Failed to send the notification: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if changed {
+		db.Close()
+		os.Exit(trackChangesExitCode)
+	}
+}
+
+func sendTrackNotification(cfg *config.Config, findings []*format.Finding, dryrun bool) error {
+	var tmpl string
+	if cfg.NotifyTemplateFile != "" {
+		data, err := ioutil.ReadFile(cfg.NotifyTemplateFile)
+		if err != nil {
+			return err
+		}
+		tmpl = string(data)
+	}
+
+	n, err := format.NewNotifier(cfg.NotifyURL, tmpl, cfg.NotifySeverity, cfg.NotifySecret, cfg.NotifyRetries)
+	if err != nil {
+		return err
+	}
+
+	n.DryRun = dryrun
+	n.Output = format.ResultOutput()
+	return n.Notify(findings)
+}
+
+// trackFindings returns the names that are new in the latest output, the names with addresses
+// that changed since the previous output, and the names missing from the latest output.
+func trackFindings(prev, latest []*requests.Output) []*format.Finding {
+	omap := make(map[string]*requests.Output)
+	for _, o := range prev {
+		omap[o.Name] = o
+	}
+	lmap := make(map[string]struct{})
+	for _, o := range latest {
+		lmap[o.Name] = struct{}{}
+	}
+
+	var findings []*format.Finding
+	for _, o := range latest {
+		p, found := omap[o.Name]
+		if !found {
+			findings = append(findings, &format.Finding{
+				Severity: format.SeverityNewName,
+				Domain:   o.Domain,
+				Name:     o.Name,
+				Details:  lineOfAddresses(o.Addresses),
+			})
+		} else if !compareAddresses(o.Addresses, p.Addresses) {
+			findings = append(findings, &format.Finding{
+				Severity: format.SeverityChangedRecord,
+				Domain:   o.Domain,
+				Name:     o.Name,
+				Details:  lineOfAddresses(p.Addresses) + " -> " + lineOfAddresses(o.Addresses),
+			})
+		}
+	}
+
+	for _, o := range prev {
+		if _, found := lmap[o.Name]; !found {
+			findings = append(findings, &format.Finding{
+				Severity: format.SeverityRemovedName,
+				Domain:   o.Domain,
+				Name:     o.Name,
+				Details:  lineOfAddresses(o.Addresses),
+			})
+		}
+	}
+	return findings
 }
 
-func cumulativeOutput(domains []string, enums []string, ea, la []time.Time, db *graph.Graph) {
+// cumulativeOutput prints the differences between the latest enumeration and the names from the
+// previous enumerations, and returns the findings along with true when differences were found.
+// Nothing is printed when the quiet flag is set and no differences were found.
+func cumulativeOutput(domains []string, enums []string, ea, la []time.Time, db *graph.Graph, quiet bool) ([]*format.Finding, bool) {
 	idx := len(enums) - 1
 	filter := stringset.NewStringFilter()
 
@@ -182,60 +278,78 @@ func cumulativeOutput(domains []string, enums []string, ea, la []time.Time, db *
 		}
 	}
 
+	out := getUniqueDBOutput(enums[idx], domains, db)
+	diff := diffEnumOutput(cum, out)
+	findings := trackFindings(cum, out)
+	changed := len(diff) > 0 || len(findings) > 0
+	if !changed && quiet {
+		return findings, false
+	}
+
 	blueLine()
-	fmt.Fprintf(color.Output, "%s\t%s%s%s\n%s\t%s%s%s\n", blue("Between"),
+	fmt.Fprintf(format.MessageOutput(), "%s\t%s%s%s\n%s\t%s%s%s\n", blue("Between"),
 		yellow(ea[0].Format(timeFormat)), blue(" -> "), yellow(la[0].Format(timeFormat)),
 		blue("and"), yellow(ea[idx].Format(timeFormat)), blue(" -> "), yellow(la[idx].Format(timeFormat)))
 	blueLine()
 
-	var updates bool
-	out := getUniqueDBOutput(enums[idx], domains, db)
-	for _, d := range diffEnumOutput(cum, out) {
-		updates = true
-		fmt.Fprintln(color.Output, d)
+	for _, d := range diff {
+		fmt.Fprintln(format.ResultOutput(), d)
 	}
-	if !updates {
-		g.Println("No differences discovered")
+	if len(diff) == 0 {
+		g.Fprintln(format.MessageOutput(), "No differences discovered")
 	}
+	return findings, changed
 }
 
-func completeHistoryOutput(domains []string, enums []string, ea, la []time.Time, db *graph.Graph) {
+// completeHistoryOutput prints the differences between each pair of enumerations, and returns
+// true when differences were found. The pairs without differences are not printed when the
+// quiet flag is set.
+func completeHistoryOutput(domains []string, enums []string, ea, la []time.Time, db *graph.Graph, quiet bool) bool {
 	var prev string
+	var printed, changed bool
 
 	for i, enum := range enums {
 		if prev == "" {
 			prev = enum
 			continue
 		}
-		if i != 1 {
-			fmt.Println()
+
+		out1 := getUniqueDBOutput(prev, domains, db)
+		out2 := getUniqueDBOutput(enum, domains, db)
+		diff := diffEnumOutput(out1, out2)
+		prev = enum
+		if len(diff) > 0 {
+			changed = true
+		} else if quiet {
+			continue
 		}
 
+		if printed {
+			fmt.Fprintln(format.MessageOutput())
+		}
+		printed = true
+
 		blueLine()
-		fmt.Fprintf(color.Output, "%s\t%s%s%s\n%s\t%s%s%s\n", blue("Between"),
+		fmt.Fprintf(format.MessageOutput(), "%s\t%s%s%s\n%s\t%s%s%s\n", blue("Between"),
 			yellow(ea[i-1].Format(timeFormat)), blue(" -> "), yellow(la[i-1].Format(timeFormat)),
 			blue("and"), yellow(ea[i].Format(timeFormat)), blue(" -> "), yellow(la[i].Format(timeFormat)))
 		blueLine()
 
-		var updates bool
-		out1 := getUniqueDBOutput(prev, domains, db)
-		out2 := getUniqueDBOutput(enum, domains, db)
-		for _, d := range diffEnumOutput(out1, out2) {
-			updates = true
-			fmt.Fprintln(color.Output, d)
+		for _, d := range diff {
+			fmt.Fprintln(format.ResultOutput(), d)
 		}
-		if !updates {
-			g.Println("No differences discovered")
+		if len(diff) == 0 {
+			g.Fprintln(format.MessageOutput(), "No differences discovered")
 		}
-		prev = enum
 	}
+	return changed
 }
 
 func blueLine() {
 	for i := 0; i < 8; i++ {
-		b.Fprint(color.Output, "----------")
+		b.Fprint(format.MessageOutput(), "----------")
 	}
-	fmt.Println()
+	fmt.Fprintln(format.MessageOutput())
 }
 
 func diffEnumOutput(out1, out2 []*requests.Output) []string {
@@ -255,8 +369,8 @@ func diffEnumOutput(out1, out2 []*requests.Output) []string {
 		handled[o.Name] = struct{}{}
 
 		if _, found := omap2[o.Name]; !found {
-			diff = append(diff, fmt.Sprintf("%s%s %s", blue("Found: "),
-				green(o.Name), yellow(lineOfAddresses(o.Addresses))))
+			diff = append(diff, fmt.Sprintf("%s%s %s %s", blue("Found: "),
+				green(o.Name), yellow(lineOfAddresses(o.Addresses)), blue("["+format.SourcesString(o)+"]")))
 			continue
 		}
 
@@ -274,8 +388,8 @@ func diffEnumOutput(out1, out2 []*requests.Output) []string {
 		}
 
 		if _, found := omap1[o.Name]; !found {
-			diff = append(diff, fmt.Sprintf("%s%s %s", blue("Removed: "),
-				green(o.Name), yellow(lineOfAddresses(o.Addresses))))
+			diff = append(diff, fmt.Sprintf("%s%s %s %s", blue("Removed: "),
+				green(o.Name), yellow(lineOfAddresses(o.Addresses)), blue("["+format.SourcesString(o)+"]")))
 		}
 	}
 	return diff
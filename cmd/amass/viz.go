@@ -20,12 +20,16 @@ import (
 
 const (
 	vizUsageMsg = "viz -d3|-dot||-gexf|-graphistry|-maltego [options]"
+
+	// The default maximum number of nodes written to the visualization files
+	defaultVizNodesMax = 50000
 )
 
 type vizArgs struct {
-	Domains stringset.Set
-	Enum    int
-	Options struct {
+	Domains  stringset.Set
+	Enum     int
+	NodesMax int
+	Options  struct {
 		D3         bool
 		DOT        bool
 		GEXF       bool
@@ -55,6 +59,7 @@ func runVizCommand(clArgs []string) {
 	vizCommand.BoolVar(&help2, "help", false, "Show the program usage message")
 	vizCommand.Var(&args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
 	vizCommand.IntVar(&args.Enum, "enum", 0, "Identify an enumeration via an index from the listing")
+	vizCommand.IntVar(&args.NodesMax, "nodes-max", defaultVizNodesMax, "Maximum number of nodes to visualize (0 disables the limit)")
 	vizCommand.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
 	vizCommand.StringVar(&args.Filepaths.Directory, "dir", "", "Path to the directory containing the graph database")
 	vizCommand.StringVar(&args.Filepaths.Domains, "df", "", "Path to a file providing root domain names")
@@ -146,7 +151,18 @@ func runVizCommand(clArgs []string) {
 		os.Exit(1)
 	}
 
-	nodes, edges := db.VizData(uuid)
+	// Only walk the subgraph reachable from the selected domains
+	nodes, edges := db.VizData(uuid, args.Domains.Slice()...)
+	if len(nodes) == 0 {
+		r.Fprintln(color.Error, "No nodes found for the selected domains and enumeration")
+		os.Exit(1)
+	}
+	if args.NodesMax > 0 && len(nodes) > args.NodesMax {
+		r.Fprintf(color.Error, "The visualization would contain %d nodes, exceeding the limit of %d\n", len(nodes), args.NodesMax)
+		r.Fprintln(color.Error, "Select fewer domains with -d or -df, an enumeration with -enum, or raise the limit with -nodes-max")
+		os.Exit(1)
+	}
+
 	if args.Options.D3 {
 		dir := filepath.Join(args.Filepaths.Output, "amass_d3.html")
 		writeD3File(dir, nodes, edges)
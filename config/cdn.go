@@ -0,0 +1,149 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/go-ini/ini"
+)
+
+// The address ranges and CNAME suffixes of the commonly used content delivery networks.
+var defaultCDNs = map[string][]string{
+	"akamai": {
+		"akamaiedge.net",
+		"akamaitechnologies.com",
+		"edgekey.net",
+		"edgesuite.net",
+	},
+	"cloudflare": {
+		"cdn.cloudflare.net",
+		"103.21.244.0/22",
+		"104.16.0.0/13",
+		"172.64.0.0/13",
+		"173.245.48.0/20",
+		"2606:4700::/32",
+	},
+	"cloudfront": {
+		"cloudfront.net",
+	},
+	"fastly": {
+		"fastly.net",
+		"fastlylb.net",
+		"151.101.0.0/16",
+		"2a04:4e40::/32",
+	},
+	"azure-cdn": {
+		"azureedge.net",
+	},
+	"imperva": {
+		"incapdns.net",
+	},
+}
+
+// CDNRange is an address range belonging to the content delivery network of the provider.
+type CDNRange struct {
+	Provider string
+	CIDR     *net.IPNet
+}
+
+// AddCDN identifies the provider by the value, which is either a CIDR or a CNAME suffix.
+func (c *Config) AddCDN(provider, value string) error {
+	provider = strings.TrimSpace(provider)
+	value = strings.Trim(strings.ToLower(strings.TrimSpace(value)), ".")
+	if provider == "" || value == "" {
+		return fmt.Errorf("The CDN provider and value must be provided")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if strings.Contains(value, "/") {
+		_, ipnet, err := net.ParseCIDR(value)
+		if err != nil {
+			return fmt.Errorf("The CDN range %s is not valid: %v", value, err)
+		}
+
+		c.CDNRanges = append(c.CDNRanges, CDNRange{Provider: provider, CIDR: ipnet})
+		return nil
+	}
+
+	if c.CDNSuffixes == nil {
+		c.CDNSuffixes = make(map[string]string)
+	}
+	c.CDNSuffixes[value] = provider
+	return nil
+}
+
+// CDNByAddress returns the provider of the content delivery network the address belongs to,
+// or an empty string when the address is not within a known range.
+func (c *Config) CDNByAddress(addr string) string {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return ""
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	var provider string
+	var longest int
+	// The most specific range identifies the provider
+	for _, r := range c.CDNRanges {
+		if ones, _ := r.CIDR.Mask.Size(); r.CIDR.Contains(ip) && (provider == "" || ones > longest) {
+			provider = r.Provider
+			longest = ones
+		}
+	}
+	return provider
+}
+
+// CDNByName returns the provider of the content delivery network the CNAME target belongs to,
+// using the longest matching suffix, or an empty string when the target is not a known CDN.
+func (c *Config) CDNByName(target string) string {
+	name := dns.Canonical(target)
+
+	c.Lock()
+	defer c.Unlock()
+
+	for name != "" {
+		if provider, found := c.CDNSuffixes[name]; found {
+			return provider
+		}
+
+		i := strings.Index(name, ".")
+		if i == -1 {
+			break
+		}
+		name = name[i+1:]
+	}
+	return ""
+}
+
+func (c *Config) loadCDNSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("cdn")
+	if err != nil {
+		return nil
+	}
+
+	for _, key := range sec.Keys() {
+		for _, value := range key.ValueWithShadows() {
+			if err := c.AddCDN(key.Name(), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) addDefaultCDNs() {
+	for provider, values := range defaultCDNs {
+		for _, value := range values {
+			c.AddCDN(provider, value)
+		}
+	}
+}
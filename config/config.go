@@ -6,15 +6,19 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/OWASP/Amass/v3/format"
+	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/semaphore"
 	"github.com/OWASP/Amass/v3/stringset"
 	"github.com/OWASP/Amass/v3/wordlist"
@@ -24,6 +28,27 @@ import (
 
 const defaultConcurrentDNSQueries = 10000
 
+// DefaultTimeoutGrace is the time allowed for the work still running after the enumeration deadline.
+const DefaultTimeoutGrace = 30 * time.Second
+
+// DefaultGraphProbeInterval is the time between the probe writes sent to a skipped graph database.
+const DefaultGraphProbeInterval = 30 * time.Second
+
+// DefaultWildcardCNAMEThreshold is the number of labels below a zone sharing the CNAME target
+// that identify a wildcard CNAME.
+const DefaultWildcardCNAMEThreshold = 5
+
+// DefaultMaxRecordsPerDomain is the number of records stored for each root domain name
+// before further records are dropped.
+const DefaultMaxRecordsPerDomain = 1000000
+
+// DefaultMaxNumericAlts is the number of numeric alteration names generated for each resolved name.
+const DefaultMaxNumericAlts = 100
+
+// DefaultMaxTTL is the largest record TTL in seconds accepted before the TTL is clamped,
+// which matches the one week cap commonly enforced by recursive resolvers.
+const DefaultMaxTTL = 604800
+
 var defaultPublicResolvers = []string{
 	"1.1.1.1",     // Cloudflare
 	"8.8.8.8",     // Google
@@ -35,6 +60,36 @@ var defaultPublicResolvers = []string{
 	"77.88.8.1",   // Yandex.DNS Secondary
 }
 
+// DefaultMinReresolveInterval is the shortest time allowed between resolutions of a record,
+// which clamps the very short TTLs of the record types without an interval override.
+const DefaultMinReresolveInterval = time.Minute
+
+// The minimum intervals between resolutions of the record types that rarely change.
+var defaultReresolveIntervals = map[string]time.Duration{
+	"NS":  24 * time.Hour,
+	"MX":  12 * time.Hour,
+	"SOA": 24 * time.Hour,
+}
+
+// The canonical names for the cloud services commonly targeted by CNAME records.
+var defaultCloudServices = map[string]string{
+	"s3.amazonaws.com":       "aws-s3",
+	"cloudfront.net":         "aws-cloudfront",
+	"elb.amazonaws.com":      "aws-elb",
+	"elasticbeanstalk.com":   "aws-elasticbeanstalk",
+	"blob.core.windows.net":  "azure-blob",
+	"azurewebsites.net":      "azure-app-service",
+	"cloudapp.net":           "azure-cloud-service",
+	"azureedge.net":          "azure-cdn",
+	"trafficmanager.net":     "azure-traffic-manager",
+	"storage.googleapis.com": "gcp-storage",
+	"appspot.com":            "gcp-app-engine",
+	"herokuapp.com":          "heroku",
+	"herokudns.com":          "heroku",
+	"github.io":              "github-pages",
+	"fastly.net":             "fastly",
+}
+
 // Updater allows an object to implement a method that updates a configuration.
 type Updater interface {
 	OverrideConfig(*Config) error
@@ -58,21 +113,59 @@ type Config struct {
 	GremlinUser string
 	GremlinPass string
 
+	// The settings for sending the findings to a syslog server
+	SyslogNetwork  string
+	SyslogAddress  string
+	SyslogFacility string
+	SyslogFormat   string
+
+	// The settings for sending webhook notifications about new findings
+	NotifyURL          string
+	NotifyTemplateFile string
+	NotifySeverity     string
+	NotifySecret       string
+	NotifyRetries      int
+
+	// The settings for the HTTP client shared by the data sources
+	HTTPProxy           string
+	HTTPMaxConnsPerHost int
+	HTTPRetries         int
+	HTTPMaxResponseSize int64
+	HTTPTimeout         int
+
 	// The maximum number of concurrent DNS queries
 	MaxDNSQueries int `ini:"maximum_dns_queries"`
 
+	// The maximum number of DNS queries per second sent overall, where zero is unlimited
+	MaxDNSQPS int `ini:"maximum_dns_qps"`
+
+	// The maximum number of concurrent connections made to the target hosts by the active techniques
+	MaxActiveConns int `ini:"maximum_active_connections"`
+
+	// The maximum number of connections per second started by the active techniques, where zero is unlimited
+	ActiveConnsPerSec int `ini:"active_connections_per_second"`
+
+	// The number of seconds allowed for establishing a connection with a target host
+	ConnectTimeout int `ini:"connect_timeout"`
+
 	// Semaphore to enforce the maximum DNS queries
 	SemMaxDNSQueries semaphore.Semaphore
 
 	// Names provided to seed the enumeration
 	ProvidedNames []string
 
+	// Paths to the DNS zone files providing records to be imported
+	ZoneFiles []string
+
 	// The IP addresses specified as in scope
 	Addresses []net.IP
 
 	// CIDR that is in scope
 	CIDRs []*net.IPNet
 
+	// Netblocks excluded from the network scope
+	BlacklistedCIDRs []*net.IPNet
+
 	// ASNs specified as in scope
 	ASNs []int
 
@@ -91,6 +184,11 @@ type Config struct {
 	// Minimum number of subdomain discoveries before performing recursive brute forcing
 	MinForRecursive int
 
+	// The templates generating structured labels for brute forcing, and the maximum number
+	// of labels generated by each template
+	BruteTemplates    []*LabelTemplate
+	MaxTemplateLabels int
+
 	// Will discovered subdomain name alterations be generated?
 	Alterations    bool
 	FlipWords      bool
@@ -101,6 +199,20 @@ type Config struct {
 	EditDistance   int
 	AltWordlist    []string
 
+	// The words added before and after the labels of the resolved names
+	AltPrefixes []string
+	AltSuffixes []string
+
+	// The numeric alterations applied to the runs of digits within the resolved names: the
+	// mode, the range of numbers substituted, the zero-padding width, the distance of the
+	// increments and decrements, and the maximum number of names generated for each name
+	NumericMode     string
+	NumericMin      int
+	NumericMax      int
+	NumericPadding  int
+	NumericDistance int
+	MaxNumericAlts  int
+
 	// Only access the data sources for names and return results?
 	Passive bool
 
@@ -110,9 +222,165 @@ type Config struct {
 	// Determines if unresolved DNS names will be output by the enumeration
 	IncludeUnresolvable bool `ini:"include_unresolvable"`
 
+	// Determines if ASN descriptions will be case-folded after normalization
+	FoldASNDescriptions bool `ini:"fold_asn_descriptions"`
+
+	// Determines if the raw ASN descriptions will be stored with the normalized descriptions
+	StoreRawASNDescriptions bool `ini:"store_raw_asn_descriptions"`
+
+	// Determines if the ports previously observed open on an address are linked to the names resolving to it
+	LinkKnownPorts bool `ini:"link_known_ports"`
+
+	// Determines if the IPv4 and IPv6 addresses of a name are linked to a common host node
+	MergeHosts bool `ini:"merge_hosts"`
+
+	// Determines if addresses with multiple PTR records are annotated and all the targets stored
+	MultiPTR bool `ini:"multi_ptr"`
+
+	// Determines if addresses are only stored, without triggering the address-based enumeration
+	LeafAddresses bool `ini:"leaf_addresses"`
+
+	// Determines if reserved and private addresses are stored as internal, without further enumeration
+	ExcludeReservedAddrs bool `ini:"exclude_reserved_addresses"`
+
+	// Determines if names are tagged as internal or external based on the addresses they resolve to
+	ClassifyNames bool `ini:"classify_names"`
+
+	// Determines if the addresses of out of scope CNAME targets are linked to the in scope names
+	LinkCNAMETargets bool `ini:"link_cname_targets"`
+
+	// Determines if only the names found in DNSSEC authenticated records are sent out for resolution
+	RequireDNSSEC bool `ini:"require_dnssec"`
+
+	// The maximum number of records stored for each root domain name, where zero removes the cap
+	MaxRecordsPerDomain int `ini:"max_records_per_domain"`
+
+	// The range in seconds that the record TTLs are clamped to before they are stored or used
+	// for scheduling, where a zero maximum removes the upper limit
+	MinTTL int `ini:"minimum_ttl"`
+	MaxTTL int `ini:"maximum_ttl"`
+
+	// The minimum number of distinct names resolving to an address before it is enriched
+	MinAddrNames int `ini:"minimum_names_per_address"`
+
+	// Determines how the writes are fanned out to the graph databases: sequential, concurrent or ordered
+	GraphWrites string `ini:"graph_writes"`
+
+	// The time allowed for a write to a graph database before it counts as a failure (zero disables the timeout)
+	GraphWriteTimeout time.Duration `ini:"graph_write_timeout"`
+
+	// The consecutive failures of a graph database before its writes are skipped (zero disables skipping)
+	GraphFailureThreshold int `ini:"graph_failure_threshold"`
+
+	// The time between the probe writes sent to a skipped graph database to find out if it has recovered
+	GraphProbeInterval time.Duration `ini:"graph_probe_interval"`
+
+	// The number of edges inserted into a graph database at once, and the time an edge waits
+	// for the batch to fill up before it is inserted regardless (zero size disables batching)
+	GraphBatchSize   int           `ini:"graph_batch_size"`
+	GraphBatchWindow time.Duration `ini:"graph_batch_window"`
+
+	// Determines how the DNS requests without a root domain name are handled: allow, drop or derive
+	EmptyDomainPolicy string `ini:"empty_domain_policy"`
+
+	// The maximum number of names derived from the records of a single request that are
+	// re-published at once, and how the remaining names are handled: queue or drop
+	MaxFanOut    int    `ini:"maximum_fanout"`
+	FanOutPolicy string `ini:"fanout_policy"`
+
+	// Determines how the names answered by a wildcard CNAME are handled: store or collapse. The
+	// wildcard is detected once the threshold of labels below a zone share the CNAME target, and
+	// the listed names are stored individually even when collapsing
+	WildcardCNAMEPolicy    string `ini:"wildcard_cname_policy"`
+	WildcardCNAMEThreshold int    `ini:"wildcard_cname_threshold"`
+	WildcardCNAMENames     []string
+
+	// Determines how the records whose data points to the owner name are handled: flag or drop
+	SelfReferencePolicy string `ini:"self_reference_policy"`
+
+	// Determines if the addresses only mentioned in the collected data, rather than resolved from
+	// in-scope names, are used for infrastructure expansion (reverse sweeps, certificates, etc.)
+	AggressiveExpansion bool `ini:"aggressive_expansion"`
+
+	// Determines if the name and address pairs are marked when the forward and reverse records agree
+	ConfirmFCrDNS bool `ini:"confirm_fcrdns"`
+
+	// Determine if the IPv4 and IPv6 addresses discovered trigger the reverse DNS sweeps
+	ReverseIPv4 bool `ini:"reverse_ipv4"`
+	ReverseIPv6 bool `ini:"reverse_ipv6"`
+
+	// Determines if an NS target shared by the records of a zone is only republished once for the zone
+	DedupNSTargets bool `ini:"dedup_ns_targets"`
+
+	// Determines if names and addresses are extracted from the base64 encoded tokens in TXT records
+	DecodeBase64TXT bool `ini:"decode_base64_txt"`
+
+	// How the control characters in TXT records are handled before the data is scanned (strip or
+	// escape), and determines if the unmodified TXT data is stored in the graph database
+	TXTControlChars string `ini:"txt_control_chars"`
+	StoreRawTXT     bool   `ini:"store_raw_txt"`
+
+	// Determines if the RFC 8805 geofeeds referenced by the records are fetched, so the
+	// netblocks listed are stored with their locations
+	FetchGeofeeds bool `ini:"fetch_geofeeds"`
+
+	// Determines if the deprecated SPF records are skipped, since the policies are also published
+	// in TXT records, along with the other record types that are not processed (e.g. SPF,HINFO)
+	SkipSPF         bool `ini:"skip_spf"`
+	SkipRecordTypes []string
+
+	// Additional suffixes, such as internal zones, treated as public when deriving registered
+	// domains, and determines if the private domains section of the public suffix list is ignored
+	PublicSuffixes    []string
+	ICANNSuffixesOnly bool `ini:"icann_suffixes_only"`
+
+	// The encoding of the log messages (text or json) and the minimum level of the messages logged
+	LogFormat string `ini:"log_format"`
+	LogLevel  string `ini:"log_level"`
+
+	// Determines if every event bus publish made by the data manager is logged and counted
+	DebugPublishes bool `ini:"debug_publishes"`
+
+	// The file that persists the names waiting to be processed after being re-published
+	FrontierPath string `ini:"frontier_file"`
+
+	// The SQLite file that receives the discovered records, in addition to the graph database
+	SQLitePath string `ini:"sqlite_file"`
+
+	// Determines if each stored record is written to the RecordStream as a JSON line
+	StreamRecords bool `ini:"stream_records"`
+
+	// The writer receiving the streamed records, which is the standard output when not provided
+	RecordStream io.Writer
+
+	// The key used to compute the HMAC stored with each record for tamper evidence,
+	// where the HMACs are not computed when empty
+	RecordHMACKey string `ini:"record_hmac_key"`
+
+	// The custom metadata key-value pairs (e.g. ticket IDs) attached to each stored record,
+	// where the metadata of the DNS requests takes precedence
+	RecordMetadata map[string]string
+
+	// Maps the suffixes of CNAME targets to the canonical names of the cloud services they belong to
+	CloudServices map[string]string
+
+	// The providers of the content delivery networks, identified by CNAME suffix and address range
+	CDNSuffixes map[string]string
+	CDNRanges   []CDNRange
+
+	// The minimum time between resolutions of a record, keyed by the record type (e.g. NS),
+	// which overrides the TTL when re-resolution is scheduled
+	ReresolveIntervals map[string]time.Duration
+
+	// The minimum time between resolutions of the record types without an override
+	MinReresolveInterval time.Duration
+
 	// A blacklist of subdomain names that will not be investigated
 	Blacklist []string
 
+	// Regular expressions matching names whose DNS records will not be stored
+	Denylist []string
+
 	// A list of data sources that should not be utilized
 	SourceFilter struct {
 		Include bool // true = include, false = exclude
@@ -129,15 +397,38 @@ type Config struct {
 	// Enumeration Timeout
 	Timeout int
 
+	// The time allowed for the services and the final output after the enumeration deadline,
+	// before the enumeration ends regardless
+	TimeoutGrace time.Duration `ini:"timeout_grace"`
+
 	// Option for verbose logging and output
 	Verbose bool
 
-	// The root domain names that the enumeration will target
-	domains []string
+	// The root domain names that the enumeration will target, and the version counting
+	// the changes, so the name scope is rebuilt after the domains are added
+	domains        []string
+	domainsVersion int
 
 	// The regular expressions for the root domains added to the enumeration
 	regexps map[string]*regexp.Regexp
 
+	// The compiled regular expressions from the denylist
+	denylist []*regexp.Regexp
+
+	// The network scope built from the addresses and netblocks, and the number of
+	// each used to build it, so it can be rebuilt after they change
+	addrScopeLock sync.Mutex
+	addrScope     *amassnet.CIDRSet
+	addrBlacklist *amassnet.CIDRSet
+	addrScopeSize [3]int
+
+	// The scope of names built from the domains and the blacklist, along with the version of
+	// the domains and a copy of the blacklist used to build it, so it can be rebuilt after they change
+	nameScopeLock  sync.Mutex
+	scope          *domainTrie
+	scopeVersion   int
+	scopeBlacklist []string
+
 	// The API keys used by various data sources
 	apikeys map[string]*APIKey
 }
@@ -158,7 +449,26 @@ func NewConfig() *Config {
 		Ports:         []int{443},
 		MaxDNSQueries: defaultConcurrentDNSQueries,
 
-		MinForRecursive: 1,
+		MaxActiveConns: amassnet.DefaultMaxActiveConns,
+
+		MinForRecursive:   1,
+		MaxTemplateLabels: DefaultMaxTemplateLabels,
+
+		ExcludeReservedAddrs: true,
+		ReverseIPv4:          true,
+		ReverseIPv6:          true,
+
+		MaxRecordsPerDomain: DefaultMaxRecordsPerDomain,
+		MaxTTL:              DefaultMaxTTL,
+		TimeoutGrace:        DefaultTimeoutGrace,
+		GraphProbeInterval:  DefaultGraphProbeInterval,
+
+		WildcardCNAMEThreshold: DefaultWildcardCNAMEThreshold,
+
+		CloudServices: make(map[string]string),
+
+		ReresolveIntervals:   make(map[string]time.Duration),
+		MinReresolveInterval: DefaultMinReresolveInterval,
 
 		Resolvers:           defaultPublicResolvers,
 		MonitorResolverRate: true,
@@ -172,6 +482,19 @@ func NewConfig() *Config {
 		MinForWordFlip: 2,
 		EditDistance:   1,
 		Recursive:      true,
+
+		NumericMode:     "flip",
+		NumericMax:      9,
+		NumericDistance: 1,
+		MaxNumericAlts:  DefaultMaxNumericAlts,
+	}
+
+	for suffix, service := range defaultCloudServices {
+		c.CloudServices[suffix] = service
+	}
+	c.addDefaultCDNs()
+	for rrtype, interval := range defaultReresolveIntervals {
+		c.ReresolveIntervals[rrtype] = interval
 	}
 
 	c.SemMaxDNSQueries = semaphore.NewSimpleSemaphore(c.MaxDNSQueries)
@@ -195,6 +518,16 @@ func (c *Config) CheckSettings() error {
 	if c.Passive && c.Active {
 		return errors.New("Active enumeration cannot be performed without DNS resolution")
 	}
+	for k, v := range c.RecordMetadata {
+		if k == "" || strings.ContainsAny(k, "=\t") || strings.Contains(v, "\t") {
+			return fmt.Errorf("The record metadata key-value pair %q=%q is not valid", k, v)
+		}
+	}
+	for _, bl := range c.Blacklist {
+		if _, _, _, err := parseBlacklistEntry(bl); err != nil {
+			return err
+		}
+	}
 	if c.Alterations {
 		if len(c.AltWordlist) == 0 {
 			c.AltWordlist, err = getWordlistByFS("/alterations.txt")
@@ -239,9 +572,10 @@ func (c *Config) AddDomain(domain string) {
 	c.Lock()
 	defer c.Unlock()
 
-	// Check that the domain string is not empty
-	d := strings.TrimSpace(domain)
-	if d == "" {
+	// Check that the domain string is not empty, while internationalized domains are
+	// kept in the punycode form
+	d, err := dns.NormalizeName(domain)
+	if err != nil || d == "" {
 		return
 	}
 	// Check that it is a domain with at least two labels
@@ -269,6 +603,7 @@ func (c *Config) AddDomain(domain string) {
 	}
 
 	c.domains = stringset.Deduplicate(c.domains)
+	c.domainsVersion++
 }
 
 // Domains returns the list of domain names currently in the configuration.
@@ -281,68 +616,197 @@ func (c *Config) Domains() []string {
 
 // IsDomainInScope returns true if the DNS name in the parameter ends with a domain in the config list.
 func (c *Config) IsDomainInScope(name string) bool {
-	var discovered bool
+	domains, _ := c.nameScope().Match(dns.Canonical(name))
 
-	n := strings.ToLower(strings.TrimSpace(name))
-	for _, d := range c.Domains() {
-		if n == d || strings.HasSuffix(n, "."+d) {
-			discovered = true
-			break
-		}
-	}
-	return discovered
+	return len(domains) > 0
 }
 
-// WhichDomain returns the domain in the config list that the DNS name in the parameter ends with.
+// WhichDomain returns the most specific domain in the config list that the DNS name in the parameter
+// ends with. The domains that are public suffixes are skipped, since the names belong to separate registrants.
 func (c *Config) WhichDomain(name string) string {
-	n := strings.TrimSpace(name)
+	domains, _ := c.nameScope().Match(dns.Canonical(name))
+	if len(domains) == 0 {
+		return ""
+	}
 
-	for _, d := range c.Domains() {
-		if n == d || strings.HasSuffix(n, "."+d) {
+	suffixes := c.Suffixes()
+	for i := len(domains) - 1; i >= 0; i-- {
+		if d := domains[i]; suffixes.PublicSuffix(d) != d {
 			return d
 		}
 	}
 	return ""
 }
 
+// Suffixes returns a SuffixList that derives the registered domains using the additional public
+// suffixes and the ICANN setting of the configuration.
+func (c *Config) Suffixes() *dns.SuffixList {
+	return dns.NewSuffixList(!c.ICANNSuffixesOnly, c.PublicSuffixes)
+}
+
+// CloudService returns the canonical name of the cloud service the CNAME target belongs to, using
+// the longest matching suffix, or an empty string when the target is not a known cloud service.
+func (c *Config) CloudService(target string) string {
+	name := dns.Canonical(target)
+
+	for name != "" {
+		if service, found := c.CloudServices[name]; found {
+			return service
+		}
+
+		i := strings.Index(name, ".")
+		if i == -1 {
+			break
+		}
+		name = name[i+1:]
+	}
+	return ""
+}
+
+// ClampTTL returns the record TTL in seconds limited to the configured range, and true when
+// the TTL was outside of the range.
+func (c *Config) ClampTTL(ttl int) (int, bool) {
+	if ttl < c.MinTTL {
+		return c.MinTTL, true
+	}
+	if c.MaxTTL > 0 && ttl > c.MaxTTL {
+		return c.MaxTTL, true
+	}
+	return ttl, false
+}
+
+// ReresolveInterval returns the time to wait before resolving a record of the type again, which
+// is the clamped record TTL unless shorter than the minimum interval configured for the type.
+func (c *Config) ReresolveInterval(rrtype string, ttl time.Duration) time.Duration {
+	if secs, clamped := c.ClampTTL(int(ttl / time.Second)); clamped {
+		ttl = time.Duration(secs) * time.Second
+	}
+
+	floor := c.MinReresolveInterval
+	if interval, found := c.ReresolveIntervals[strings.ToUpper(strings.TrimSpace(rrtype))]; found {
+		floor = interval
+	}
+
+	if ttl < floor {
+		return floor
+	}
+	return ttl
+}
+
 // IsAddressInScope returns true if the addr parameter matches provided network scope and when
-// no network scope has been set.
+// no network scope has been set. Addresses within the blacklisted netblocks are never in scope.
 func (c *Config) IsAddressInScope(addr string) bool {
 	ip := net.ParseIP(addr)
 	if ip == nil {
 		return false
 	}
 
-	if len(c.Addresses) == 0 && len(c.CIDRs) == 0 {
-		return true
+	scope, blacklist := c.networkScope()
+	if blacklist.Contains(ip) {
+		return false
+	}
+	return scope.Empty() || scope.Contains(ip)
+}
+
+// ReverseAllowed returns true if the IP version of the addr parameter is configured to trigger
+// the reverse DNS sweeps.
+func (c *Config) ReverseAllowed(addr string) bool {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return false
 	}
 
+	if ip.To4() != nil {
+		return c.ReverseIPv4
+	}
+	return c.ReverseIPv6
+}
+
+// AddressBlacklisted returns true if the addr parameter is within the blacklisted netblocks.
+func (c *Config) AddressBlacklisted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	_, blacklist := c.networkScope()
+	return blacklist.Contains(ip)
+}
+
+// networkScope returns the sets of in-scope and blacklisted addresses, which are rebuilt
+// when the addresses or netblocks have been modified.
+func (c *Config) networkScope() (*amassnet.CIDRSet, *amassnet.CIDRSet) {
+	c.addrScopeLock.Lock()
+	defer c.addrScopeLock.Unlock()
+
+	size := [3]int{len(c.Addresses), len(c.CIDRs), len(c.BlacklistedCIDRs)}
+	if c.addrScope != nil && size == c.addrScopeSize {
+		return c.addrScope, c.addrBlacklist
+	}
+
+	c.addrScope = amassnet.NewCIDRSet(c.CIDRs...)
 	for _, a := range c.Addresses {
-		if a.String() == ip.String() {
-			return true
+		c.addrScope.AddIP(a)
+	}
+	c.addrBlacklist = amassnet.NewCIDRSet(c.BlacklistedCIDRs...)
+	c.addrScopeSize = size
+	return c.addrScope, c.addrBlacklist
+}
+
+// Blacklisted returns true is the name in the parameter is within a subdomain name in the config blacklist,
+// below a '*.' wildcard entry, or matches a regular expression entry enclosed by slashes.
+func (c *Config) Blacklisted(name string) bool {
+	_, blacklisted := c.nameScope().Match(dns.Canonical(name))
+
+	return blacklisted
+}
+
+// SetDenylist compiles the regular expressions provided in the parameter and assigns them to the config denylist.
+func (c *Config) SetDenylist(patterns []string) error {
+	var res []*regexp.Regexp
+
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("Invalid denylist pattern %s: %v", p, err)
 		}
+		res = append(res, re)
 	}
 
-	for _, cidr := range c.CIDRs {
-		if cidr.Contains(ip) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.Denylist = patterns
+	c.denylist = res
+	return nil
+}
+
+// Denylisted returns true if the name in the parameter matches a regular expression in the config denylist.
+func (c *Config) Denylisted(name string) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	n := dns.Canonical(name)
+	for _, re := range c.denylist {
+		if re.MatchString(n) {
 			return true
 		}
 	}
 	return false
 }
 
-// Blacklisted returns true is the name in the parameter ends with a subdomain name in the config blacklist.
-func (c *Config) Blacklisted(name string) bool {
-	var resp bool
+// SkippedRecordType returns true if the records of the type, such as "SPF", are not processed.
+func (c *Config) SkippedRecordType(rrtype string) bool {
+	if c.SkipSPF && strings.EqualFold(rrtype, "SPF") {
+		return true
+	}
 
-	n := strings.TrimSpace(name)
-	for _, bl := range c.Blacklist {
-		if match := strings.HasSuffix(n, bl); match {
-			resp = true
-			break
+	for _, t := range c.SkipRecordTypes {
+		if strings.EqualFold(t, rrtype) {
+			return true
 		}
 	}
-	return resp
+	return false
 }
 
 // SetResolvers assigns the resolver names provided in the parameter to the list in the configuration.
@@ -419,10 +883,19 @@ func (c *Config) GetAPIKey(source string) *APIKey {
 
 // LoadSettings parses settings from an .ini file and assigns them to the Config.
 func (c *Config) LoadSettings(path string) error {
+	return c.loadSettings(path)
+}
+
+// LoadSettingsData parses settings from the contents of an .ini file and assigns them to the Config.
+func (c *Config) LoadSettingsData(data []byte) error {
+	return c.loadSettings(data)
+}
+
+func (c *Config) loadSettings(source interface{}) error {
 	cfg, err := ini.LoadSources(ini.LoadOptions{
 		Insensitive:  true,
 		AllowShadows: true,
-	}, path)
+	}, source)
 	if err != nil {
 		return fmt.Errorf("Failed to load the configuration file: %v", err)
 	}
@@ -440,27 +913,144 @@ func (c *Config) LoadSettings(path string) error {
 			c.Active = true
 		}
 	}
+	if f := strings.ToLower(c.LogFormat); f != "" && f != "text" && f != "json" {
+		return fmt.Errorf("The log format %q is not valid", c.LogFormat)
+	}
+	if c.LogLevel != "" && !requests.ValidLogLevel(c.LogLevel) {
+		return fmt.Errorf("The log level %q is not valid", c.LogLevel)
+	}
+	if c.MinTTL < 0 || c.MaxTTL < 0 || (c.MaxTTL > 0 && c.MaxTTL < c.MinTTL) {
+		return fmt.Errorf("The TTL range [%d, %d] is not valid", c.MinTTL, c.MaxTTL)
+	}
+	if types := cfg.Section(ini.DEFAULT_SECTION).Key("skip_record_types").String(); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+				c.SkipRecordTypes = append(c.SkipRecordTypes, t)
+			}
+		}
+	}
+	if c.MaxFanOut < 0 {
+		return fmt.Errorf("The maximum fan-out %d is not valid", c.MaxFanOut)
+	}
+	if p := strings.ToLower(c.FanOutPolicy); p != "" && p != "queue" && p != "drop" {
+		return fmt.Errorf("The fan-out policy %q is not valid", c.FanOutPolicy)
+	}
+	if p := strings.ToLower(c.WildcardCNAMEPolicy); p != "" && p != "store" && p != "collapse" {
+		return fmt.Errorf("The wildcard CNAME policy %q is not valid", c.WildcardCNAMEPolicy)
+	}
+	if p := strings.ToLower(c.SelfReferencePolicy); p != "" && p != "flag" && p != "drop" {
+		return fmt.Errorf("The self-reference policy %q is not valid", c.SelfReferencePolicy)
+	}
+	if c.WildcardCNAMEThreshold < 0 {
+		return fmt.Errorf("The wildcard CNAME threshold %d is not valid", c.WildcardCNAMEThreshold)
+	}
+	if names := cfg.Section(ini.DEFAULT_SECTION).Key("wildcard_cname_names").String(); names != "" {
+		for _, n := range strings.Split(names, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				c.WildcardCNAMENames = append(c.WildcardCNAMENames, n)
+			}
+		}
+	}
+	if p := strings.ToLower(c.TXTControlChars); p != "" && p != "strip" && p != "escape" {
+		return fmt.Errorf("The TXT control character handling %q is not valid", c.TXTControlChars)
+	}
 	// Load up all the DNS domain names
 	if domains, err := cfg.GetSection("domains"); err == nil {
 		for _, domain := range domains.Key("domain").ValueWithShadows() {
 			c.AddDomain(domain)
 		}
 	}
-	// Load up all the blacklisted subdomain names
+	// Load up all the blacklisted subdomain names and netblocks
 	if blacklisted, err := cfg.GetSection("blacklisted"); err == nil {
 		c.Blacklist = stringset.Deduplicate(blacklisted.Key("subdomain").ValueWithShadows())
+
+		if blacklisted.HasKey("cidr") {
+			var cidrs []*net.IPNet
+
+			for _, cidr := range blacklisted.Key("cidr").ValueWithShadows() {
+				_, ipnet, err := net.ParseCIDR(cidr)
+				if err != nil {
+					return err
+				}
+				cidrs = append(cidrs, ipnet)
+			}
+			c.BlacklistedCIDRs = amassnet.NewCIDRSet(cidrs...).Aggregate()
+		}
+	}
+	// Load up all the regular expressions for names that will not be stored
+	if denylist, err := cfg.GetSection("denylist"); err == nil {
+		if err := c.SetDenylist(stringset.Deduplicate(denylist.Key("pattern").ValueWithShadows())); err != nil {
+			return err
+		}
+	}
+	// Load up the additional suffixes treated as public
+	if suffixes, err := cfg.GetSection("public_suffixes"); err == nil {
+		for _, suffix := range suffixes.Key("suffix").ValueWithShadows() {
+			if suffix = dns.Canonical(suffix); suffix != "" {
+				c.PublicSuffixes = append(c.PublicSuffixes, suffix)
+			}
+		}
+		c.PublicSuffixes = stringset.Deduplicate(c.PublicSuffixes)
 	}
 	// Load up all the disabled data source names
 	if disabled, err := cfg.GetSection("disabled_data_sources"); err == nil {
 		c.SourceFilter.Sources = stringset.Deduplicate(disabled.Key("data_source").ValueWithShadows())
 		c.SourceFilter.Include = false
 	}
+	// Load up the canonical names for the cloud services targeted by CNAME records
+	if cloud, err := cfg.GetSection("cloud_services"); err == nil {
+		if c.CloudServices == nil {
+			c.CloudServices = make(map[string]string)
+		}
+
+		for _, key := range cloud.Keys() {
+			suffix := strings.Trim(strings.ToLower(strings.TrimSpace(key.Name())), ".")
+			if suffix == "" {
+				continue
+			}
+			// An empty name removes one of the default cloud services
+			if service := strings.TrimSpace(key.String()); service != "" {
+				c.CloudServices[suffix] = service
+			} else {
+				delete(c.CloudServices, suffix)
+			}
+		}
+	}
+	if err := c.loadReresolveSettings(cfg); err != nil {
+		return err
+	}
+	if err := c.loadCDNSettings(cfg); err != nil {
+		return err
+	}
 	// Load up all the Gremlin Server settings
 	if gremlin, err := cfg.GetSection("gremlin"); err == nil {
 		c.GremlinURL = gremlin.Key("url").String()
 		c.GremlinUser = gremlin.Key("username").String()
 		c.GremlinPass = gremlin.Key("password").String()
 	}
+	// Load up all the webhook notification settings
+	if notify, err := cfg.GetSection("notifications"); err == nil {
+		c.NotifyURL = notify.Key("url").String()
+		c.NotifyTemplateFile = notify.Key("template_file").String()
+		c.NotifySeverity = notify.Key("minimum_severity").String()
+		c.NotifySecret = notify.Key("secret").String()
+		c.NotifyRetries = notify.Key("retries").MustInt(3)
+	}
+	// Load up all the settings for the HTTP client used by the data sources
+	if client, err := cfg.GetSection("http"); err == nil {
+		c.HTTPProxy = client.Key("proxy").String()
+		c.HTTPMaxConnsPerHost = client.Key("max_conns_per_host").MustInt(0)
+		c.HTTPRetries = client.Key("retries").MustInt(0)
+		c.HTTPMaxResponseSize = client.Key("max_response_size").MustInt64(0)
+		c.HTTPTimeout = client.Key("timeout").MustInt(0)
+	}
+	// Load up all the syslog output settings
+	if syslog, err := cfg.GetSection("syslog"); err == nil {
+		c.SyslogNetwork = syslog.Key("network").String()
+		c.SyslogAddress = syslog.Key("address").String()
+		c.SyslogFacility = syslog.Key("facility").String()
+		c.SyslogFormat = syslog.Key("format").String()
+	}
 
 	if err := c.loadResolverSettings(cfg); err != nil {
 		return err
@@ -484,8 +1074,16 @@ func (c *Config) LoadSettings(path string) error {
 		"domains":               struct{}{},
 		"resolvers":             struct{}{},
 		"blacklisted":           struct{}{},
+		"denylist":              struct{}{},
 		"disabled_data_sources": struct{}{},
 		"gremlin":               struct{}{},
+		"syslog":                struct{}{},
+		"notifications":         struct{}{},
+		"cloud_services":        struct{}{},
+		"http":                  struct{}{},
+		"reresolution":          struct{}{},
+		"cdn":                   struct{}{},
+		"public_suffixes":       struct{}{},
 	}
 
 	for _, section := range cfg.Sections() {
@@ -494,6 +1092,10 @@ func (c *Config) LoadSettings(path string) error {
 		if _, skip := nonAPISections[name]; skip {
 			continue
 		}
+		// The child sections of the bruteforce section provide the label templates
+		if strings.HasPrefix(name, "bruteforce.") {
+			continue
+		}
 
 		key := new(APIKey)
 		// Parse the API key information and assign to the Config
@@ -504,6 +1106,31 @@ func (c *Config) LoadSettings(path string) error {
 	return nil
 }
 
+func (c *Config) loadReresolveSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("reresolution")
+	if err != nil {
+		return nil
+	}
+
+	if c.ReresolveIntervals == nil {
+		c.ReresolveIntervals = make(map[string]time.Duration)
+	}
+
+	for _, key := range sec.Keys() {
+		interval, err := time.ParseDuration(strings.TrimSpace(key.String()))
+		if err != nil || interval < 0 {
+			return fmt.Errorf("The reresolution interval %q for %s is not valid", key.String(), key.Name())
+		}
+
+		if name := strings.ToUpper(strings.TrimSpace(key.Name())); name == "MINIMUM" {
+			c.MinReresolveInterval = interval
+		} else {
+			c.ReresolveIntervals[name] = interval
+		}
+	}
+	return nil
+}
+
 func (c *Config) loadNetworkSettings(cfg *ini.File) error {
 	network, err := cfg.GetSection("network_settings")
 	if err != nil {
@@ -530,6 +1157,8 @@ func (c *Config) loadNetworkSettings(cfg *ini.File) error {
 			}
 			c.CIDRs = append(c.CIDRs, ipnet)
 		}
+		// Merge the overlapping and adjacent netblocks
+		c.CIDRs = amassnet.NewCIDRSet(c.CIDRs...).Aggregate()
 	}
 
 	if network.HasKey("asn") {
@@ -571,7 +1200,7 @@ func (c *Config) loadBruteForceSettings(cfg *ini.File) error {
 	}
 
 	c.Wordlist = stringset.Deduplicate(c.Wordlist)
-	return nil
+	return c.loadLabelTemplates(bruteforce)
 }
 
 func (c *Config) loadAlterationSettings(cfg *ini.File) error {
@@ -592,6 +1221,27 @@ func (c *Config) loadAlterationSettings(cfg *ini.File) error {
 	c.MinForWordFlip = alterations.Key("minimum_for_word_flip").MustInt(2)
 	c.EditDistance = alterations.Key("edit_distance").MustInt(1)
 
+	c.NumericMode = strings.ToLower(alterations.Key("numeric_mode").MustString("flip"))
+	switch c.NumericMode {
+	case "flip", "range", "adjacent":
+	default:
+		return fmt.Errorf("The alterations numeric_mode must be 'flip', 'range' or 'adjacent': %s", c.NumericMode)
+	}
+	c.NumericMin = alterations.Key("numeric_minimum").MustInt(0)
+	c.NumericMax = alterations.Key("numeric_maximum").MustInt(9)
+	if c.NumericMin < 0 || c.NumericMax < c.NumericMin {
+		return fmt.Errorf("The alterations numeric range is invalid: %d-%d", c.NumericMin, c.NumericMax)
+	}
+	c.NumericPadding = alterations.Key("numeric_padding").MustInt(0)
+	if c.NumericPadding < 0 || c.NumericPadding > 10 {
+		return fmt.Errorf("The alterations numeric_padding must be between 0 and 10: %d", c.NumericPadding)
+	}
+	c.NumericDistance = alterations.Key("numeric_distance").MustInt(1)
+	if c.NumericDistance < 1 {
+		return fmt.Errorf("The alterations numeric_distance must be greater than zero: %d", c.NumericDistance)
+	}
+	c.MaxNumericAlts = alterations.Key("maximum_numeric_alterations").MustInt(DefaultMaxNumericAlts)
+
 	if alterations.HasKey("wordlist_file") {
 		for _, wordlist := range alterations.Key("wordlist_file").ValueWithShadows() {
 			list, err := GetListFromFile(wordlist)
@@ -603,9 +1253,44 @@ func (c *Config) loadAlterationSettings(cfg *ini.File) error {
 	}
 
 	c.AltWordlist = stringset.Deduplicate(c.AltWordlist)
+
+	prefixes, err := loadAffixFiles(alterations, "add_prefixes")
+	if err != nil {
+		return err
+	}
+	c.AltPrefixes = prefixes
+
+	suffixes, err := loadAffixFiles(alterations, "add_suffixes")
+	if err != nil {
+		return err
+	}
+	c.AltSuffixes = suffixes
 	return nil
 }
 
+// loadAffixFiles returns the words from the files of the alterations setting, without the
+// hyphens joining the words to the labels, since both concatenations are generated.
+func loadAffixFiles(alterations *ini.Section, key string) ([]string, error) {
+	if !alterations.HasKey(key) {
+		return nil, nil
+	}
+
+	var words []string
+	for _, path := range alterations.Key(key).ValueWithShadows() {
+		list, err := GetListFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to load the file in the alterations %s setting: %s: %v", key, path, err)
+		}
+
+		for _, w := range list {
+			if w = strings.Trim(strings.ToLower(strings.TrimSpace(w)), "-"); w != "" {
+				words = append(words, w)
+			}
+		}
+	}
+	return stringset.Deduplicate(words), nil
+}
+
 func (c *Config) loadResolverSettings(cfg *ini.File) error {
 	sec, err := cfg.GetSection("resolvers")
 	if err != nil {
@@ -4,10 +4,15 @@
 package config
 
 import (
+	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestCheckSettings(t *testing.T) {
@@ -82,6 +87,36 @@ func TestAddDomains(t *testing.T) {
 	})
 }
 
+func TestInternationalizedDomainScope(t *testing.T) {
+	c := NewConfig()
+	c.AddDomain("münchen.example")
+
+	if domains := c.Domains(); len(domains) != 1 || domains[0] != "xn--mnchen-3ya.example" {
+		t.Errorf("Expected the domain in the punycode form, got %v", domains)
+	}
+	for _, name := range []string{"www.münchen.example", "www.xn--mnchen-3ya.example", "WWW.MÜNCHEN.example."} {
+		if !c.IsDomainInScope(name) {
+			t.Errorf("%s was considered out of scope", name)
+		}
+		if d := c.WhichDomain(name); d != "xn--mnchen-3ya.example" {
+			t.Errorf("%s: Expected the domain xn--mnchen-3ya.example, got %s", name, d)
+		}
+	}
+}
+
+func TestWhichDomainPublicSuffix(t *testing.T) {
+	c := NewConfig()
+	c.PublicSuffixes = []string{"example-cdn.net"}
+	c.AddDomains([]string{"example-cdn.net", "customer.example-cdn.net"})
+
+	if d := c.WhichDomain("www.customer.example-cdn.net"); d != "customer.example-cdn.net" {
+		t.Errorf("Expected the domain customer.example-cdn.net, got %s", d)
+	}
+	if d := c.WhichDomain("www.other.example-cdn.net"); d != "" {
+		t.Errorf("The name was matched with the public suffix %s", d)
+	}
+}
+
 func TestIsAddressInScope(t *testing.T) {
 	c := NewConfig()
 	example := "10.10.0.1"
@@ -91,6 +126,137 @@ func TestIsAddressInScope(t *testing.T) {
 	}
 }
 
+func TestReverseAllowed(t *testing.T) {
+	c := NewConfig()
+	if !c.ReverseAllowed("72.237.4.113") || !c.ReverseAllowed("2606:4700::6812:1a4d") {
+		t.Errorf("The reverse DNS sweeps were not allowed by default")
+	}
+
+	c.ReverseIPv6 = false
+	if !c.ReverseAllowed("72.237.4.113") {
+		t.Errorf("The IPv4 address was not allowed to trigger the reverse DNS sweeps")
+	}
+	if c.ReverseAllowed("2606:4700::6812:1a4d") {
+		t.Errorf("The IPv6 address was allowed to trigger the reverse DNS sweeps")
+	}
+	if c.ReverseAllowed("not an address") {
+		t.Errorf("The invalid address was allowed to trigger the reverse DNS sweeps")
+	}
+}
+
+func TestBlacklistedCIDRs(t *testing.T) {
+	c := NewConfig()
+
+	_, allowed, _ := net.ParseCIDR("10.0.0.0/16")
+	_, excluded, _ := net.ParseCIDR("10.0.5.0/24")
+	c.CIDRs = append(c.CIDRs, allowed)
+	c.BlacklistedCIDRs = append(c.BlacklistedCIDRs, excluded)
+
+	tests := []struct {
+		Address  string
+		Expected bool
+	}{
+		{"10.0.0.1", true},
+		{"10.0.5.1", false},
+		{"10.1.0.1", false},
+	}
+
+	for _, test := range tests {
+		if got := c.IsAddressInScope(test.Address); got != test.Expected {
+			t.Errorf("IsAddressInScope(%s) returned %t", test.Address, got)
+		}
+	}
+
+	// The scope must reflect addresses added after the previous checks
+	c.Addresses = append(c.Addresses, net.ParseIP("10.1.0.1"))
+	if !c.IsAddressInScope("10.1.0.1") {
+		t.Errorf("The address added to the scope was not found")
+	}
+	if !c.AddressBlacklisted("10.0.5.1") {
+		t.Errorf("The address within the blacklisted netblock was not identified")
+	}
+}
+
+func TestCloudService(t *testing.T) {
+	c := NewConfig()
+	c.CloudServices["web.core.windows.net"] = "azure-static-website"
+
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{"bucket.s3.amazonaws.com", "aws-s3"},
+		{"D111111ABCDEF8.CloudFront.net.", "aws-cloudfront"},
+		{"site.z13.web.core.windows.net", "azure-static-website"},
+		{"www.owasp.org", ""},
+		{"amazonaws.com", ""},
+	}
+
+	for _, test := range tests {
+		if service := c.CloudService(test.target); service != test.expected {
+			t.Errorf("CloudService(%q) returned %q, expected %q", test.target, service, test.expected)
+		}
+	}
+}
+
+func TestCDN(t *testing.T) {
+	c := NewConfig()
+	if err := c.AddCDN("examplecdn", "104.16.1.0/24"); err != nil {
+		t.Fatalf("AddCDN failed: %v", err)
+	}
+	if err := c.AddCDN("examplecdn", "not-a-cidr/24"); err == nil {
+		t.Errorf("AddCDN accepted an invalid range")
+	}
+
+	addrs := map[string]string{
+		"104.16.1.10":       "examplecdn",
+		"104.17.0.1":        "cloudflare",
+		"2606:4700::6810:1": "cloudflare",
+		"151.101.1.69":      "fastly",
+		"8.8.8.8":           "",
+	}
+	for addr, expected := range addrs {
+		if provider := c.CDNByAddress(addr); provider != expected {
+			t.Errorf("CDNByAddress(%q) returned %q, expected %q", addr, provider, expected)
+		}
+	}
+
+	names := map[string]string{
+		"e1234.a.akamaiedge.net.":          "akamai",
+		"www.owasp.org.cdn.cloudflare.net": "cloudflare",
+		"owasp.github.io":                  "",
+	}
+	for name, expected := range names {
+		if provider := c.CDNByName(name); provider != expected {
+			t.Errorf("CDNByName(%q) returned %q, expected %q", name, provider, expected)
+		}
+	}
+}
+
+func TestReresolveInterval(t *testing.T) {
+	c := NewConfig()
+	c.ReresolveIntervals["NS"] = 6 * time.Hour
+
+	tests := []struct {
+		rrtype   string
+		ttl      time.Duration
+		expected time.Duration
+	}{
+		{"NS", 60 * time.Second, 6 * time.Hour},
+		{"ns", 48 * time.Hour, 48 * time.Hour},
+		{"MX", time.Minute, 12 * time.Hour},
+		{"A", 5 * time.Second, DefaultMinReresolveInterval},
+		{"A", time.Hour, time.Hour},
+		{"A", 4000000000 * time.Second, DefaultMaxTTL * time.Second},
+	}
+
+	for _, test := range tests {
+		if interval := c.ReresolveInterval(test.rrtype, test.ttl); interval != test.expected {
+			t.Errorf("ReresolveInterval(%q, %v) returned %v, expected %v", test.rrtype, test.ttl, interval, test.expected)
+		}
+	}
+}
+
 func TestBlacklist(t *testing.T) {
 	c := NewConfig()
 	example := "owasp.org"
@@ -132,3 +298,82 @@ func TestLoadSettings(t *testing.T) {
 		t.Errorf("Config file failed to load.")
 	}
 }
+
+func TestSkippedRecordType(t *testing.T) {
+	c := NewConfig()
+	if c.SkippedRecordType("SPF") {
+		t.Errorf("The SPF records were skipped by default")
+	}
+
+	if err := c.LoadSettingsData([]byte("skip_spf = true\nskip_record_types = hinfo, NULL\n")); err != nil {
+		t.Fatalf("Failed to load the settings: %v", err)
+	}
+	for rrtype, expected := range map[string]bool{"SPF": true, "HINFO": true, "null": true, "TXT": false} {
+		if skipped := c.SkippedRecordType(rrtype); skipped != expected {
+			t.Errorf("SkippedRecordType(%q) returned %t, expected %t", rrtype, skipped, expected)
+		}
+	}
+}
+
+func TestNumericAlterationSettings(t *testing.T) {
+	c := NewConfig()
+	data := "[alterations]\nnumeric_mode = Range\nnumeric_minimum = 1\nnumeric_maximum = 4\nnumeric_padding = 2\nmaximum_numeric_alterations = 20\n"
+	if err := c.LoadSettingsData([]byte(data)); err != nil {
+		t.Fatalf("Failed to load the settings: %v", err)
+	}
+	if c.NumericMode != "range" || c.NumericMin != 1 || c.NumericMax != 4 ||
+		c.NumericPadding != 2 || c.NumericDistance != 1 || c.MaxNumericAlts != 20 {
+		t.Errorf("Unexpected numeric alteration settings: %s %d-%d padding %d distance %d maximum %d", c.NumericMode,
+			c.NumericMin, c.NumericMax, c.NumericPadding, c.NumericDistance, c.MaxNumericAlts)
+	}
+
+	for _, data := range []string{
+		"[alterations]\nnumeric_mode = random\n",
+		"[alterations]\nnumeric_minimum = 5\nnumeric_maximum = 4\n",
+		"[alterations]\nnumeric_padding = -1\n",
+		"[alterations]\nnumeric_distance = 0\n",
+	} {
+		if err := NewConfig().LoadSettingsData([]byte(data)); err == nil {
+			t.Errorf("The invalid settings were accepted: %q", data)
+		}
+	}
+}
+
+func TestAlterationAffixes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "affixes")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	prefixes := filepath.Join(dir, "prefixes.txt")
+	suffixes := filepath.Join(dir, "suffixes.txt")
+	if err := ioutil.WriteFile(prefixes, []byte("dev-\nStage\n\ninternal\n"), 0644); err != nil {
+		t.Fatalf("Failed to write the prefixes file: %v", err)
+	}
+	if err := ioutil.WriteFile(suffixes, []byte("-api\n-admin\n-old\n"), 0644); err != nil {
+		t.Fatalf("Failed to write the suffixes file: %v", err)
+	}
+
+	c := NewConfig()
+	data := fmt.Sprintf("[alterations]\nadd_words = false\nedit_distance = 0\nadd_prefixes = %s\nadd_suffixes = %s\n", prefixes, suffixes)
+	if err := c.LoadSettingsData([]byte(data)); err != nil {
+		t.Fatalf("Failed to load the settings: %v", err)
+	}
+
+	sort.Strings(c.AltPrefixes)
+	sort.Strings(c.AltSuffixes)
+	if expected := []string{"dev", "internal", "stage"}; !reflect.DeepEqual(c.AltPrefixes, expected) {
+		t.Errorf("Expected the prefixes %v, got %v", expected, c.AltPrefixes)
+	}
+	if expected := []string{"admin", "api", "old"}; !reflect.DeepEqual(c.AltSuffixes, expected) {
+		t.Errorf("Expected the suffixes %v, got %v", expected, c.AltSuffixes)
+	}
+	if c.AddWords || c.EditDistance != 0 {
+		t.Errorf("The other word alterations were not disabled independently")
+	}
+
+	if err := NewConfig().LoadSettingsData([]byte("[alterations]\nadd_prefixes = " + filepath.Join(dir, "missing.txt") + "\n")); err == nil {
+		t.Errorf("The missing prefixes file was accepted")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/stringset"
+	"github.com/go-ini/ini"
+)
+
+// DefaultMaxTemplateLabels is the maximum number of labels generated by a brute forcing template.
+const DefaultMaxTemplateLabels = 10000
+
+// LabelTemplate generates the brute forcing labels from the cross product of the ordered
+// component wordlists, such as the <service>-<env>-<region> naming convention.
+type LabelTemplate struct {
+	Name       string
+	Components [][]string
+
+	// The separators placed between the consecutive components
+	Separators []string
+}
+
+// Labels returns the labels of the template in the component order, until the maximum
+// number of labels is reached. A maximum of zero or less does not limit the labels.
+func (t *LabelTemplate) Labels(max int) []string {
+	if len(t.Components) == 0 {
+		return nil
+	}
+
+	labels := []string{""}
+	for i, words := range t.Components {
+		var sep string
+		if i > 0 && i-1 < len(t.Separators) {
+			sep = t.Separators[i-1]
+		}
+
+		var next []string
+	loop:
+		for _, prefix := range labels {
+			for _, w := range words {
+				if max > 0 && len(next) >= max {
+					break loop
+				}
+				if i == 0 {
+					next = append(next, w)
+				} else {
+					next = append(next, prefix+sep+w)
+				}
+			}
+		}
+		labels = next
+	}
+	return labels
+}
+
+// loadLabelTemplates reads the brute forcing templates from the child sections of the
+// bruteforce section. Each template lists the component wordlist files in order, along with
+// a single separator used between all the components or one separator for each pair.
+func (c *Config) loadLabelTemplates(bruteforce *ini.Section) error {
+	c.MaxTemplateLabels = bruteforce.Key("maximum_template_labels").MustInt(DefaultMaxTemplateLabels)
+
+	for _, sec := range bruteforce.ChildSections() {
+		t := &LabelTemplate{Name: strings.TrimPrefix(sec.Name(), bruteforce.Name()+".")}
+
+		for _, path := range sec.Key("component").ValueWithShadows() {
+			list, err := GetListFromFile(path)
+			if err != nil {
+				return fmt.Errorf("Unable to load the component file of the %s template: %s: %v", t.Name, path, err)
+			}
+
+			var words []string
+			seen := stringset.New()
+			for _, w := range list {
+				if w = strings.ToLower(strings.TrimSpace(w)); w != "" && !seen.Has(w) {
+					seen.Insert(w)
+					words = append(words, w)
+				}
+			}
+			t.Components = append(t.Components, words)
+		}
+		if len(t.Components) == 0 {
+			return fmt.Errorf("The %s template does not provide any component files", t.Name)
+		}
+
+		if sec.HasKey("separator") {
+			seps := sec.Key("separator").ValueWithShadows()
+
+			switch len(seps) {
+			case 1:
+				for i := 1; i < len(t.Components); i++ {
+					t.Separators = append(t.Separators, seps[0])
+				}
+			case len(t.Components) - 1:
+				t.Separators = seps
+			default:
+				return fmt.Errorf("The %s template must provide one separator or one for each pair of components", t.Name)
+			}
+		}
+
+		c.BruteTemplates = append(c.BruteTemplates, t)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLabelTemplateLabels(t *testing.T) {
+	tmpl := &LabelTemplate{
+		Name:       "service-env-region",
+		Components: [][]string{{"api", "web"}, {"dev", "prod"}, {"us"}},
+		Separators: []string{"-", "."},
+	}
+
+	expected := []string{"api-dev.us", "api-prod.us", "web-dev.us", "web-prod.us"}
+	if got := tmpl.Labels(0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the labels %v, got %v", expected, got)
+	}
+	// The cross product is bounded by the maximum
+	if got := tmpl.Labels(3); len(got) != 3 {
+		t.Errorf("Expected 3 labels within the maximum, got %v", got)
+	}
+}
+
+func TestLoadLabelTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, words := range map[string]string{
+		"services.txt": "api\nWeb\nAPI\napi \n",
+		"envs.txt":     "dev\nprod\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(words), 0644); err != nil {
+			t.Fatalf("Failed to write the component file: %v", err)
+		}
+	}
+
+	data := fmt.Sprintf("[bruteforce]\nenabled = true\nmaximum_template_labels = 100\n\n"+
+		"[bruteforce.service-env]\ncomponent = %s\ncomponent = %s\nseparator = -\n",
+		filepath.Join(dir, "services.txt"), filepath.Join(dir, "envs.txt"))
+
+	c := NewConfig()
+	if err := c.LoadSettingsData([]byte(data)); err != nil {
+		t.Fatalf("Failed to load the templates: %v", err)
+	}
+	if len(c.BruteTemplates) != 1 || c.BruteTemplates[0].Name != "service-env" {
+		t.Fatalf("Expected the service-env template, got %v", c.BruteTemplates)
+	}
+	if c.MaxTemplateLabels != 100 {
+		t.Errorf("Expected a maximum of 100 template labels, got %d", c.MaxTemplateLabels)
+	}
+
+	expected := []string{"api-dev", "api-prod", "web-dev", "web-prod"}
+	if got := c.BruteTemplates[0].Labels(c.MaxTemplateLabels); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the labels %v, got %v", expected, got)
+	}
+	if _, found := c.apikeys["bruteforce.service-env"]; found {
+		t.Errorf("The template section was loaded as an API key")
+	}
+}
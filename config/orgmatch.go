@@ -0,0 +1,216 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultOrgMatchThreshold is the minimum score of the ASN descriptions matching an organization name.
+const DefaultOrgMatchThreshold = 80
+
+// The legal entity suffixes and other tokens that do not identify the organization
+var orgStopTokens = map[string]struct{}{
+	"ag": {}, "as": {}, "asn": {}, "bv": {}, "co": {}, "company": {}, "corp": {},
+	"corporation": {}, "gmbh": {}, "inc": {}, "incorporated": {}, "limited": {}, "llc": {},
+	"llp": {}, "ltd": {}, "nv": {}, "plc": {}, "pty": {}, "sa": {}, "srl": {}, "the": {},
+}
+
+// ASNMatch is an autonomous system with a description matching an organization name,
+// along with the score of the match from 0 to 100.
+type ASNMatch struct {
+	ASN         int
+	Description string
+	CC          string
+	Score       int
+}
+
+// LookupASNsByOrg returns the autonomous systems with descriptions that match the organization
+// name with a score of at least the threshold, sorted by the score. Unlike LookupASNsByName,
+// the names are compared after case folding, punctuation stripping and the removal of the
+// legal entity suffixes, so the 'Example Corp' name matches 'EXAMPLE-CORP-AS'.
+func LookupASNsByOrg(org string, threshold int) ([]*ASNMatch, error) {
+	var matches []*ASNMatch
+
+	fsOnce.Do(openTheFS)
+
+	content, err := StatikFS.Open("/asnlist.txt")
+	if err != nil {
+		return matches, fmt.Errorf("Failed to obtain the embedded ASN information: asnlist.txt: %v", err)
+	}
+	defer content.Close()
+
+	query := orgTokens(org)
+	scanner := bufio.NewScanner(content)
+	for scanner.Scan() {
+		asn, desc, cc, ok := parseASNListLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		if score := orgMatchScore(query, orgTokens(desc)); score >= threshold {
+			matches = append(matches, &ASNMatch{
+				ASN:         asn,
+				Description: desc,
+				CC:          cc,
+				Score:       score,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return matches, fmt.Errorf("Failed to read the embedded ASN information: asnlist.txt: %v", err)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches, nil
+}
+
+// parseASNListLine splits the 'ASN,DESCRIPTION, CC' line of the embedded ASN list.
+func parseASNListLine(line string) (int, string, string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(line), ",", 2)
+	if len(parts) != 2 {
+		return 0, "", "", false
+	}
+
+	asn, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", "", false
+	}
+
+	desc := strings.TrimSpace(parts[1])
+	var cc string
+	// The country code follows the last comma of the description
+	if i := strings.LastIndex(desc, ","); i != -1 {
+		cc = strings.TrimSpace(desc[i+1:])
+		desc = strings.TrimSpace(desc[:i])
+	}
+	return asn, desc, cc, true
+}
+
+// orgTokens returns the distinct lowercase tokens of the organization name, without the
+// punctuation and the legal entity suffixes. The tokens are only kept when all are suffixes.
+func orgTokens(name string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens, all []string
+	seen := make(map[string]struct{})
+	for _, f := range fields {
+		if _, found := seen[f]; found {
+			continue
+		}
+		seen[f] = struct{}{}
+
+		all = append(all, f)
+		if _, stop := orgStopTokens[f]; !stop {
+			tokens = append(tokens, f)
+		}
+	}
+	if len(tokens) == 0 {
+		tokens = all
+	}
+
+	sort.Strings(tokens)
+	return tokens
+}
+
+// orgMatchScore returns the similarity of the sorted organization name tokens from 0 to 100.
+// The score is the average of the token set ratio, which is high when the tokens of one name
+// are found in the other, and the ratio of all the tokens, which penalizes the extra tokens.
+func orgMatchScore(a, b []string) int {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	var common, onlyA, onlyB []string
+	inB := make(map[string]struct{}, len(b))
+	for _, t := range b {
+		inB[t] = struct{}{}
+	}
+	inA := make(map[string]struct{}, len(a))
+	for _, t := range a {
+		inA[t] = struct{}{}
+		if _, found := inB[t]; found {
+			common = append(common, t)
+		} else {
+			onlyA = append(onlyA, t)
+		}
+	}
+	for _, t := range b {
+		if _, found := inA[t]; !found {
+			onlyB = append(onlyB, t)
+		}
+	}
+
+	base := strings.Join(common, " ")
+	withA := strings.TrimSpace(base + " " + strings.Join(onlyA, " "))
+	withB := strings.TrimSpace(base + " " + strings.Join(onlyB, " "))
+
+	set := similarity(withA, withB)
+	if base != "" {
+		if s := similarity(base, withA); s > set {
+			set = s
+		}
+		if s := similarity(base, withB); s > set {
+			set = s
+		}
+	}
+
+	sorted := similarity(strings.Join(a, " "), strings.Join(b, " "))
+	return int((set+sorted)/2 + 0.5)
+}
+
+// similarity returns the ratio from 0 to 100 based on the edit distance between the strings.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 100
+	}
+	return 100 * float64(longest-levenshtein(ra, rb)) / float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
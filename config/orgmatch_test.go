@@ -0,0 +1,56 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrgTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"Example Corp", []string{"example"}},
+		{"EXAMPLE-CORP-AS", []string{"example"}},
+		{"Example Corporation Pty. Ltd.", []string{"example"}},
+		{"The Example Widget Co., Inc.", []string{"example", "widget"}},
+		{"Corp Inc", []string{"corp", "inc"}},
+	}
+
+	for _, test := range tests {
+		if got := orgTokens(test.name); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: Expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestOrgMatchScore(t *testing.T) {
+	query := orgTokens("Example Corp")
+
+	for _, desc := range []string{"EXAMPLE-CORP-AS", "Example Corporation Pty Ltd", "EXAMPLE-AS - Example, Inc."} {
+		if score := orgMatchScore(query, orgTokens(desc)); score != 100 {
+			t.Errorf("%q: Expected a score of 100, got %d", desc, score)
+		}
+	}
+
+	// Unrelated organizations containing the token score below the default threshold
+	for _, desc := range []string{"EXAMPLE-FOODS", "Counter Example Networks", "AMPLE-AS"} {
+		if score := orgMatchScore(query, orgTokens(desc)); score >= DefaultOrgMatchThreshold {
+			t.Errorf("%q: Expected a score below %d, got %d", desc, DefaultOrgMatchThreshold, score)
+		}
+	}
+}
+
+func TestParseASNListLine(t *testing.T) {
+	asn, desc, cc, ok := parseASNListLine("15169,GOOGLE - Google LLC, US")
+	if !ok || asn != 15169 || desc != "GOOGLE - Google LLC" || cc != "US" {
+		t.Errorf("Unexpected values: %d, %q, %q, %t", asn, desc, cc, ok)
+	}
+
+	if _, _, _, ok := parseASNListLine("not an ASN"); ok {
+		t.Errorf("The invalid line was parsed")
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/net/dns"
+)
+
+// domainTrie holds the in-scope domains and the blacklisted subdomains keyed by the labels in
+// reverse order, so the names are matched on label boundaries in a single walk.
+type domainTrie struct {
+	root *trieNode
+	// The regular expressions matching the blacklisted names
+	patterns []*regexp.Regexp
+}
+
+type trieNode struct {
+	children map[string]*trieNode
+	// The domain when this node ends an in-scope domain
+	domain      string
+	blacklisted bool
+	// Set when only the names below the node are blacklisted
+	wildcard bool
+}
+
+func newDomainTrie() *domainTrie {
+	return &domainTrie{root: new(trieNode)}
+}
+
+func (t *domainTrie) node(name string) *trieNode {
+	labels := strings.Split(name, ".")
+
+	n := t.root
+	for i := len(labels) - 1; i >= 0; i-- {
+		if n.children == nil {
+			n.children = make(map[string]*trieNode)
+		}
+
+		next, found := n.children[labels[i]]
+		if !found {
+			next = new(trieNode)
+			n.children[labels[i]] = next
+		}
+		n = next
+	}
+	return n
+}
+
+// AddDomain inserts the canonical domain into the trie as in scope.
+func (t *domainTrie) AddDomain(domain string) {
+	if domain != "" {
+		t.node(domain).domain = domain
+	}
+}
+
+// AddBlacklisted inserts the canonical name into the trie as a blacklisted subtree. When the
+// wildcard parameter is true, the name itself is not blacklisted, only the names below it.
+func (t *domainTrie) AddBlacklisted(name string, wildcard bool) {
+	if name == "" {
+		return
+	}
+
+	if wildcard {
+		t.node(name).wildcard = true
+	} else {
+		t.node(name).blacklisted = true
+	}
+}
+
+// AddBlacklistPattern adds the regular expression matching blacklisted names to the trie.
+func (t *domainTrie) AddBlacklistPattern(re *regexp.Regexp) {
+	if re != nil {
+		t.patterns = append(t.patterns, re)
+	}
+}
+
+// Match walks the trie with the canonical name and returns the in-scope domains the name
+// belongs to, ordered from the least specific, and whether the name is blacklisted.
+func (t *domainTrie) Match(name string) ([]string, bool) {
+	var domains []string
+	var blacklisted bool
+
+	n := t.root
+	for end := len(name); end > 0 && n != nil; {
+		start := strings.LastIndexByte(name[:end], '.') + 1
+
+		n = n.children[name[start:end]]
+		if n == nil {
+			break
+		}
+		if n.domain != "" {
+			domains = append(domains, n.domain)
+		}
+		// The wildcard only matches when labels remain to the left of the node
+		if n.blacklisted || (n.wildcard && start > 0) {
+			blacklisted = true
+		}
+		end = start - 1
+	}
+
+	for i := 0; !blacklisted && i < len(t.patterns); i++ {
+		blacklisted = t.patterns[i].MatchString(name)
+	}
+	return domains, blacklisted
+}
+
+// parseBlacklistEntry returns the canonical name of the blacklist entry and whether the entry
+// is a '*.' wildcard, or the regular expression when the entry is enclosed by slashes.
+func parseBlacklistEntry(entry string) (string, bool, *regexp.Regexp, error) {
+	entry = strings.TrimSpace(entry)
+
+	if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+		re, err := regexp.Compile(entry[1 : len(entry)-1])
+		if err != nil {
+			return "", false, nil, fmt.Errorf("Invalid blacklist pattern %s: %v", entry, err)
+		}
+		return "", false, re, nil
+	}
+
+	if strings.HasPrefix(entry, "*.") {
+		return dns.Canonical(entry[2:]), true, nil, nil
+	}
+	return dns.Canonical(entry), false, nil, nil
+}
+
+// nameScope returns the trie built from the domains and the blacklist, which is rebuilt
+// when either has been modified. The contents of the blacklist are compared, since the
+// exported field can be assigned or modified in place without changing its length.
+func (c *Config) nameScope() *domainTrie {
+	c.Lock()
+	domains, version := c.domains, c.domainsVersion
+	c.Unlock()
+
+	c.nameScopeLock.Lock()
+	defer c.nameScopeLock.Unlock()
+
+	if c.scope != nil && version == c.scopeVersion && sameStrings(c.Blacklist, c.scopeBlacklist) {
+		return c.scope
+	}
+
+	c.scope = newDomainTrie()
+	for _, d := range domains {
+		c.scope.AddDomain(d)
+	}
+	for _, bl := range c.Blacklist {
+		// The invalid patterns are reported by CheckSettings
+		name, wildcard, re, err := parseBlacklistEntry(bl)
+		if err != nil {
+			continue
+		}
+
+		if re != nil {
+			c.scope.AddBlacklistPattern(re)
+		} else {
+			c.scope.AddBlacklisted(name, wildcard)
+		}
+	}
+	c.scopeVersion = version
+	c.scopeBlacklist = append([]string(nil), c.Blacklist...)
+	return c.scope
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
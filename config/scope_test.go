@@ -0,0 +1,161 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/net/dns"
+)
+
+func TestNameScopeBoundaries(t *testing.T) {
+	c := NewConfig()
+	c.AddDomains([]string{"example.com", "corp.example.com", "owasp.org"})
+	c.Blacklist = []string{"dev.owasp.org"}
+
+	tests := []struct {
+		name        string
+		domain      string
+		blacklisted bool
+	}{
+		{"example.com", "example.com", false},
+		{"www.example.com", "example.com", false},
+		{"WWW.Example.COM.", "example.com", false},
+		{"notexample.com", "", false},
+		{"example.com.evil.net", "", false},
+		{"com", "", false},
+		{"host.corp.example.com", "corp.example.com", false},
+		{"host.notcorp.example.com", "example.com", false},
+		{"dev.owasp.org", "owasp.org", true},
+		{"www.dev.owasp.org", "owasp.org", true},
+		{"mydev.owasp.org", "owasp.org", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		if d := c.WhichDomain(test.name); d != test.domain {
+			t.Errorf("WhichDomain(%q) returned %q, expected %q", test.name, d, test.domain)
+		}
+		if in := c.IsDomainInScope(test.name); in != (test.domain != "") {
+			t.Errorf("IsDomainInScope(%q) returned %t", test.name, in)
+		}
+		if bl := c.Blacklisted(test.name); bl != test.blacklisted {
+			t.Errorf("Blacklisted(%q) returned %t, expected %t", test.name, bl, test.blacklisted)
+		}
+	}
+
+	// The trie is rebuilt once the domains change
+	c.AddDomain("notexample.com")
+	if d := c.WhichDomain("www.notexample.com"); d != "notexample.com" {
+		t.Errorf("The added domain was not matched, got %q", d)
+	}
+}
+
+func TestNameScopeBlacklistChanges(t *testing.T) {
+	c := NewConfig()
+	c.AddDomain("owasp.org")
+	c.Blacklist = []string{"dev.owasp.org"}
+
+	if !c.Blacklisted("dev.owasp.org") {
+		t.Errorf("The blacklisted name was not matched")
+	}
+
+	// Assigning a blacklist of the same length rebuilds the trie
+	c.Blacklist = []string{"qa.owasp.org"}
+	if c.Blacklisted("dev.owasp.org") || !c.Blacklisted("qa.owasp.org") {
+		t.Errorf("The assigned blacklist was not matched")
+	}
+
+	// Modifying the blacklist in place rebuilds the trie
+	c.Blacklist[0] = "test.owasp.org"
+	if c.Blacklisted("qa.owasp.org") || !c.Blacklisted("test.owasp.org") {
+		t.Errorf("The modified blacklist was not matched")
+	}
+
+	c.Blacklist = nil
+	if c.Blacklisted("test.owasp.org") {
+		t.Errorf("The name was blacklisted after the blacklist was cleared")
+	}
+}
+
+func TestBlacklistEntries(t *testing.T) {
+	c := NewConfig()
+	c.AddDomain("owasp.org")
+	c.Blacklist = []string{"Admin.OWASP.org.", "*.dev.owasp.org", `/^(qa|test)\d*\.owasp\.org$/`}
+
+	tests := []struct {
+		name        string
+		blacklisted bool
+	}{
+		{"admin.owasp.org", true},
+		{"vpn.admin.owasp.org", true},
+		{"dev.owasp.org", false},
+		{"api.dev.owasp.org", true},
+		{"a.b.dev.owasp.org", true},
+		{"mydev.owasp.org", false},
+		{"qa.owasp.org", true},
+		{"test12.owasp.org", true},
+		{"www.test12.owasp.org", false},
+	}
+
+	for _, test := range tests {
+		if bl := c.Blacklisted(test.name); bl != test.blacklisted {
+			t.Errorf("Blacklisted(%q) returned %t, expected %t", test.name, bl, test.blacklisted)
+		}
+	}
+
+	// The invalid patterns are reported by the settings check
+	c.Blacklist = append(c.Blacklist, "/[a-z/")
+	if err := c.CheckSettings(); err == nil {
+		t.Errorf("CheckSettings did not report the invalid blacklist pattern")
+	}
+}
+
+func benchmarkScope() (*Config, []string) {
+	c := NewConfig()
+
+	var names []string
+	for i := 0; i < 1000; i++ {
+		c.AddDomain(fmt.Sprintf("domain%d.example", i))
+		names = append(names, fmt.Sprintf("www.host%d.domain%d.example", i, (i*7)%1000))
+	}
+	names = append(names, "www.outofscope.example")
+	return c, names
+}
+
+func BenchmarkWhichDomainTrie(b *testing.B) {
+	c, names := benchmarkScope()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = c.WhichDomain(names[i%len(names)])
+	}
+}
+
+func BenchmarkWhichDomainLinear(b *testing.B) {
+	c, names := benchmarkScope()
+	domains := c.Domains()
+
+	// The linear scan with suffix comparisons replaced by the trie
+	linear := func(name string) string {
+		n := dns.Canonical(name)
+
+		for _, d := range domains {
+			if dns.PublicSuffix(d) == d {
+				continue
+			}
+			if n == d || strings.HasSuffix(n, "."+d) {
+				return d
+			}
+		}
+		return ""
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = linear(names[i%len(names)])
+	}
+}
@@ -165,15 +165,17 @@ func getWordlistByFS(path string) ([]string, error) {
 func getWordList(reader io.Reader) ([]string, error) {
 	var words []string
 
+	seen := stringset.New()
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		// Get the next word in the list
+		// Get the next word in the list, keeping the order of the file
 		w := strings.TrimSpace(scanner.Text())
-		if err := scanner.Err(); err == nil && w != "" {
+		if err := scanner.Err(); err == nil && w != "" && !seen.Has(w) {
+			seen.Insert(w)
 			words = append(words, w)
 		}
 	}
-	return stringset.Deduplicate(words), nil
+	return words, nil
 }
 
 func uniqueIntAppend(s []int, e string) []int {
@@ -18,8 +18,9 @@ func (e *Enumeration) newAddress(req *requests.AddrRequest) {
 		return
 	}
 
-	// Is this address relevant to the enumeration?
-	if !e.hasAddress(req.Address) {
+	// Is this address relevant to the enumeration? The addresses only mentioned in the
+	// collected data are expanded when requested by the configuration
+	if !e.hasAddress(req.Address) && !(req.Incidental && e.Config.AggressiveExpansion) {
 		return
 	}
 
@@ -81,9 +82,9 @@ loop:
 			// Write the ASN information to the graph databases
 			e.dataMgr.ASNRequest(e.ctx, asn)
 
-			// Perform the reverse DNS sweep if the IP address is in scope
+			// Perform the reverse DNS sweep if the IP address is in scope and its IP version allows it
 			if e.Config.IsDomainInScope(req.Domain) {
-				if _, cidr, _ := net.ParseCIDR(asn.Prefix); cidr != nil {
+				if _, cidr, _ := net.ParseCIDR(asn.Prefix); cidr != nil && !req.NoReverse {
 					go e.reverseDNSSweep(req.Address, cidr)
 				}
 
@@ -107,16 +108,10 @@ func (e *Enumeration) asnRequestAllSources(req *requests.ASNRequest) {
 }
 
 func (e *Enumeration) addAddress(addr string) {
-	e.addrsLock.Lock()
-	defer e.addrsLock.Unlock()
-
-	e.addrs.Insert(strings.TrimSpace(addr))
+	e.addrs.InsertIfAbsent(strings.TrimSpace(addr))
 }
 
 func (e *Enumeration) hasAddress(addr string) bool {
-	e.addrsLock.Lock()
-	defer e.addrsLock.Unlock()
-
 	return e.addrs.Has(strings.TrimSpace(addr))
 }
 
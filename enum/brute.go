@@ -4,6 +4,7 @@
 package enum
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/OWASP/Amass/v3/requests"
@@ -66,6 +67,10 @@ func (e *Enumeration) bruteSendNewNames(req *requests.DNSRequest) {
 			Source: "Brute Forcing",
 		})
 	}
+
+	for _, r := range e.templates.names(subdomain, domain) {
+		e.newNameEvent(r)
+	}
 }
 
 func (e *Enumeration) performAlterations() {
@@ -97,7 +102,7 @@ func (e *Enumeration) executeAlts(req *requests.DNSRequest) {
 	names := stringset.New()
 
 	if e.Config.FlipNumbers {
-		names.InsertMany(e.altState.FlipNumbers(req.Name)...)
+		names.InsertMany(e.numericAlts.insert(e.altState.NumericAlterations(req.Name))...)
 	}
 	if e.Config.AddNumbers {
 		names.InsertMany(e.altState.AppendNumbers(req.Name)...)
@@ -112,6 +117,12 @@ func (e *Enumeration) executeAlts(req *requests.DNSRequest) {
 	if e.Config.EditDistance > 0 {
 		names.InsertMany(e.altState.FuzzyLabelSearches(req.Name)...)
 	}
+	if len(e.Config.AltPrefixes) > 0 {
+		names.InsertMany(e.altState.AddPrefixes(req.Name)...)
+	}
+	if len(e.Config.AltSuffixes) > 0 {
+		names.InsertMany(e.altState.AddSuffixes(req.Name)...)
+	}
 
 	for name := range names {
 		if !e.Config.IsDomainInScope(name) {
@@ -127,6 +138,35 @@ func (e *Enumeration) executeAlts(req *requests.DNSRequest) {
 	}
 }
 
+// alterationFeatures returns the alteration techniques enabled by the configuration.
+func (e *Enumeration) alterationFeatures() []string {
+	var features []string
+
+	if e.Config.FlipWords {
+		features = append(features, "flip_words")
+	}
+	if e.Config.FlipNumbers {
+		features = append(features, "flip_numbers ("+e.Config.NumericMode+")")
+	}
+	if e.Config.AddWords {
+		features = append(features, "add_words")
+	}
+	if e.Config.AddNumbers {
+		features = append(features, "add_numbers")
+	}
+	if e.Config.EditDistance > 0 {
+		features = append(features, fmt.Sprintf("edit_distance (%d)", e.Config.EditDistance))
+	}
+	if n := len(e.Config.AltPrefixes); n > 0 {
+		features = append(features, fmt.Sprintf("add_prefixes (%d words)", n))
+	}
+	if n := len(e.Config.AltSuffixes); n > 0 {
+		features = append(features, fmt.Sprintf("add_suffixes (%d words)", n))
+	}
+	// The names generated by the markov model are always sent
+	return append(features, "markov_model")
+}
+
 func (e *Enumeration) useMarkovModel(req *requests.DNSRequest) {
 	e.markovModel.Train(req.Name)
 
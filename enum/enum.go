@@ -6,12 +6,15 @@ package enum
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	alts "github.com/OWASP/Amass/v3/alterations"
 	"github.com/OWASP/Amass/v3/config"
 	eb "github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/format"
 	"github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/queue"
 	"github.com/OWASP/Amass/v3/requests"
@@ -22,7 +25,6 @@ import (
 
 // Filters contains the set of string filters required during an enumeration.
 type Filters struct {
-	NewNames      *stringset.StringFilter
 	Resolved      *stringset.StringFilter
 	NewAddrs      *stringset.StringFilter
 	SweepAddrs    *stringset.StringFilter
@@ -44,15 +46,20 @@ type Enumeration struct {
 	startedAlts bool
 	altQueue    *queue.Queue
 	moreAlts    chan struct{}
+	numericAlts *numericAlts
 
 	ctx context.Context
 
 	filters *Filters
 	dataMgr services.Service
 
+	// Normalizes, checks and deduplicates the new names once for the pipeline
+	nameFilter *services.NameFilter
+
 	startedBrute bool
 	bruteQueue   *queue.Queue
 	moreBrute    chan struct{}
+	templates    *bruteTemplates
 
 	srcsLock sync.Mutex
 	srcs     stringset.Set
@@ -65,8 +72,19 @@ type Enumeration struct {
 	logQueue *queue.Queue
 
 	// Broadcast channel that indicates no further writes to the output channel
-	done   chan struct{}
-	closed sync.Once
+	done         chan struct{}
+	closed       sync.Once
+	outputClosed sync.Once
+
+	// Closed when the work still running after the deadline and grace period is abandoned
+	abandoned   chan struct{}
+	abandonOnce sync.Once
+	outputLock  sync.Mutex
+	outputDone  bool
+
+	started   bool
+	startTime time.Time
+	found     int64
 
 	// Cache for the infrastructure data collected from online sources
 	netCache *net.ASNCache
@@ -75,8 +93,8 @@ type Enumeration struct {
 	subLock    sync.Mutex
 	subdomains map[string]int
 
-	addrsLock sync.Mutex
-	addrs     stringset.Set
+	// The addresses resolved for names within the scope
+	addrs *stringset.StringFilter
 
 	lastLock  sync.Mutex
 	last      time.Time
@@ -90,18 +108,66 @@ type Enumeration struct {
 
 	pro          interface{ Stop() }
 	profileStart sync.Once
+
+	// Sends the validated names to a syslog server when configured
+	syslog *format.SyslogSink
+}
+
+// Progress is a snapshot of the state of a running Enumeration.
+type Progress struct {
+	// The time the enumeration was started
+	Started time.Time
+
+	// The number of findings sent on the Results channel
+	Names int64
+
+	// The number of discovered names yet to be resolved
+	DNSNamesRemaining int64
+
+	// The average number of DNS queries performed each second during the last minute
+	DNSQueriesPerSec int64
+
+	// Indicate the phases of the enumeration that have begun
+	BruteForcing bool
+	Alterations  bool
+
+	// Done is true once the enumeration has been stopped or has completed
+	Done bool
+}
+
+// New returns an Enumeration using a LocalSystem built from the configuration, which is the
+// entry point for embedding Amass. The System can be shutdown through the Sys field after the
+// Results channel has been closed and the findings have been consumed.
+func New(cfg *config.Config) (*Enumeration, error) {
+	sys, err := services.NewLocalSystem(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	e := NewEnumeration(sys)
+	if e == nil {
+		sys.Shutdown()
+		return nil, errors.New("The system did not provide the Data Manager service")
+	}
+	return e, nil
 }
 
-// NewEnumeration returns an initialized Enumeration that has not been started yet.
+// NewEnumeration returns an initialized Enumeration that has not been started yet, using
+// the configuration of the System.
 func NewEnumeration(sys services.System) *Enumeration {
+	cfg := sys.Config()
+	if cfg == nil {
+		cfg = config.NewConfig()
+	}
+
+	bus := eb.NewEventBus(10000)
 	e := &Enumeration{
-		Config:   config.NewConfig(),
-		Bus:      eb.NewEventBus(10000),
+		Config:   cfg,
+		Bus:      bus,
 		Sys:      sys,
 		altQueue: new(queue.Queue),
 		moreAlts: make(chan struct{}, 10),
 		filters: &Filters{
-			NewNames:      stringset.NewStringFilter(),
 			Resolved:      stringset.NewStringFilter(),
 			NewAddrs:      stringset.NewStringFilter(),
 			SweepAddrs:    stringset.NewStringFilter(),
@@ -111,11 +177,12 @@ func NewEnumeration(sys services.System) *Enumeration {
 		bruteQueue:  new(queue.Queue),
 		moreBrute:   make(chan struct{}, 10),
 		srcs:        stringset.New(),
-		addrs:       stringset.New(),
+		addrs:       stringset.NewStringFilter(),
 		Output:      make(chan *requests.Output, 1000),
 		outputQueue: new(queue.Queue),
 		logQueue:    new(queue.Queue),
 		done:        make(chan struct{}),
+		abandoned:   make(chan struct{}),
 		netCache:    net.NewASNCache(),
 		netQueue:    new(queue.Queue),
 		subdomains:  make(map[string]int),
@@ -147,13 +214,121 @@ func (e *Enumeration) Done() {
 	})
 }
 
-// Start begins the DNS enumeration process for the Amass Enumeration object.
-func (e *Enumeration) Start() error {
+// Stop ends the enumeration, and the Results channel is closed once the remaining findings
+// have been sent. Stop can be called multiple times and before the enumeration is started.
+func (e *Enumeration) Stop() {
+	e.Done()
+}
+
+// Results returns the channel that streams the validated findings of the enumeration. The
+// channel is closed when the enumeration ends, including when Start returns an error.
+func (e *Enumeration) Results() <-chan *requests.Output {
+	return e.Output
+}
+
+// Progress returns a snapshot of the state of the enumeration.
+func (e *Enumeration) Progress() Progress {
+	persec, _ := e.dnsQueriesPerSec()
+	p := Progress{
+		Names:             atomic.LoadInt64(&e.found),
+		DNSNamesRemaining: e.DNSNamesRemaining(),
+		DNSQueriesPerSec:  persec,
+	}
+
+	e.Lock()
+	p.Started = e.startTime
+	p.BruteForcing = e.startedBrute
+	p.Alterations = e.startedAlts
+	e.Unlock()
+
+	select {
+	case <-e.done:
+		p.Done = true
+	default:
+	}
+	return p
+}
+
+// closeOutput safely closes the channel that receives the results.
+func (e *Enumeration) closeOutput() {
+	e.outputClosed.Do(func() {
+		e.outputLock.Lock()
+		defer e.outputLock.Unlock()
+
+		if e.Output != nil {
+			close(e.Output)
+		}
+		e.outputDone = true
+	})
+}
+
+// abandon closes the Results channel without waiting for the findings still being processed.
+func (e *Enumeration) abandon() {
+	e.abandonOnce.Do(func() {
+		close(e.abandoned)
+	})
+	e.closeOutput()
+}
+
+// sendResult sends the finding on the Results channel, and returns false once the channel
+// has been closed or the remaining work has been abandoned.
+func (e *Enumeration) sendResult(o *requests.Output) bool {
+	e.outputLock.Lock()
+	defer e.outputLock.Unlock()
+
+	if e.outputDone {
+		return false
+	}
+
+	select {
+	case e.Output <- o:
+		return true
+	case <-e.abandoned:
+		return false
+	}
+}
+
+// Start begins the DNS enumeration process for the Amass Enumeration object, and blocks until
+// the enumeration completes, is stopped or the context expires. An Enumeration can only be
+// started once.
+func (e *Enumeration) Start(ctx context.Context) error {
+	e.Lock()
+	if e.started {
+		e.Unlock()
+		return errors.New("The enumeration has already been started")
+	}
+	e.started = true
+	e.startTime = time.Now()
+	e.Unlock()
+
 	if e.Output == nil {
 		return errors.New("The enumeration did not have an output channel")
 	} else if err := e.Config.CheckSettings(); err != nil {
+		e.Done()
+		e.closeOutput()
 		return err
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	e.templates = newBruteTemplates(e.Config)
+	// The name filter checks the scope of the configuration that is final once started
+	e.nameFilter = services.NewNameFilter(e.Config, e.Bus)
+
+	if e.Config.SyslogAddress != "" {
+		sink, err := format.NewSyslogSink(e.Config.SyslogNetwork,
+			e.Config.SyslogAddress, e.Config.SyslogFacility, e.Config.SyslogFormat)
+		if err != nil {
+			e.Done()
+			e.closeOutput()
+			return err
+		}
+		e.syslog = sink
+	}
+	if qps := e.Config.MaxDNSQPS; qps > 0 && qps < resolvers.MinWildcardQPS {
+		e.log(requests.LogWarn, "The limit of %d DNS queries per second is below the %d needed to test for DNS wildcards in time",
+			qps, resolvers.MinWildcardQPS)
+	}
 
 	// Setup the stringset of included data sources
 	e.srcsLock.Lock()
@@ -175,9 +350,26 @@ func (e *Enumeration) Start() error {
 	e.altState = alts.NewState(e.Config.AltWordlist)
 	e.altState.MinForWordFlip = e.Config.MinForWordFlip
 	e.altState.EditDistance = e.Config.EditDistance
+	e.altState.NumericMode = e.Config.NumericMode
+	e.altState.NumericMin = e.Config.NumericMin
+	e.altState.NumericMax = e.Config.NumericMax
+	e.altState.NumericPadding = e.Config.NumericPadding
+	e.altState.NumericDistance = e.Config.NumericDistance
+	e.altState.MaxNumericAlts = e.Config.MaxNumericAlts
+	e.altState.PrefixWords = e.Config.AltPrefixes
+	e.altState.SuffixWords = e.Config.AltSuffixes
+	e.numericAlts = newNumericAlts()
+
+	// Setup the context used throughout the enumeration, which expires at the hard deadline
+	// derived from the config timeout and the deadline of the caller
+	if e.Config.Timeout > 0 {
+		var dcancel context.CancelFunc
 
-	// Setup the context used throughout the enumeration
-	ctx, cancel := context.WithCancel(context.Background())
+		deadline := e.startTime.Add(time.Duration(e.Config.Timeout) * time.Minute)
+		ctx, dcancel = context.WithDeadline(ctx, deadline)
+		defer dcancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
 	ctx = context.WithValue(ctx, requests.ContextConfig, e.Config)
 	e.ctx = context.WithValue(ctx, requests.ContextEventBus, e.Bus)
 
@@ -193,13 +385,6 @@ func (e *Enumeration) Start() error {
 	go e.submitProvidedNames(startChan)
 	go e.processOutput(endChan)
 
-	if e.Config.Timeout > 0 {
-		time.AfterFunc(time.Duration(e.Config.Timeout)*time.Minute, func() {
-			e.Config.Log.Printf("Enumeration exceeded provided timeout")
-			e.Done()
-		})
-	}
-
 	e.srcsLock.Lock()
 	for _, src := range e.Sys.DataSources() {
 		if !e.srcs.Has(src.String()) {
@@ -229,10 +414,23 @@ loop:
 		select {
 		case <-e.done:
 			break loop
+		case <-e.ctx.Done():
+			if e.ctx.Err() == context.DeadlineExceeded {
+				e.log(requests.LogWarn, "Enumeration exceeded provided timeout")
+			}
+			e.Done()
+			break loop
 		case <-startChan:
 			startDone++
 			if startDone == 2 {
-				time.Sleep(10 * time.Second)
+				// Allow the initial requests to be processed, unless the enumeration is stopped
+				t := time.NewTimer(10 * time.Second)
+				select {
+				case <-t.C:
+				case <-e.done:
+				case <-e.ctx.Done():
+				}
+				t.Stop()
 			}
 		case <-twoSec.C:
 			e.releaseAttempts()
@@ -249,7 +447,7 @@ loop:
 					pct = (float64(retries) / float64(sec)) * 100
 				}
 
-				e.Config.Log.Printf("Average DNS queries performed: %d/sec, Average retries required: %.2f%%", sec, pct)
+				e.log(requests.LogInfo, "Average DNS queries performed: %d/sec, Average retries required: %.2f%%", sec, pct)
 				e.clearPerSec()
 			}
 		}
@@ -257,9 +455,73 @@ loop:
 
 	twoSec.Stop()
 	perMin.Stop()
+	expired := e.ctx.Err() == context.DeadlineExceeded
 	cancel()
 	e.cleanEventBus()
-	<-endChan
+	if expired {
+		// The work still running after the deadline is abandoned once the grace period elapses
+		t := time.NewTimer(e.Config.TimeoutGrace)
+		select {
+		case <-endChan:
+		case <-t.C:
+			e.log(requests.LogWarn, "The enumeration did not finish within %s of the deadline", e.Config.TimeoutGrace)
+			e.abandon()
+		}
+		t.Stop()
+	} else {
+		<-endChan
+	}
+	if e.syslog != nil {
+		e.syslog.Close()
+		if dropped := e.syslog.Dropped(); dropped > 0 {
+			e.log(requests.LogWarn, "%d findings could not be sent to the syslog server", dropped)
+		}
+	}
+	if dialer := e.Sys.Dialer(); dialer != nil && dialer.Dials() > 0 {
+		e.log(requests.LogInfo, "%d active connections were started at an average of %.2f/sec",
+			dialer.Dials(), dialer.AverageRate())
+	}
+	if limiter := e.Sys.QueryLimiter(); limiter != nil && limiter.Queries() > 0 {
+		e.log(requests.LogInfo, "%d DNS queries were sent at an average of %.2f/sec and a peak of %d/sec",
+			limiter.Queries(), limiter.AverageRate(), limiter.PeakRate())
+	}
+	if invalid := e.nameFilter.Invalid(); invalid > 0 {
+		e.log(requests.LogInfo, "%d names were dropped for not being valid DNS hostnames", invalid)
+	}
+	if scope := e.nameFilter.OutOfScope(); scope > 0 {
+		e.log(requests.LogInfo, "%d names outside the scope were dropped before resolution", scope)
+	}
+	if bl := e.nameFilter.Blacklisted(); bl > 0 {
+		e.log(requests.LogInfo, "%d blacklisted names were dropped before resolution", bl)
+	}
+	e.logDuplicateNames()
+	e.logTemplateStats()
+	e.logNumericStats()
+	e.logEventStats()
+	if dms, ok := e.dataMgr.(*services.DataManagerService); ok {
+		if denied := dms.Denied(); denied > 0 {
+			e.log(requests.LogInfo, "%d names and records matching the denylist were dropped", denied)
+		}
+		if internal := dms.Internal(); internal > 0 {
+			e.log(requests.LogInfo, "%d reserved and private addresses were stored without further enumeration", internal)
+		}
+		if unauth := dms.Unauthenticated(); unauth > 0 {
+			e.log(requests.LogInfo, "%d names were not enumerated, since the records were not DNSSEC authenticated", unauth)
+		}
+		if empty := dms.EmptyDomain(); empty > 0 {
+			e.log(requests.LogInfo, "%d requests without a root domain name were dropped", empty)
+		}
+		if selfRefs := dms.SelfReferences(); selfRefs > 0 {
+			action := "flagged"
+			if strings.EqualFold(e.Config.SelfReferencePolicy, services.SelfReferenceDrop) {
+				action = "dropped"
+			}
+			e.log(requests.LogInfo, "%d records with data pointing to the owner name were %s", selfRefs, action)
+		}
+		if collapsed := dms.WildcardCNAMECollapsed(); collapsed > 0 {
+			e.log(requests.LogInfo, "%d names answered by wildcard CNAMEs were collapsed into the patterns", collapsed)
+		}
+	}
 	e.writeLogs(true)
 	return nil
 }
@@ -302,24 +564,29 @@ func (e *Enumeration) nextPhase(first bool) {
 	altsReady := !e.Config.Passive && e.Config.Alterations && !e.startedAlts
 
 	if bruteReady {
+		e.Lock()
 		e.startedBrute = true
+		e.Unlock()
 		go e.startBruteForcing()
 
 		for i := 0; i < 10; i++ {
 			e.moreBruteForcing()
 		}
 
-		e.Config.Log.Print("Starting DNS queries for brute forcing")
+		e.log(requests.LogInfo, "Starting DNS queries for brute forcing")
 		e.lastPhase = time.Now()
 	} else if altsReady {
+		e.Lock()
 		e.startedAlts = true
+		e.Unlock()
 		go e.performAlterations()
 
 		for i := 0; i < 100; i++ {
 			e.moreAlterations()
 		}
 
-		e.Config.Log.Print("Starting DNS queries for altered names")
+		e.log(requests.LogInfo, "Starting DNS queries for altered names")
+		e.log(requests.LogInfo, "Alteration features: %s", strings.Join(e.alterationFeatures(), ", "))
 		e.lastPhase = time.Now()
 	} else if !first && inactive && persec < 50 {
 		// End the enumeration!
@@ -409,10 +676,13 @@ func (e *Enumeration) setupEventBus() {
 	e.Bus.Subscribe(requests.SetActiveTopic, e.updateLastActive)
 	e.Bus.Subscribe(requests.ResolveCompleted, e.incQueriesPerSec)
 
-	e.Bus.Subscribe(requests.NewNameTopic, e.newNECallback)
+	e.Bus.Subscribe(requests.NewNameTopic, e.nameFilter.OnNewName)
+	e.Bus.Subscribe(requests.InvalidNameTopic, e.nameFilter.OnInvalidName)
+	e.Bus.Subscribe(requests.NameAcceptedTopic, e.newNECallback)
 
 	if !e.Config.Passive {
 		e.Bus.Subscribe(requests.NameResolvedTopic, e.newRNCallback)
+		e.Bus.Subscribe(requests.NameDoneTopic, e.nameDoneCallback)
 
 		e.Bus.Subscribe(requests.NewAddrTopic, e.newAddress)
 		e.Bus.Subscribe(requests.NewASNTopic, e.netCache.Update)
@@ -443,10 +713,13 @@ func (e *Enumeration) cleanEventBus() {
 	e.Bus.Unsubscribe(requests.SetActiveTopic, e.updateLastActive)
 	e.Bus.Unsubscribe(requests.ResolveCompleted, e.incQueriesPerSec)
 
-	e.Bus.Unsubscribe(requests.NewNameTopic, e.newNECallback)
+	e.Bus.Unsubscribe(requests.NewNameTopic, e.nameFilter.OnNewName)
+	e.Bus.Unsubscribe(requests.InvalidNameTopic, e.nameFilter.OnInvalidName)
+	e.Bus.Unsubscribe(requests.NameAcceptedTopic, e.newNECallback)
 
 	if !e.Config.Passive {
 		e.Bus.Unsubscribe(requests.NameResolvedTopic, e.newRNCallback)
+		e.Bus.Unsubscribe(requests.NameDoneTopic, e.nameDoneCallback)
 
 		e.Bus.Unsubscribe(requests.NewAddrTopic, e.newAddress)
 		e.Bus.Unsubscribe(requests.NewASNTopic, e.netCache.Update)
@@ -0,0 +1,367 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/graph/db"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/services"
+)
+
+const domainTest = "owasp.org"
+
+// testSystem is a System with a single stubbed data source and no DNS resolution.
+type testSystem struct {
+	cfg    *config.Config
+	graphs []*graph.Graph
+	core   []services.Service
+	srcs   []services.Service
+	client *amasshttp.Client
+}
+
+func (ts *testSystem) Config() *config.Config                 { return ts.cfg }
+func (ts *testSystem) Pool() resolvers.Resolver               { return nil }
+func (ts *testSystem) QueryLimiter() *resolvers.QueryLimiter  { return nil }
+func (ts *testSystem) Dialer() *amassnet.RateLimitedDialer    { return nil }
+func (ts *testSystem) PortChecker() *amassnet.PortChecker     { return nil }
+func (ts *testSystem) HTTPClient() *amasshttp.Client          { return ts.client }
+func (ts *testSystem) Suffixes() *amassdns.SuffixList         { return ts.cfg.Suffixes() }
+func (ts *testSystem) AddSource(srv services.Service) error   { return nil }
+func (ts *testSystem) AddAndStart(srv services.Service) error { return nil }
+func (ts *testSystem) DataSources() []services.Service        { return ts.srcs }
+func (ts *testSystem) CoreServices() []services.Service       { return ts.core }
+func (ts *testSystem) GraphDatabases() []*graph.Graph         { return ts.graphs }
+func (ts *testSystem) Shutdown() error                        { return nil }
+
+// stubSource is a data source that returns the same names for every domain.
+type stubSource struct {
+	services.BaseService
+
+	names []string
+}
+
+func newStubSource(sys services.System, names ...string) *stubSource {
+	s := &stubSource{names: names}
+
+	s.BaseService = *services.NewBaseService(s, "Stub", sys)
+	return s
+}
+
+// OnDNSRequest implements the Service interface.
+func (s *stubSource) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
+	for _, name := range s.names {
+		services.PublishName(ctx, &requests.DNSRequest{
+			Name:   name,
+			Domain: req.Domain,
+			Tag:    requests.API,
+			Source: s.String(),
+		})
+	}
+}
+
+func newTestEnumeration(t *testing.T, names ...string) *Enumeration {
+	cfg := config.NewConfig()
+	cfg.Passive = true
+	cfg.AddDomain(domainTest)
+
+	sys := &testSystem{
+		cfg:    cfg,
+		graphs: []*graph.Graph{graph.NewGraph(db.NewCayleyGraphMemory())},
+	}
+	sys.core = []services.Service{services.NewDataManagerService(sys)}
+
+	src := newStubSource(sys, names...)
+	if err := src.Start(); err != nil {
+		t.Fatalf("Failed to start the stubbed data source: %v", err)
+	}
+	sys.srcs = []services.Service{src}
+
+	e := NewEnumeration(sys)
+	if e == nil {
+		t.Fatalf("NewEnumeration returned nil")
+	}
+	return e
+}
+
+func TestIncidentalAddressExpansion(t *testing.T) {
+	for _, aggressive := range []bool{false, true} {
+		e := newTestEnumeration(t)
+		e.Config.AggressiveExpansion = aggressive
+
+		e.newAddress(&requests.AddrRequest{Address: "104.22.27.77", Domain: domainTest, Incidental: true})
+		if expanded := e.netQueue.Len() == 1; expanded != aggressive {
+			t.Errorf("aggressive %t: The incidental address was expanded: %t", aggressive, expanded)
+		}
+
+		// The addresses resolved from in-scope names are always expanded
+		queued := e.netQueue.Len()
+		e.addAddress("104.22.26.77")
+		e.newAddress(&requests.AddrRequest{Address: "104.22.26.77", Domain: domainTest})
+		if e.netQueue.Len() != queued+1 {
+			t.Errorf("aggressive %t: The resolved address was not expanded", aggressive)
+		}
+	}
+}
+
+func TestEnumerationResults(t *testing.T) {
+	e := newTestEnumeration(t, "www."+domainTest, "mail."+domainTest, "www.example.com")
+	defer e.Sys.DataSources()[0].Stop()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Start(context.Background())
+	}()
+
+	found := make(map[string]bool)
+	timeout := time.After(10 * time.Second)
+loop:
+	for len(found) < 2 {
+		select {
+		case out, ok := <-e.Results():
+			if !ok {
+				break loop
+			}
+			found[out.Name] = true
+		case <-timeout:
+			break loop
+		}
+	}
+
+	for _, name := range []string{"www." + domainTest, "mail." + domainTest} {
+		if !found[name] {
+			t.Errorf("The name %s was not sent on the Results channel", name)
+		}
+	}
+	if p := e.Progress(); p.Names != 2 || p.Started.IsZero() || p.Done {
+		t.Errorf("Unexpected progress before the enumeration was stopped: %+v", p)
+	}
+
+	// Stop must be idempotent
+	e.Stop()
+	e.Stop()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start returned an error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Start did not return after the enumeration was stopped")
+	}
+
+	// The channel must be closed once the enumeration ends
+	for out := range e.Results() {
+		if out.Name == "www.example.com" {
+			t.Errorf("The out of scope name %s was sent on the Results channel", out.Name)
+		}
+	}
+	if !e.Progress().Done {
+		t.Errorf("The progress did not report the enumeration as done")
+	}
+	if err := e.Start(context.Background()); err == nil {
+		t.Errorf("The enumeration was started a second time")
+	}
+}
+
+func TestEnumerationContext(t *testing.T) {
+	e := newTestEnumeration(t)
+	defer e.Sys.DataSources()[0].Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Start(ctx)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Start did not return after the context was cancelled")
+	}
+
+	select {
+	case _, ok := <-e.Results():
+		if ok {
+			t.Errorf("Unexpected finding after the context was cancelled")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("The Results channel was not closed")
+	}
+}
+
+func TestEnumerationStartError(t *testing.T) {
+	e := newTestEnumeration(t)
+	defer e.Sys.DataSources()[0].Stop()
+
+	// Brute forcing cannot be performed in passive mode
+	e.Config.BruteForcing = true
+	if err := e.Start(context.Background()); err == nil {
+		t.Fatalf("Start did not return the configuration error")
+	}
+
+	select {
+	case _, ok := <-e.Results():
+		if ok {
+			t.Errorf("Unexpected finding from the failed enumeration")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("The Results channel was not closed after Start failed")
+	}
+}
+
+// slowGraphDB is a graph database that takes the delay to insert nodes and to list the nodes.
+type slowGraphDB struct {
+	db.GraphDatabase
+
+	delay time.Duration
+}
+
+func (s *slowGraphDB) InsertNode(id, ntype string) (db.Node, error) {
+	time.Sleep(s.delay)
+	return s.GraphDatabase.InsertNode(id, ntype)
+}
+
+func (s *slowGraphDB) AllNodesOfType(ntype string, events ...string) ([]db.Node, error) {
+	time.Sleep(s.delay)
+	return s.GraphDatabase.AllNodesOfType(ntype, events...)
+}
+
+// hungSource is a data source whose web requests never receive a response.
+type hungSource struct {
+	services.BaseService
+
+	url     string
+	fetched chan error
+}
+
+// OnDNSRequest implements the Service interface.
+func (h *hungSource) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
+	_, err := h.System().HTTPClient().RequestWebPage(ctx, h.url, nil, nil, "", "")
+	h.fetched <- err
+}
+
+func TestEnumerationDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	e := newTestEnumeration(t, "www."+domainTest)
+	defer e.Sys.DataSources()[0].Stop()
+
+	sys := e.Sys.(*testSystem)
+	sys.graphs = []*graph.Graph{graph.NewGraph(&slowGraphDB{
+		GraphDatabase: db.NewCayleyGraphMemory(),
+		delay:         10 * time.Second,
+	})}
+
+	sys.client, _ = amasshttp.NewClient(amasshttp.ClientSettings{})
+	hung := &hungSource{url: srv.URL, fetched: make(chan error, 1)}
+	hung.BaseService = *services.NewBaseService(hung, "Hung", sys)
+	if err := hung.Start(); err != nil {
+		t.Fatalf("Failed to start the hung data source: %v", err)
+	}
+	defer hung.Stop()
+	sys.srcs = append(sys.srcs, hung)
+
+	deadline, grace := time.Second, time.Second
+	e.Config.TimeoutGrace = grace
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Start(ctx)
+	}()
+
+	limit := deadline + grace + time.Second
+	select {
+	case <-done:
+	case <-time.After(limit):
+		t.Fatalf("Start did not return within the deadline and grace period")
+	}
+
+	// The Results channel must be closed, even though the graph database is still busy
+	for range e.Results() {
+	}
+	if elapsed := time.Since(start); elapsed > limit {
+		t.Errorf("The enumeration ran for %s, past the deadline and grace period", elapsed)
+	}
+
+	select {
+	case err := <-hung.fetched:
+		if err == nil {
+			t.Errorf("The hung web request did not fail at the deadline")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("The hung web request was not cancelled at the deadline")
+	}
+}
+
+func TestActivityHeartbeat(t *testing.T) {
+	e := newTestEnumeration(t)
+	src := e.Sys.DataSources()[0]
+	defer src.Stop()
+
+	var lock sync.Mutex
+	var published int
+	e.Bus.Subscribe(requests.SetActiveTopic, e.updateLastActive)
+	e.Bus.Subscribe(requests.SetActiveTopic, func(srv string) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		published++
+	})
+	defer e.Bus.Stop()
+
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, e.Config)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, e.Bus)
+
+	// The data source reports the activity much more often than the ActivityInterval
+	active := 3 * services.ActivityInterval
+	for start := time.Now(); time.Since(start) < active; {
+		src.(*stubSource).SetActive(ctx)
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	lock.Lock()
+	n := published
+	lock.Unlock()
+	if max := int(active/services.ActivityInterval) + 1; n < 2 || n > max {
+		t.Errorf("Expected between 2 and %d activity publications, got %d", max, n)
+	}
+
+	// The idle detection still finds the enumeration active with the reduced heartbeat
+	last := e.lastActive()
+	if idle := time.Since(last); idle > 2*services.ActivityInterval {
+		t.Errorf("The enumeration was found idle for %s while the data source was active", idle)
+	}
+
+	// Once the activity ends, the enumeration becomes idle
+	time.Sleep(services.ActivityInterval)
+	if !e.lastActive().Equal(last) {
+		t.Errorf("The last activity was updated after the data source became idle")
+	}
+}
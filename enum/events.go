@@ -0,0 +1,74 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"sort"
+
+	eb "github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// EventStats summarizes the events lost and delayed by the event bus of the enumeration, where the
+// topics carrying the discovered data are distinguished from the housekeeping topics.
+type EventStats struct {
+	DataDropped         uint64                   `json:"data_dropped"`
+	HousekeepingDropped uint64                   `json:"housekeeping_dropped"`
+	Overflows           uint64                   `json:"overflows"`
+	Topics              map[string]eb.TopicStats `json:"topics"`
+}
+
+// Dropped returns true when any events were dropped or delayed by the event bus.
+func (s *EventStats) Dropped() bool {
+	return s.DataDropped > 0 || s.HousekeepingDropped > 0 || s.Overflows > 0
+}
+
+// EventStats returns the number of events dropped and delayed by the event bus.
+func (e *Enumeration) EventStats() *EventStats {
+	stats := &EventStats{Topics: e.Bus.Stats()}
+
+	for topic, s := range stats.Topics {
+		if requests.IsDataTopic(topic) {
+			stats.DataDropped += s.Dropped
+		} else {
+			stats.HousekeepingDropped += s.Dropped
+		}
+		stats.Overflows += s.Overflows
+	}
+	return stats
+}
+
+// logEventStats warns about the events dropped and delayed by the event bus, so the user knows
+// when data may have gone missing.
+func (e *Enumeration) logEventStats() {
+	stats := e.EventStats()
+	if !stats.Dropped() {
+		return
+	}
+
+	e.log(requests.LogWarn, "%d housekeeping events dropped; %d data events dropped",
+		stats.HousekeepingDropped, stats.DataDropped)
+	if stats.Overflows > 0 {
+		e.log(requests.LogWarn, "%d event deliveries waited for the saturated subscribers", stats.Overflows)
+	}
+
+	var topics []string
+	for topic := range stats.Topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	for _, topic := range topics {
+		s := stats.Topics[topic]
+		if s.Dropped == 0 && s.Overflows == 0 {
+			continue
+		}
+
+		kind := "housekeeping"
+		if requests.IsDataTopic(topic) {
+			kind = "data"
+		}
+		e.log(requests.LogWarn, "Topic %s (%s): %d dropped, %d overflows", topic, kind, s.Dropped, s.Overflows)
+	}
+}
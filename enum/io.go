@@ -4,12 +4,15 @@
 package enum
 
 import (
+	"fmt"
+	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/OWASP/Amass/v3/eventbus"
 	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
 	"github.com/OWASP/Amass/v3/stringset"
 )
 
@@ -28,7 +31,7 @@ func (e *Enumeration) submitKnownNames(c chan struct{}) {
 		for _, event := range events {
 			for _, output := range g.EventOutput(event, nil, nil) {
 				if e.Config.IsDomainInScope(output.Name) {
-					e.Bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+					services.PublishName(e.ctx, &requests.DNSRequest{
 						Name:   output.Name,
 						Domain: output.Domain,
 						Tag:    output.Tag,
@@ -39,13 +42,41 @@ func (e *Enumeration) submitKnownNames(c chan struct{}) {
 		}
 	}
 
+	// Resume the names that were pending when a previous enumeration was interrupted
+	if dms, ok := e.dataMgr.(*services.DataManagerService); ok {
+		if num := dms.ResumeFrontier(e.ctx); num > 0 {
+			e.log(requests.LogInfo, "Resuming %d names from the frontier file", num)
+		}
+
+		for _, path := range e.Config.ZoneFiles {
+			e.importZoneFile(dms, path)
+		}
+	}
+
 	c <- struct{}{}
 }
 
+func (e *Enumeration) importZoneFile(dms *services.DataManagerService, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		e.log(requests.LogError, "Failed to open the zone file: %v", err)
+		return
+	}
+	defer f.Close()
+
+	num, err := dms.ImportZoneFile(e.ctx, f, "", path)
+	if err != nil {
+		e.log(requests.LogError, "Failed to import the zone file %s: %v", path, err)
+	}
+	if num > 0 {
+		e.log(requests.LogInfo, "Imported %d names from the zone file %s", num, path)
+	}
+}
+
 func (e *Enumeration) submitProvidedNames(c chan struct{}) {
 	for _, name := range e.Config.ProvidedNames {
 		if domain := e.Config.WhichDomain(name); domain != "" {
-			e.Bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			services.PublishName(e.ctx, &requests.DNSRequest{
 				Name:   name,
 				Domain: domain,
 				Tag:    requests.EXTERNAL,
@@ -58,10 +89,10 @@ func (e *Enumeration) submitProvidedNames(c chan struct{}) {
 }
 
 func (e *Enumeration) namesFromCertificates(addr string) {
-	for _, name := range http.PullCertificateNames(addr, e.Config.Ports) {
+	for _, name := range http.PullCertificateNames(addr, e.Config.Ports, e.Sys.PortChecker()) {
 		if n := strings.TrimSpace(name); n != "" {
 			if domain := e.Config.WhichDomain(n); domain != "" {
-				e.Bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+				services.PublishName(e.ctx, &requests.DNSRequest{
 					Name:   n,
 					Domain: domain,
 					Tag:    requests.CERT,
@@ -73,7 +104,7 @@ func (e *Enumeration) namesFromCertificates(addr string) {
 }
 
 func (e *Enumeration) processOutput(c chan struct{}) {
-	defer close(e.Output)
+	defer e.closeOutput()
 	defer close(c)
 
 	curIdx := 0
@@ -139,7 +170,13 @@ func (e *Enumeration) emptyOutputQueue() bool {
 		sent = true
 		o := element.(*requests.Output)
 		if e.Config.IsDomainInScope(o.Name) && !e.filters.Output.Duplicate(o.Name) {
-			e.Output <- o
+			if e.syslog != nil {
+				e.syslog.Send("name", o)
+			}
+			atomic.AddInt64(&e.found, 1)
+			if !e.sendResult(o) {
+				return sent
+			}
 		}
 	}
 
@@ -155,8 +192,8 @@ func (e *Enumeration) sendOutput(o *requests.Output) {
 	}
 }
 
-func (e *Enumeration) queueLog(msg string) {
-	e.logQueue.Append(msg)
+func (e *Enumeration) queueLog(entry *requests.LogEntry) {
+	e.logQueue.Append(entry)
 }
 
 func (e *Enumeration) writeLogs(all bool) {
@@ -166,17 +203,40 @@ func (e *Enumeration) writeLogs(all bool) {
 	}
 
 	for i := 0; ; i++ {
-		msg, ok := e.logQueue.Next()
+		entry, ok := e.logQueue.Next()
 		if !ok {
 			break
 		}
 
-		if e.Config.Log != nil {
-			e.Config.Log.Print(msg.(string))
-		}
+		e.writeLog(entry.(*requests.LogEntry))
 
 		if !all && i >= num {
 			break
 		}
 	}
 }
+
+// log writes the message of the enumeration immediately, without going through the queue.
+func (e *Enumeration) log(level, format string, args ...interface{}) {
+	e.writeLog(requests.NewLogEntry(level, "", format, args...))
+}
+
+// writeLog encodes the entry as selected by the configuration, unless the level of the
+// entry is below the minimum level.
+func (e *Enumeration) writeLog(entry *requests.LogEntry) {
+	if e.Config.Log == nil || !requests.LogLevelEnabled(entry.Level, e.Config.LogLevel) {
+		return
+	}
+
+	le := *entry
+	if le.EventID == "" {
+		le.EventID = e.Config.UUID.String()
+	}
+
+	if strings.EqualFold(e.Config.LogFormat, "json") {
+		// The entries carry the timestamps, so the prefix of the logger is not used
+		fmt.Fprintln(e.Config.Log.Writer(), le.JSON())
+		return
+	}
+	e.Config.Log.Print(le.String())
+}
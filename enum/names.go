@@ -5,7 +5,7 @@ package enum
 
 import (
 	"net"
-	"strconv"
+	"sort"
 	"strings"
 
 	"github.com/OWASP/Amass/v3/eventbus"
@@ -13,6 +13,7 @@ import (
 	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/services"
 	"github.com/miekg/dns"
 )
 
@@ -32,44 +33,63 @@ var probeNames = []string{
 }
 
 func (e *Enumeration) newNECallback(req *requests.DNSRequest) {
-	go e.newNameEvent(req)
+	go e.acceptedName(req)
 }
 
+// newNameEvent handles the names generated by the enumeration, which are checked by the
+// name filter the same way as the names published on the NewNameTopic.
 func (e *Enumeration) newNameEvent(req *requests.DNSRequest) {
-	if req == nil || req.Name == "" || req.Domain == "" {
-		return
+	if e.nameFilter.Accept(req) {
+		e.acceptedName(req)
 	}
+}
 
-	req.Name = strings.ToLower(amassdns.RemoveAsteriskLabel(req.Name))
-	req.Name = strings.Trim(req.Name, ".")
-	req.Domain = strings.ToLower(req.Domain)
-
-	// Filter on the DNS name + the value from TrustedTag
-	if e.filters.NewNames.Duplicate(req.Name +
-		strconv.FormatBool(requests.TrustedTag(req.Tag))) {
+// acceptedName sends the name accepted by the name filter out for resolution.
+func (e *Enumeration) acceptedName(req *requests.DNSRequest) {
+	if req == nil || req.Name == "" || req.Domain == "" {
 		return
 	}
 
 	if e.Config.Passive {
 		e.updateLastActive("enum")
-		if e.Config.IsDomainInScope(req.Name) {
-			e.Bus.Publish(requests.OutputTopic, eventbus.PriorityLow, &requests.Output{
-				Name:   req.Name,
-				Domain: req.Domain,
-				Tag:    req.Tag,
-				Source: req.Source,
-			})
-		}
+		e.Bus.Publish(requests.OutputTopic, eventbus.PriorityLow, &requests.Output{
+			Name:   req.Name,
+			Domain: req.Domain,
+			Tag:    req.Tag,
+			Source: req.Source,
+		})
 		return
 	}
 
 	e.Bus.Publish(requests.ResolveNameTopic, eventbus.PriorityLow, e.ctx, req)
 }
 
+// logDuplicateNames reports the number of duplicate names dropped for each data source.
+func (e *Enumeration) logDuplicateNames() {
+	dups := e.nameFilter.Duplicates()
+
+	var sources []string
+	for src := range dups {
+		sources = append(sources, src)
+	}
+	sort.Strings(sources)
+
+	for _, src := range sources {
+		e.log(requests.LogInfo, "%s: %d duplicate names were dropped", src, dups[src])
+	}
+}
+
 func (e *Enumeration) newRNCallback(req *requests.DNSRequest) {
 	go e.newResolvedName(req)
 }
 
+func (e *Enumeration) nameDoneCallback(name string) {
+	// The name is no longer pending in the frontier of the data manager
+	if dms, ok := e.dataMgr.(*services.DataManagerService); ok {
+		dms.NameDone(e.ctx, name)
+	}
+}
+
 func (e *Enumeration) newResolvedName(req *requests.DNSRequest) {
 	req.Name = strings.ToLower(amassdns.RemoveAsteriskLabel(req.Name))
 	req.Name = strings.Trim(req.Name, ".")
@@ -77,8 +97,9 @@ func (e *Enumeration) newResolvedName(req *requests.DNSRequest) {
 
 	// Write the DNS name information to the graph databases
 	e.dataMgr.DNSRequest(e.ctx, req)
-	// Add addresses that are relevant to the enumeration
-	if !e.hasCNAMERecord(req) && e.hasARecords(req) {
+	// Add addresses that are relevant to the enumeration, which are the addresses resolved
+	// from the in-scope names
+	if !e.hasCNAMERecord(req) && e.hasARecords(req) && e.Config.IsDomainInScope(req.Name) {
 		for _, r := range req.Records {
 			t := uint16(r.Type)
 
@@ -93,6 +114,8 @@ func (e *Enumeration) newResolvedName(req *requests.DNSRequest) {
 	if e.filters.Resolved.Duplicate(req.Name) || !e.Config.IsDomainInScope(req.Name) {
 		return
 	}
+	e.templates.resolved(req)
+	e.numericAlts.resolved(req)
 	// Keep track of all domains and proper subdomains discovered
 	e.checkSubdomain(req)
 	// Send out some probe requests to help cause recursive brute forcing
@@ -0,0 +1,74 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"sync"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// numericAlts tracks the names generated by the numeric alterations, so the number of the
+// names that resolved can be reported.
+type numericAlts struct {
+	sync.Mutex
+	// The generated names, mapped to whether the name resolved
+	names     map[string]bool
+	generated uint64
+	hits      uint64
+}
+
+func newNumericAlts() *numericAlts {
+	return &numericAlts{names: make(map[string]bool)}
+}
+
+// insert records the names generated by the numeric alterations and returns them.
+func (n *numericAlts) insert(names []string) []string {
+	if n == nil {
+		return names
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	for _, name := range names {
+		if _, found := n.names[name]; !found {
+			n.names[name] = false
+			n.generated++
+		}
+	}
+	return names
+}
+
+// resolved counts the name as a hit when it was generated by the numeric alterations.
+func (n *numericAlts) resolved(req *requests.DNSRequest) {
+	if n == nil || req.Tag != requests.ALT {
+		return
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	// The name is only counted once
+	if resolved, found := n.names[req.Name]; found && !resolved {
+		n.names[req.Name] = true
+		n.hits++
+	}
+}
+
+// logNumericStats reports the names generated and resolved by the numeric alterations.
+func (e *Enumeration) logNumericStats() {
+	if e.numericAlts == nil {
+		return
+	}
+
+	e.numericAlts.Lock()
+	generated, hits := e.numericAlts.generated, e.numericAlts.hits
+	e.numericAlts.Unlock()
+
+	if generated > 0 {
+		e.log(requests.LogInfo, "Numeric alterations: %d names generated, %d resolved (%.2f%% hit rate)",
+			generated, hits, 100*float64(hits)/float64(generated))
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// templateStats holds the labels generated by a brute forcing template, along with the number
+// of names generated and resolved, so the unproductive templates can be pruned.
+type templateStats struct {
+	name      string
+	labels    []string
+	generated uint64
+	hits      uint64
+}
+
+// bruteTemplates generates the names of the config label templates for the brute forcing.
+type bruteTemplates struct {
+	stats []*templateStats
+	// The template that generated each label, used for counting the resolved names
+	byLabel map[string]*templateStats
+}
+
+func newBruteTemplates(cfg *config.Config) *bruteTemplates {
+	b := &bruteTemplates{byLabel: make(map[string]*templateStats)}
+
+	for _, t := range cfg.BruteTemplates {
+		s := &templateStats{
+			name:   t.Name,
+			labels: t.Labels(cfg.MaxTemplateLabels),
+		}
+
+		b.stats = append(b.stats, s)
+		for _, label := range s.labels {
+			if _, found := b.byLabel[label]; !found {
+				b.byLabel[label] = s
+			}
+		}
+	}
+	return b
+}
+
+// names returns the names generated by the templates below the subdomain.
+func (b *bruteTemplates) names(subdomain, domain string) []*requests.DNSRequest {
+	var reqs []*requests.DNSRequest
+	if b == nil {
+		return reqs
+	}
+
+	for _, s := range b.stats {
+		for _, label := range s.labels {
+			reqs = append(reqs, &requests.DNSRequest{
+				Name:   label + "." + subdomain,
+				Domain: domain,
+				Tag:    requests.BRUTE,
+				Source: "Brute Forcing",
+			})
+		}
+		atomic.AddUint64(&s.generated, uint64(len(s.labels)))
+	}
+	return reqs
+}
+
+// resolved counts the brute forced name as a hit for the template that generated its label.
+func (b *bruteTemplates) resolved(req *requests.DNSRequest) {
+	if b == nil || req.Tag != requests.BRUTE || len(b.byLabel) == 0 {
+		return
+	}
+
+	label := req.Name
+	if i := strings.IndexByte(label, '.'); i != -1 {
+		label = label[:i]
+	}
+	if s, found := b.byLabel[label]; found {
+		atomic.AddUint64(&s.hits, 1)
+	}
+}
+
+// logTemplateStats reports the names generated and resolved for each brute forcing template.
+func (e *Enumeration) logTemplateStats() {
+	if e.templates == nil {
+		return
+	}
+
+	for _, s := range e.templates.stats {
+		generated := atomic.LoadUint64(&s.generated)
+		if generated == 0 {
+			continue
+		}
+
+		hits := atomic.LoadUint64(&s.hits)
+		e.log(requests.LogInfo, "Template %s: %d names generated, %d resolved (%.2f%% hit rate)",
+			s.name, generated, hits, 100*float64(hits)/float64(generated))
+	}
+}
@@ -16,6 +16,16 @@ const (
 	PriorityCritical
 )
 
+// TopicStats holds the number of events of a topic that were lost or delayed by the EventBus.
+type TopicStats struct {
+	// The events published after the bus was stopped, left in the queues when stopped, or
+	// published once all the subscribers of the topic had unsubscribed
+	Dropped uint64 `json:"dropped"`
+
+	// The callbacks that waited, since the maximum number of callbacks were already running
+	Overflows uint64 `json:"overflows"`
+}
+
 type pubReq struct {
 	Topic string
 	Args  []reflect.Value
@@ -29,6 +39,9 @@ type EventBus struct {
 	queues []*queue.Queue
 	done   chan struct{}
 	closed sync.Once
+
+	statsLock sync.Mutex
+	stats     map[string]*TopicStats
 }
 
 // NewEventBus initializes and returns an EventBus object.
@@ -41,7 +54,8 @@ func NewEventBus(max int) *EventBus {
 			new(queue.Queue),
 			new(queue.Queue),
 		},
-		done: make(chan struct{}, 2),
+		done:  make(chan struct{}, 2),
+		stats: make(map[string]*TopicStats),
 	}
 
 	go eb.processRequests()
@@ -52,9 +66,55 @@ func NewEventBus(max int) *EventBus {
 func (eb *EventBus) Stop() {
 	eb.closed.Do(func() {
 		close(eb.done)
+
+		// The events still queued are never delivered
+		for _, q := range eb.queues {
+			for {
+				element, found := q.Next()
+				if !found {
+					break
+				}
+				eb.dropped(element.(*pubReq).Topic)
+			}
+		}
 	})
 }
 
+// Stats returns the number of events dropped and delayed for each topic.
+func (eb *EventBus) Stats() map[string]TopicStats {
+	eb.statsLock.Lock()
+	defer eb.statsLock.Unlock()
+
+	stats := make(map[string]TopicStats, len(eb.stats))
+	for topic, s := range eb.stats {
+		stats[topic] = *s
+	}
+	return stats
+}
+
+func (eb *EventBus) topicStats(topic string) *TopicStats {
+	s, found := eb.stats[topic]
+	if !found {
+		s = new(TopicStats)
+		eb.stats[topic] = s
+	}
+	return s
+}
+
+func (eb *EventBus) dropped(topic string) {
+	eb.statsLock.Lock()
+	defer eb.statsLock.Unlock()
+
+	eb.topicStats(topic).Dropped++
+}
+
+func (eb *EventBus) overflowed(topic string) {
+	eb.statsLock.Lock()
+	defer eb.statsLock.Unlock()
+
+	eb.topicStats(topic).Overflows++
+}
+
 // Subscribe registers callback to be executed for all requests on the channel.
 func (eb *EventBus) Subscribe(topic string, fn interface{}) {
 	if topic != "" && reflect.TypeOf(fn).Kind() == reflect.Func {
@@ -88,6 +148,13 @@ func (eb *EventBus) Unsubscribe(topic string, fn interface{}) {
 // Publish sends req on the channel labeled with name.
 func (eb *EventBus) Publish(topic string, priority int, args ...interface{}) {
 	if topic != "" && priority >= PriorityLow && priority <= PriorityCritical {
+		select {
+		case <-eb.done:
+			eb.dropped(topic)
+			return
+		default:
+		}
+
 		passedArgs := make([]reflect.Value, 0)
 
 		for _, arg := range args {
@@ -138,9 +205,17 @@ func (eb *EventBus) processRequests() {
 			if !found {
 				continue
 			}
+			// The topic no longer has subscribers to receive the event
+			if len(callbacks) == 0 {
+				eb.dropped(p.Topic)
+				continue
+			}
 
 			for _, cb := range callbacks {
-				eb.max.Acquire(1)
+				if !eb.max.TryAcquire(1) {
+					eb.overflowed(p.Topic)
+					eb.max.Acquire(1)
+				}
 				go eb.execute(cb, p.Args)
 			}
 		}
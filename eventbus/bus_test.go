@@ -66,3 +66,31 @@ func TestEventBus(t *testing.T) {
 	bus.Stop()
 	time.Sleep(time.Second)
 }
+
+func TestEventBusStats(t *testing.T) {
+	topic := "testing"
+	bus := NewEventBus(1000)
+
+	fn := func(v bool) {}
+	bus.Subscribe(topic, fn)
+	bus.Unsubscribe(topic, fn)
+
+	bus.Publish(topic, PriorityLow, true)
+	time.Sleep(time.Second)
+
+	if s := bus.Stats()[topic]; s.Dropped != 1 {
+		t.Errorf("The event published without subscribers was counted as dropped %d times", s.Dropped)
+	}
+
+	bus.Stop()
+	bus.Publish(topic, PriorityHigh, true)
+	bus.Publish("other", PriorityHigh, true)
+
+	stats := bus.Stats()
+	if s := stats[topic]; s.Dropped != 2 {
+		t.Errorf("The topic returned %d dropped events after the stop, expected 2", s.Dropped)
+	}
+	if s := stats["other"]; s.Dropped != 1 {
+		t.Errorf("The other topic returned %d dropped events, expected 1", s.Dropped)
+	}
+}
@@ -1,36 +1,40 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/enum"
-	"github.com/OWASP/Amass/v3/services"
 )
 
 func main() {
 	// Seed the default pseudo-random number generator
 	rand.Seed(time.Now().UTC().UnixNano())
 
-	sys, err := services.NewLocalSystem(config.NewConfig())
-	if err != nil {
-		return
-	}
+	// Setup the most basic amass configuration
+	cfg := config.NewConfig()
+	cfg.AddDomain("example.com")
 
-	e := enum.NewEnumeration(sys)
-	if e == nil {
+	e, err := enum.New(cfg)
+	if err != nil {
 		return
 	}
+	defer e.Sys.Shutdown()
 
+	done := make(chan error, 1)
 	go func() {
-		for result := range e.Output {
-			fmt.Println(result.Name)
-		}
+		done <- e.Start(context.Background())
 	}()
 
-	// Setup the most basic amass configuration
-	e.Config.AddDomain("example.com")
-	e.Start()
+	// The channel is closed when the enumeration ends
+	for result := range e.Results() {
+		fmt.Println(result.Name)
+	}
+
+	if err := <-done; err != nil {
+		fmt.Println(err)
+	}
 }
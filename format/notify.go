@@ -0,0 +1,219 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
+)
+
+// The severities of the findings sent in notifications, from the least to the most severe.
+const (
+	SeverityNewName       = "new_name"
+	SeverityRemovedName   = "removed_name"
+	SeverityChangedRecord = "changed_record"
+	SeverityTakeover      = "takeover"
+)
+
+// NotifySignatureHeader is the HTTP header containing the HMAC-SHA256 signature of the payload.
+const NotifySignatureHeader = "X-Amass-Signature"
+
+var severityLevels = map[string]int{
+	SeverityNewName:       1,
+	SeverityRemovedName:   1,
+	SeverityChangedRecord: 2,
+	SeverityTakeover:      3,
+}
+
+// The headings of the findings grouped in the notification text, in the order shown.
+var severityHeadings = []struct {
+	severity string
+	heading  string
+}{
+	{SeverityTakeover, "Takeovers"},
+	{SeverityNewName, "New names"},
+	{SeverityRemovedName, "Removed names"},
+	{SeverityChangedRecord, "Changed records"},
+}
+
+// The time waited before the first retry, which doubles after each failed attempt.
+var notifyRetryDelay = 2 * time.Second
+
+// Finding is a single item of interest included in a notification.
+type Finding struct {
+	Severity string `json:"severity"`
+	Domain   string `json:"domain"`
+	Name     string `json:"name"`
+	Details  string `json:"details,omitempty"`
+}
+
+// NotificationData is provided to the notification template.
+type NotificationData struct {
+	Text     string     `json:"text"`
+	Findings []*Finding `json:"findings"`
+}
+
+// Notifier sends batches of findings to a webhook, such as a Slack incoming webhook.
+type Notifier struct {
+	url      string
+	tmpl     *template.Template
+	minLevel int
+	secret   string
+	retries  int
+
+	// When DryRun is set, the notifications are written to Output instead of being sent
+	DryRun bool
+	Output io.Writer
+}
+
+// NewNotifier returns a Notifier that posts to the URL. The optional template text formats
+// the payload, the findings below the minimum severity are not sent, and the payload is
+// signed when a secret is provided.
+func NewNotifier(url, tmpl, minSeverity, secret string, retries int) (*Notifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("NewNotifier: No webhook URL provided")
+	}
+
+	if minSeverity == "" {
+		minSeverity = SeverityNewName
+	}
+	level, found := severityLevels[strings.ToLower(minSeverity)]
+	if !found {
+		return nil, fmt.Errorf("NewNotifier: Unknown severity %s", minSeverity)
+	}
+
+	n := &Notifier{
+		url:      url,
+		minLevel: level,
+		secret:   secret,
+		retries:  retries,
+	}
+
+	if tmpl != "" {
+		t, err := template.New("notification").Funcs(template.FuncMap{
+			"json": func(v interface{}) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+		}).Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("NewNotifier: Failed to parse the template: %v", err)
+		}
+		n.tmpl = t
+	}
+
+	return n, nil
+}
+
+// Notify sends the findings that meet the minimum severity in a single request.
+func (n *Notifier) Notify(findings []*Finding) error {
+	var selected []*Finding
+	for _, f := range findings {
+		if severityLevels[f.Severity] >= n.minLevel {
+			selected = append(selected, f)
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	payload, err := n.Payload(selected)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	if n.secret != "" {
+		headers[NotifySignatureHeader] = "sha256=" + NotifySignature(n.secret, payload)
+	}
+
+	if n.DryRun {
+		if n.Output != nil {
+			fmt.Fprintf(n.Output, "POST %s\n", n.url)
+			for _, k := range []string{"Content-Type", NotifySignatureHeader} {
+				if v, found := headers[k]; found {
+					fmt.Fprintf(n.Output, "%s: %s\n", k, v)
+				}
+			}
+			fmt.Fprintf(n.Output, "\n%s\n", payload)
+		}
+		return nil
+	}
+
+	delay := notifyRetryDelay
+	for attempt := 0; ; attempt++ {
+		_, err = amasshttp.RequestWebPage(n.url, bytes.NewReader(payload), headers, "", "")
+		if err == nil || attempt >= n.retries {
+			break
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+	return err
+}
+
+// Payload returns the body of the notification for the findings.
+func (n *Notifier) Payload(findings []*Finding) ([]byte, error) {
+	data := &NotificationData{
+		Text:     notificationText(findings),
+		Findings: findings,
+	}
+
+	if n.tmpl == nil {
+		return json.Marshal(data)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := n.tmpl.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NotifySignature returns the hex encoded HMAC-SHA256 of the payload using the secret.
+func NotifySignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// notificationText returns the summary of the findings, grouped by the severity.
+func notificationText(findings []*Finding) string {
+	var lines []string
+
+	lines = append(lines, fmt.Sprintf("OWASP Amass found %d findings", len(findings)))
+	for _, h := range severityHeadings {
+		var group []string
+
+		for _, f := range findings {
+			if f.Severity != h.severity {
+				continue
+			}
+
+			line := "  " + f.Name
+			if f.Details != "" {
+				line += " " + f.Details
+			}
+			group = append(group, line)
+		}
+
+		if len(group) > 0 {
+			lines = append(lines, fmt.Sprintf("%s (%d):", h.heading, len(group)))
+			lines = append(lines, group...)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
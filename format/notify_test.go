@@ -0,0 +1,121 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNotifier(t *testing.T) {
+	notifyRetryDelay = time.Millisecond
+
+	var lock sync.Mutex
+	var attempts int
+	var bodies [][]byte
+	var signatures []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		attempts++
+		// Fail the first attempt to exercise the retries
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get(NotifySignatureHeader))
+	}))
+	defer srv.Close()
+
+	n, err := NewNotifier(srv.URL, "", SeverityChangedRecord, "secret", 2)
+	if err != nil {
+		t.Fatalf("Failed to create the notifier: %v", err)
+	}
+
+	findings := []*Finding{
+		{Severity: SeverityNewName, Domain: "owasp.org", Name: "new.owasp.org"},
+		{Severity: SeverityChangedRecord, Domain: "owasp.org", Name: "www.owasp.org", Details: "104.22.26.77"},
+		{Severity: SeverityChangedRecord, Domain: "owasp.org", Name: "api.owasp.org", Details: "104.22.27.77"},
+	}
+	if err := n.Notify(findings); err != nil {
+		t.Fatalf("Failed to send the notification: %v", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if attempts != 2 || len(bodies) != 1 {
+		t.Fatalf("Expected a single notification after one retry, got %d attempts", attempts)
+	}
+
+	var data NotificationData
+	if err := json.Unmarshal(bodies[0], &data); err != nil {
+		t.Fatalf("The notification was not valid JSON: %v", err)
+	}
+	if len(data.Findings) != 2 || data.Text == "" {
+		t.Errorf("The findings below the minimum severity were not removed: %s", bodies[0])
+	}
+	if signatures[0] != "sha256="+NotifySignature("secret", bodies[0]) {
+		t.Errorf("The notification had an invalid signature: %s", signatures[0])
+	}
+
+	// Nothing is sent when no findings meet the minimum severity
+	if err := n.Notify(findings[:1]); err != nil || attempts != 2 {
+		t.Errorf("A notification was sent without any findings")
+	}
+}
+
+func TestNotifierDryRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("The dry run sent a notification")
+	}))
+	defer srv.Close()
+
+	n, err := NewNotifier(srv.URL, `{"text": {{json (printf "%d names" (len .Findings))}}}`, "", "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create the notifier: %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	n.DryRun = true
+	n.Output = buf
+	if err := n.Notify([]*Finding{{Severity: SeverityNewName, Domain: "owasp.org", Name: "new.owasp.org"}}); err != nil {
+		t.Fatalf("The dry run failed: %v", err)
+	}
+
+	if out := buf.String(); !strings.HasPrefix(out, "POST "+srv.URL) || !strings.Contains(out, `{"text": "1 names"}`) {
+		t.Errorf("Unexpected dry run output: %s", out)
+	}
+
+	if _, err := NewNotifier(srv.URL, "", "critical", "", 0); err == nil {
+		t.Errorf("NewNotifier accepted an unknown severity")
+	}
+}
+
+func TestNotificationText(t *testing.T) {
+	text := notificationText([]*Finding{
+		{Severity: SeverityChangedRecord, Domain: "owasp.org", Name: "www.owasp.org", Details: "192.0.2.1 -> 192.0.2.2"},
+		{Severity: SeverityNewName, Domain: "owasp.org", Name: "new.owasp.org"},
+		{Severity: SeverityRemovedName, Domain: "owasp.org", Name: "old.owasp.org"},
+		{Severity: SeverityNewName, Domain: "owasp.org", Name: "api.owasp.org"},
+	})
+
+	expected := "OWASP Amass found 4 findings\n" +
+		"New names (2):\n  new.owasp.org\n  api.owasp.org\n" +
+		"Removed names (1):\n  old.owasp.org\n" +
+		"Changed records (1):\n  www.owasp.org 192.0.2.1 -> 192.0.2.2"
+	if text != expected {
+		t.Errorf("Unexpected notification text:\n%s", text)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+var (
+	outputLock  sync.Mutex
+	machineMode bool
+	resultOut   io.Writer = os.Stdout
+)
+
+// SetMachineMode determines if stdout will only carry the results, without color, while the
+// banner, progress and other messages are written to stderr.
+func SetMachineMode(enabled bool) {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	machineMode = enabled
+}
+
+// MachineMode returns true when stdout only carries the results.
+func MachineMode() bool {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	return machineMode
+}
+
+// ResultOutput returns the io.Writer that receives the results.
+func ResultOutput() io.Writer {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	if machineMode {
+		return resultOut
+	}
+	return color.Output
+}
+
+// MessageOutput returns the io.Writer that receives the messages that are not results.
+func MessageOutput() io.Writer {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	if machineMode {
+		return color.Error
+	}
+	return color.Output
+}
+
+// PrintResult writes a single result line. The plain line is used in machine mode, and the
+// colored line is used otherwise.
+func PrintResult(plain, colored string) {
+	line := colored
+	if MachineMode() {
+		line = plain
+	}
+
+	fmt.Fprintln(ResultOutput(), line)
+}
@@ -0,0 +1,39 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestMachineMode(t *testing.T) {
+	buf := new(bytes.Buffer)
+	outputLock.Lock()
+	prev := resultOut
+	resultOut = buf
+	outputLock.Unlock()
+	defer func() {
+		SetMachineMode(false)
+		outputLock.Lock()
+		resultOut = prev
+		outputLock.Unlock()
+	}()
+
+	SetMachineMode(true)
+	PrintResult("www.owasp.org 104.22.26.77", green("www.owasp.org")+" "+yellow("104.22.26.77"))
+	if got := buf.String(); got != "www.owasp.org 104.22.26.77\n" {
+		t.Errorf("Machine mode did not write the plain result: %q", got)
+	}
+	if MessageOutput() != color.Error {
+		t.Errorf("Machine mode did not send the messages to stderr")
+	}
+
+	SetMachineMode(false)
+	if ResultOutput() != color.Output || MessageOutput() != color.Output {
+		t.Errorf("The default mode did not write everything to stdout")
+	}
+}
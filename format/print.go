@@ -4,12 +4,17 @@
 package format
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/stringset"
 	"github.com/fatih/color"
 )
 
@@ -142,6 +147,45 @@ func PrintEnumerationSummary(total int, tags map[string]int, asns map[int]*ASNSu
 	}
 }
 
+// PrintInfrastructureSummary outputs the autonomous systems and netblocks containing the discovered names.
+func PrintInfrastructureSummary(summary []*requests.ASNSummary, demo bool) {
+	if len(summary) == 0 {
+		return
+	}
+
+	b.Fprintln(color.Error, strings.Repeat("-", 80))
+	for _, s := range summary {
+		asnstr := strconv.Itoa(s.ASN)
+		datastr := s.Description
+
+		if demo && s.ASN > 0 {
+			asnstr = censorString(asnstr, 0, len(asnstr))
+			datastr = censorString(datastr, 0, len(datastr))
+		}
+
+		fmt.Fprintf(color.Error, "%s%s %s %s %s\n", blue("ASN: "), yellow(asnstr),
+			green("-"), green(datastr), yellow(fmt.Sprintf("(%d)", s.Names)))
+
+		for _, nb := range s.Netblocks {
+			cidrstr := nb.CIDR
+			if demo {
+				cidrstr = censorNetBlock(cidrstr)
+			}
+
+			fmt.Fprintf(color.Error, "%s%s %s\n", yellow(fmt.Sprintf("\t%-18s", cidrstr)),
+				yellow(fmt.Sprintf("\t%-4d", nb.Names)), blue("Subdomain Name(s)"))
+		}
+	}
+}
+
+// WriteInfrastructureSummary writes the infrastructure summary to the io.Writer as JSON.
+func WriteInfrastructureSummary(w io.Writer, summary []*requests.ASNSummary) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(summary)
+}
+
 // PrintBanner outputs the Amass banner the same for all tools.
 func PrintBanner() {
 	y := color.New(color.FgHiYellow)
@@ -188,10 +232,24 @@ func censorString(input string, start, end int) string {
 	return string(runes)
 }
 
+// SourcesString returns the data sources that reported the requests.Output and the
+// number of records contributed by each.
+func SourcesString(out *requests.Output) string {
+	if len(out.Sources) == 0 {
+		return out.Source
+	}
+
+	var parts []string
+	for _, s := range out.Sources {
+		parts = append(parts, fmt.Sprintf("%s:%d", s.Source, s.Count))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // OutputLineParts returns the parts of a line to be printed for a requests.Output.
 func OutputLineParts(out *requests.Output, src, addrs, demo bool) (source, name, ips string) {
 	if src {
-		source = fmt.Sprintf("%-18s", "["+out.Source+"] ")
+		source = fmt.Sprintf("%-18s", "["+SourcesString(out)+"] ")
 	}
 	if addrs {
 		for i, a := range out.Addresses {
@@ -215,6 +273,81 @@ func OutputLineParts(out *requests.Output, src, addrs, demo bool) (source, name,
 	return
 }
 
+// AddressDetails returns a line part for each address in the slice containing the address, the covering
+// netblock, the ASN and the AS description. The parts are sorted by address and padded into stable columns.
+func AddressDetails(addrs []requests.AddressInfo, demo bool) []string {
+	sorted := make([]requests.AddressInfo, len(addrs))
+	copy(sorted, addrs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Address.To16(), sorted[j].Address.To16()) < 0
+	})
+
+	var details []string
+	for _, a := range sorted {
+		addr := a.Address.String()
+		cidr := a.CIDRStr
+		if cidr == "" && a.Netblock != nil {
+			cidr = a.Netblock.String()
+		}
+
+		asn := "N/A"
+		if a.ASN != 0 {
+			asn = strconv.Itoa(a.ASN)
+		}
+
+		desc := strings.TrimSpace(a.Description)
+		if demo {
+			addr = censorIP(addr)
+			if cidr != "" {
+				cidr = censorNetBlock(cidr)
+			}
+			desc = censorString(desc, 0, len(desc))
+		}
+		if cidr == "" {
+			cidr = "N/A"
+		}
+
+		details = append(details, strings.TrimRight(
+			fmt.Sprintf("%-39s %-43s %-8s %s", addr, cidr, asn, desc), " "))
+	}
+	return details
+}
+
+// OutputTags returns the tags of all the data sources that reported the requests.Output.
+func OutputTags(out *requests.Output) stringset.Set {
+	tags := stringset.New()
+
+	if out.Tag != "" {
+		tags.Insert(out.Tag)
+	}
+	for _, s := range out.Sources {
+		if s.Tag != "" {
+			tags.Insert(s.Tag)
+		}
+	}
+	return tags
+}
+
+// DesiredTags returns true when the requests.Output was reported with at least one of the included
+// tags and none of the excluded tags. Empty sets do not filter the output.
+func DesiredTags(out *requests.Output, include, exclude stringset.Set) bool {
+	tags := OutputTags(out)
+
+	if include.Len() > 0 && !hasAnyTag(tags, include) {
+		return false
+	}
+	return exclude.Len() == 0 || !hasAnyTag(tags, exclude)
+}
+
+func hasAnyTag(tags, other stringset.Set) bool {
+	for _, tag := range other.Slice() {
+		if tags.Has(tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // DesiredAddrTypes removes undesired address types from the AddressInfo slice.
 func DesiredAddrTypes(addrs []requests.AddressInfo, ipv4, ipv6 bool) []requests.AddressInfo {
 	if !ipv4 && !ipv6 {
@@ -232,3 +365,23 @@ func DesiredAddrTypes(addrs []requests.AddressInfo, ipv4, ipv6 bool) []requests.
 	}
 	return keep
 }
+
+// RecordsString returns the DNS records of the output as a single line part, such as
+// "a→1.2.3.4, cname→target". The records keep the ordering provided by the graph.
+func RecordsString(out *requests.Output, demo bool) string {
+	var parts []string
+
+	for _, rec := range out.Records {
+		data := rec.Data
+		if demo {
+			if rec.Type == "A" || rec.Type == "AAAA" {
+				data = censorIP(data)
+			} else {
+				data = censorDomain(data)
+			}
+		}
+
+		parts = append(parts, strings.ToLower(rec.Type)+"→"+data)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/stringset"
+)
+
+func TestAddressDetails(t *testing.T) {
+	addrs := []requests.AddressInfo{
+		{
+			Address:     net.ParseIP("2606:4700:10::6816:1a4d"),
+			CIDRStr:     "2606:4700:10::/44",
+			ASN:         13335,
+			Description: "CLOUDFLARENET - Cloudflare, Inc.",
+		},
+		{
+			Address:     net.ParseIP("104.22.26.77"),
+			CIDRStr:     "104.22.16.0/20",
+			ASN:         13335,
+			Description: "CLOUDFLARENET - Cloudflare, Inc.",
+		},
+		{
+			Address: net.ParseIP("10.0.0.1"),
+		},
+	}
+
+	details := AddressDetails(addrs, false)
+	if len(details) != len(addrs) {
+		t.Fatalf("Expected %d lines, got %d", len(addrs), len(details))
+	}
+
+	expected := []string{"10.0.0.1", "104.22.26.77", "2606:4700:10::6816:1a4d"}
+	for i, addr := range expected {
+		if !strings.HasPrefix(details[i], addr+" ") {
+			t.Errorf("Line %d: expected the address %s first, got %s", i+1, addr, details[i])
+		}
+	}
+
+	if f := strings.Fields(details[0]); len(f) != 3 || f[1] != "N/A" || f[2] != "N/A" {
+		t.Errorf("Expected N/A for the missing netblock and ASN, got %s", details[0])
+	}
+	if !strings.HasSuffix(details[1], "104.22.16.0/20                              13335    CLOUDFLARENET - Cloudflare, Inc.") {
+		t.Errorf("The netblock, ASN and description were not aligned: %s", details[1])
+	}
+	// The columns following the address must start at the same offset on every line
+	if strings.Index(details[1], "104.22.16.0/20") != strings.Index(details[2], "2606:4700:10::/44") {
+		t.Errorf("The netblock columns are not aligned:\n%s\n%s", details[1], details[2])
+	}
+}
+
+func TestRecordsString(t *testing.T) {
+	out := &requests.Output{
+		Name: "www.owasp.org",
+		Records: []requests.RecordInfo{
+			{Type: "A", Data: "104.22.26.77"},
+			{Type: "AAAA", Data: "2606:4700:10::6816:1a4d"},
+			{Type: "CNAME", Data: "owasp.org"},
+		},
+	}
+
+	expected := "a→104.22.26.77, aaaa→2606:4700:10::6816:1a4d, cname→owasp.org"
+	if got := RecordsString(out, false); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	if got := RecordsString(&requests.Output{Name: "owasp.org"}, false); got != "" {
+		t.Errorf("Expected an empty string for a name without records, got %s", got)
+	}
+}
+
+func TestDesiredTags(t *testing.T) {
+	out := &requests.Output{
+		Name:   "www.owasp.org",
+		Tag:    requests.CERT,
+		Source: "Crtsh",
+		Sources: []requests.SourceInfo{
+			{Source: "Crtsh", Tag: requests.CERT, Count: 2},
+			{Source: "Brute Forcing", Tag: requests.BRUTE, Count: 1},
+		},
+	}
+
+	tests := []struct {
+		include, exclude []string
+		expected         bool
+	}{
+		{nil, nil, true},
+		{[]string{requests.BRUTE}, nil, true},
+		{[]string{requests.API, requests.CERT}, nil, true},
+		{[]string{requests.API}, nil, false},
+		{nil, []string{requests.BRUTE}, false},
+		{nil, []string{requests.ALT}, true},
+		{[]string{requests.CERT}, []string{requests.BRUTE}, false},
+	}
+
+	for _, tt := range tests {
+		got := DesiredTags(out, stringset.New(tt.include...), stringset.New(tt.exclude...))
+		if got != tt.expected {
+			t.Errorf("Include %v and exclude %v: expected %v, got %v", tt.include, tt.exclude, tt.expected, got)
+		}
+	}
+
+	if len(out.Sources) != 2 {
+		t.Errorf("The tag filtering modified the output sources")
+	}
+}
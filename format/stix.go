@@ -0,0 +1,219 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/google/uuid"
+)
+
+// STIXSpecVersion is the version of the STIX specification implemented by the bundle export.
+const STIXSpecVersion = "2.1"
+
+// The namespace defined by the STIX 2.1 specification for deterministic cyber-observable identifiers.
+var stixSCONamespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+
+// STIXBundle is the STIX 2.1 bundle containing the objects exported from an enumeration.
+type STIXBundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []*STIXObject `json:"objects"`
+}
+
+// STIXObject represents the STIX 2.1 domain, cyber-observable and relationship objects used by Amass.
+type STIXObject struct {
+	Type             string   `json:"type"`
+	SpecVersion      string   `json:"spec_version"`
+	ID               string   `json:"id"`
+	Created          string   `json:"created,omitempty"`
+	Modified         string   `json:"modified,omitempty"`
+	CreatedByRef     string   `json:"created_by_ref,omitempty"`
+	Name             string   `json:"name,omitempty"`
+	IdentityClass    string   `json:"identity_class,omitempty"`
+	Abstract         string   `json:"abstract,omitempty"`
+	Content          string   `json:"content,omitempty"`
+	ObjectRefs       []string `json:"object_refs,omitempty"`
+	Value            string   `json:"value,omitempty"`
+	Number           int      `json:"number,omitempty"`
+	ResolvesToRefs   []string `json:"resolves_to_refs,omitempty"`
+	BelongsToRefs    []string `json:"belongs_to_refs,omitempty"`
+	RelationshipType string   `json:"relationship_type,omitempty"`
+	SourceRef        string   `json:"source_ref,omitempty"`
+	TargetRef        string   `json:"target_ref,omitempty"`
+}
+
+// STIXTimestamp returns the time formatted as required for STIX 2.1 timestamp properties.
+func STIXTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// NewSTIXBundle converts the enumeration output into a STIX 2.1 bundle. The identifiers
+// are derived from the assets and the enumeration UUID, so repeated exports of the same
+// data produce identical objects.
+func NewSTIXBundle(eventID string, start, finish time.Time, outputs []*requests.Output) *STIXBundle {
+	created := STIXTimestamp(start)
+	modified := STIXTimestamp(finish)
+	if finish.Before(start) {
+		modified = created
+	}
+
+	identity := &STIXObject{
+		Type:          "identity",
+		SpecVersion:   STIXSpecVersion,
+		ID:            stixID("identity", "OWASP Amass"),
+		Created:       created,
+		Modified:      created,
+		Name:          "OWASP Amass",
+		IdentityClass: "system",
+	}
+
+	objs := make(map[string]*STIXObject)
+	rels := make(map[string]*STIXObject)
+	addRel := func(rtype, src, target string) {
+		id := stixID("relationship", src+"|"+rtype+"|"+target)
+
+		rels[id] = &STIXObject{
+			Type:             "relationship",
+			SpecVersion:      STIXSpecVersion,
+			ID:               id,
+			Created:          created,
+			Modified:         modified,
+			CreatedByRef:     identity.ID,
+			RelationshipType: rtype,
+			SourceRef:        src,
+			TargetRef:        target,
+		}
+	}
+
+	for _, out := range outputs {
+		name := strings.ToLower(strings.TrimSpace(out.Name))
+		if name == "" {
+			continue
+		}
+
+		dn := stixSCO(objs, "domain-name", map[string]interface{}{"value": name})
+		dn.Value = name
+
+		for _, addr := range out.Addresses {
+			if addr.Address == nil {
+				continue
+			}
+
+			atype := "ipv4-addr"
+			if addr.Address.To4() == nil {
+				atype = "ipv6-addr"
+			}
+
+			ip := addr.Address.String()
+			ipobj := stixSCO(objs, atype, map[string]interface{}{"value": ip})
+			ipobj.Value = ip
+			dn.ResolvesToRefs = appendUniqueRef(dn.ResolvesToRefs, ipobj.ID)
+			addRel("resolves-to", dn.ID, ipobj.ID)
+
+			if addr.ASN == 0 {
+				continue
+			}
+
+			as := stixSCO(objs, "autonomous-system", map[string]interface{}{"number": addr.ASN})
+			as.Number = addr.ASN
+			if as.Name == "" {
+				as.Name = addr.Description
+			}
+			ipobj.BelongsToRefs = appendUniqueRef(ipobj.BelongsToRefs, as.ID)
+			addRel("belongs-to", ipobj.ID, as.ID)
+		}
+	}
+
+	var refs []string
+	for id := range objs {
+		refs = append(refs, id)
+	}
+	sort.Strings(refs)
+
+	note := &STIXObject{
+		Type:         "note",
+		SpecVersion:  STIXSpecVersion,
+		ID:           stixID("note", eventID),
+		Created:      created,
+		Modified:     modified,
+		CreatedByRef: identity.ID,
+		Abstract:     "Amass enumeration " + eventID,
+		Content: fmt.Sprintf("Enumeration UUID: %s\nStart: %s\nFinish: %s",
+			eventID, STIXTimestamp(start), STIXTimestamp(finish)),
+		ObjectRefs: refs,
+	}
+	// The note must reference at least one object
+	if len(note.ObjectRefs) == 0 {
+		note.ObjectRefs = []string{identity.ID}
+	}
+
+	bundle := &STIXBundle{
+		Type:    "bundle",
+		ID:      stixID("bundle", eventID),
+		Objects: []*STIXObject{identity, note},
+	}
+	for _, id := range refs {
+		bundle.Objects = append(bundle.Objects, objs[id])
+	}
+
+	var relIDs []string
+	for id := range rels {
+		relIDs = append(relIDs, id)
+	}
+	sort.Strings(relIDs)
+	for _, id := range relIDs {
+		bundle.Objects = append(bundle.Objects, rels[id])
+	}
+
+	return bundle
+}
+
+// WriteSTIXBundle writes the enumeration output to the io.Writer as a STIX 2.1 bundle.
+func WriteSTIXBundle(w io.Writer, eventID string, start, finish time.Time, outputs []*requests.Output) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(NewSTIXBundle(eventID, start, finish, outputs))
+}
+
+func stixSCO(objs map[string]*STIXObject, stype string, props map[string]interface{}) *STIXObject {
+	// The STIX specification requires a UUIDv5 generated from the ID contributing properties
+	data, _ := json.Marshal(props)
+	id := stype + "--" + uuid.NewSHA1(stixSCONamespace, data).String()
+
+	if obj, found := objs[id]; found {
+		return obj
+	}
+
+	obj := &STIXObject{
+		Type:        stype,
+		SpecVersion: STIXSpecVersion,
+		ID:          id,
+	}
+	objs[id] = obj
+	return obj
+}
+
+func stixID(stype, name string) string {
+	return stype + "--" + uuid.NewSHA1(stixSCONamespace, []byte(stype+":"+name)).String()
+}
+
+func appendUniqueRef(refs []string, ref string) []string {
+	for _, r := range refs {
+		if r == ref {
+			return refs
+		}
+	}
+
+	refs = append(refs, ref)
+	sort.Strings(refs)
+	return refs
+}
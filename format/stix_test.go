@@ -0,0 +1,193 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+var stixIDRE = regexp.MustCompile(`^([a-z0-9-]+)--[0-9a-f]{8}-[0-9a-f]{4}-[1-5][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// The properties required by the STIX 2.1 JSON schemas for each object type exported.
+var stixRequired = map[string][]string{
+	"identity":          {"type", "spec_version", "id", "created", "modified", "name"},
+	"note":              {"type", "spec_version", "id", "created", "modified", "content", "object_refs"},
+	"domain-name":       {"type", "id", "value"},
+	"ipv4-addr":         {"type", "id", "value"},
+	"ipv6-addr":         {"type", "id", "value"},
+	"autonomous-system": {"type", "id", "number"},
+	"relationship":      {"type", "spec_version", "id", "created", "modified", "relationship_type", "source_ref", "target_ref"},
+}
+
+func stixTestOutput() []*requests.Output {
+	return []*requests.Output{
+		{
+			Name:   "www.owasp.org",
+			Domain: "owasp.org",
+			Addresses: []requests.AddressInfo{
+				{
+					Address:     net.ParseIP("104.22.26.77"),
+					CIDRStr:     "104.22.16.0/20",
+					ASN:         13335,
+					Description: "CLOUDFLARENET - Cloudflare, Inc.",
+				},
+				{
+					Address:     net.ParseIP("2606:4700:10::6816:1a4d"),
+					CIDRStr:     "2606:4700:10::/44",
+					ASN:         13335,
+					Description: "CLOUDFLARENET - Cloudflare, Inc.",
+				},
+			},
+			Tag:    requests.CERT,
+			Source: "Crtsh",
+		},
+		{
+			Name:   "owasp.org",
+			Domain: "owasp.org",
+			Addresses: []requests.AddressInfo{
+				{
+					Address:     net.ParseIP("104.22.26.77"),
+					CIDRStr:     "104.22.16.0/20",
+					ASN:         13335,
+					Description: "CLOUDFLARENET - Cloudflare, Inc.",
+				},
+			},
+			Tag:    requests.DNS,
+			Source: "DNS",
+		},
+	}
+}
+
+func TestSTIXBundleValidates(t *testing.T) {
+	start := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	finish := start.Add(time.Hour)
+
+	buf := new(bytes.Buffer)
+	if err := WriteSTIXBundle(buf, "ef9f9475-34eb-465e-81eb-77c944822d0f", start, finish, stixTestOutput()); err != nil {
+		t.Fatalf("Failed to write the STIX bundle: %v", err)
+	}
+
+	var bundle struct {
+		Type    string                   `json:"type"`
+		ID      string                   `json:"id"`
+		Objects []map[string]interface{} `json:"objects"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &bundle); err != nil {
+		t.Fatalf("The STIX bundle is not valid JSON: %v", err)
+	}
+
+	if bundle.Type != "bundle" {
+		t.Errorf("Expected the bundle type, got %s", bundle.Type)
+	}
+	if m := stixIDRE.FindStringSubmatch(bundle.ID); m == nil || m[1] != "bundle" {
+		t.Errorf("The bundle has an invalid identifier: %s", bundle.ID)
+	}
+
+	ids := make(map[string]string)
+	for _, obj := range bundle.Objects {
+		otype, _ := obj["type"].(string)
+		id, _ := obj["id"].(string)
+
+		required, found := stixRequired[otype]
+		if !found {
+			t.Errorf("Unexpected object type in the bundle: %s", otype)
+			continue
+		}
+		for _, prop := range required {
+			if _, ok := obj[prop]; !ok {
+				t.Errorf("The %s object %s is missing the required %s property", otype, id, prop)
+			}
+		}
+
+		if m := stixIDRE.FindStringSubmatch(id); m == nil || m[1] != otype {
+			t.Errorf("The %s object has an invalid identifier: %s", otype, id)
+		}
+		if v, ok := obj["spec_version"]; ok && v != STIXSpecVersion {
+			t.Errorf("The %s object has the wrong spec_version: %v", id, v)
+		}
+		for _, prop := range []string{"created", "modified"} {
+			if v, ok := obj[prop].(string); ok {
+				if _, err := time.Parse(time.RFC3339, v); err != nil {
+					t.Errorf("The %s object has an invalid %s timestamp: %s", id, prop, v)
+				}
+			}
+		}
+
+		ids[id] = otype
+	}
+
+	counts := make(map[string]int)
+	for _, otype := range ids {
+		counts[otype]++
+	}
+	expected := map[string]int{
+		"identity":          1,
+		"note":              1,
+		"domain-name":       2,
+		"ipv4-addr":         1,
+		"ipv6-addr":         1,
+		"autonomous-system": 1,
+		"relationship":      5,
+	}
+	for otype, num := range expected {
+		if counts[otype] != num {
+			t.Errorf("Expected %d %s objects, got %d", num, otype, counts[otype])
+		}
+	}
+
+	// Check that all the references point to objects within the bundle
+	for _, obj := range bundle.Objects {
+		var refs []interface{}
+
+		for _, prop := range []string{"source_ref", "target_ref", "created_by_ref"} {
+			if v, ok := obj[prop]; ok {
+				refs = append(refs, v)
+			}
+		}
+		for _, prop := range []string{"object_refs", "resolves_to_refs", "belongs_to_refs"} {
+			if v, ok := obj[prop].([]interface{}); ok {
+				refs = append(refs, v...)
+			}
+		}
+
+		for _, ref := range refs {
+			if _, found := ids[ref.(string)]; !found {
+				t.Errorf("The %v object references %v, which is not in the bundle", obj["id"], ref)
+			}
+		}
+	}
+}
+
+func TestSTIXBundleDeterministic(t *testing.T) {
+	start := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+	finish := start.Add(time.Hour)
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+
+	first := new(bytes.Buffer)
+	WriteSTIXBundle(first, event, start, finish, stixTestOutput())
+
+	// Reverse the order of the output to make sure it does not impact the bundle
+	out := stixTestOutput()
+	out[0], out[1] = out[1], out[0]
+
+	second := new(bytes.Buffer)
+	WriteSTIXBundle(second, event, start, finish, out)
+
+	if first.String() != second.String() {
+		t.Errorf("Repeated exports of the same data produced different bundles")
+	}
+
+	dn := NewSTIXBundle(event, start, finish, out).Objects[2]
+	other := NewSTIXBundle("b1ac2b5d-cf4c-4a7f-bab5-5ab3c54c9c8a", start, finish, out).Objects[2]
+	if dn.ID != other.ID {
+		t.Errorf("The %s identifier changed across enumerations: %s and %s", dn.Type, dn.ID, other.ID)
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The framing formats supported by the SyslogSink.
+const (
+	SyslogRFC5424 = "rfc5424"
+	SyslogRFC3164 = "rfc3164"
+)
+
+const (
+	syslogQueueSize    = 10000
+	syslogSeverity     = 6 // Informational
+	syslogDialTimeout  = 5 * time.Second
+	syslogWriteTimeout = 5 * time.Second
+	syslogMaxBackoff   = time.Minute
+)
+
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// SyslogSink sends enumeration findings to a syslog server. Messages are queued so a slow
+// or unavailable server never blocks the caller, and messages are dropped once the queue
+// is full. The connection is reestablished with an exponential backoff after failures.
+type SyslogSink struct {
+	network  string
+	address  string
+	priority int
+	framing  string
+	hostname string
+	pid      int
+
+	queue   chan string
+	dropped uint64
+	done    chan struct{}
+	wg      sync.WaitGroup
+	closed  sync.Once
+}
+
+// NewSyslogSink returns a SyslogSink that sends messages to the address using the network
+// protocol (udp, tcp or unix), the named facility and the framing format.
+func NewSyslogSink(network, address, facility, framing string) (*SyslogSink, error) {
+	return newSyslogSink(network, address, facility, framing, syslogQueueSize)
+}
+
+func newSyslogSink(network, address, facility, framing string, size int) (*SyslogSink, error) {
+	if network == "" {
+		network = "udp"
+	}
+	if address == "" {
+		return nil, fmt.Errorf("NewSyslogSink: No syslog address provided")
+	}
+
+	fac, found := syslogFacilities[strings.ToLower(facility)]
+	if facility == "" {
+		fac, found = syslogFacilities["local0"], true
+	}
+	if !found {
+		return nil, fmt.Errorf("NewSyslogSink: Unknown syslog facility %s", facility)
+	}
+
+	framing = strings.ToLower(framing)
+	if framing == "" {
+		framing = SyslogRFC5424
+	}
+	if framing != SyslogRFC5424 && framing != SyslogRFC3164 {
+		return nil, fmt.Errorf("NewSyslogSink: Unknown syslog format %s", framing)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	s := &SyslogSink{
+		network:  network,
+		address:  address,
+		priority: fac*8 + syslogSeverity,
+		framing:  framing,
+		hostname: hostname,
+		pid:      os.Getpid(),
+		queue:    make(chan string, size),
+		done:     make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.processMessages()
+	return s, nil
+}
+
+// Send queues a message containing the JSON encoding of v. The msgID identifies the type
+// of finding, such as "name". The message is dropped when the queue is full.
+func (s *SyslogSink) Send(msgID string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-s.done:
+		atomic.AddUint64(&s.dropped, 1)
+		return nil
+	default:
+	}
+
+	select {
+	case s.queue <- s.frame(msgID, string(data), time.Now()):
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+	return nil
+}
+
+// Dropped returns the number of messages that could not be sent to the syslog server.
+func (s *SyslogSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close attempts to send the queued messages and then releases the connection.
+func (s *SyslogSink) Close() {
+	s.closed.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+	})
+}
+
+func (s *SyslogSink) frame(msgID, msg string, t time.Time) string {
+	if s.framing == SyslogRFC3164 {
+		return fmt.Sprintf("<%d>%s %s amass[%d]: %s", s.priority,
+			t.Format(time.Stamp), s.hostname, s.pid, msg)
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s amass %d %s - %s", s.priority,
+		t.UTC().Format("2006-01-02T15:04:05.000000Z07:00"), s.hostname, s.pid, msgID, msg)
+}
+
+func (s *SyslogSink) processMessages() {
+	defer s.wg.Done()
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	backoff := time.Second
+	for {
+		var msg string
+
+		select {
+		case msg = <-s.queue:
+		case <-s.done:
+			// Send the messages remaining in the queue before returning
+			select {
+			case msg = <-s.queue:
+			default:
+				return
+			}
+		}
+
+		for {
+			if conn == nil {
+				c, err := net.DialTimeout(s.network, s.address, syslogDialTimeout)
+				if err == nil {
+					conn = c
+					backoff = time.Second
+				}
+			}
+
+			if conn != nil {
+				conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+				if _, err := conn.Write([]byte(s.terminate(msg))); err == nil {
+					break
+				}
+
+				conn.Close()
+				conn = nil
+			}
+
+			// Wait before attempting to reconnect with the syslog server
+			t := time.NewTimer(backoff)
+			select {
+			case <-t.C:
+			case <-s.done:
+				// Do not hold up the shutdown for an unavailable server
+				t.Stop()
+				atomic.AddUint64(&s.dropped, uint64(len(s.queue)+1))
+				return
+			}
+
+			if backoff *= 2; backoff > syslogMaxBackoff {
+				backoff = syslogMaxBackoff
+			}
+		}
+	}
+}
+
+// terminate adds the trailer required to separate messages on stream connections.
+func (s *SyslogSink) terminate(msg string) string {
+	if strings.HasPrefix(s.network, "udp") || s.network == "unixgram" {
+		return msg
+	}
+	return msg + "\n"
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen for the syslog messages: %v", err)
+	}
+	defer conn.Close()
+
+	for _, framing := range []string{SyslogRFC5424, SyslogRFC3164} {
+		sink, err := NewSyslogSink("udp", conn.LocalAddr().String(), "local3", framing)
+		if err != nil {
+			t.Fatalf("Failed to create the syslog sink: %v", err)
+		}
+
+		sink.Send("name", &requests.Output{Name: "www.owasp.org", Domain: "owasp.org", Source: "DNS"})
+		sink.Close()
+
+		buf := make([]byte, 4096)
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Failed to receive the %s message: %v", framing, err)
+		}
+		msg := string(buf[:n])
+
+		// The local3 facility with the informational severity
+		if !strings.HasPrefix(msg, "<158>") {
+			t.Errorf("The %s message has the wrong priority: %s", framing, msg)
+		}
+		if framing == SyslogRFC5424 && !strings.HasPrefix(msg, "<158>1 ") {
+			t.Errorf("The message does not have the RFC5424 version: %s", msg)
+		}
+
+		var out requests.Output
+		if err := json.Unmarshal([]byte(msg[strings.Index(msg, "{"):]), &out); err != nil || out.Name != "www.owasp.org" {
+			t.Errorf("The %s message did not contain the output: %s", framing, msg)
+		}
+	}
+
+	if _, err := NewSyslogSink("udp", conn.LocalAddr().String(), "bogus", ""); err == nil {
+		t.Errorf("NewSyslogSink accepted an unknown facility")
+	}
+	if _, err := NewSyslogSink("udp", conn.LocalAddr().String(), "", "rfc9999"); err == nil {
+		t.Errorf("NewSyslogSink accepted an unknown format")
+	}
+}
+
+func TestSyslogSinkDrops(t *testing.T) {
+	// Nothing is listening on the address, so the messages cannot be delivered
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to obtain an unused address: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	sink, err := newSyslogSink("tcp", addr, "", "", 2)
+	if err != nil {
+		t.Fatalf("Failed to create the syslog sink: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			sink.Send("name", &requests.Output{Name: "www.owasp.org"})
+		}
+		sink.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The unavailable syslog server stalled the sink")
+	}
+	if sink.Dropped() != 10 {
+		t.Errorf("Expected 10 dropped messages, got %d", sink.Dropped())
+	}
+}
@@ -4,7 +4,12 @@
 package graph
 
 import (
+	"net"
+	"sort"
+
 	"github.com/OWASP/Amass/v3/graph/db"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -19,7 +24,187 @@ func (g *Graph) InsertAddress(addr, source, tag, eventID string) (db.Node, error
 		return node, err
 	}
 
-	return node, nil
+	return node, g.markSeen(node)
+}
+
+// MarkInternalAddress annotates the IP address as internal, such as a private or reserved address
+// leaked by split-horizon DNS, which is not investigated further.
+func (g *Graph) MarkInternalAddress(addr string) error {
+	node, err := g.db.ReadNode(addr, "ipaddr")
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(node, "internal", "true")
+}
+
+// IsInternalAddress returns true if the IP address was annotated as internal.
+func (g *Graph) IsInternalAddress(addr string) bool {
+	node, err := g.db.ReadNode(addr, "ipaddr")
+	if err != nil {
+		return false
+	}
+
+	p, err := g.db.ReadProperties(node, "internal")
+	return err == nil && len(p) > 0
+}
+
+// InsertUnexpandedAddress stores the IP address that was only mentioned in the collected data,
+// such as TXT records, and annotates it as not being used for infrastructure expansion.
+func (g *Graph) InsertUnexpandedAddress(addr, source, tag, eventID string) error {
+	node, err := g.InsertAddress(addr, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(node, "unexpanded", "true")
+}
+
+// IsUnexpandedAddress returns true if the IP address was annotated as unexpanded, and has not
+// since been resolved from the A or AAAA record of a name.
+func (g *Graph) IsUnexpandedAddress(addr string) bool {
+	node, err := g.db.ReadNode(addr, "ipaddr")
+	if err != nil {
+		return false
+	}
+
+	if p, err := g.db.ReadProperties(node, "unexpanded"); err != nil || len(p) == 0 {
+		return false
+	}
+
+	count, err := g.db.CountInEdges(node, "a_record", "aaaa_record")
+	return err == nil && count == 0
+}
+
+// The exposure tags assigned to names by UpdateNameExposure.
+const (
+	InternalName = "internal"
+	ExternalName = "external"
+)
+
+// UpdateNameExposure tags the FQDN as internal when all the addresses it resolves to are
+// private or reserved, such as names leaked by split-horizon DNS, and as external otherwise.
+// The tag is replaced as more records arrive and the new tag is returned.
+func (g *Graph) UpdateNameExposure(fqdn string) (string, error) {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return "", err
+	}
+
+	edges, err := g.db.ReadOutEdges(node, "a_record", "aaaa_record")
+	if err != nil {
+		return "", err
+	}
+	if len(edges) == 0 {
+		return "", nil
+	}
+
+	exposure := InternalName
+	for _, edge := range edges {
+		if reserved, _ := amassnet.IsReservedAddress(g.db.NodeToID(edge.To)); !reserved {
+			exposure = ExternalName
+			break
+		}
+	}
+
+	if p, err := g.db.ReadProperties(node, "exposure"); err == nil {
+		for _, prop := range p {
+			if prop.Value == exposure {
+				return exposure, nil
+			}
+			// Remove the previous tag before replacing it
+			g.db.DeleteProperty(node, prop.Predicate, prop.Value)
+		}
+	}
+
+	return exposure, g.db.InsertProperty(node, "exposure", exposure)
+}
+
+// ReadNameExposure returns the internal or external tag of the FQDN, or an empty string when
+// the name has not been tagged.
+func (g *Graph) ReadNameExposure(fqdn string) string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return ""
+	}
+
+	if p, err := g.db.ReadProperties(node, "exposure"); err == nil && len(p) > 0 {
+		return p[0].Value
+	}
+	return ""
+}
+
+// UpdateFCrDNS marks the address of the FQDN as forward-confirmed reverse DNS when both the
+// A or AAAA record from the FQDN to the address, and the PTR record from the reverse name of
+// the address back to the FQDN, are stored. True is returned when the pair is confirmed.
+func (g *Graph) UpdateFCrDNS(fqdn, addr string) (bool, error) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false, nil
+	}
+
+	fqdnNode, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return false, nil
+	}
+
+	// The edges are missing until the records have been stored
+	var ipNode db.Node
+	if forward, err := g.db.ReadOutEdges(fqdnNode, "a_record", "aaaa_record"); err == nil {
+		for _, edge := range forward {
+			if ip.Equal(net.ParseIP(g.db.NodeToID(edge.To))) {
+				ipNode = edge.To
+				break
+			}
+		}
+	}
+	if ipNode == nil {
+		return false, nil
+	}
+
+	ptrNode, err := g.db.ReadNode(amassdns.ReverseName(addr), "fqdn")
+	if err != nil {
+		return false, nil
+	}
+
+	reverse, err := g.db.ReadOutEdges(ptrNode, "ptr_record")
+	if err != nil {
+		return false, nil
+	}
+
+	for _, edge := range reverse {
+		if g.db.NodeToID(edge.To) == fqdn {
+			// The address is stored as a node, so the confirmation links the FQDN to it
+			return true, g.InsertEdge(&db.Edge{
+				Predicate: "fcrdns",
+				From:      fqdnNode,
+				To:        ipNode,
+			})
+		}
+	}
+	return false, nil
+}
+
+// IsFCrDNSConfirmed returns true if the address of the FQDN was marked as forward-confirmed reverse DNS.
+func (g *Graph) IsFCrDNSConfirmed(fqdn, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	if edges, err := g.db.ReadOutEdges(node, "fcrdns"); err == nil {
+		for _, edge := range edges {
+			if ip.Equal(net.ParseIP(g.db.NodeToID(edge.To))) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // InsertA creates FQDN, IP address and A record edge in the graph and associates them with a source and event.
@@ -44,9 +229,51 @@ func (g *Graph) InsertA(fqdn, addr, source, tag, eventID string) error {
 		return err
 	}
 
+	if g.MergeHosts {
+		return g.insertHostAddress(fqdnNode, ipNode, eventID)
+	}
 	return nil
 }
 
+// InsertTargetAddress links the FQDN to an address of its CNAME target, which allows the
+// addresses of out of scope targets to be associated with the in scope name.
+func (g *Graph) InsertTargetAddress(fqdn, addr, source, tag, eventID string) error {
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	ipNode, err := g.InsertAddress(addr, "DNS", requests.DNS, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.InsertEdge(&db.Edge{
+		Predicate: "target_address",
+		From:      fqdnNode,
+		To:        ipNode,
+	})
+}
+
+// ReadTargetAddresses returns the sorted addresses of the CNAME target linked to the FQDN.
+func (g *Graph) ReadTargetAddresses(fqdn string) []string {
+	var addrs []string
+
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return addrs
+	}
+
+	if edges, err := g.db.ReadOutEdges(node, "target_address"); err == nil {
+		for _, edge := range edges {
+			addrs = append(addrs, g.db.NodeToID(edge.To))
+		}
+	}
+
+	sort.Strings(addrs)
+	return addrs
+}
+
 // InsertAAAA creates FQDN, IP address and AAAA record edge in the graph and associates them with a source and event.
 func (g *Graph) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
 	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
@@ -69,5 +296,61 @@ func (g *Graph) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
 		return err
 	}
 
+	if g.MergeHosts {
+		return g.insertHostAddress(fqdnNode, ipNode, eventID)
+	}
 	return nil
 }
+
+// hostNodeID returns the ID of the host node for the FQDN, which cannot collide with the FQDN node.
+func hostNodeID(fqdn string) string {
+	return "host:" + fqdn
+}
+
+// insertHostAddress links the address to the host node that shares the name of the FQDN.
+func (g *Graph) insertHostAddress(fqdnNode, ipNode db.Node, eventID string) error {
+	hostNode, err := g.InsertNodeIfNotExist(hostNodeID(g.db.NodeToID(fqdnNode)), "host")
+	if err != nil {
+		return err
+	}
+
+	if err := g.AddNodeToEvent(hostNode, "DNS", requests.DNS, eventID); err != nil {
+		return err
+	}
+
+	hostEdge := &db.Edge{
+		Predicate: "host",
+		From:      fqdnNode,
+		To:        hostNode,
+	}
+	if err := g.InsertEdge(hostEdge); err != nil {
+		return err
+	}
+
+	return g.InsertEdge(&db.Edge{
+		Predicate: "host_address",
+		From:      hostNode,
+		To:        ipNode,
+	})
+}
+
+// HostAddresses returns the IPv4 and IPv6 addresses linked to the host node of the FQDN.
+func (g *Graph) HostAddresses(fqdn string) []string {
+	hostNode, err := g.db.ReadNode(hostNodeID(fqdn), "host")
+	if err != nil {
+		return nil
+	}
+
+	edges, err := g.db.ReadOutEdges(hostNode, "host_address")
+	if err != nil {
+		return nil
+	}
+
+	var addrs []string
+	for _, edge := range edges {
+		addrs = append(addrs, g.db.NodeToID(edge.To))
+	}
+
+	sort.Strings(addrs)
+	return addrs
+}
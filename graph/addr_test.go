@@ -42,3 +42,38 @@ func TestAddress(t *testing.T) {
 
 	g.Close()
 }
+
+func TestMergeHosts(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+	defer g.Close()
+	g.MergeHosts = true
+
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+	if err := g.InsertA("www.owasp.org", "104.22.26.77", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	if err := g.InsertAAAA("www.owasp.org", "2606:4700:10::6816:1a4d", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert the AAAA record: %v", err)
+	}
+
+	hosts, err := g.db.AllNodesOfType("host")
+	if err != nil || len(hosts) != 1 {
+		t.Fatalf("Expected one host node for the dual-stack name, got %d", len(hosts))
+	}
+
+	addrs := g.HostAddresses("www.owasp.org")
+	if len(addrs) != 2 || addrs[0] != "104.22.26.77" || addrs[1] != "2606:4700:10::6816:1a4d" {
+		t.Errorf("The host node did not link both addresses: %v", addrs)
+	}
+
+	// The flat model remains the default
+	flat := NewGraph(db.NewCayleyGraphMemory())
+	defer flat.Close()
+
+	if err := flat.InsertA("www.owasp.org", "104.22.26.77", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	if addrs := flat.HostAddresses("www.owasp.org"); len(addrs) != 0 {
+		t.Errorf("Host nodes were created without the option: %v", addrs)
+	}
+}
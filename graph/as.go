@@ -82,6 +82,35 @@ func (g *Graph) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eve
 	return nil
 }
 
+// InsertRawASDescription stores the unmodified description provided for an autonomous system in the graph.
+func (g *Graph) InsertRawASDescription(asn, raw string) error {
+	asNode, err := g.db.ReadNode(asn, "as")
+	if err != nil {
+		return err
+	}
+
+	if p, err := g.db.ReadProperties(asNode, "raw_description"); err == nil && len(p) > 0 {
+		if p[0].Value == raw {
+			return nil
+		}
+		// Update the 'raw_description' property
+		g.db.DeleteProperty(asNode, p[0].Predicate, p[0].Value)
+	}
+
+	return g.db.InsertProperty(asNode, "raw_description", raw)
+}
+
+// ReadRawASDescription returns the unmodified description of an autonomous system in the graph.
+func (g *Graph) ReadRawASDescription(asn string) string {
+	if asNode, err := g.db.ReadNode(asn, "as"); err == nil {
+		if p, err := g.db.ReadProperties(asNode, "raw_description"); err == nil && len(p) > 0 {
+			return p[0].Value
+		}
+	}
+
+	return ""
+}
+
 // ReadASDescription the description property of an autonomous system in the graph.
 func (g *Graph) ReadASDescription(asn string) string {
 	if asNode, err := g.db.ReadNode(asn, "as"); err == nil {
@@ -0,0 +1,134 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+// DefaultBatchWindow is the time an edge waits in a batch when no window is configured.
+const DefaultBatchWindow = 100 * time.Millisecond
+
+// Batcher collects the edges inserted into a graph database, and applies them with InsertBatch
+// once the batch reaches the size, or the window has elapsed since the first edge of the batch,
+// whichever comes first. Busy periods are written efficiently, while quiet periods do not
+// leave the edges waiting. The pending edges are applied when the context is cancelled.
+type Batcher struct {
+	db     db.GraphDatabase
+	size   int
+	window time.Duration
+
+	lock   sync.Mutex
+	edges  []*db.Edge
+	timer  *time.Timer
+	err    error
+	closed bool
+	done   chan struct{}
+}
+
+// NewBatcher returns a Batcher applying the edges to the graph database in batches of size
+// edges. A window of zero selects the DefaultBatchWindow.
+func NewBatcher(ctx context.Context, database db.GraphDatabase, size int, window time.Duration) *Batcher {
+	if size <= 0 {
+		size = 1
+	}
+	if window <= 0 {
+		window = DefaultBatchWindow
+	}
+
+	b := &Batcher{
+		db:     database,
+		size:   size,
+		window: window,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.Close()
+		case <-b.done:
+		}
+	}()
+	return b
+}
+
+// Add queues the edge, and applies the batch once it reaches the size. The edges added after
+// the Batcher was closed are inserted right away. An error returned by a batch applied when
+// the window elapsed is returned by the next call.
+func (b *Batcher) Add(edge *db.Edge) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return b.db.InsertEdge(edge)
+	}
+
+	b.edges = append(b.edges, edge)
+	if len(b.edges) >= b.size {
+		return b.flush()
+	}
+	// The window starts with the first edge of the batch
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.flushWindow)
+	}
+	return b.takeErr()
+}
+
+// Flush applies the pending edges to the graph database.
+func (b *Batcher) Flush() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.flush()
+}
+
+// Close applies the pending edges, and the edges added afterward are inserted right away.
+func (b *Batcher) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		close(b.done)
+	}
+	return b.flush()
+}
+
+func (b *Batcher) flushWindow() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.flush(); err != nil {
+		b.err = err
+	}
+}
+
+func (b *Batcher) flush() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.edges) == 0 {
+		return b.takeErr()
+	}
+
+	edges := b.edges
+	b.edges = nil
+	if err := b.db.InsertBatch(edges); err != nil {
+		return err
+	}
+	return b.takeErr()
+}
+
+// takeErr returns the error of the last batch applied when the window elapsed, and clears it.
+func (b *Batcher) takeErr() error {
+	err := b.err
+	b.err = nil
+	return err
+}
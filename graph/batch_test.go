@@ -0,0 +1,163 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+// batchCountingDatabase records the size of each batch applied to the wrapped graph database.
+type batchCountingDatabase struct {
+	db.GraphDatabase
+	sync.Mutex
+	batches []int
+}
+
+func (b *batchCountingDatabase) InsertBatch(edges []*db.Edge) error {
+	b.Lock()
+	b.batches = append(b.batches, len(edges))
+	b.Unlock()
+
+	return b.GraphDatabase.InsertBatch(edges)
+}
+
+func (b *batchCountingDatabase) sizes() []int {
+	b.Lock()
+	defer b.Unlock()
+
+	return append([]int(nil), b.batches...)
+}
+
+func newBatchTestGraph(t *testing.T) (*batchCountingDatabase, db.Node, []db.Node) {
+	database := &batchCountingDatabase{GraphDatabase: db.NewCayleyGraphMemory()}
+
+	from, err := database.InsertNode("batch.owasp.org", "fqdn")
+	if err != nil {
+		t.Fatalf("Failed to insert the node: %v", err)
+	}
+
+	var to []db.Node
+	for i := 0; i < 5; i++ {
+		node, err := database.InsertNode("10.0.0."+strconv.Itoa(i+1), "ipaddr")
+		if err != nil {
+			t.Fatalf("Failed to insert the node: %v", err)
+		}
+		to = append(to, node)
+	}
+	return database, from, to
+}
+
+func batchEdgeCount(database db.GraphDatabase, from db.Node) int {
+	edges, err := database.ReadOutEdges(from, "a_record")
+	if err != nil {
+		return 0
+	}
+	return len(edges)
+}
+
+func TestBatcherSizeTrigger(t *testing.T) {
+	database, from, to := newBatchTestGraph(t)
+	defer database.Close()
+
+	b := NewBatcher(context.Background(), database, 3, time.Hour)
+	defer b.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := b.Add(&db.Edge{Predicate: "a_record", From: from, To: to[i]}); err != nil {
+			t.Fatalf("Failed to add the edge: %v", err)
+		}
+	}
+	if n := batchEdgeCount(database, from); n != 0 {
+		t.Errorf("%d edges were inserted before the batch reached the size", n)
+	}
+
+	if err := b.Add(&db.Edge{Predicate: "a_record", From: from, To: to[2]}); err != nil {
+		t.Fatalf("Failed to add the edge: %v", err)
+	}
+	if n := batchEdgeCount(database, from); n != 3 {
+		t.Errorf("The batch reaching the size inserted %d edges, expected 3", n)
+	}
+	if sizes := database.sizes(); len(sizes) != 1 || sizes[0] != 3 {
+		t.Errorf("The batches applied were %v, expected [3]", sizes)
+	}
+}
+
+func TestBatcherTimeTrigger(t *testing.T) {
+	database, from, to := newBatchTestGraph(t)
+	defer database.Close()
+
+	window := 50 * time.Millisecond
+	b := NewBatcher(context.Background(), database, 100, window)
+	defer b.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := b.Add(&db.Edge{Predicate: "a_record", From: from, To: to[i]}); err != nil {
+			t.Fatalf("Failed to add the edge: %v", err)
+		}
+	}
+	if n := batchEdgeCount(database, from); n != 0 {
+		t.Errorf("%d edges were inserted before the window elapsed", n)
+	}
+
+	deadline := time.Now().Add(20 * window)
+	for batchEdgeCount(database, from) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("The edges were not inserted after the window elapsed")
+		}
+		time.Sleep(window / 5)
+	}
+	if sizes := database.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Errorf("The batches applied were %v, expected [2]", sizes)
+	}
+}
+
+func TestBatcherContextCancellation(t *testing.T) {
+	database, from, to := newBatchTestGraph(t)
+	defer database.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := NewBatcher(ctx, database, 100, time.Hour)
+
+	if err := b.Add(&db.Edge{Predicate: "a_record", From: from, To: to[0]}); err != nil {
+		t.Fatalf("Failed to add the edge: %v", err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for batchEdgeCount(database, from) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("The pending edge was not inserted when the context was cancelled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The edges added afterward are inserted right away
+	if err := b.Add(&db.Edge{Predicate: "a_record", From: from, To: to[1]}); err != nil {
+		t.Fatalf("Failed to add the edge: %v", err)
+	}
+	if n := batchEdgeCount(database, from); n != 2 {
+		t.Errorf("The edge added after the cancellation was not inserted, %d edges found", n)
+	}
+}
+
+func TestGraphCloseFlushesBatch(t *testing.T) {
+	database, from, to := newBatchTestGraph(t)
+
+	g := NewGraph(database)
+	g.EnableBatching(context.Background(), 100, time.Hour)
+	if err := g.InsertEdge(&db.Edge{Predicate: "a_record", From: from, To: to[0]}); err != nil {
+		t.Fatalf("Failed to insert the edge: %v", err)
+	}
+	g.Close()
+
+	if sizes := database.sizes(); len(sizes) != 1 || sizes[0] != 1 {
+		t.Errorf("Closing the graph applied the batches %v, expected [1]", sizes)
+	}
+}
@@ -149,8 +149,8 @@ func (g *CayleyGraph) removeAllNodeQuads(id string) error {
 
 	// Build the transaction that will perform the deletion
 	t := cayley.NewTransaction()
-	for _, predicate := range g.nodePredicates(id, "both") {
-		path := cayley.StartPath(g.store, quad.String(id)).Both(quad.String(predicate))
+	for _, predicate := range g.nodePredicates(id, "out") {
+		path := cayley.StartPath(g.store, quad.String(id)).Out(quad.String(predicate))
 
 		g.optimizedIterate(path, func(val quad.Value) {
 			vstr := quad.ToString(val)
@@ -158,6 +158,16 @@ func (g *CayleyGraph) removeAllNodeQuads(id string) error {
 			t.RemoveQuad(quad.Make(id, predicate, vstr, nil))
 		})
 	}
+	// The edges pointing to the node are removed as well
+	for _, predicate := range g.nodePredicates(id, "in") {
+		path := cayley.StartPath(g.store, quad.String(id)).In(quad.String(predicate))
+
+		g.optimizedIterate(path, func(val quad.Value) {
+			vstr := quad.ToString(val)
+
+			t.RemoveQuad(quad.Make(vstr, predicate, id, nil))
+		})
+	}
 
 	// Attempt to perform the deletion transaction
 	return g.store.ApplyTransaction(t)
@@ -449,6 +459,32 @@ func (g *CayleyGraph) InsertEdge(edge *Edge) error {
 	return g.store.AddQuad(quad.Make(nstr1, edge.Predicate, nstr2, nil))
 }
 
+// InsertBatch implements the GraphDatabase interface.
+func (g *CayleyGraph) InsertBatch(edges []*Edge) error {
+	g.Lock()
+	defer g.Unlock()
+
+	quads := make([]quad.Quad, 0, len(edges))
+	for _, edge := range edges {
+		nstr1 := g.NodeToID(edge.From)
+		nstr2 := g.NodeToID(edge.To)
+		if nstr1 == "" || nstr2 == "" {
+			return fmt.Errorf("%s: InsertBatch: Invalid edge argument", g.String())
+		}
+
+		for _, nstr := range []string{nstr1, nstr2} {
+			p := cayley.StartPath(g.store, quad.String(nstr)).Has(quad.String("type"))
+			if first := g.optimizedFirst(p); first == nil {
+				return fmt.Errorf("%s: InsertBatch: Node %s does not exist", g.String(), nstr)
+			}
+		}
+
+		quads = append(quads, quad.Make(nstr1, edge.Predicate, nstr2, nil))
+	}
+
+	return g.store.AddQuadSet(quads)
+}
+
 // ReadEdges implements the GraphDatabase interface.
 func (g *CayleyGraph) ReadEdges(node Node, predicates ...string) ([]*Edge, error) {
 	nstr := g.NodeToID(node)
@@ -49,6 +49,8 @@ type GraphDatabase interface {
 
 	// Graph operations for adding and removing edges
 	InsertEdge(edge *Edge) error
+	// Inserts the edges at once, where the nodes of each edge must already exist
+	InsertBatch(edges []*Edge) error
 	ReadEdges(node Node, predicates ...string) ([]*Edge, error)
 	ReadInEdges(node Node, predicates ...string) ([]*Edge, error)
 	CountInEdges(node Node, predicates ...string) (int, error)
@@ -334,6 +334,16 @@ func (g *Gremlin) InsertEdge(edge *Edge) error {
 	return g.store.AddQuad(quad.Make(nstr1, edge.Predicate, nstr2, nil))
 }
 
+// InsertBatch implements the GraphDatabase interface.
+func (g *Gremlin) InsertBatch(edges []*Edge) error {
+	for _, edge := range edges {
+		if err := g.InsertEdge(edge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ReadEdges implements the GraphDatabase interface.
 func (g *Gremlin) ReadEdges(node Node, predicates ...string) ([]*Edge, error) {
 	nstr := g.NodeToID(node)
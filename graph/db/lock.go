@@ -0,0 +1,68 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package db
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+// WriterLockFile is created in the graph database directory while an enumeration writes to the
+// database, so the commands modifying the stored data can refuse to run.
+const WriterLockFile = "writer.lock"
+
+// WriterLock marks the graph database directory as being written by the process.
+type WriterLock struct {
+	path string
+}
+
+// AcquireWriterLock creates the writer lock file, containing the process ID, in the graph
+// database directory.
+func AcquireWriterLock(dir string) (*WriterLock, error) {
+	dir = config.OutputDirectory(dir)
+	if dir == "" {
+		return nil, errors.New("AcquireWriterLock: Failed to obtain the graph database directory")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, WriterLockFile)
+	if err := ioutil.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		return nil, err
+	}
+	return &WriterLock{path: path}, nil
+}
+
+// Release removes the writer lock file.
+func (l *WriterLock) Release() error {
+	if l == nil {
+		return nil
+	}
+	return os.Remove(l.path)
+}
+
+// ActiveWriter returns true when the writer lock file exists in the graph database directory,
+// along with the ID of the process that created it. The file is left behind by an enumeration
+// that did not shut down cleanly, and can be removed once no enumeration is running.
+func ActiveWriter(dir string) (int, bool) {
+	dir = config.OutputDirectory(dir)
+	if dir == "" {
+		return 0, false
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, WriterLockFile))
+	if err != nil {
+		return 0, false
+	}
+
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, true
+}
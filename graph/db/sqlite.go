@@ -0,0 +1,637 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3" // Need the SQLite driver
+)
+
+// The schema keeps the graph in the nodes, properties and edges tables, while the views
+// provide the normalized names, records, addrs and infra tables for portable result access.
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS nodes (
+		id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		PRIMARY KEY (id, type))`,
+	`CREATE TABLE IF NOT EXISTS properties (
+		node TEXT NOT NULL,
+		predicate TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (node, predicate, value))`,
+	`CREATE TABLE IF NOT EXISTS edges (
+		from_node TEXT NOT NULL,
+		predicate TEXT NOT NULL,
+		to_node TEXT NOT NULL,
+		PRIMARY KEY (from_node, predicate, to_node))`,
+	`CREATE INDEX IF NOT EXISTS nodes_type ON nodes (type)`,
+	`CREATE INDEX IF NOT EXISTS edges_to_node ON edges (to_node, predicate)`,
+	`CREATE VIEW IF NOT EXISTS names AS
+		SELECT id AS name FROM nodes WHERE type = 'fqdn'`,
+	`CREATE VIEW IF NOT EXISTS addrs AS
+		SELECT id AS addr FROM nodes WHERE type = 'ipaddr'`,
+	`CREATE VIEW IF NOT EXISTS records AS
+		SELECT from_node AS name, upper(substr(predicate, 1, length(predicate) - 7)) AS type, to_node AS data
+		FROM edges WHERE predicate LIKE '%\_record' ESCAPE '\'`,
+	`CREATE VIEW IF NOT EXISTS infra AS
+		SELECT p.from_node AS asn, d.value AS description, p.to_node AS netblock, c.to_node AS addr
+		FROM edges AS p
+		JOIN edges AS c ON c.from_node = p.to_node AND c.predicate = 'contains'
+		LEFT JOIN properties AS d ON d.node = p.from_node AND d.predicate = 'description'
+		WHERE p.predicate = 'prefix'`,
+}
+
+// SQLiteGraph is the object for managing a network infrastructure link graph in a SQLite file.
+type SQLiteGraph struct {
+	sync.Mutex
+	db   *sql.DB
+	path string
+}
+
+// NewSQLiteGraph returns an intialized SQLiteGraph object using the file at the path.
+func NewSQLiteGraph(path string) *SQLiteGraph {
+	if path == "" {
+		return nil
+	}
+
+	// If the directory does not yet exist, create it
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil
+	}
+	// The receiver serializes access to the single connection, while the WAL
+	// journal allows other processes to read the file during the enumeration
+	db.SetMaxOpenConns(1)
+
+	pragmas := []string{
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA busy_timeout = 5000",
+	}
+	for _, stmt := range append(pragmas, sqliteSchema...) {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil
+		}
+	}
+
+	return &SQLiteGraph{
+		db:   db,
+		path: path,
+	}
+}
+
+// Close implements the GraphDatabase interface.
+func (g *SQLiteGraph) Close() {
+	g.db.Close()
+}
+
+// String returns a description for the SQLiteGraph object.
+func (g *SQLiteGraph) String() string {
+	return "SQLite Graph"
+}
+
+// NodeToID implements the GraphDatabase interface.
+func (g *SQLiteGraph) NodeToID(n Node) string {
+	return fmt.Sprintf("%s", n)
+}
+
+// InsertNode implements the GraphDatabase interface.
+func (g *SQLiteGraph) InsertNode(id, ntype string) (Node, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	if id == "" || ntype == "" {
+		return nil, fmt.Errorf("%s: InsertNode: Empty required arguments", g.String())
+	}
+
+	_, err := g.db.Exec("INSERT OR IGNORE INTO nodes (id, type) VALUES (?, ?)", id, ntype)
+	return id, err
+}
+
+// ReadNode implements the GraphDatabase interface.
+func (g *SQLiteGraph) ReadNode(id, ntype string) (Node, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	if id == "" || ntype == "" {
+		return nil, fmt.Errorf("%s: ReadNode: Empty required arguments", g.String())
+	}
+
+	// Check that a node with 'id' already exists
+	if g.nodeExists(id) {
+		return id, nil
+	}
+
+	return nil, fmt.Errorf("%s: ReadNode: Node %s does not exist", g.String(), id)
+}
+
+// DeleteNode implements the GraphDatabase interface.
+func (g *SQLiteGraph) DeleteNode(node Node) error {
+	g.Lock()
+	defer g.Unlock()
+
+	id := g.NodeToID(node)
+	if id == "" {
+		return fmt.Errorf("%s: DeleteNode: Empty node id provided", g.String())
+	}
+
+	if !g.nodeExists(id) {
+		return fmt.Errorf("%s: DeleteNode: Node %s does not exist", g.String(), id)
+	}
+
+	tx, err := g.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range []string{
+		"DELETE FROM nodes WHERE id = ?1",
+		"DELETE FROM properties WHERE node = ?1",
+		"DELETE FROM edges WHERE from_node = ?1 OR to_node = ?1",
+	} {
+		if _, err := tx.Exec(stmt, id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// AllNodesOfType implements the GraphDatabase interface.
+func (g *SQLiteGraph) AllNodesOfType(ntype string, events ...string) ([]Node, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	var nodes []Node
+	if ntype == "event" && len(events) > 0 {
+		for _, event := range events {
+			nodes = append(nodes, event)
+		}
+
+		return nodes, nil
+	}
+
+	var ids []string
+	var err error
+	if ntype == "event" {
+		ids, err = g.queryStrings("SELECT id FROM nodes WHERE type = 'event'")
+	} else {
+		in, args := sqliteIn("e.from_node", events)
+
+		ids, err = g.queryStrings(`SELECT DISTINCT e.to_node FROM edges AS e
+			JOIN nodes AS ev ON ev.id = e.from_node AND ev.type = 'event'
+			JOIN nodes AS n ON n.id = e.to_node AND n.type = ? WHERE 1 = 1`+in, append([]interface{}{ntype}, args...)...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: AllNodesOfType: %v", g.String(), err)
+	}
+
+	for _, id := range ids {
+		nodes = append(nodes, id)
+	}
+
+	return nodes, nil
+}
+
+// NameToIPAddrs implements the GraphDatabase interface.
+func (g *SQLiteGraph) NameToIPAddrs(node Node) ([]Node, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return nil, fmt.Errorf("%s: NameToIPAddrs: Invalid node reference argument", g.String())
+	}
+
+	queries := []string{
+		// Does this name have A/AAAA records?
+		`SELECT to_node FROM edges WHERE from_node = ? AND predicate IN ('a_record', 'aaaa_record')`,
+		// Attempt to traverse a SRV record
+		`SELECT a.to_node FROM edges AS s
+			JOIN edges AS a ON a.from_node = s.to_node AND a.predicate IN ('a_record', 'aaaa_record')
+			WHERE s.from_node = ? AND s.predicate = 'srv_record'`,
+		// Traverse CNAME records
+		`WITH RECURSIVE chain(name, depth) AS (
+			SELECT to_node, 1 FROM edges WHERE from_node = ? AND predicate = 'cname_record'
+			UNION
+			SELECT e.to_node, c.depth + 1 FROM edges AS e
+			JOIN chain AS c ON e.from_node = c.name
+			WHERE e.predicate = 'cname_record' AND c.depth < 10)
+		SELECT DISTINCT a.to_node FROM chain AS c
+			JOIN edges AS a ON a.from_node = c.name AND a.predicate IN ('a_record', 'aaaa_record')`,
+	}
+
+	var nodes []Node
+	for _, query := range queries {
+		addrs, err := g.queryStrings(query, nstr)
+		if err != nil {
+			return nodes, fmt.Errorf("%s: NameToIPAddrs: %v", g.String(), err)
+		}
+
+		for _, addr := range addrs {
+			nodes = append(nodes, addr)
+		}
+		if len(nodes) > 0 {
+			return nodes, nil
+		}
+	}
+
+	return nodes, fmt.Errorf("%s: NameToIPAddrs: No addresses were discovered for %s", g.String(), nstr)
+}
+
+// NodeSources implements the GraphDatabase interface.
+func (g *SQLiteGraph) NodeSources(node Node, events ...string) ([]string, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return nil, fmt.Errorf("%s: NodeSources: Invalid node reference argument", g.String())
+	}
+
+	in, args := sqliteIn("e.from_node", events)
+	preds, err := g.queryStrings(`SELECT DISTINCT e.predicate FROM edges AS e
+		JOIN nodes AS ev ON ev.id = e.from_node AND ev.type = 'event'
+		WHERE e.to_node = ?`+in, append([]interface{}{nstr}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: NodeSources: %v", g.String(), err)
+	}
+
+	var sources []string
+	for _, pred := range preds {
+		if !notDataSourceSet.Has(pred) {
+			sources = append(sources, pred)
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%s: NodeSources: Failed to discover edges leaving the node %s", g.String(), nstr)
+	}
+
+	return sources, nil
+}
+
+// InsertProperty implements the GraphDatabase interface.
+func (g *SQLiteGraph) InsertProperty(node Node, predicate, value string) error {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return fmt.Errorf("%s: InsertProperty: Invalid node reference argument", g.String())
+	}
+
+	// Check if the node has already been inserted
+	if !g.nodeExists(nstr) {
+		return fmt.Errorf("%s: InsertProperty: Node %s does not exist", g.String(), nstr)
+	}
+
+	_, err := g.db.Exec("INSERT OR IGNORE INTO properties (node, predicate, value) VALUES (?, ?, ?)",
+		nstr, predicate, value)
+	return err
+}
+
+// ReadProperties implements the GraphDatabase interface.
+// The node types are provided as the 'type' properties, consistent with the CayleyGraph.
+func (g *SQLiteGraph) ReadProperties(node Node, predicates ...string) ([]*Property, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	var properties []*Property
+
+	if nstr == "" {
+		return properties, fmt.Errorf("%s: ReadProperties: Invalid node reference argument", g.String())
+	}
+
+	in, args := sqliteIn("predicate", predicates)
+	rows, err := g.db.Query(`SELECT predicate, value FROM (
+		SELECT 'type' AS predicate, type AS value FROM nodes WHERE id = ?1
+		UNION ALL
+		SELECT predicate, value FROM properties WHERE node = ?1) WHERE 1 = 1`+in,
+		append([]interface{}{nstr}, args...)...)
+	if err != nil {
+		return properties, fmt.Errorf("%s: ReadProperties: %v", g.String(), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := new(Property)
+
+		if err := rows.Scan(&p.Predicate, &p.Value); err == nil {
+			properties = append(properties, p)
+		}
+	}
+
+	if len(properties) == 0 {
+		return properties, fmt.Errorf("%s: ReadProperties: No properties discovered", g.String())
+	}
+
+	return properties, nil
+}
+
+// CountProperties implements the GraphDatabase interface.
+func (g *SQLiteGraph) CountProperties(node Node, predicates ...string) (int, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return 0, fmt.Errorf("%s: CountProperties: Invalid node reference argument", g.String())
+	}
+
+	in, args := sqliteIn("predicate", predicates)
+	return g.queryCount(`SELECT COUNT(*) FROM (
+		SELECT 'type' AS predicate FROM nodes WHERE id = ?1
+		UNION ALL
+		SELECT predicate FROM properties WHERE node = ?1) WHERE 1 = 1`+in,
+		append([]interface{}{nstr}, args...)...)
+}
+
+// DeleteProperty implements the GraphDatabase interface.
+func (g *SQLiteGraph) DeleteProperty(node Node, predicate, value string) error {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return fmt.Errorf("%s: DeleteProperty: Invalid node reference argument", g.String())
+	}
+
+	_, err := g.db.Exec("DELETE FROM properties WHERE node = ? AND predicate = ? AND value = ?",
+		nstr, predicate, value)
+	return err
+}
+
+// InsertEdge implements the GraphDatabase interface.
+func (g *SQLiteGraph) InsertEdge(edge *Edge) error {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr1 := g.NodeToID(edge.From)
+	nstr2 := g.NodeToID(edge.To)
+	if nstr1 == "" || nstr2 == "" {
+		return fmt.Errorf("%s: InsertEdge: Invalid edge argument", g.String())
+	}
+
+	// Check if the from node has already been inserted
+	if !g.nodeExists(nstr1) {
+		return fmt.Errorf("%s: InsertEdge: Node %s does not exist", g.String(), nstr1)
+	}
+
+	// Check if the to node has already been inserted
+	if !g.nodeExists(nstr2) {
+		return fmt.Errorf("%s: InsertEdge: Node %s does not exist", g.String(), nstr2)
+	}
+
+	_, err := g.db.Exec("INSERT OR IGNORE INTO edges (from_node, predicate, to_node) VALUES (?, ?, ?)",
+		nstr1, edge.Predicate, nstr2)
+	return err
+}
+
+// InsertBatch implements the GraphDatabase interface.
+func (g *SQLiteGraph) InsertBatch(edges []*Edge) error {
+	g.Lock()
+	defer g.Unlock()
+
+	// The nodes are checked before the transaction holds the only connection
+	for _, edge := range edges {
+		nstr1 := g.NodeToID(edge.From)
+		nstr2 := g.NodeToID(edge.To)
+		if nstr1 == "" || nstr2 == "" {
+			return fmt.Errorf("%s: InsertBatch: Invalid edge argument", g.String())
+		}
+
+		for _, nstr := range []string{nstr1, nstr2} {
+			if !g.nodeExists(nstr) {
+				return fmt.Errorf("%s: InsertBatch: Node %s does not exist", g.String(), nstr)
+			}
+		}
+	}
+
+	tx, err := g.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, edge := range edges {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO edges (from_node, predicate, to_node) VALUES (?, ?, ?)",
+			g.NodeToID(edge.From), edge.Predicate, g.NodeToID(edge.To)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReadEdges implements the GraphDatabase interface.
+func (g *SQLiteGraph) ReadEdges(node Node, predicates ...string) ([]*Edge, error) {
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return nil, fmt.Errorf("%s: ReadEdges: Invalid node reference argument", g.String())
+	}
+
+	var edges []*Edge
+	if e, err := g.ReadInEdges(node, predicates...); err == nil {
+		edges = append(edges, e...)
+	}
+
+	if e, err := g.ReadOutEdges(node, predicates...); err == nil {
+		edges = append(edges, e...)
+	}
+
+	if len(edges) == 0 {
+		return nil, fmt.Errorf("%s: ReadEdges: Failed to discover edges for the node %s", g.String(), nstr)
+	}
+
+	return edges, nil
+}
+
+// ReadInEdges implements the GraphDatabase interface.
+func (g *SQLiteGraph) ReadInEdges(node Node, predicates ...string) ([]*Edge, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return nil, fmt.Errorf("%s: ReadInEdges: Invalid node reference argument", g.String())
+	}
+
+	in, args := sqliteIn("predicate", predicates)
+	rows, err := g.db.Query("SELECT predicate, from_node FROM edges WHERE to_node = ?"+in,
+		append([]interface{}{nstr}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: ReadInEdges: %v", g.String(), err)
+	}
+	defer rows.Close()
+
+	var edges []*Edge
+	for rows.Next() {
+		var pred, from string
+
+		if err := rows.Scan(&pred, &from); err == nil {
+			edges = append(edges, &Edge{
+				Predicate: pred,
+				From:      from,
+				To:        node,
+			})
+		}
+	}
+
+	if len(edges) == 0 {
+		return nil, fmt.Errorf("%s: ReadInEdges: Failed to discover edges coming into the node %s", g.String(), nstr)
+	}
+
+	return edges, nil
+}
+
+// CountInEdges implements the GraphDatabase interface.
+func (g *SQLiteGraph) CountInEdges(node Node, predicates ...string) (int, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return 0, fmt.Errorf("%s: CountInEdges: Invalid node reference argument", g.String())
+	}
+
+	in, args := sqliteIn("predicate", predicates)
+	return g.queryCount("SELECT COUNT(*) FROM edges WHERE to_node = ?"+in,
+		append([]interface{}{nstr}, args...)...)
+}
+
+// ReadOutEdges implements the GraphDatabase interface.
+func (g *SQLiteGraph) ReadOutEdges(node Node, predicates ...string) ([]*Edge, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return nil, fmt.Errorf("%s: ReadOutEdges: Invalid node reference argument", g.String())
+	}
+
+	in, args := sqliteIn("predicate", predicates)
+	rows, err := g.db.Query("SELECT predicate, to_node FROM edges WHERE from_node = ?"+in,
+		append([]interface{}{nstr}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: ReadOutEdges: %v", g.String(), err)
+	}
+	defer rows.Close()
+
+	var edges []*Edge
+	for rows.Next() {
+		var pred, to string
+
+		if err := rows.Scan(&pred, &to); err == nil {
+			edges = append(edges, &Edge{
+				Predicate: pred,
+				From:      node,
+				To:        to,
+			})
+		}
+	}
+
+	if len(edges) == 0 {
+		return nil, fmt.Errorf("%s: ReadOutEdges: Failed to discover edges leaving the node %s", g.String(), nstr)
+	}
+
+	return edges, nil
+}
+
+// CountOutEdges implements the GraphDatabase interface.
+func (g *SQLiteGraph) CountOutEdges(node Node, predicates ...string) (int, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	nstr := g.NodeToID(node)
+	if nstr == "" {
+		return 0, fmt.Errorf("%s: CountOutEdges: Invalid node reference argument", g.String())
+	}
+
+	in, args := sqliteIn("predicate", predicates)
+	return g.queryCount("SELECT COUNT(*) FROM edges WHERE from_node = ?"+in,
+		append([]interface{}{nstr}, args...)...)
+}
+
+// DeleteEdge implements the GraphDatabase interface.
+func (g *SQLiteGraph) DeleteEdge(edge *Edge) error {
+	g.Lock()
+	defer g.Unlock()
+
+	from := g.NodeToID(edge.From)
+	to := g.NodeToID(edge.To)
+	if from == "" || to == "" {
+		return fmt.Errorf("%s: DeleteEdge: Invalid edge reference argument", g.String())
+	}
+
+	_, err := g.db.Exec("DELETE FROM edges WHERE from_node = ? AND predicate = ? AND to_node = ?",
+		from, edge.Predicate, to)
+	return err
+}
+
+// nodeExists returns true when a node with the id has been inserted, regardless of the type.
+// MAKE SURE TO ACQUIRE THE LOCK PRIOR TO EXECUTING THIS METHOD
+func (g *SQLiteGraph) nodeExists(id string) bool {
+	var found int
+
+	err := g.db.QueryRow("SELECT 1 FROM nodes WHERE id = ? LIMIT 1", id).Scan(&found)
+	return err == nil && found == 1
+}
+
+func (g *SQLiteGraph) queryStrings(query string, args ...interface{}) ([]string, error) {
+	rows, err := g.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []string
+	for rows.Next() {
+		var s string
+
+		if err := rows.Scan(&s); err == nil {
+			results = append(results, s)
+		}
+	}
+
+	return results, rows.Err()
+}
+
+func (g *SQLiteGraph) queryCount(query string, args ...interface{}) (int, error) {
+	var count int
+
+	if err := g.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s: %v", g.String(), err)
+	}
+
+	return count, nil
+}
+
+// sqliteIn returns the condition restricting the column to the values, and the query
+// arguments, or an empty condition when no values are provided.
+func sqliteIn(column string, values []string) (string, []interface{}) {
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	var args []interface{}
+	for _, v := range values {
+		args = append(args, v)
+	}
+
+	return " AND " + column + " IN (?" + strings.Repeat(", ?", len(values)-1) + ")", args
+}
@@ -5,11 +5,11 @@ package graph
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/OWASP/Amass/v3/graph/db"
 	"github.com/OWASP/Amass/v3/stringset"
-	"golang.org/x/net/publicsuffix"
 )
 
 // InsertEvent create an event node in the graph that represents a discovery task.
@@ -158,7 +158,7 @@ func (g *Graph) EventDomains(uuid string) []string {
 
 	domains := stringset.New()
 	for _, name := range names {
-		d, err := publicsuffix.EffectiveTLDPlusOne(g.db.NodeToID(name))
+		d, err := g.registeredDomain(g.db.NodeToID(name))
 
 		if err == nil && d != "" {
 			domains.Insert(d)
@@ -178,7 +178,7 @@ func (g *Graph) EventSubdomains(events ...string) []string {
 	var names []string
 	for _, n := range nodes {
 		d := g.db.NodeToID(n)
-		etld, err := publicsuffix.EffectiveTLDPlusOne(d)
+		etld, err := g.registeredDomain(d)
 		if err != nil || etld == d {
 			continue
 		}
@@ -207,3 +207,73 @@ func (g *Graph) EventDateRange(uuid string) (time.Time, time.Time) {
 
 	return start, finish
 }
+
+// EventDeletion reports what was removed from the graph by DeleteEvent.
+type EventDeletion struct {
+	Edges int
+	Nodes int
+}
+
+// DeleteEvent removes the edges of the event, along with the event and the nodes no longer
+// referenced by the remaining events. No enumeration can be writing to the graph while the
+// event is removed, since the new references to the nodes could be lost.
+func (g *Graph) DeleteEvent(uuid string) (*EventDeletion, error) {
+	removed := new(EventDeletion)
+
+	eventNode, err := g.db.ReadNode(uuid, "event")
+	if err != nil {
+		return removed, fmt.Errorf("DeleteEvent: Event %s does not exist", uuid)
+	}
+
+	events := stringset.New(g.EventList()...)
+	events.Remove(uuid)
+
+	var nodes []db.Node
+	seen := stringset.New()
+	edges, _ := g.db.ReadOutEdges(eventNode)
+	for _, edge := range edges {
+		if err := g.db.DeleteEdge(edge); err != nil {
+			return removed, err
+		}
+		removed.Edges++
+
+		if id := g.db.NodeToID(edge.To); !seen.Has(id) {
+			seen.Insert(id)
+			nodes = append(nodes, edge.To)
+		}
+	}
+
+	// The nodes are kept when any of the remaining events reference them
+	for _, node := range nodes {
+		if g.referencedByEvents(node, events) {
+			continue
+		}
+
+		if err := g.db.DeleteNode(node); err != nil {
+			return removed, err
+		}
+		removed.Nodes++
+	}
+
+	if err := g.db.DeleteNode(eventNode); err != nil {
+		return removed, err
+	}
+	removed.Nodes++
+
+	g.eventFinishLock.Lock()
+	delete(g.eventFinishes, uuid)
+	g.eventFinishLock.Unlock()
+	return removed, nil
+}
+
+func (g *Graph) referencedByEvents(node db.Node, events stringset.Set) bool {
+	// An error is returned when the node has no edges coming in
+	edges, _ := g.db.ReadInEdges(node)
+
+	for _, edge := range edges {
+		if events.Has(g.db.NodeToID(edge.From)) {
+			return true
+		}
+	}
+	return false
+}
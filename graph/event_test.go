@@ -103,3 +103,44 @@ func TestEvent(t *testing.T) {
 	}
 	g.Close()
 }
+
+func TestDeleteEvent(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	first, second := "ef9f9475-34ff-4a71-b2da-e1c2a2e5f9b2", "5c1f8e93-3b9a-4d4b-8a1e-3c5d7e0f2a41"
+	for _, e := range []string{first, second} {
+		if _, err := g.InsertEvent(e); err != nil {
+			t.Fatalf("Failed to insert the event: %v", err)
+		}
+	}
+	if _, err := g.InsertFQDN("only.owasp.org", "DNS", "dns", first); err != nil {
+		t.Fatalf("Failed to insert the name: %v", err)
+	}
+	for _, e := range []string{first, second} {
+		if _, err := g.InsertFQDN("shared.owasp.org", "DNS", "dns", e); err != nil {
+			t.Fatalf("Failed to insert the name: %v", err)
+		}
+	}
+
+	removed, err := g.DeleteEvent(first)
+	if err != nil {
+		t.Fatalf("Failed to delete the event: %v", err)
+	}
+	if removed.Edges == 0 || removed.Nodes < 2 {
+		t.Errorf("DeleteEvent reported %d edges and %d nodes removed", removed.Edges, removed.Nodes)
+	}
+
+	if events := g.EventList(); !reflect.DeepEqual(events, []string{second}) {
+		t.Errorf("EventList returned %v after the deletion", events)
+	}
+	if _, err := g.db.ReadNode("only.owasp.org", "fqdn"); err == nil {
+		t.Errorf("The name only referenced by the deleted event was kept")
+	}
+	if _, err := g.db.ReadNode("shared.owasp.org", "fqdn"); err != nil {
+		t.Errorf("The name referenced by the remaining event was removed")
+	}
+
+	if _, err := g.DeleteEvent(first); err == nil {
+		t.Errorf("DeleteEvent did not fail for the removed event")
+	}
+}
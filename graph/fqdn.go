@@ -5,16 +5,21 @@ package graph
 
 import (
 	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/OWASP/Amass/v3/graph/db"
-	"golang.org/x/net/publicsuffix"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
 )
 
 // InsertFQDN adds a fully qualified domain name to the graph.
 func (g *Graph) InsertFQDN(name, source, tag, eventID string) (db.Node, error) {
-	tld, _ := publicsuffix.PublicSuffix(name)
+	name = amassdns.Canonical(name)
+	tld := g.publicSuffix(name)
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(name)
+	domain, err := g.registeredDomain(name)
 	if err != nil {
 		return nil, errors.New("InsertFQDN: Failed to obtain valid domain name(s)")
 	}
@@ -73,7 +78,7 @@ func (g *Graph) InsertFQDN(name, source, tag, eventID string) (db.Node, error) {
 		return fqdnNode, err
 	}
 
-	return fqdnNode, nil
+	return fqdnNode, g.markSeen(fqdnNode)
 }
 
 // InsertCNAME adds the FQDNs and CNAME record between them to the graph.
@@ -81,6 +86,37 @@ func (g *Graph) InsertCNAME(fqdn, target, source, tag, eventID string) error {
 	return g.insertAlias(fqdn, target, "cname_record", source, tag, eventID)
 }
 
+// InsertWildcardCNAME adds the single node representing the wildcard CNAME of the zone, such as
+// *.example.com, with the CNAME record to the target shared by the names below the zone.
+func (g *Graph) InsertWildcardCNAME(zone, target, source, tag, eventID string) error {
+	zone = amassdns.Canonical(zone)
+	if zone == "" {
+		return errors.New("InsertWildcardCNAME: Empty zone provided")
+	}
+
+	pattern := "*." + zone
+	if err := g.insertAlias(pattern, target, "cname_record", source, tag, eventID); err != nil {
+		return err
+	}
+
+	node, err := g.db.ReadNode(pattern, "fqdn")
+	if err != nil {
+		return err
+	}
+	return g.insertUniqueProperty(node, "wildcard_cname", "true")
+}
+
+// IsWildcardCNAME returns true if the FQDN is the node representing a wildcard CNAME.
+func (g *Graph) IsWildcardCNAME(fqdn string) bool {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	p, err := g.db.ReadProperties(node, "wildcard_cname")
+	return err == nil && len(p) > 0
+}
+
 // IsCNAMENode returns true if the FQDN has a CNAME edge to another FQDN in the graph.
 func (g *Graph) IsCNAMENode(fqdn string) bool {
 	return g.checkForOutEdge(fqdn, "cname_record")
@@ -148,6 +184,661 @@ func (g *Graph) IsMXNode(fqdn string) bool {
 	return g.checkForInEdge(fqdn, "mx_record")
 }
 
+// InsertRcode increments the number of times the DNS response code was observed for the FQDN.
+func (g *Graph) InsertRcode(fqdn, rcode, source, tag, eventID string) error {
+	if rcode == "" {
+		return errors.New("InsertRcode: Empty response code provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.incrementCount(fqdnNode, "rcode", rcode, 1)
+}
+
+// ReadRcodes returns the DNS response codes observed for the FQDN and the number of times each was observed.
+func (g *Graph) ReadRcodes(fqdn string) map[string]int {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return make(map[string]int)
+	}
+
+	return g.readCounts(node, "rcode")
+}
+
+// InsertRecordSetSignature stores the signature of the FQDN record set and returns the
+// signature that was previously stored, or an empty string when none existed.
+func (g *Graph) InsertRecordSetSignature(fqdn, sig, source, tag, eventID string) (string, error) {
+	if sig == "" {
+		return "", errors.New("InsertRecordSetSignature: Empty signature provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return "", err
+	}
+
+	var prev string
+	if p, err := g.db.ReadProperties(fqdnNode, "record_set"); err == nil && len(p) > 0 {
+		prev = p[0].Value
+		if prev == sig {
+			return prev, nil
+		}
+
+		for _, prop := range p {
+			g.db.DeleteProperty(fqdnNode, prop.Predicate, prop.Value)
+		}
+	}
+
+	return prev, g.db.InsertProperty(fqdnNode, "record_set", sig)
+}
+
+// ReadRecordSetSignature returns the signature of the record set stored for the FQDN.
+func (g *Graph) ReadRecordSetSignature(fqdn string) string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return ""
+	}
+
+	if p, err := g.db.ReadProperties(node, "record_set"); err == nil && len(p) > 0 {
+		return p[0].Value
+	}
+	return ""
+}
+
+// InsertSeed links the FQDN to the seed domain that led to its discovery. The seeds accumulate
+// when the FQDN is discovered by way of multiple seed domains.
+func (g *Graph) InsertSeed(fqdn, seed, source, tag, eventID string) error {
+	seed = strings.ToLower(strings.TrimSpace(seed))
+	if seed == "" {
+		return errors.New("InsertSeed: Empty seed domain provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	// The seed domain is stored as a node, since it is always a name within the graph
+	seedNode, err := g.InsertNodeIfNotExist(seed, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	return g.InsertEdge(&db.Edge{
+		Predicate: "seed",
+		From:      fqdnNode,
+		To:        seedNode,
+	})
+}
+
+// MarkQueryOrigin flags the FQDN as a query entry point, since the name entered the enumeration
+// directly, rather than being derived from the records of another name.
+func (g *Graph) MarkQueryOrigin(fqdn, source, tag, eventID string) error {
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "is_query_origin", "true")
+}
+
+// IsQueryOrigin returns true when the FQDN entered the enumeration directly.
+func (g *Graph) IsQueryOrigin(fqdn string) bool {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	p, err := g.db.ReadProperties(node, "is_query_origin")
+	return err == nil && len(p) > 0
+}
+
+// InsertCNAMEPath adds the chain of CNAME records that led to the FQDN, ordered from the owner
+// name to the FQDN. The paths accumulate when the FQDN is reached through multiple chains.
+func (g *Graph) InsertCNAMEPath(fqdn string, path []string, source, tag, eventID string) error {
+	if len(path) < 2 {
+		return errors.New("InsertCNAMEPath: The path must contain the owner and the FQDN")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "cname_path", strings.Join(path, ","))
+}
+
+// InsertConfirmation records whether the FQDN was confirmed by active DNS resolution, or only
+// reported by passive sources. Once confirmed, the FQDN is no longer flagged as passive-only.
+func (g *Graph) InsertConfirmation(fqdn string, confirmed bool, source, tag, eventID string) error {
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	value := strconv.FormatBool(!confirmed)
+	if p, err := g.db.ReadProperties(fqdnNode, "passive_only"); err == nil {
+		for _, prop := range p {
+			// A confirmed FQDN is never flagged as passive-only again
+			if prop.Value == value || prop.Value == "false" {
+				return nil
+			}
+			// Remove the passive-only flag before the FQDN is confirmed
+			g.db.DeleteProperty(fqdnNode, prop.Predicate, prop.Value)
+		}
+	}
+
+	return g.db.InsertProperty(fqdnNode, "passive_only", value)
+}
+
+// IsPassiveOnly returns true if the FQDN was only reported by passive sources and never
+// confirmed by active DNS resolution.
+func (g *Graph) IsPassiveOnly(fqdn string) bool {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	return g.isPassiveOnly(node)
+}
+
+func (g *Graph) isPassiveOnly(node db.Node) bool {
+	if p, err := g.db.ReadProperties(node, "passive_only"); err == nil {
+		for _, prop := range p {
+			if prop.Value == "true" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// InsertAuthServer adds the authoritative server that answered queries for the FQDN directly,
+// as opposed to the recursive resolvers used for most queries.
+func (g *Graph) InsertAuthServer(fqdn, server, source, tag, eventID string) error {
+	server = amassdns.Canonical(server)
+	if server == "" {
+		return errors.New("InsertAuthServer: Empty server provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	// The server is commonly the target of an NS record stored as a node
+	serverNode, err := g.InsertNodeIfNotExist(server, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	return g.InsertEdge(&db.Edge{
+		Predicate: "auth_server",
+		From:      fqdnNode,
+		To:        serverNode,
+	})
+}
+
+// InsertClientSubnetAddress stores the address returned for the FQDN when the query carried the
+// EDNS client subnet, so the addresses served by geo-distributed infrastructure to each subnet
+// can be compared.
+func (g *Graph) InsertClientSubnetAddress(fqdn, subnet, addr, source, tag, eventID string) error {
+	_, ipnet, err := net.ParseCIDR(strings.TrimSpace(subnet))
+	if err != nil {
+		return errors.New("InsertClientSubnetAddress: Invalid client subnet provided")
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return errors.New("InsertClientSubnetAddress: Invalid address provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "ecs_address", ipnet.String()+" "+ip.String())
+}
+
+// InsertMultiPTR annotates the reverse DNS name as having multiple PTR records, which is
+// common for shared hosting, and stores all the targets including those out of scope.
+func (g *Graph) InsertMultiPTR(fqdn string, targets []string, source, tag, eventID string) error {
+	if len(targets) < 2 {
+		return errors.New("InsertMultiPTR: Less than two PTR targets provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := g.insertUniqueProperty(fqdnNode, "multi_ptr", "true"); err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		target = amassdns.Canonical(target)
+		if target == "" {
+			continue
+		}
+
+		// The out of scope targets are not added to the event
+		targetNode, err := g.InsertNodeIfNotExist(target, "fqdn")
+		if err != nil {
+			return err
+		}
+
+		if err := g.InsertEdge(&db.Edge{
+			Predicate: "ptr_target",
+			From:      fqdnNode,
+			To:        targetNode,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsMultiPTR returns true if the reverse DNS name was annotated as having multiple PTR records.
+func (g *Graph) IsMultiPTR(fqdn string) bool {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	p, err := g.db.ReadProperties(node, "multi_ptr")
+	return err == nil && len(p) > 0
+}
+
+// ReadPTRTargets returns the sorted targets stored for a reverse DNS name with multiple PTR records.
+func (g *Graph) ReadPTRTargets(fqdn string) []string {
+	var targets []string
+
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return targets
+	}
+
+	if edges, err := g.db.ReadOutEdges(node, "ptr_target"); err == nil {
+		for _, edge := range edges {
+			targets = append(targets, g.db.NodeToID(edge.To))
+		}
+	}
+
+	sort.Strings(targets)
+	return targets
+}
+
+// InsertCloudService annotates the FQDN, commonly a CNAME target, with the canonical name
+// of the cloud service it belongs to, so the noisy service hostnames can be grouped.
+func (g *Graph) InsertCloudService(fqdn, service, source, tag, eventID string) error {
+	if service == "" {
+		return errors.New("InsertCloudService: Empty cloud service name provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "cloud_service", service)
+}
+
+// ReadCloudService returns the canonical name of the cloud service the FQDN belongs to.
+func (g *Graph) ReadCloudService(fqdn string) string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return ""
+	}
+
+	if p, err := g.db.ReadProperties(node, "cloud_service"); err == nil && len(p) > 0 {
+		return p[0].Value
+	}
+	return ""
+}
+
+// InsertCDN annotates the FQDN with the provider of the content delivery network fronting it,
+// so the name can be deprioritized for host-level scanning.
+func (g *Graph) InsertCDN(fqdn, provider, source, tag, eventID string) error {
+	if provider == "" {
+		return errors.New("InsertCDN: Empty CDN provider provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "cdn", provider)
+}
+
+// ReadCDN returns the provider of the content delivery network fronting the FQDN.
+func (g *Graph) ReadCDN(fqdn string) string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return ""
+	}
+
+	if p, err := g.db.ReadProperties(node, "cdn"); err == nil && len(p) > 0 {
+		return p[0].Value
+	}
+	return ""
+}
+
+// MarkCappedDomain annotates the root domain name as having reached the maximum number of
+// records stored, so the data for the domain is known to be incomplete.
+func (g *Graph) MarkCappedDomain(domain string) error {
+	node, err := g.InsertNodeIfNotExist(domain, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(node, "capped", "true")
+}
+
+// IsCappedDomain returns true if the root domain name was annotated as capped.
+func (g *Graph) IsCappedDomain(domain string) bool {
+	node, err := g.db.ReadNode(domain, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	p, err := g.db.ReadProperties(node, "capped")
+	return err == nil && len(p) > 0
+}
+
+// InsertAuthenticatedRecord annotates the FQDN with a record of the provided type and data that
+// carried the DNSSEC authenticated-data flag when it was resolved.
+func (g *Graph) InsertAuthenticatedRecord(fqdn, rrtype, data, source, tag, eventID string) error {
+	if rrtype == "" || data == "" {
+		return errors.New("InsertAuthenticatedRecord: Empty record type or data provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "authenticated", recordKey(rrtype, data))
+}
+
+// IsAuthenticatedRecord returns true when the record of the FQDN was marked as DNSSEC authenticated.
+func (g *Graph) IsAuthenticatedRecord(fqdn, rrtype, data string) bool {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	value := recordKey(rrtype, data)
+	if p, err := g.db.ReadProperties(node, "authenticated"); err == nil {
+		for _, prop := range p {
+			if prop.Value == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// InsertSelfReference flags the record of the FQDN whose data points to the FQDN itself.
+func (g *Graph) InsertSelfReference(fqdn, rrtype, data, source, tag, eventID string) error {
+	if rrtype == "" || data == "" {
+		return errors.New("InsertSelfReference: Empty record type or data provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "self_reference", recordKey(rrtype, data))
+}
+
+// IsSelfReference returns true when the record of the FQDN was flagged as pointing to the FQDN itself.
+func (g *Graph) IsSelfReference(fqdn, rrtype, data string) bool {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	value := recordKey(rrtype, data)
+	if p, err := g.db.ReadProperties(node, "self_reference"); err == nil {
+		for _, prop := range p {
+			if prop.Value == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordKey returns the key that identifies the record of the type with the data within the
+// property values of the FQDN node.
+func recordKey(rrtype, data string) string {
+	return strings.ToUpper(rrtype) + " " + data
+}
+
+// ReadAuthServers returns the sorted authoritative servers that answered queries for the FQDN.
+func (g *Graph) ReadAuthServers(fqdn string) []string {
+	var servers []string
+
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return servers
+	}
+
+	if edges, err := g.db.ReadOutEdges(node, "auth_server"); err == nil {
+		for _, edge := range edges {
+			servers = append(servers, g.db.NodeToID(edge.To))
+		}
+	}
+
+	sort.Strings(servers)
+	return servers
+}
+
+// ReadClientSubnetAddresses returns the sorted addresses returned for the FQDN, keyed by the
+// EDNS client subnet the queries carried.
+func (g *Graph) ReadClientSubnetAddresses(fqdn string) map[string][]string {
+	addrs := make(map[string][]string)
+
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return addrs
+	}
+
+	if p, err := g.db.ReadProperties(node, "ecs_address"); err == nil {
+		for _, prop := range p {
+			parts := strings.SplitN(prop.Value, " ", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			addrs[parts[0]] = append(addrs[parts[0]], parts[1])
+		}
+	}
+
+	for _, list := range addrs {
+		sort.Strings(list)
+	}
+	return addrs
+}
+
+// ReadSeeds returns the sorted seed domains that led to the discovery of the FQDN.
+func (g *Graph) ReadSeeds(fqdn string) []string {
+	var seeds []string
+
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return seeds
+	}
+
+	if edges, err := g.db.ReadOutEdges(node, "seed"); err == nil {
+		for _, edge := range edges {
+			seeds = append(seeds, g.db.NodeToID(edge.To))
+		}
+	}
+
+	sort.Strings(seeds)
+	return seeds
+}
+
+// ReadCNAMEPaths returns the sorted chains of CNAME records that led to the FQDN.
+func (g *Graph) ReadCNAMEPaths(fqdn string) [][]string {
+	var paths [][]string
+
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return paths
+	}
+
+	p, err := g.db.ReadProperties(node, "cname_path")
+	if err != nil {
+		return paths
+	}
+
+	values := make([]string, 0, len(p))
+	for _, prop := range p {
+		values = append(values, prop.Value)
+	}
+
+	sort.Strings(values)
+	for _, v := range values {
+		paths = append(paths, strings.Split(v, ","))
+	}
+	return paths
+}
+
+// InsertUnicodeName adds the Unicode form of the internationalized FQDN, which is stored in
+// the punycode form, for display purposes.
+func (g *Graph) InsertUnicodeName(fqdn, unicode, source, tag, eventID string) error {
+	unicode = strings.TrimSpace(unicode)
+	if unicode == "" {
+		return errors.New("InsertUnicodeName: Empty Unicode name provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "unicode_name", unicode)
+}
+
+// ReadUnicodeName returns the Unicode form of the internationalized FQDN, or an empty string
+// when the name is not internationalized.
+func (g *Graph) ReadUnicodeName(fqdn string) string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return ""
+	}
+
+	if p, err := g.db.ReadProperties(node, "unicode_name"); err == nil && len(p) > 0 {
+		return p[0].Value
+	}
+	return ""
+}
+
+// InsertRawTXT adds the unmodified data of a TXT record, including any control characters,
+// to the FQDN in the graph.
+func (g *Graph) InsertRawTXT(fqdn, raw, source, tag, eventID string) error {
+	if raw == "" {
+		return errors.New("InsertRawTXT: Empty TXT data provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "raw_txt", raw)
+}
+
+// ReadRawTXT returns the unmodified TXT data stored for the FQDN.
+func (g *Graph) ReadRawTXT(fqdn string) []string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	var txt []string
+	if p, err := g.db.ReadProperties(node, "raw_txt"); err == nil {
+		for _, prop := range p {
+			txt = append(txt, prop.Value)
+		}
+	}
+	return txt
+}
+
+// InsertGeofeed adds the URL of a RFC 8805 geofeed referenced by the records of the FQDN.
+func (g *Graph) InsertGeofeed(fqdn, url, source, tag, eventID string) error {
+	if url == "" {
+		return errors.New("InsertGeofeed: Empty geofeed URL provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "geofeed", url)
+}
+
+// ReadGeofeeds returns the geofeed URLs referenced by the records of the FQDN.
+func (g *Graph) ReadGeofeeds(fqdn string) []string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	var urls []string
+	if p, err := g.db.ReadProperties(node, "geofeed"); err == nil {
+		for _, prop := range p {
+			urls = append(urls, prop.Value)
+		}
+	}
+	return urls
+}
+
+// InsertReputation annotates the FQDN with the verdict of the reputation service.
+func (g *Graph) InsertReputation(fqdn, service, verdict, source, tag, eventID string) error {
+	if service == "" || verdict == "" {
+		return errors.New("InsertReputation: Empty reputation service or verdict provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertUniqueProperty(fqdnNode, "reputation", service+":"+verdict)
+}
+
+// ReadReputation returns the verdicts stored for the FQDN, keyed by the reputation service.
+func (g *Graph) ReadReputation(fqdn string) map[string]string {
+	verdicts := make(map[string]string)
+
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return verdicts
+	}
+
+	if p, err := g.db.ReadProperties(node, "reputation"); err == nil {
+		for _, prop := range p {
+			if parts := strings.SplitN(prop.Value, ":", 2); len(parts) == 2 {
+				verdicts[parts[0]] = parts[1]
+			}
+		}
+	}
+	return verdicts
+}
+
 // IsRootDomainNode returns true if the FQDN has a 'root' edge pointing to it in the graph.
 func (g *Graph) IsRootDomainNode(fqdn string) bool {
 	return g.checkForInEdge(fqdn, "root")
@@ -181,3 +872,71 @@ func (g *Graph) checkForOutEdge(id, predicate string) bool {
 
 	return false
 }
+
+// MergeCanonicalNames moves the edges and properties of FQDN nodes stored under a
+// non-canonical name, such as mixed-case names written by earlier versions, onto the
+// node of the canonical name and removes the old node. The number of merged nodes is returned.
+func (g *Graph) MergeCanonicalNames() (int, error) {
+	nodes, err := g.db.AllNodesOfType("fqdn")
+	if err != nil {
+		return 0, err
+	}
+
+	var merged int
+	for _, node := range nodes {
+		id := g.db.NodeToID(node)
+		name := amassdns.Canonical(id)
+		if name == "" || name == id {
+			continue
+		}
+
+		if err := g.mergeNode(node, id, name); err != nil {
+			return merged, err
+		}
+		merged++
+	}
+
+	return merged, nil
+}
+
+func (g *Graph) mergeNode(node db.Node, id, name string) error {
+	cnode, err := g.InsertNodeIfNotExist(name, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	replace := func(n db.Node) db.Node {
+		if g.db.NodeToID(n) == id {
+			return cnode
+		}
+		return n
+	}
+
+	var edges []*db.Edge
+	if out, err := g.db.ReadOutEdges(node); err == nil {
+		edges = append(edges, out...)
+	}
+	if in, err := g.db.ReadInEdges(node); err == nil {
+		edges = append(edges, in...)
+	}
+
+	for _, edge := range edges {
+		if err := g.InsertEdge(&db.Edge{
+			Predicate: edge.Predicate,
+			From:      replace(edge.From),
+			To:        replace(edge.To),
+		}); err != nil {
+			return err
+		}
+	}
+
+	if props, err := g.db.ReadProperties(node); err == nil {
+		for _, p := range props {
+			if err := g.insertUniqueProperty(cnode, p.Predicate, p.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return g.db.DeleteNode(node)
+}
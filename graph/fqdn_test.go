@@ -106,3 +106,91 @@ func TestFQDN(t *testing.T) {
 
 	g.Close()
 }
+
+func TestRcodes(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	for _, tt := range graphTest {
+		observed := []string{"NOERROR", "SERVFAIL", "NOERROR", "REFUSED", "NOERROR"}
+
+		for _, rcode := range observed {
+			if err := g.InsertRcode(tt.FQDN, rcode, tt.Source, tt.Tag, tt.EventID); err != nil {
+				t.Errorf("Failed inserting the response code %s.\n%v", rcode, err)
+			}
+		}
+
+		got := g.ReadRcodes(tt.FQDN)
+		expected := map[string]int{"NOERROR": 3, "SERVFAIL": 1, "REFUSED": 1}
+		if len(got) != len(expected) {
+			t.Errorf("Expected %d response codes, got %d: %v", len(expected), len(got), got)
+		}
+
+		for rcode, count := range expected {
+			if got[rcode] != count {
+				t.Errorf("Expected %s to be observed %d times, got %d", rcode, count, got[rcode])
+			}
+		}
+	}
+}
+
+func TestSeeds(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	for _, tt := range graphTest {
+		for _, seed := range []string{"example.com", "owasp.org", "example.com"} {
+			if err := g.InsertSeed(tt.FQDN, seed, tt.Source, tt.Tag, tt.EventID); err != nil {
+				t.Errorf("Failed inserting the seed %s.\n%v", seed, err)
+			}
+		}
+
+		got := g.ReadSeeds(tt.FQDN)
+		if len(got) != 2 || got[0] != "example.com" || got[1] != "owasp.org" {
+			t.Errorf("Expected the seeds to accumulate without duplicates, got %v", got)
+		}
+	}
+}
+
+func TestMergeCanonicalNames(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	// Simulate the mixed-case node written before the names were canonicalized
+	mixed, err := g.db.InsertNode("WWW.Owasp.org", "fqdn")
+	if err != nil {
+		t.Fatalf("Failed to insert the mixed-case node: %v", err)
+	}
+	if err := g.AddNodeToEvent(mixed, "DNS", "dns", "ef9f9475-34eb-465e-81eb-77c944822d0f"); err != nil {
+		t.Fatalf("Failed to link the mixed-case node to the event: %v", err)
+	}
+	addr, _ := g.InsertNodeIfNotExist("192.0.2.10", "ipaddr")
+	if err := g.InsertEdge(&db.Edge{Predicate: "a_record", From: mixed, To: addr}); err != nil {
+		t.Fatalf("Failed to insert the edge: %v", err)
+	}
+	seed, _ := g.InsertNodeIfNotExist("owasp.org", "fqdn")
+	if err := g.InsertEdge(&db.Edge{Predicate: "seed", From: mixed, To: seed}); err != nil {
+		t.Fatalf("Failed to insert the seed edge: %v", err)
+	}
+	if err := g.db.InsertProperty(mixed, "is_query_origin", "true"); err != nil {
+		t.Fatalf("Failed to insert the property: %v", err)
+	}
+
+	merged, err := g.MergeCanonicalNames()
+	if err != nil {
+		t.Fatalf("MergeCanonicalNames returned an error: %v", err)
+	}
+	if merged != 1 {
+		t.Errorf("Expected 1 merged node, got %d", merged)
+	}
+
+	if _, err := g.db.ReadNode("WWW.Owasp.org", "fqdn"); err == nil {
+		t.Errorf("The mixed-case node was not removed")
+	}
+	if !g.checkForOutEdge("www.owasp.org", "a_record") {
+		t.Errorf("The a_record edge was not moved to the canonical node")
+	}
+	if seeds := g.ReadSeeds("www.owasp.org"); len(seeds) != 1 || seeds[0] != "owasp.org" {
+		t.Errorf("The seed edge was not moved to the canonical node, got %v", seeds)
+	}
+	if !g.IsQueryOrigin("www.owasp.org") {
+		t.Errorf("The properties were not moved to the canonical node")
+	}
+}
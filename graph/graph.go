@@ -4,9 +4,14 @@
 package graph
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/OWASP/Amass/v3/graph/db"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
 )
 
 // Graph implements the Amass network infrastructure data model.
@@ -14,6 +19,15 @@ type Graph struct {
 	db            db.GraphDatabase
 	alreadyClosed bool
 
+	// batcher applies the inserted edges in batches, when batching has been enabled
+	batcher *Batcher
+
+	// MergeHosts causes the IPv4 and IPv6 addresses of a name to be linked to a common host node
+	MergeHosts bool
+
+	// Suffixes derives the registered domains of the names, where nil selects the default settings
+	Suffixes *amassdns.SuffixList
+
 	// eventFinishes maintains a cache of the latest finish time for each event
 	// This reduces roundtrips to the graph when adding nodes to events.
 	eventFinishes   map[string]string
@@ -36,6 +50,10 @@ func NewGraph(database db.GraphDatabase) *Graph {
 func (g *Graph) Close() {
 	if !g.alreadyClosed {
 		g.alreadyClosed = true
+		// The pending edges are applied before the graph database is closed
+		if g.batcher != nil {
+			g.batcher.Close()
+		}
 		g.db.Close()
 	}
 }
@@ -45,6 +63,22 @@ func (g *Graph) String() string {
 	return g.db.String()
 }
 
+// registeredDomain returns the registered domain of the name, honoring the suffix settings of the Graph.
+func (g *Graph) registeredDomain(name string) (string, error) {
+	if g.Suffixes == nil {
+		return amassdns.RegisteredDomain(name)
+	}
+	return g.Suffixes.RegisteredDomain(name)
+}
+
+// publicSuffix returns the public suffix of the name, honoring the suffix settings of the Graph.
+func (g *Graph) publicSuffix(name string) string {
+	if g.Suffixes == nil {
+		return amassdns.PublicSuffix(name)
+	}
+	return g.Suffixes.PublicSuffix(name)
+}
+
 // InsertNodeIfNotExist will create a node in the database if it does not already exist.
 func (g *Graph) InsertNodeIfNotExist(id, ntype string) (db.Node, error) {
 	node, err := g.db.ReadNode(id, ntype)
@@ -55,7 +89,80 @@ func (g *Graph) InsertNodeIfNotExist(id, ntype string) (db.Node, error) {
 	return node, err
 }
 
+// EnableBatching causes the edges to be inserted in batches of size edges, or after the window
+// has elapsed, whichever comes first. The pending edges are applied when the context is cancelled
+// or the Graph is closed, while the edges are not returned by the reads until they are applied.
+func (g *Graph) EnableBatching(ctx context.Context, size int, window time.Duration) {
+	g.batcher = NewBatcher(ctx, g.db, size, window)
+}
+
 // InsertEdge will create an edge in the database if it does not already exist.
 func (g *Graph) InsertEdge(edge *db.Edge) error {
+	if g.batcher != nil {
+		return g.batcher.Add(edge)
+	}
 	return g.db.InsertEdge(edge)
 }
+
+// insertUniqueProperty adds the value to the predicate properties of the node if not already present.
+func (g *Graph) insertUniqueProperty(node db.Node, predicate, value string) error {
+	if p, err := g.db.ReadProperties(node, predicate); err == nil {
+		for _, prop := range p {
+			if prop.Value == value {
+				return nil
+			}
+		}
+	}
+
+	return g.db.InsertProperty(node, predicate, value)
+}
+
+// incrementCount adds num to the count stored for the key within the predicate property values of the node.
+func (g *Graph) incrementCount(node db.Node, predicate, key string, num int) error {
+	var count int
+
+	if p, err := g.db.ReadProperties(node, predicate); err == nil {
+		for _, prop := range p {
+			k, c, ok := splitCount(prop.Value)
+			if !ok || k != key {
+				continue
+			}
+
+			count = c
+			// Remove the existing property before updating the count
+			g.db.DeleteProperty(node, prop.Predicate, prop.Value)
+			break
+		}
+	}
+
+	return g.db.InsertProperty(node, predicate, key+":"+strconv.Itoa(count+num))
+}
+
+// readCounts returns the keys and counts stored within the predicate property values of the node.
+func (g *Graph) readCounts(node db.Node, predicate string) map[string]int {
+	counts := make(map[string]int)
+
+	if p, err := g.db.ReadProperties(node, predicate); err == nil {
+		for _, prop := range p {
+			if k, c, ok := splitCount(prop.Value); ok {
+				counts[k] = c
+			}
+		}
+	}
+
+	return counts
+}
+
+func splitCount(value string) (string, int, bool) {
+	idx := strings.LastIndex(value, ":")
+	if idx <= 0 {
+		return "", 0, false
+	}
+
+	count, err := strconv.Atoi(value[idx+1:])
+	if err != nil {
+		return "", 0, false
+	}
+
+	return value[:idx], count, true
+}
@@ -6,13 +6,13 @@ package graph
 import (
 	"math/rand"
 	"net"
+	"sort"
 	"strconv"
 
 	"github.com/OWASP/Amass/v3/graph/db"
 	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
-	"golang.org/x/net/publicsuffix"
 )
 
 // EventOutput returns findings within the receiver Graph for the event identified by the uuid string
@@ -84,18 +84,23 @@ func (g *Graph) buildOutput(sub db.Node, uuid string, cache *amassnet.ASNCache,
 	}
 	src := sources[0]
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(substr)
+	domain, err := g.registeredDomain(substr)
 	if err != nil {
 		c <- nil
 		return
 	}
 
 	output := &requests.Output{
-		Name:   substr,
-		Domain: domain,
-		Tag:    g.SourceTag(src),
-		Source: src,
+		Name:    substr,
+		Domain:  domain,
+		Tag:     g.SourceTag(src),
+		Source:  src,
+		Sources: g.sourceAttribution(sub, sources),
+		Records: g.nameRecords(sub),
+		Ports:   g.readPorts(sub),
 	}
+	output.FirstSeen, output.LastSeen = g.readSeen(sub)
+	output.PassiveOnly = g.isPassiveOnly(sub)
 
 	addrs, err := g.db.NameToIPAddrs(sub)
 	if err != nil {
@@ -130,6 +135,35 @@ func (g *Graph) buildOutput(sub db.Node, uuid string, cache *amassnet.ASNCache,
 	c <- output
 }
 
+// sourceAttribution returns the data sources that reported the node and the number of records each contributed.
+func (g *Graph) sourceAttribution(node db.Node, sources []string) []requests.SourceInfo {
+	counts := g.readCounts(node, "source_count")
+
+	var attrs []requests.SourceInfo
+	for _, src := range sources {
+		count := counts[src]
+		// The data source reported the name at least once
+		if count == 0 {
+			count = 1
+		}
+
+		attrs = append(attrs, requests.SourceInfo{
+			Source: src,
+			Tag:    g.SourceTag(src),
+			Count:  count,
+		})
+	}
+
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].Count != attrs[j].Count {
+			return attrs[i].Count > attrs[j].Count
+		}
+		return attrs[i].Source < attrs[j].Source
+	})
+
+	return attrs
+}
+
 func randomIndex(length int) int {
 	if length == 1 {
 		return 0
@@ -146,6 +180,7 @@ func (g *Graph) buildAddrInfo(addr db.Node, uuid string, cache *amassnet.ASNCach
 
 	address := g.db.NodeToID(addr)
 	ainfo := &requests.AddressInfo{Address: net.ParseIP(address)}
+	ainfo.FirstSeen, ainfo.LastSeen = g.readSeen(addr)
 	// Check the ASNCache before querying the graph database
 	if a := cache.AddrSearch(address); a != nil {
 		var err error
@@ -0,0 +1,114 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+// InsertRecordMetadata attaches the metadata key-value pairs to the record of the FQDN. The value
+// of a key already attached to the record is replaced.
+func (g *Graph) InsertRecordMetadata(fqdn, rrtype, data string, metadata map[string]string, source, tag, eventID string) error {
+	if rrtype == "" || data == "" {
+		return errors.New("InsertRecordMetadata: Empty record type or data provided")
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	for k, v := range metadata {
+		if k == "" || strings.ContainsAny(k, "=\t") || strings.Contains(v, "\t") {
+			return fmt.Errorf("InsertRecordMetadata: Invalid metadata key-value pair: %q=%q", k, v)
+		}
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	prefix := recordKey(rrtype, data) + "\t"
+	existing := make(map[string]string)
+	if p, err := g.db.ReadProperties(fqdnNode, "record_metadata"); err == nil {
+		for _, prop := range p {
+			if strings.HasPrefix(prop.Value, prefix) {
+				if k, _, ok := splitMetadata(prop.Value[len(prefix):]); ok {
+					existing[k] = prop.Value
+				}
+			}
+		}
+	}
+
+	for k, v := range metadata {
+		value := prefix + k + "=" + v
+		if old, found := existing[k]; found {
+			if old == value {
+				continue
+			}
+			// Remove the existing property before updating the value
+			g.db.DeleteProperty(fqdnNode, "record_metadata", old)
+		}
+
+		if err := g.db.InsertProperty(fqdnNode, "record_metadata", value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadRecordMetadata returns the metadata key-value pairs attached to the record of the FQDN.
+func (g *Graph) ReadRecordMetadata(fqdn, rrtype, data string) map[string]string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return make(map[string]string)
+	}
+
+	metadata := g.readRecordMetadata(node)[recordKey(rrtype, data)]
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	return metadata
+}
+
+// readRecordMetadata returns the metadata attached to the records of the node, keyed by the record.
+func (g *Graph) readRecordMetadata(node db.Node) map[string]map[string]string {
+	records := make(map[string]map[string]string)
+
+	p, err := g.db.ReadProperties(node, "record_metadata")
+	if err != nil {
+		return records
+	}
+
+	for _, prop := range p {
+		// The record data can contain spaces, but not tabs
+		idx := strings.LastIndex(prop.Value, "\t")
+		if idx <= 0 {
+			continue
+		}
+
+		k, v, ok := splitMetadata(prop.Value[idx+1:])
+		if !ok {
+			continue
+		}
+
+		record := prop.Value[:idx]
+		if records[record] == nil {
+			records[record] = make(map[string]string)
+		}
+		records[record][k] = v
+	}
+	return records
+}
+
+func splitMetadata(pair string) (string, string, bool) {
+	idx := strings.Index(pair, "=")
+	if idx <= 0 {
+		return "", "", false
+	}
+	return pair[:idx], pair[idx+1:], true
+}
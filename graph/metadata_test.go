@@ -0,0 +1,40 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+func TestRecordMetadata(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	err := g.InsertRecordMetadata("www.owasp.org", "A", "104.22.26.77",
+		map[string]string{"ticket": "SEC-1", "campaign": "q3"}, "DNS", "dns", "event")
+	if err != nil {
+		t.Fatalf("Failed to insert the record metadata: %v", err)
+	}
+	// The value of an existing key is replaced
+	err = g.InsertRecordMetadata("www.owasp.org", "A", "104.22.26.77",
+		map[string]string{"campaign": "q4 external"}, "DNS", "dns", "event")
+	if err != nil {
+		t.Fatalf("Failed to update the record metadata: %v", err)
+	}
+
+	expected := map[string]string{"ticket": "SEC-1", "campaign": "q4 external"}
+	if got := g.ReadRecordMetadata("www.owasp.org", "A", "104.22.26.77"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the record metadata %v, got %v", expected, got)
+	}
+	if got := g.ReadRecordMetadata("www.owasp.org", "AAAA", "104.22.26.77"); len(got) != 0 {
+		t.Errorf("Unexpected metadata for another record: %v", got)
+	}
+
+	if err := g.InsertRecordMetadata("www.owasp.org", "A", "104.22.26.77",
+		map[string]string{"a=b": "c"}, "DNS", "dns", "event"); err == nil {
+		t.Errorf("The invalid metadata key was accepted")
+	}
+}
@@ -4,6 +4,8 @@
 package graph
 
 import (
+	"errors"
+
 	"github.com/OWASP/Amass/v3/graph/db"
 )
 
@@ -20,3 +22,37 @@ func (g *Graph) InsertNetblock(cidr, source, tag, eventID string) (db.Node, erro
 
 	return cidrNode, nil
 }
+
+// InsertGeofeedNetblock adds the netblock listed by the geofeed at the URL, along with the
+// location provided by the geofeed entry (country, region, city and postal code).
+func (g *Graph) InsertGeofeedNetblock(cidr, url, location, source, tag, eventID string) error {
+	if url == "" {
+		return errors.New("InsertGeofeedNetblock: Empty geofeed URL provided")
+	}
+
+	cidrNode, err := g.InsertNetblock(cidr, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	if err := g.insertUniqueProperty(cidrNode, "geofeed", url); err != nil {
+		return err
+	}
+	if location == "" {
+		return nil
+	}
+	return g.insertUniqueProperty(cidrNode, "geolocation", location)
+}
+
+// ReadGeolocation returns the location provided by a geofeed for the netblock.
+func (g *Graph) ReadGeolocation(cidr string) string {
+	node, err := g.db.ReadNode(cidr, "netblock")
+	if err != nil {
+		return ""
+	}
+
+	if p, err := g.db.ReadProperties(node, "geolocation"); err == nil && len(p) > 0 {
+		return p[0].Value
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+// InsertOpenPort adds a port that was observed open on the IP address.
+func (g *Graph) InsertOpenPort(addr string, port int, source, tag, eventID string) error {
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("InsertOpenPort: Invalid port %d provided", port)
+	}
+
+	ipNode, err := g.InsertAddress(addr, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.insertPorts(ipNode, []int{port})
+}
+
+// ReadOpenPorts returns the sorted ports observed open on the IP address.
+func (g *Graph) ReadOpenPorts(addr string) []int {
+	node, err := g.db.ReadNode(addr, "ipaddr")
+	if err != nil {
+		return nil
+	}
+
+	return g.readPorts(node)
+}
+
+// LinkAddressPorts links the ports previously observed open on the IP address to the FQDN,
+// so the services are known for the name without scanning the address again.
+func (g *Graph) LinkAddressPorts(fqdn, addr string) error {
+	ipNode, err := g.db.ReadNode(addr, "ipaddr")
+	if err != nil {
+		return nil
+	}
+
+	ports := g.readPorts(ipNode)
+	if len(ports) == 0 {
+		return nil
+	}
+
+	fqdnNode, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	return g.insertPorts(fqdnNode, ports)
+}
+
+// ReadNamePorts returns the sorted open ports that were linked to the FQDN.
+func (g *Graph) ReadNamePorts(fqdn string) []int {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	return g.readPorts(node)
+}
+
+func (g *Graph) insertPorts(node db.Node, ports []int) error {
+	existing := make(map[int]struct{})
+	for _, port := range g.readPorts(node) {
+		existing[port] = struct{}{}
+	}
+
+	for _, port := range ports {
+		if _, found := existing[port]; found {
+			continue
+		}
+
+		if err := g.db.InsertProperty(node, "open_port", strconv.Itoa(port)); err != nil {
+			return err
+		}
+		existing[port] = struct{}{}
+	}
+	return nil
+}
+
+func (g *Graph) readPorts(node db.Node) []int {
+	var ports []int
+
+	if p, err := g.db.ReadProperties(node, "open_port"); err == nil {
+		for _, prop := range p {
+			if port, err := strconv.Atoi(prop.Value); err == nil {
+				ports = append(ports, port)
+			}
+		}
+	}
+
+	sort.Ints(ports)
+	return ports
+}
@@ -0,0 +1,39 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+func TestOpenPorts(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+	defer g.Close()
+
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+	for _, port := range []int{8443, 443, 443} {
+		if err := g.InsertOpenPort("104.22.26.77", port, "Scan", "active", event); err != nil {
+			t.Fatalf("Failed to insert the open port: %v", err)
+		}
+	}
+	if err := g.InsertOpenPort("104.22.26.77", 70000, "Scan", "active", event); err == nil {
+		t.Errorf("InsertOpenPort accepted an invalid port")
+	}
+
+	if ports := g.ReadOpenPorts("104.22.26.77"); len(ports) != 2 || ports[0] != 443 || ports[1] != 8443 {
+		t.Errorf("Unexpected open ports for the address: %v", ports)
+	}
+
+	if err := g.InsertA("www.owasp.org", "104.22.26.77", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	if err := g.LinkAddressPorts("www.owasp.org", "104.22.26.77"); err != nil {
+		t.Fatalf("Failed to link the open ports: %v", err)
+	}
+	if ports := g.ReadNamePorts("www.owasp.org"); len(ports) != 2 || ports[0] != 443 || ports[1] != 8443 {
+		t.Errorf("Unexpected open ports for the name: %v", ports)
+	}
+}
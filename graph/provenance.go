@@ -0,0 +1,91 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+)
+
+// The record types that receive an HMAC when they are stored.
+var signedRecordTypes = []string{RecordA, RecordAAAA, RecordCNAME, RecordPTR, RecordNS, RecordMX, RecordSRV}
+
+// RecordHMAC returns the hex encoded HMAC-SHA256 computed with the key over the canonical
+// form of the record, so later changes to the stored record can be detected.
+func RecordHMAC(key []byte, rtype, name, data string) string {
+	mac := hmac.New(sha256.New, key)
+
+	mac.Write([]byte(canonicalRecord(rtype, name, data)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func canonicalRecord(rtype, name, data string) string {
+	return strings.Join([]string{strings.ToUpper(rtype), amassdns.Canonical(name), data}, "\x00")
+}
+
+// InsertRecordHMAC annotates the name with the HMAC of the record, computed using the key.
+func (g *Graph) InsertRecordHMAC(key []byte, rtype, name, data, source, tag, eventID string) error {
+	if len(key) == 0 {
+		return errors.New("InsertRecordHMAC: Empty key provided")
+	}
+	if rtype == "" || data == "" {
+		return errors.New("InsertRecordHMAC: Empty record type or data provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(name, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	value := recordKey(rtype, data) + " " + RecordHMAC(key, rtype, name, data)
+	return g.insertUniqueProperty(fqdnNode, "record_hmac", value)
+}
+
+// VerifyRecord recomputes the HMAC of the record using the key and returns true when it
+// matches the HMAC stored for the record.
+func (g *Graph) VerifyRecord(key []byte, rtype, name, data string) bool {
+	node, err := g.db.ReadNode(amassdns.Canonical(name), "fqdn")
+	if err != nil {
+		return false
+	}
+
+	return g.verifyRecord(node, key, rtype, name, data)
+}
+
+func (g *Graph) verifyRecord(node db.Node, key []byte, rtype, name, data string) bool {
+	p, err := g.db.ReadProperties(node, "record_hmac")
+	if err != nil {
+		return false
+	}
+
+	prefix := recordKey(rtype, data) + " "
+	expected := []byte(RecordHMAC(key, rtype, name, data))
+	for _, prop := range p {
+		// The data can contain spaces, so the HMAC follows the last one
+		if idx := strings.LastIndex(prop.Value, " "); idx == len(prefix)-1 &&
+			strings.HasPrefix(prop.Value, prefix) && hmac.Equal([]byte(prop.Value[idx+1:]), expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnverifiedRecords returns the stored DNS records without an HMAC that matches the one
+// recomputed using the key, such as the records altered after they were collected.
+func (g *Graph) UnverifiedRecords(key []byte) []*Record {
+	var unverified []*Record
+
+	for _, r := range g.Records(signedRecordTypes...) {
+		if !g.VerifyRecord(key, r.Type, r.Name, r.Data) {
+			unverified = append(unverified, r)
+		}
+	}
+	return unverified
+}
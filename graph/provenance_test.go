@@ -0,0 +1,57 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+func TestRecordHMAC(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+	key := []byte("forensics")
+
+	if err := g.InsertA("www.owasp.org", "192.0.2.10", "DNS", "dns", "event"); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	if err := g.InsertRecordHMAC(key, RecordA, "www.owasp.org", "192.0.2.10", "DNS", "dns", "event"); err != nil {
+		t.Fatalf("Failed to insert the record HMAC: %v", err)
+	}
+
+	if !g.VerifyRecord(key, RecordA, "www.owasp.org", "192.0.2.10") {
+		t.Errorf("The HMAC of the stored record did not verify")
+	}
+	if g.VerifyRecord([]byte("other"), RecordA, "www.owasp.org", "192.0.2.10") {
+		t.Errorf("The HMAC verified using a different key")
+	}
+	if g.VerifyRecord(key, RecordA, "www.owasp.org", "192.0.2.66") {
+		t.Errorf("The HMAC verified for different record data")
+	}
+	if len(g.UnverifiedRecords(key)) != 0 {
+		t.Errorf("Expected all the stored records to verify, got %v", g.UnverifiedRecords(key))
+	}
+
+	// Tamper with the stored record after it was collected
+	if err := g.InsertA("www.owasp.org", "192.0.2.66", "DNS", "dns", "event"); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	unverified := g.UnverifiedRecords(key)
+	if len(unverified) != 1 || unverified[0].Data != "192.0.2.66" {
+		t.Errorf("Expected the tampered record to fail the verification, got %v", unverified)
+	}
+
+	// Tamper with the stored HMAC
+	node, _ := g.db.ReadNode("www.owasp.org", "fqdn")
+	mac := RecordHMAC(key, RecordA, "www.owasp.org", "192.0.2.10")
+	tampered := "0"
+	if mac[len(mac)-1] == '0' {
+		tampered = "1"
+	}
+	g.db.DeleteProperty(node, "record_hmac", "A 192.0.2.10 "+mac)
+	g.db.InsertProperty(node, "record_hmac", "A 192.0.2.10 "+mac[:len(mac)-1]+tampered)
+	if g.VerifyRecord(key, RecordA, "www.owasp.org", "192.0.2.10") {
+		t.Errorf("The record verified with a tampered HMAC")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"sort"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/stringset"
+)
+
+// Record types returned by the Records method.
+const (
+	RecordA        = "A"
+	RecordAAAA     = "AAAA"
+	RecordCNAME    = "CNAME"
+	RecordPTR      = "PTR"
+	RecordNS       = "NS"
+	RecordMX       = "MX"
+	RecordSRV      = "SRV"
+	RecordNetblock = "NETBLOCK"
+	RecordASN      = "ASN"
+)
+
+// Record represents a DNS record or infrastructure association stored in the graph.
+type Record struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Data      string `json:"data"`
+	FirstSeen string `json:"first_seen,omitempty"`
+	LastSeen  string `json:"last_seen,omitempty"`
+	// The number of distinct data sources that observed the DNS record
+	Sources int `json:"sources,omitempty"`
+	// The custom metadata key-value pairs attached to the DNS record
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+var recordPredicates = map[string]string{
+	"a_record":     RecordA,
+	"aaaa_record":  RecordAAAA,
+	"cname_record": RecordCNAME,
+	"ptr_record":   RecordPTR,
+	"ns_record":    RecordNS,
+	"mx_record":    RecordMX,
+	"srv_record":   RecordSRV,
+	"contains":     RecordNetblock,
+	"prefix":       RecordASN,
+}
+
+// Records returns the DNS records and infrastructure associations stored in the graph.
+// The types parameter optionally restricts the record types returned. The records are
+// sorted by type, name and data.
+func (g *Graph) Records(types ...string) []*Record {
+	var records []*Record
+
+	filter := stringset.New(types...)
+
+	for _, ntype := range []string{"fqdn", "netblock", "as"} {
+		nodes, err := g.db.AllNodesOfType(ntype)
+		if err != nil {
+			continue
+		}
+
+		for _, node := range nodes {
+			edges, err := g.db.ReadOutEdges(node)
+			if err != nil {
+				continue
+			}
+
+			// Only the names track when they were seen and the sources of the records
+			var first, last string
+			var sources map[string]int
+			var metadata map[string]map[string]string
+			if ntype == "fqdn" {
+				f, l := g.readSeen(node)
+				first, last = seenString(f), seenString(l)
+				sources = g.readCounts(node, "record_source_count")
+				metadata = g.readRecordMetadata(node)
+			}
+
+			for _, edge := range edges {
+				rtype, found := recordPredicates[edge.Predicate]
+				if !found || (filter.Len() > 0 && !filter.Has(rtype)) {
+					continue
+				}
+
+				data := g.db.NodeToID(edge.To)
+				records = append(records, &Record{
+					Type:      rtype,
+					Name:      g.db.NodeToID(edge.From),
+					Data:      data,
+					FirstSeen: first,
+					LastSeen:  last,
+					Sources:   sources[recordKey(rtype, data)],
+					Metadata:  metadata[recordKey(rtype, data)],
+				})
+			}
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Type != records[j].Type {
+			return records[i].Type < records[j].Type
+		}
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		return records[i].Data < records[j].Data
+	})
+
+	return records
+}
+
+func seenString(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// NameRecords returns the DNS records stored in the graph for the FQDN, sorted by type and data.
+func (g *Graph) NameRecords(fqdn string) []requests.RecordInfo {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	return g.nameRecords(node)
+}
+
+func (g *Graph) nameRecords(node db.Node) []requests.RecordInfo {
+	var records []requests.RecordInfo
+
+	if edges, err := g.db.ReadOutEdges(node, "a_record", "aaaa_record",
+		"cname_record", "ptr_record", "ns_record", "mx_record"); err == nil {
+		for _, edge := range edges {
+			records = append(records, requests.RecordInfo{
+				Type: recordPredicates[edge.Predicate],
+				Data: g.db.NodeToID(edge.To),
+			})
+		}
+	}
+
+	// The SRV records are attached to the service names that identify the FQDN
+	if edges, err := g.db.ReadInEdges(node, "service"); err == nil {
+		for _, edge := range edges {
+			targets, err := g.db.ReadOutEdges(edge.From, "srv_record")
+			if err != nil {
+				continue
+			}
+
+			for _, t := range targets {
+				records = append(records, requests.RecordInfo{
+					Type: RecordSRV,
+					Data: g.db.NodeToID(t.To),
+				})
+			}
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Type != records[j].Type {
+			return records[i].Type < records[j].Type
+		}
+		return records[i].Data < records[j].Data
+	})
+
+	return records
+}
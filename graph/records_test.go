@@ -0,0 +1,50 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestNameRecords(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+
+	if err := g.InsertAAAA("owasp.org", "2606:4700:10::6816:1a4d", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert the AAAA record: %v", err)
+	}
+	if err := g.InsertA("owasp.org", "104.22.26.77", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	if err := g.InsertMX("owasp.org", "mail.owasp.org", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert the MX record: %v", err)
+	}
+	if err := g.InsertSRV("owasp.org", "_sip._tcp.owasp.org", "sip.owasp.org", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert the SRV record: %v", err)
+	}
+
+	expected := []requests.RecordInfo{
+		{Type: RecordA, Data: "104.22.26.77"},
+		{Type: RecordAAAA, Data: "2606:4700:10::6816:1a4d"},
+		{Type: RecordMX, Data: "mail.owasp.org"},
+		{Type: RecordSRV, Data: "sip.owasp.org"},
+	}
+
+	got := g.NameRecords("owasp.org")
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d records, got %v", len(expected), got)
+	}
+	for i, rec := range expected {
+		if got[i] != rec {
+			t.Errorf("Record %d: expected %v, got %v", i, rec, got[i])
+		}
+	}
+
+	if recs := g.NameRecords("missing.owasp.org"); len(recs) != 0 {
+		t.Errorf("Expected no records for a missing name, got %v", recs)
+	}
+}
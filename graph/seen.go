@@ -0,0 +1,97 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+// markSeen updates the first_seen and last_seen properties of the node with the current time.
+func (g *Graph) markSeen(node db.Node) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if p, err := g.db.ReadProperties(node, "first_seen"); err != nil || len(p) == 0 {
+		if err := g.db.InsertProperty(node, "first_seen", now); err != nil {
+			return err
+		}
+	}
+
+	if p, err := g.db.ReadProperties(node, "last_seen"); err == nil {
+		for _, prop := range p {
+			if prop.Value == now {
+				return nil
+			}
+			// Remove the existing property before updating the time
+			g.db.DeleteProperty(node, prop.Predicate, prop.Value)
+		}
+	}
+
+	return g.db.InsertProperty(node, "last_seen", now)
+}
+
+// ReadSeen returns the times when the node identified by id and ntype was first and last seen.
+// Nodes stored without the times fall back to the date ranges of the events they belong to.
+func (g *Graph) ReadSeen(id, ntype string) (time.Time, time.Time) {
+	node, err := g.db.ReadNode(id, ntype)
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+
+	return g.readSeen(node)
+}
+
+func (g *Graph) readSeen(node db.Node) (time.Time, time.Time) {
+	var first, last time.Time
+
+	if p, err := g.db.ReadProperties(node, "first_seen", "last_seen"); err == nil {
+		for _, prop := range p {
+			t, err := time.Parse(time.RFC3339, prop.Value)
+			if err != nil {
+				continue
+			}
+
+			if prop.Predicate == "first_seen" && (first.IsZero() || t.Before(first)) {
+				first = t
+			} else if prop.Predicate == "last_seen" && t.After(last) {
+				last = t
+			}
+		}
+	}
+
+	if !first.IsZero() && !last.IsZero() {
+		return first, last
+	}
+
+	// Fall back to the events that the node was discovered during
+	edges, err := g.db.ReadInEdges(node)
+	if err != nil {
+		return first, last
+	}
+
+	var start, finish time.Time
+	for _, edge := range edges {
+		id := g.db.NodeToID(edge.From)
+		if _, err := g.db.ReadNode(id, "event"); err != nil {
+			continue
+		}
+
+		s, f := g.EventDateRange(id)
+		if !s.IsZero() && (start.IsZero() || s.Before(start)) {
+			start = s
+		}
+		if f.After(finish) {
+			finish = f
+		}
+	}
+
+	if first.IsZero() {
+		first = start
+	}
+	if last.IsZero() {
+		last = finish
+	}
+	return first, last
+}
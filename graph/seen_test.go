@@ -0,0 +1,50 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+func TestSeen(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+	before := time.Now().Add(-time.Second)
+
+	for _, tt := range graphTest {
+		if err := g.InsertA(tt.FQDN, tt.Addr, tt.Source, tt.Tag, tt.EventID); err != nil {
+			t.Fatalf("Failed to insert the A record.\n%v", err)
+		}
+
+		for _, node := range []struct{ id, ntype string }{{tt.FQDN, "fqdn"}, {tt.Addr, "ipaddr"}} {
+			first, last := g.ReadSeen(node.id, node.ntype)
+			if first.Before(before) || last.Before(first) {
+				t.Errorf("Unexpected times for %s: first seen %v, last seen %v", node.id, first, last)
+			}
+		}
+	}
+}
+
+func TestSeenEventFallback(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	for _, tt := range graphTest {
+		// Simulate a node stored before the times were tracked
+		node, err := g.InsertNodeIfNotExist(tt.Addr, "ipaddr")
+		if err != nil {
+			t.Fatalf("Failed to insert the node.\n%v", err)
+		}
+		if err := g.AddNodeToEvent(node, tt.Source, tt.Tag, tt.EventID); err != nil {
+			t.Fatalf("Failed to add the node to the event.\n%v", err)
+		}
+
+		start, finish := g.EventDateRange(tt.EventID)
+		first, last := g.ReadSeen(tt.Addr, "ipaddr")
+		if !first.Equal(start) || !last.Equal(finish) {
+			t.Errorf("Expected the event date range %v - %v, got %v - %v", start, finish, first, last)
+		}
+	}
+}
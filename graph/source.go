@@ -4,6 +4,8 @@
 package graph
 
 import (
+	"errors"
+
 	"github.com/OWASP/Amass/v3/graph/db"
 )
 
@@ -53,3 +55,68 @@ func (g *Graph) SourceTag(source string) string {
 
 	return ""
 }
+
+// InsertSourceCount adds num to the number of records the data source contributed for the FQDN.
+func (g *Graph) InsertSourceCount(fqdn, source, tag, eventID string, num int) error {
+	if num <= 0 {
+		return nil
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	return g.incrementCount(fqdnNode, "source_count", source, num)
+}
+
+// ReadSourceCounts returns the number of records each data source contributed for the FQDN.
+func (g *Graph) ReadSourceCounts(fqdn string) map[string]int {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return make(map[string]int)
+	}
+
+	return g.readCounts(node, "source_count")
+}
+
+// InsertRecordSource attributes the record of the FQDN to the data source. The number of distinct
+// data sources that observed the record is incremented when the data source is new for the record.
+func (g *Graph) InsertRecordSource(fqdn, rrtype, data, source, tag, eventID string) error {
+	if rrtype == "" || data == "" || source == "" {
+		return errors.New("InsertRecordSource: Empty record type, data or source provided")
+	}
+
+	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	record := recordKey(rrtype, data)
+	// The data source is only counted once for the record
+	predicate := recordSourceKey(record, source)
+	if count, err := g.db.CountProperties(fqdnNode, predicate); err == nil && count > 0 {
+		return nil
+	}
+
+	if err := g.db.InsertProperty(fqdnNode, predicate, record); err != nil {
+		return err
+	}
+	return g.incrementCount(fqdnNode, "record_source_count", record, 1)
+}
+
+// recordSourceKey returns the predicate that attributes the record to the data source, so the
+// attribution is looked up directly instead of scanning the sources of every record.
+func recordSourceKey(record, source string) string {
+	return "record_source:" + record + "\t" + source
+}
+
+// ReadRecordSourceCount returns the number of distinct data sources that observed the record of the FQDN.
+func (g *Graph) ReadRecordSourceCount(fqdn, rrtype, data string) int {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return 0
+	}
+
+	return g.readCounts(node, "record_source_count")[recordKey(rrtype, data)]
+}
@@ -30,3 +30,65 @@ func TestSource(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceCounts(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	for _, tt := range graphTest {
+		if err := g.InsertSourceCount(tt.FQDN, tt.Source, tt.Tag, tt.EventID, 2); err != nil {
+			t.Errorf("Failed to insert the source count.\n%v", err)
+		}
+		if err := g.InsertSourceCount(tt.FQDN, "DNS", "dns", tt.EventID, 1); err != nil {
+			t.Errorf("Failed to insert the source count.\n%v", err)
+		}
+		if err := g.InsertSourceCount(tt.FQDN, tt.Source, tt.Tag, tt.EventID, 3); err != nil {
+			t.Errorf("Failed to insert the source count.\n%v", err)
+		}
+
+		got := g.ReadSourceCounts(tt.FQDN)
+		if got[tt.Source] != 5 || got["DNS"] != 1 {
+			t.Errorf("Unexpected source counts: %v", got)
+		}
+
+		node, err := g.db.ReadNode(tt.FQDN, "fqdn")
+		if err != nil {
+			t.Fatalf("Failed to read the FQDN node.\n%v", err)
+		}
+
+		sources, err := g.db.NodeSources(node, tt.EventID)
+		if err != nil {
+			t.Fatalf("Failed to obtain the node sources.\n%v", err)
+		}
+
+		attrs := g.sourceAttribution(node, sources)
+		if len(attrs) != 2 || attrs[0].Source != tt.Source || attrs[0].Count != 5 || attrs[0].Tag != tt.Tag {
+			t.Errorf("Unexpected source attribution: %v", attrs)
+		}
+	}
+}
+
+func TestRecordSources(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	for _, source := range []string{"DNS", "Crtsh", "DNS", "Brute Forcing"} {
+		if err := g.InsertRecordSource("www.owasp.org", "A", "104.22.26.77", source, "dns", "event"); err != nil {
+			t.Errorf("Failed to insert the record source.\n%v", err)
+		}
+	}
+	if err := g.InsertRecordSource("www.owasp.org", "A", "104.22.27.77", "DNS", "dns", "event"); err != nil {
+		t.Errorf("Failed to insert the record source.\n%v", err)
+	}
+	if err := g.InsertRecordSource("www.owasp.org", "A", "104.22.26.77", "", "dns", "event"); err == nil {
+		t.Errorf("InsertRecordSource did not return an error when the source was empty")
+	}
+
+	if got := g.ReadRecordSourceCount("www.owasp.org", "A", "104.22.26.77"); got != 3 {
+		t.Errorf("Expected 3 distinct sources for the record, got %d", got)
+	}
+	if got := g.ReadRecordSourceCount("www.owasp.org", "A", "104.22.27.77"); got != 1 {
+		t.Errorf("Expected 1 distinct source for the record, got %d", got)
+	}
+	if got := g.ReadRecordSourceCount("www.owasp.org", "AAAA", "104.22.26.77"); got != 0 {
+		t.Errorf("Expected no sources for the unknown record, got %d", got)
+	}
+}
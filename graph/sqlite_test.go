@@ -0,0 +1,152 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+func TestSQLiteGraph(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-sqlite")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "amass.sqlite")
+	sqlite := db.NewSQLiteGraph(path)
+	if sqlite == nil {
+		t.Fatalf("Failed to create the SQLite graph")
+	}
+
+	g := NewGraph(sqlite)
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+	for _, insert := range []func() error{
+		func() error { return g.InsertA("www.owasp.org", "104.22.26.77", "DNS", "dns", event) },
+		func() error { return g.InsertAAAA("www.owasp.org", "2606:4700:10::6816:1a4d", "DNS", "dns", event) },
+		func() error { return g.InsertCNAME("owasp.org.example.com", "www.owasp.org", "DNS", "dns", event) },
+		func() error { return g.InsertPTR("77.26.22.104.in-addr.arpa", "www.owasp.org", "DNS", "dns", event) },
+		func() error {
+			return g.InsertSRV("owasp.org", "_sip._tcp.owasp.org", "sip.owasp.org", "DNS", "dns", event)
+		},
+		func() error { return g.InsertNS("owasp.org", "ns1.owasp.org", "DNS", "dns", event) },
+		func() error { return g.InsertMX("owasp.org", "mail.owasp.org", "DNS", "dns", event) },
+		func() error {
+			return g.InsertInfrastructure(13335, "CLOUDFLARENET", "104.22.26.77", "104.22.16.0/20", "RIR", "rir", event)
+		},
+	} {
+		if err := insert(); err != nil {
+			t.Fatalf("Failed to insert into the SQLite graph: %v", err)
+		}
+	}
+
+	if addrs, err := sqlite.NameToIPAddrs("owasp.org.example.com"); err != nil || len(addrs) != 2 {
+		t.Errorf("Failed to traverse the CNAME record to the addresses: %v", addrs)
+	}
+	g.Close()
+
+	// Read the records back from the normalized views of the file
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open the SQLite file: %v", err)
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query("SELECT name, type, data FROM records")
+	if err != nil {
+		t.Fatalf("Failed to query the records view: %v", err)
+	}
+	defer rows.Close()
+
+	var records []string
+	for rows.Next() {
+		var name, rtype, data string
+
+		if err := rows.Scan(&name, &rtype, &data); err == nil {
+			records = append(records, strings.Join([]string{name, rtype, data}, " "))
+		}
+	}
+	sort.Strings(records)
+
+	expected := []string{
+		"77.26.22.104.in-addr.arpa PTR www.owasp.org",
+		"_sip._tcp.owasp.org SRV sip.owasp.org",
+		"owasp.org MX mail.owasp.org",
+		"owasp.org NS ns1.owasp.org",
+		"owasp.org.example.com CNAME www.owasp.org",
+		"www.owasp.org A 104.22.26.77",
+		"www.owasp.org AAAA 2606:4700:10::6816:1a4d",
+	}
+	if strings.Join(records, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected the records %v, got %v", expected, records)
+	}
+
+	var asn, desc, netblock string
+	err = conn.QueryRow("SELECT asn, description, netblock FROM infra WHERE addr = ?", "104.22.26.77").Scan(&asn, &desc, &netblock)
+	if err != nil || asn != "13335" || desc != "CLOUDFLARENET" || netblock != "104.22.16.0/20" {
+		t.Errorf("Unexpected infrastructure read from the SQLite file: %s %s %s %v", asn, desc, netblock, err)
+	}
+
+	var names, addrs int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM names").Scan(&names); err != nil || names == 0 {
+		t.Errorf("The names were not read from the SQLite file: %v", err)
+	}
+	if err := conn.QueryRow("SELECT COUNT(*) FROM addrs").Scan(&addrs); err != nil || addrs != 2 {
+		t.Errorf("Expected 2 addresses in the SQLite file, got %d: %v", addrs, err)
+	}
+}
+
+func TestSQLiteGraphBatching(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-sqlite")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sqlite := db.NewSQLiteGraph(filepath.Join(dir, "amass.sqlite"))
+	if sqlite == nil {
+		t.Fatalf("Failed to create the SQLite graph")
+	}
+
+	g := NewGraph(sqlite)
+	defer g.Close()
+	g.EnableBatching(context.Background(), 1000, time.Hour)
+
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+	if err := g.InsertA("www.owasp.org", "104.22.26.77", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert into the SQLite graph: %v", err)
+	}
+	if err := g.InsertAAAA("www.owasp.org", "2606:4700:10::6816:1a4d", "DNS", "dns", event); err != nil {
+		t.Fatalf("Failed to insert into the SQLite graph: %v", err)
+	}
+	if addrs, _ := sqlite.NameToIPAddrs("www.owasp.org"); len(addrs) != 0 {
+		t.Errorf("The edges were inserted before the batch was applied: %v", addrs)
+	}
+
+	if err := g.batcher.Flush(); err != nil {
+		t.Fatalf("Failed to apply the batch: %v", err)
+	}
+	if addrs, err := sqlite.NameToIPAddrs("www.owasp.org"); err != nil || len(addrs) != 2 {
+		t.Errorf("The batch did not insert the edges to the addresses: %v", addrs)
+	}
+
+	// A batch holding an edge to a missing node is rejected as a whole
+	from, _ := sqlite.ReadNode("www.owasp.org", "fqdn")
+	edges := []*db.Edge{
+		{Predicate: "cname_record", From: from, To: "missing.owasp.org"},
+	}
+	if err := sqlite.InsertBatch(edges); err == nil {
+		t.Errorf("The batch holding an edge to a missing node was accepted")
+	}
+}
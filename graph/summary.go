@@ -0,0 +1,235 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/stringset"
+)
+
+type summaryInfra struct {
+	cidr string
+	asn  string
+}
+
+// InfrastructureSummary returns the autonomous systems and netblocks containing the addresses
+// that names within the domains resolved to during the events. The summaries are sorted by the
+// number of names, and all names are considered in scope when no domains are provided.
+func (g *Graph) InfrastructureSummary(domains []string, events ...string) []*requests.ASNSummary {
+	if len(events) == 0 {
+		return nil
+	}
+
+	nodes, err := g.db.AllNodesOfType("fqdn", events...)
+	if err != nil {
+		return nil
+	}
+
+	cache := make(map[string]*summaryInfra)
+	asNames := make(map[string]stringset.Set)
+	cidrNames := make(map[string]stringset.Set)
+	cidrToAS := make(map[string]string)
+	for _, node := range nodes {
+		name := g.db.NodeToID(node)
+		if len(domains) > 0 && !summaryNameInScope(name, domains) {
+			continue
+		}
+
+		addrs, err := g.db.NameToIPAddrs(node)
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			id := g.db.NodeToID(addr)
+
+			infra, found := cache[id]
+			if !found {
+				infra = g.addrInfrastructure(addr, events)
+				cache[id] = infra
+			}
+			if infra == nil {
+				continue
+			}
+
+			if _, found := cidrNames[infra.cidr]; !found {
+				cidrNames[infra.cidr] = stringset.New()
+			}
+			cidrNames[infra.cidr].Insert(name)
+			cidrToAS[infra.cidr] = infra.asn
+
+			if _, found := asNames[infra.asn]; !found {
+				asNames[infra.asn] = stringset.New()
+			}
+			asNames[infra.asn].Insert(name)
+		}
+	}
+
+	summaries := make(map[string]*requests.ASNSummary)
+	for asn, names := range asNames {
+		num, _ := strconv.Atoi(asn)
+
+		summaries[asn] = &requests.ASNSummary{
+			ASN:         num,
+			Description: g.ReadASDescription(asn),
+			Names:       names.Len(),
+		}
+	}
+
+	for cidr, names := range cidrNames {
+		s := summaries[cidrToAS[cidr]]
+
+		s.Netblocks = append(s.Netblocks, &requests.NetblockSummary{
+			CIDR:  cidr,
+			Names: names.Len(),
+		})
+	}
+
+	var results []*requests.ASNSummary
+	for _, s := range summaries {
+		sort.Slice(s.Netblocks, func(i, j int) bool {
+			if s.Netblocks[i].Names != s.Netblocks[j].Names {
+				return s.Netblocks[i].Names > s.Netblocks[j].Names
+			}
+			return s.Netblocks[i].CIDR < s.Netblocks[j].CIDR
+		})
+
+		results = append(results, s)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Names != results[j].Names {
+			return results[i].Names > results[j].Names
+		}
+		return results[i].ASN < results[j].ASN
+	})
+
+	return results
+}
+
+// ASNName is a name that resolved to an address within a netblock announced by an autonomous system.
+type ASNName struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Netblock string `json:"netblock"`
+	ASN      int    `json:"asn"`
+}
+
+// NamesByASN walks the infrastructure edges from the autonomous system to the netblocks, the
+// addresses and back to the names that resolved to them. Only the names within the domains
+// and events are returned, and all names are considered in scope when none are provided.
+func (g *Graph) NamesByASN(asn int, domains []string, events ...string) ([]*ASNName, error) {
+	asNode, err := g.db.ReadNode(strconv.Itoa(asn), "as")
+	if err != nil {
+		return nil, fmt.Errorf("no data stored for AS%d", asn)
+	}
+
+	prefixes, err := g.db.ReadOutEdges(asNode, "prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*ASNName
+	for _, prefix := range prefixes {
+		cidr := g.db.NodeToID(prefix.To)
+
+		addrs, err := g.db.ReadOutEdges(prefix.To, "contains")
+		if err != nil {
+			continue
+		}
+
+		for _, a := range addrs {
+			addr := g.db.NodeToID(a.To)
+
+			names, err := g.db.ReadInEdges(a.To, "a_record", "aaaa_record")
+			if err != nil {
+				continue
+			}
+
+			for _, n := range names {
+				name := g.db.NodeToID(n.From)
+				if len(domains) > 0 && !summaryNameInScope(name, domains) {
+					continue
+				}
+				if len(events) > 0 && !g.inAnyEventScope(n.From, events) {
+					continue
+				}
+
+				results = append(results, &ASNName{
+					Name:     name,
+					Address:  addr,
+					Netblock: cidr,
+					ASN:      asn,
+				})
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Address < results[j].Address
+	})
+	return results, nil
+}
+
+// addrInfrastructure returns the netblock and autonomous system stored for the address during the events.
+func (g *Graph) addrInfrastructure(addr db.Node, events []string) *summaryInfra {
+	edges, err := g.db.ReadInEdges(addr, "contains")
+	if err != nil {
+		return nil
+	}
+
+	for _, edge := range edges {
+		if !g.inAnyEventScope(edge.From, events, "RIR") {
+			continue
+		}
+
+		prefixes, err := g.db.ReadInEdges(edge.From, "prefix")
+		if err != nil {
+			continue
+		}
+
+		for _, prefix := range prefixes {
+			if g.inAnyEventScope(prefix.From, events, "RIR") {
+				return &summaryInfra{
+					cidr: g.db.NodeToID(edge.From),
+					asn:  g.db.NodeToID(prefix.From),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *Graph) inAnyEventScope(node db.Node, events []string, predicates ...string) bool {
+	for _, event := range events {
+		if g.inEventScope(node, event, predicates...) {
+			return true
+		}
+	}
+	return false
+}
+
+func summaryNameInScope(name string, domains []string) bool {
+	n := amassdns.Canonical(name)
+
+	for _, d := range domains {
+		d = amassdns.Canonical(d)
+
+		if n == d || strings.HasSuffix(n, "."+d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+func TestInfrastructureSummary(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+
+	records := map[string]string{
+		"owasp.org":       "104.22.26.77",
+		"www.owasp.org":   "104.22.27.77",
+		"lists.owasp.org": "172.67.12.6",
+		"www.example.com": "93.184.216.34",
+	}
+	for name, addr := range records {
+		if err := g.InsertA(name, addr, "DNS", "dns", event); err != nil {
+			t.Fatalf("Failed to insert the A record: %v", err)
+		}
+	}
+
+	infra := []struct {
+		asn  int
+		desc string
+		addr string
+		cidr string
+	}{
+		{13335, "CLOUDFLARENET", "104.22.26.77", "104.22.16.0/20"},
+		{13335, "CLOUDFLARENET", "104.22.27.77", "104.22.16.0/20"},
+		{13335, "CLOUDFLARENET", "172.67.12.6", "172.67.0.0/16"},
+		{15133, "EDGECAST", "93.184.216.34", "93.184.216.0/24"},
+	}
+	for _, i := range infra {
+		if err := g.InsertInfrastructure(i.asn, i.desc, i.addr, i.cidr, "RIR", "rir", event); err != nil {
+			t.Fatalf("Failed to insert the infrastructure data: %v", err)
+		}
+	}
+
+	summary := g.InfrastructureSummary([]string{"owasp.org"}, event)
+	if len(summary) != 1 {
+		t.Fatalf("Expected one autonomous system in the summary, got %d", len(summary))
+	}
+
+	as := summary[0]
+	if as.ASN != 13335 || as.Description != "CLOUDFLARENET" || as.Names != 3 {
+		t.Errorf("Unexpected autonomous system summary: %+v", as)
+	}
+	if len(as.Netblocks) != 2 {
+		t.Fatalf("Expected two netblocks in the summary, got %d", len(as.Netblocks))
+	}
+	if nb := as.Netblocks[0]; nb.CIDR != "104.22.16.0/20" || nb.Names != 2 {
+		t.Errorf("Unexpected netblock summary: %+v", nb)
+	}
+	if nb := as.Netblocks[1]; nb.CIDR != "172.67.0.0/16" || nb.Names != 1 {
+		t.Errorf("Unexpected netblock summary: %+v", nb)
+	}
+
+	// Without domains all the names are included and sorted by the name count
+	summary = g.InfrastructureSummary(nil, event)
+	if len(summary) != 2 || summary[0].ASN != 13335 || summary[1].ASN != 15133 {
+		t.Errorf("Unexpected summary for all the names: %+v", summary)
+	}
+
+	if summary := g.InfrastructureSummary(nil, "b1ac2b5d-cf4c-4a7f-bab5-5ab3c54c9c8a"); len(summary) != 0 {
+		t.Errorf("Expected an empty summary for an unknown event, got %+v", summary)
+	}
+}
+
+func TestNamesByASN(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+
+	records := map[string]string{
+		"www.owasp.org":   "192.0.2.10",
+		"lists.owasp.org": "192.0.2.20",
+		"www.example.com": "192.0.2.30",
+		"mail.owasp.org":  "198.51.100.5",
+	}
+	for name, addr := range records {
+		if err := g.InsertA(name, addr, "DNS", "dns", event); err != nil {
+			t.Fatalf("Failed to insert the A record: %v", err)
+		}
+	}
+	for addr, cidr := range map[string]string{
+		"192.0.2.10": "192.0.2.0/25",
+		"192.0.2.20": "192.0.2.0/25",
+		"192.0.2.30": "192.0.2.0/25",
+	} {
+		if err := g.InsertInfrastructure(64496, "EXAMPLE-AS", addr, cidr, "RIR", "rir", event); err != nil {
+			t.Fatalf("Failed to insert the infrastructure data: %v", err)
+		}
+	}
+	if err := g.InsertInfrastructure(64497, "OTHER-AS", "198.51.100.5", "198.51.100.0/24", "RIR", "rir", event); err != nil {
+		t.Fatalf("Failed to insert the infrastructure data: %v", err)
+	}
+
+	names, err := g.NamesByASN(64496, []string{"owasp.org"}, event)
+	if err != nil {
+		t.Fatalf("Failed to obtain the names for the ASN: %v", err)
+	}
+	expected := []ASNName{
+		{Name: "lists.owasp.org", Address: "192.0.2.20", Netblock: "192.0.2.0/25", ASN: 64496},
+		{Name: "www.owasp.org", Address: "192.0.2.10", Netblock: "192.0.2.0/25", ASN: 64496},
+	}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %d names, got %d", len(expected), len(names))
+	}
+	for i, n := range names {
+		if *n != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], *n)
+		}
+	}
+
+	if names, err := g.NamesByASN(64496, nil, "b1ac2b5d-cf4c-4a7f-bab5-5ab3c54c9c8a"); err != nil || len(names) != 0 {
+		t.Errorf("Expected no names for an unknown event, got %v: %v", names, err)
+	}
+	if _, err := g.NamesByASN(64511, nil, event); err == nil || err.Error() != "no data stored for AS64511" {
+		t.Errorf("Expected an error for the unknown ASN, got %v", err)
+	}
+}
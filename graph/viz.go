@@ -4,13 +4,16 @@
 package graph
 
 import (
+	"strings"
+
 	"github.com/OWASP/Amass/v3/graph/db"
 	"github.com/OWASP/Amass/v3/stringset"
 	"github.com/OWASP/Amass/v3/viz"
 )
 
-// VizData returns the current state of the Graph as viz package Nodes and Edges.
-func (g *Graph) VizData(uuid string) ([]viz.Node, []viz.Edge) {
+// VizData returns the current state of the Graph as viz package Nodes and Edges. When domains
+// are provided, only the subgraph reachable from the names within those domains is returned.
+func (g *Graph) VizData(uuid string, domains ...string) ([]viz.Node, []viz.Edge) {
 	event, err := g.db.ReadNode(uuid, "event")
 	if err != nil {
 		return nil, nil
@@ -75,9 +78,116 @@ func (g *Graph) VizData(uuid string) ([]viz.Node, []viz.Edge) {
 		}
 	}
 
+	if len(domains) > 0 {
+		nodes, edges = vizSubgraph(nodes, edges, domains)
+	}
 	return nodes, edges
 }
 
+// vizSubgraph walks from the names within the domains to their records and then up to the
+// infrastructure level, so netblocks and ASNs shared with other domains do not pull them in.
+func vizSubgraph(nodes []viz.Node, edges []viz.Edge, domains []string) ([]viz.Node, []viz.Edge) {
+	out := make(map[int][]viz.Edge)
+	in := make(map[int][]viz.Edge)
+	for _, e := range edges {
+		out[e.From] = append(out[e.From], e)
+		in[e.To] = append(in[e.To], e)
+	}
+
+	var queue []int
+	reached := make(map[int]bool)
+	visit := func(idx int) {
+		if !reached[idx] {
+			reached[idx] = true
+			queue = append(queue, idx)
+		}
+	}
+
+	for i, n := range nodes {
+		if vizNameNode(n.Type) && inVizDomains(n.Label, domains) {
+			visit(i)
+		}
+	}
+
+	for len(queue) > 0 {
+		idx := queue[0]
+		queue = queue[1:]
+
+		switch nodes[idx].Type {
+		case "address":
+			for _, e := range in[idx] {
+				if e.Title == "contains" {
+					visit(e.From)
+				}
+			}
+		case "netblock":
+			for _, e := range in[idx] {
+				if e.Title == "prefix" {
+					visit(e.From)
+				}
+			}
+		case "as":
+		default:
+			inScope := inVizDomains(nodes[idx].Label, domains)
+
+			for _, e := range out[idx] {
+				// Do not expand to the subdomains of other domains referenced by the records
+				if e.Title == "root" && !inScope {
+					continue
+				}
+				visit(e.To)
+			}
+			// Include the reverse DNS names pointing at the names
+			for _, e := range in[idx] {
+				if e.Title == "ptr_record" {
+					visit(e.From)
+				}
+			}
+		}
+	}
+
+	var subnodes []viz.Node
+	indices := make(map[int]int)
+	for i, n := range nodes {
+		if reached[i] {
+			indices[i] = len(subnodes)
+			n.ID = len(subnodes)
+			subnodes = append(subnodes, n)
+		}
+	}
+
+	var subedges []viz.Edge
+	for _, e := range edges {
+		from, ok1 := indices[e.From]
+		to, ok2 := indices[e.To]
+
+		if ok1 && ok2 {
+			e.From = from
+			e.To = to
+			subedges = append(subedges, e)
+		}
+	}
+
+	return subnodes, subedges
+}
+
+func vizNameNode(ntype string) bool {
+	return ntype == "domain" || ntype == "subdomain" || ntype == "ns" || ntype == "mx"
+}
+
+func inVizDomains(name string, domains []string) bool {
+	name = strings.ToLower(name)
+
+	for _, d := range domains {
+		d = strings.ToLower(d)
+
+		if name == d || strings.HasSuffix(name, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *Graph) buildVizNode(node db.Node, ntype, uuid string) *viz.Node {
 	id := g.db.NodeToID(node)
 
@@ -135,10 +245,13 @@ func (g *Graph) buildVizNode(node db.Node, ntype, uuid string) *viz.Node {
 		title = title + ", Desc: " + g.ReadASDescription(id)
 	}
 
+	first, _ := g.readSeen(node)
 	return &viz.Node{
-		Type:   ntype,
-		Label:  id,
-		Title:  title,
-		Source: src,
+		Type:      ntype,
+		Label:     id,
+		Title:     title,
+		Source:    src,
+		Tag:       g.SourceTag(src),
+		FirstSeen: first,
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/OWASP/Amass/v3/graph/db"
+	"github.com/OWASP/Amass/v3/viz"
 )
 
 func VizTest(t *testing.T) {
@@ -28,3 +29,69 @@ func VizTest(t *testing.T) {
 	}
 
 }
+
+func TestVizNodeMetadata(t *testing.T) {
+	g := NewGraph(db.NewCayleyGraphMemory())
+
+	if err := g.InsertA("www.owasp.org", "192.168.1.1", "DNS", "dns", "vizTest"); err != nil {
+		t.Fatalf("Error inserting A record: %v", err)
+	}
+
+	nodes, _ := g.VizData("vizTest")
+	if len(nodes) == 0 {
+		t.Fatal("VizData returned no nodes")
+	}
+
+	for _, n := range nodes {
+		if n.Source != "DNS" || n.Tag != "dns" {
+			t.Errorf("Node %s has source %s and tag %s", n.Label, n.Source, n.Tag)
+		}
+		if n.FirstSeen.IsZero() {
+			t.Errorf("Node %s is missing the first seen time", n.Label)
+		}
+	}
+}
+
+func TestVizSubgraph(t *testing.T) {
+	nodes := []viz.Node{
+		{ID: 0, Type: "domain", Label: "owasp.org"},
+		{ID: 1, Type: "subdomain", Label: "www.owasp.org"},
+		{ID: 2, Type: "address", Label: "192.168.1.1"},
+		{ID: 3, Type: "netblock", Label: "192.168.1.0/24"},
+		{ID: 4, Type: "as", Label: "26808"},
+		{ID: 5, Type: "domain", Label: "example.com"},
+		{ID: 6, Type: "subdomain", Label: "www.example.com"},
+		{ID: 7, Type: "address", Label: "192.168.1.2"},
+		{ID: 8, Type: "ptr", Label: "1.1.168.192.in-addr.arpa"},
+	}
+	edges := []viz.Edge{
+		{From: 0, To: 1, Title: "root"},
+		{From: 1, To: 2, Title: "a_record"},
+		{From: 3, To: 2, Title: "contains"},
+		{From: 4, To: 3, Title: "prefix"},
+		{From: 5, To: 6, Title: "root"},
+		{From: 6, To: 7, Title: "a_record"},
+		{From: 3, To: 7, Title: "contains"},
+		{From: 8, To: 1, Title: "ptr_record"},
+	}
+
+	subnodes, subedges := vizSubgraph(nodes, edges, []string{"owasp.org"})
+	if len(subnodes) != 6 || len(subedges) != 5 {
+		t.Fatalf("Expected 6 nodes and 5 edges, got %d nodes and %d edges", len(subnodes), len(subedges))
+	}
+
+	for i, n := range subnodes {
+		if n.ID != i {
+			t.Errorf("Node %s was not reindexed", n.Label)
+		}
+		if n.Label == "example.com" || n.Label == "www.example.com" || n.Label == "192.168.1.2" {
+			t.Errorf("Node %s outside of the domain was included", n.Label)
+		}
+	}
+
+	for _, e := range subedges {
+		if e.From >= len(subnodes) || e.To >= len(subnodes) {
+			t.Errorf("Edge %s references a node outside of the subgraph", e.Title)
+		}
+	}
+}
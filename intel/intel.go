@@ -117,12 +117,22 @@ func (c *Collection) HostedDomains() error {
 	c.filter = stringset.NewStringFilter()
 	// Start the address ranges
 	for _, addr := range c.Config.Addresses {
+		if c.Config.AddressBlacklisted(addr.String()) {
+			continue
+		}
+
 		c.Config.SemMaxDNSQueries.Acquire(1)
 		c.wg.Add(1)
 		go c.investigateAddr(addr.String())
 	}
 
-	for _, cidr := range append(c.Config.CIDRs, c.asnsToCIDRs()...) {
+	// Merge the overlapping netblocks and remove the blacklisted ranges before the sweeps
+	sweep := amassnet.NewCIDRSet(append(c.Config.CIDRs, c.asnsToCIDRs()...)...)
+	for _, cidr := range c.Config.BlacklistedCIDRs {
+		sweep.Exclude(cidr)
+	}
+
+	for _, cidr := range sweep.Aggregate() {
 		// Skip IPv6 netblocks, since they are simply too large
 		if ip := cidr.IP.Mask(cidr.Mask); amassnet.IsIPv6(ip) {
 			continue
@@ -196,7 +206,7 @@ func (c *Collection) investigateAddr(addr string) {
 		return
 	}
 
-	for _, name := range http.PullCertificateNames(addr, c.Config.Ports) {
+	for _, name := range http.PullCertificateNames(addr, c.Config.Ports, c.Sys.PortChecker()) {
 		if n := strings.TrimSpace(name); n != "" {
 			d := c.Sys.Pool().SubdomainToDomain(n)
 
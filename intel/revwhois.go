@@ -0,0 +1,283 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package intel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/stringset"
+)
+
+// The maximum number of result pages requested from a provider for each search term
+const maxReverseWhoisPages = 10
+
+// ReverseWhoisProvider returns the domain names registered with the email address or
+// organization provided as the search term.
+type ReverseWhoisProvider interface {
+	String() string
+	RegisteredDomains(ctx context.Context, term string) ([]string, error)
+}
+
+// The reverse whois backends, keyed by the name of the data source section providing the API key.
+// Adding a backend only requires an entry here, since the command code uses ReverseWhoisProviders.
+var reverseWhoisBackends = map[string]func(key *config.APIKey, client *http.Client) ReverseWhoisProvider{
+	"WhoisXML": func(key *config.APIKey, client *http.Client) ReverseWhoisProvider {
+		return &whoisXMLProvider{
+			client:  client,
+			key:     key.Key,
+			baseURL: "https://reverse-whois-api.whoisxmlapi.com/api/v2",
+			limiter: newRateLimiter(10 * time.Second),
+		}
+	},
+	"Whoxy": func(key *config.APIKey, client *http.Client) ReverseWhoisProvider {
+		return &whoxyProvider{
+			client:  client,
+			key:     key.Key,
+			baseURL: "https://api.whoxy.com/",
+			limiter: newRateLimiter(time.Second),
+		}
+	},
+}
+
+// ReverseWhoisProviders returns the reverse whois providers that have API keys in the
+// data source settings of the configuration, sorted by name. The requests are sent by the client.
+func ReverseWhoisProviders(cfg *config.Config, client *http.Client) []ReverseWhoisProvider {
+	var names []string
+	for name := range reverseWhoisBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var providers []ReverseWhoisProvider
+	for _, name := range names {
+		if key := cfg.GetAPIKey(name); key != nil && key.Key != "" {
+			providers = append(providers, reverseWhoisBackends[name](key, client))
+		}
+	}
+	return providers
+}
+
+// RegistrantDomains sends the email addresses and organizations through the reverse whois
+// providers, and the registered domain names are sent out on the Output channel.
+func (c *Collection) RegistrantDomains(terms []string) error {
+	defer close(c.Output)
+
+	providers := ReverseWhoisProviders(c.Config, c.Sys.HTTPClient())
+	if len(providers) == 0 {
+		return errors.New("No reverse whois providers have API keys in the data source settings")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	filter := stringset.NewStringFilter()
+	for _, p := range providers {
+		for _, term := range terms {
+			domains, err := p.RegisteredDomains(ctx, term)
+			if err != nil && c.Config.Log != nil {
+				c.Config.Log.Printf("%s: Reverse whois for %s: %v", p, term, err)
+			}
+
+			for _, d := range domains {
+				d = strings.ToLower(strings.TrimSpace(d))
+				if d == "" || filter.Duplicate(d) {
+					continue
+				}
+
+				c.Output <- &requests.Output{
+					Name:   d,
+					Domain: d,
+					Tag:    requests.API,
+					Source: p.String(),
+				}
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// rateLimiter spaces out the requests sent to a provider.
+type rateLimiter struct {
+	sync.Mutex
+	delay time.Duration
+	last  time.Time
+}
+
+func newRateLimiter(delay time.Duration) *rateLimiter {
+	return &rateLimiter{delay: delay}
+}
+
+// wait blocks until the delay has passed since the previous request, or the context expires.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if d := r.delay - time.Since(r.last); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	r.last = time.Now()
+	return nil
+}
+
+type whoisXMLProvider struct {
+	client  *http.Client
+	key     string
+	baseURL string
+	limiter *rateLimiter
+}
+
+type whoisXMLRequest struct {
+	Search      string      `json:"searchType"`
+	Mode        string      `json:"mode"`
+	SearchAfter interface{} `json:"searchAfter,omitempty"`
+	SearchTerms struct {
+		Include []string `json:"include"`
+	} `json:"basicSearchTerms"`
+}
+
+type whoisXMLResponse struct {
+	Found    int         `json:"domainsCount"`
+	List     []string    `json:"domainsList"`
+	NextPage interface{} `json:"nextPageSearchAfter"`
+}
+
+func (w *whoisXMLProvider) String() string {
+	return "WhoisXML"
+}
+
+// RegisteredDomains implements the ReverseWhoisProvider interface.
+func (w *whoisXMLProvider) RegisteredDomains(ctx context.Context, term string) ([]string, error) {
+	var domains []string
+	headers := map[string]string{"X-Authentication-Token": w.key}
+
+	r := whoisXMLRequest{
+		Search: "current",
+		Mode:   "purchase",
+	}
+	r.SearchTerms.Include = []string{term}
+	for i := 0; i < maxReverseWhoisPages; i++ {
+		if err := w.limiter.wait(ctx); err != nil {
+			return domains, err
+		}
+
+		body, err := json.Marshal(r)
+		if err != nil {
+			return domains, err
+		}
+
+		page, err := w.client.RequestWebPage(ctx, w.baseURL, bytes.NewReader(body), headers, "", "")
+		if err != nil {
+			return domains, err
+		}
+
+		var resp whoisXMLResponse
+		if err := json.Unmarshal([]byte(page), &resp); err != nil {
+			return domains, fmt.Errorf("Failed to decode the JSON: %v", err)
+		}
+
+		domains = append(domains, resp.List...)
+		// The next page is requested using the value provided by the previous page
+		if resp.NextPage == nil || len(resp.List) == 0 {
+			break
+		}
+		r.SearchAfter = resp.NextPage
+	}
+	return domains, nil
+}
+
+type whoxyProvider struct {
+	client  *http.Client
+	key     string
+	baseURL string
+	limiter *rateLimiter
+}
+
+type whoxyResponse struct {
+	Status       int    `json:"status"`
+	StatusReason string `json:"status_reason"`
+	TotalPages   int    `json:"total_pages"`
+	CurrentPage  int    `json:"current_page"`
+	Results      []struct {
+		Domain string `json:"domain_name"`
+	} `json:"search_result"`
+}
+
+func (w *whoxyProvider) String() string {
+	return "Whoxy"
+}
+
+// RegisteredDomains implements the ReverseWhoisProvider interface.
+func (w *whoxyProvider) RegisteredDomains(ctx context.Context, term string) ([]string, error) {
+	var domains []string
+
+	// Search terms containing an at sign are registrant email addresses
+	field := "company"
+	if strings.Contains(term, "@") {
+		field = "email"
+	}
+
+	for page := 1; page <= maxReverseWhoisPages; page++ {
+		if err := w.limiter.wait(ctx); err != nil {
+			return domains, err
+		}
+
+		q := url.Values{}
+		q.Set("key", w.key)
+		q.Set("reverse", "whois")
+		q.Set(field, term)
+		q.Set("page", strconv.Itoa(page))
+
+		body, err := w.client.RequestWebPage(ctx, w.baseURL+"?"+q.Encode(), nil, nil, "", "")
+		if err != nil {
+			return domains, err
+		}
+
+		var resp whoxyResponse
+		if err := json.Unmarshal([]byte(body), &resp); err != nil {
+			return domains, fmt.Errorf("Failed to decode the JSON: %v", err)
+		}
+		if resp.Status != 1 {
+			return domains, fmt.Errorf("The request failed: %s", resp.StatusReason)
+		}
+
+		for _, r := range resp.Results {
+			domains = append(domains, r.Domain)
+		}
+		if page >= resp.TotalPages {
+			break
+		}
+	}
+	return domains, nil
+}
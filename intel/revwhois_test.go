@@ -0,0 +1,110 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package intel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+)
+
+func TestWhoisXMLProviderPagination(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		requests++
+		if r.Header.Get("X-Authentication-Token") != "secret" {
+			t.Errorf("The API key was not provided in the request headers")
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		var req whoisXMLRequest
+		if err := json.Unmarshal(body, &req); err != nil || len(req.SearchTerms.Include) != 1 ||
+			req.SearchTerms.Include[0] != "admin@owasp.org" {
+			t.Errorf("Unexpected request body: %s", body)
+		}
+
+		if req.SearchAfter == nil {
+			io.WriteString(w, `{"domainsCount": 2, "domainsList": ["owasp.org", "owasp.net"], "nextPageSearchAfter": 1234}`)
+			return
+		}
+		io.WriteString(w, `{"domainsCount": 1, "domainsList": ["owasp.com"], "nextPageSearchAfter": null}`)
+	}))
+	defer srv.Close()
+
+	p := &whoisXMLProvider{client: testClient(t), key: "secret", baseURL: srv.URL, limiter: newRateLimiter(0)}
+	domains, err := p.RegisteredDomains(context.Background(), "admin@owasp.org")
+	if err != nil {
+		t.Fatalf("RegisteredDomains failed: %v", err)
+	}
+	if expected := []string{"owasp.org", "owasp.net", "owasp.com"}; !reflect.DeepEqual(domains, expected) {
+		t.Errorf("Expected %v, got %v", expected, domains)
+	}
+	if requests != 2 {
+		t.Errorf("Expected two pages to be requested, got %d", requests)
+	}
+}
+
+func TestWhoxyProviderPagination(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		q := r.URL.Query()
+		if q.Get("key") != "secret" || q.Get("reverse") != "whois" || q.Get("company") != "OWASP Foundation" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+
+		fmt.Fprintf(w, `{"status": 1, "total_pages": 2, "current_page": %s, "search_result": [{"domain_name": "page%s.org"}]}`,
+			q.Get("page"), q.Get("page"))
+	}))
+	defer srv.Close()
+
+	p := &whoxyProvider{client: testClient(t), key: "secret", baseURL: srv.URL + "/", limiter: newRateLimiter(0)}
+	domains, err := p.RegisteredDomains(context.Background(), "OWASP Foundation")
+	if err != nil {
+		t.Fatalf("RegisteredDomains failed: %v", err)
+	}
+	if expected := []string{"page1.org", "page2.org"}; !reflect.DeepEqual(domains, expected) {
+		t.Errorf("Expected %v, got %v", expected, domains)
+	}
+}
+
+func TestWhoxyProviderError(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		io.WriteString(w, `{"status": 0, "status_reason": "Invalid API key"}`)
+	}))
+	defer srv.Close()
+
+	p := &whoxyProvider{client: testClient(t), key: "bad", baseURL: srv.URL + "/", limiter: newRateLimiter(0)}
+	if _, err := p.RegisteredDomains(context.Background(), "admin@owasp.org"); err == nil {
+		t.Errorf("The failed request did not return an error")
+	}
+}
+
+func TestReverseWhoisProviders(t *testing.T) {
+	cfg := config.NewConfig()
+	if providers := ReverseWhoisProviders(cfg, testClient(t)); len(providers) != 0 {
+		t.Errorf("Expected no providers without API keys, got %v", providers)
+	}
+
+	cfg.AddAPIKey("Whoxy", &config.APIKey{Key: "secret"})
+	providers := ReverseWhoisProviders(cfg, testClient(t))
+	if len(providers) != 1 || providers[0].String() != "Whoxy" {
+		t.Errorf("Expected only the Whoxy provider, got %v", providers)
+	}
+}
+
+func testClient(t *testing.T) *http.Client {
+	client, err := http.NewClient(http.ClientSettings{})
+	if err != nil {
+		t.Fatalf("Failed to create the HTTP client: %v", err)
+	}
+	return client
+}
@@ -0,0 +1,304 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package net
+
+import (
+	"math/bits"
+	"net"
+	"sort"
+	"sync"
+)
+
+// CIDRSet is a set of IPv4 and IPv6 addresses built from netblocks. The addresses are kept as
+// sorted, non-overlapping ranges, so membership checks are a binary search regardless of the
+// number of netblocks added.
+type CIDRSet struct {
+	sync.RWMutex
+	v4 []ipRange
+	v6 []ipRange
+}
+
+// ipRange is an inclusive range of addresses represented as 128-bit integers.
+type ipRange struct {
+	first, last uint128
+}
+
+type uint128 struct {
+	hi, lo uint64
+}
+
+// NewCIDRSet returns a CIDRSet containing the provided netblocks.
+func NewCIDRSet(cidrs ...*net.IPNet) *CIDRSet {
+	s := new(CIDRSet)
+
+	for _, cidr := range cidrs {
+		s.Add(cidr)
+	}
+	return s
+}
+
+// Add inserts all the addresses of the netblock into the set.
+func (s *CIDRSet) Add(cidr *net.IPNet) {
+	if r, v4, ok := cidrToRange(cidr); ok {
+		s.Lock()
+		defer s.Unlock()
+
+		s.setRanges(v4, addRange(s.ranges(v4), r))
+	}
+}
+
+// AddIP inserts the address into the set.
+func (s *CIDRSet) AddIP(ip net.IP) {
+	if ip4 := ip.To4(); ip4 != nil {
+		s.Add(&net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)})
+	} else if ip16 := ip.To16(); ip16 != nil {
+		s.Add(&net.IPNet{IP: ip16, Mask: net.CIDRMask(128, 128)})
+	}
+}
+
+// Exclude removes all the addresses of the netblock from the set.
+func (s *CIDRSet) Exclude(cidr *net.IPNet) {
+	if r, v4, ok := cidrToRange(cidr); ok {
+		s.Lock()
+		defer s.Unlock()
+
+		s.setRanges(v4, excludeRange(s.ranges(v4), r))
+	}
+}
+
+// Contains returns true when the address is a member of the set.
+func (s *CIDRSet) Contains(ip net.IP) bool {
+	n, v4, ok := ipToUint128(ip)
+	if !ok {
+		return false
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	ranges := s.ranges(v4)
+	// Find the first range that does not end before the address
+	i := sort.Search(len(ranges), func(i int) bool {
+		return !ranges[i].last.less(n)
+	})
+	return i < len(ranges) && !n.less(ranges[i].first)
+}
+
+// Empty returns true when the set does not contain any addresses.
+func (s *CIDRSet) Empty() bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	return len(s.v4) == 0 && len(s.v6) == 0
+}
+
+// Aggregate returns the smallest list of netblocks covering the addresses in the set,
+// with adjacent and overlapping netblocks merged. The IPv4 netblocks are listed first.
+func (s *CIDRSet) Aggregate() []*net.IPNet {
+	s.RLock()
+	defer s.RUnlock()
+
+	var cidrs []*net.IPNet
+	for _, r := range s.v4 {
+		cidrs = append(cidrs, rangeToCIDRs(r, true)...)
+	}
+	for _, r := range s.v6 {
+		cidrs = append(cidrs, rangeToCIDRs(r, false)...)
+	}
+	return cidrs
+}
+
+func (s *CIDRSet) ranges(v4 bool) []ipRange {
+	if v4 {
+		return s.v4
+	}
+	return s.v6
+}
+
+func (s *CIDRSet) setRanges(v4 bool, ranges []ipRange) {
+	if v4 {
+		s.v4 = ranges
+	} else {
+		s.v6 = ranges
+	}
+}
+
+// addRange returns the sorted ranges with r merged in, including adjacent ranges.
+func addRange(ranges []ipRange, r ipRange) []ipRange {
+	var result []ipRange
+
+	i := 0
+	// Keep the ranges ending before r, without being adjacent
+	for ; i < len(ranges) && ranges[i].last.less(r.first) && !ranges[i].last.adjacent(r.first); i++ {
+		result = append(result, ranges[i])
+	}
+	// Merge the ranges overlapping or adjacent to r
+	for ; i < len(ranges) && (!r.last.less(ranges[i].first) || r.last.adjacent(ranges[i].first)); i++ {
+		if ranges[i].first.less(r.first) {
+			r.first = ranges[i].first
+		}
+		if r.last.less(ranges[i].last) {
+			r.last = ranges[i].last
+		}
+	}
+
+	result = append(result, r)
+	return append(result, ranges[i:]...)
+}
+
+// excludeRange returns the sorted ranges with the addresses of r removed.
+func excludeRange(ranges []ipRange, r ipRange) []ipRange {
+	var result []ipRange
+
+	for _, cur := range ranges {
+		if cur.last.less(r.first) || r.last.less(cur.first) {
+			result = append(result, cur)
+			continue
+		}
+
+		if cur.first.less(r.first) {
+			result = append(result, ipRange{first: cur.first, last: r.first.dec()})
+		}
+		if r.last.less(cur.last) {
+			result = append(result, ipRange{first: r.last.inc(), last: cur.last})
+		}
+	}
+	return result
+}
+
+// rangeToCIDRs splits the range into the largest aligned netblocks.
+func rangeToCIDRs(r ipRange, v4 bool) []*net.IPNet {
+	var cidrs []*net.IPNet
+
+	width := 128
+	if v4 {
+		width = 32
+	}
+
+	start := r.first
+	for {
+		// The largest block aligned with the start address
+		size := start.trailingZeros()
+		if size > width {
+			size = width
+		}
+		// Shrink the block until it fits within the range
+		for size > 0 && r.last.less(start.or(hostMask(size))) {
+			size--
+		}
+
+		cidrs = append(cidrs, &net.IPNet{
+			IP:   uint128ToIP(start, v4),
+			Mask: net.CIDRMask(width-size, width),
+		})
+
+		end := start.or(hostMask(size))
+		if !end.less(r.last) {
+			break
+		}
+		start = end.inc()
+	}
+	return cidrs
+}
+
+func cidrToRange(cidr *net.IPNet) (ipRange, bool, bool) {
+	if cidr == nil {
+		return ipRange{}, false, false
+	}
+
+	first, v4, ok := ipToUint128(cidr.IP.Mask(cidr.Mask))
+	if !ok {
+		return ipRange{}, false, false
+	}
+
+	ones, total := cidr.Mask.Size()
+	if total == 0 {
+		return ipRange{}, false, false
+	}
+
+	return ipRange{first: first, last: first.or(hostMask(total - ones))}, v4, true
+}
+
+func ipToUint128(ip net.IP) (uint128, bool, bool) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return uint128{lo: uint64(ip4[0])<<24 | uint64(ip4[1])<<16 | uint64(ip4[2])<<8 | uint64(ip4[3])}, true, true
+	}
+
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return uint128{}, false, false
+	}
+
+	var n uint128
+	for i := 0; i < 8; i++ {
+		n.hi = n.hi<<8 | uint64(ip16[i])
+		n.lo = n.lo<<8 | uint64(ip16[i+8])
+	}
+	return n, false, true
+}
+
+func uint128ToIP(n uint128, v4 bool) net.IP {
+	if v4 {
+		return net.IPv4(byte(n.lo>>24), byte(n.lo>>16), byte(n.lo>>8), byte(n.lo)).To4()
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	for i := 7; i >= 0; i-- {
+		ip[i] = byte(n.hi)
+		ip[i+8] = byte(n.lo)
+		n.hi >>= 8
+		n.lo >>= 8
+	}
+	return ip
+}
+
+// hostMask returns the value with the low n bits set.
+func hostMask(n int) uint128 {
+	switch {
+	case n <= 0:
+		return uint128{}
+	case n < 64:
+		return uint128{lo: 1<<uint(n) - 1}
+	case n < 128:
+		return uint128{hi: 1<<uint(n-64) - 1, lo: ^uint64(0)}
+	}
+	return uint128{hi: ^uint64(0), lo: ^uint64(0)}
+}
+
+func (n uint128) less(o uint128) bool {
+	return n.hi < o.hi || (n.hi == o.hi && n.lo < o.lo)
+}
+
+func (n uint128) or(o uint128) uint128 {
+	return uint128{hi: n.hi | o.hi, lo: n.lo | o.lo}
+}
+
+func (n uint128) inc() uint128 {
+	if n.lo == ^uint64(0) {
+		return uint128{hi: n.hi + 1}
+	}
+	return uint128{hi: n.hi, lo: n.lo + 1}
+}
+
+func (n uint128) dec() uint128 {
+	if n.lo == 0 {
+		return uint128{hi: n.hi - 1, lo: ^uint64(0)}
+	}
+	return uint128{hi: n.hi, lo: n.lo - 1}
+}
+
+// adjacent returns true when o immediately follows n.
+func (n uint128) adjacent(o uint128) bool {
+	return n.less(o) && n.inc() == o
+}
+
+func (n uint128) trailingZeros() int {
+	if n.lo != 0 {
+		return bits.TrailingZeros64(n.lo)
+	}
+	if n.hi != 0 {
+		return 64 + bits.TrailingZeros64(n.hi)
+	}
+	return 128
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package net
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func parseCIDRs(t testing.TB, cidrs ...string) []*net.IPNet {
+	var nets []*net.IPNet
+
+	for _, c := range cidrs {
+		_, ipnet, err := net.ParseCIDR(c)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", c, err)
+		}
+		nets = append(nets, ipnet)
+	}
+	return nets
+}
+
+func cidrStrings(cidrs []*net.IPNet) string {
+	return fmt.Sprintf("%v", cidrs)
+}
+
+func TestCIDRSetAggregate(t *testing.T) {
+	tests := []struct {
+		Add      []string
+		Exclude  []string
+		Expected string
+	}{
+		{[]string{"192.168.0.0/25", "192.168.0.128/25"}, nil, "[192.168.0.0/24]"},
+		{[]string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"}, nil, "[10.0.0.0/23 10.0.2.0/24]"},
+		{[]string{"10.0.0.0/16", "10.0.5.0/24"}, nil, "[10.0.0.0/16]"},
+		{[]string{"10.0.0.0/24"}, []string{"10.0.0.0/25"}, "[10.0.0.128/25]"},
+		{[]string{"10.0.0.0/24"}, []string{"10.0.0.64/26"}, "[10.0.0.0/26 10.0.0.128/25]"},
+		{[]string{"10.0.0.0/30"}, []string{"10.0.0.0/24"}, "[]"},
+		{[]string{"2001:db8::/33", "2001:db8:8000::/33"}, nil, "[2001:db8::/32]"},
+		{[]string{"2001:db8::/32"}, []string{"2001:db8::/33"}, "[2001:db8:8000::/33]"},
+		{[]string{"2001:db8::/32", "192.168.1.0/24"}, nil, "[192.168.1.0/24 2001:db8::/32]"},
+		{[]string{"0.0.0.0/0"}, nil, "[0.0.0.0/0]"},
+		{[]string{"::/0"}, []string{"::/1"}, "[8000::/1]"},
+	}
+
+	for _, test := range tests {
+		set := NewCIDRSet(parseCIDRs(t, test.Add...)...)
+		for _, cidr := range parseCIDRs(t, test.Exclude...) {
+			set.Exclude(cidr)
+		}
+
+		if got := cidrStrings(set.Aggregate()); got != test.Expected {
+			t.Errorf("Add %v Exclude %v: expected %s, got %s", test.Add, test.Exclude, test.Expected, got)
+		}
+	}
+}
+
+func TestCIDRSetContains(t *testing.T) {
+	set := NewCIDRSet(parseCIDRs(t, "10.0.0.0/8", "192.168.1.0/24", "2001:db8::/32")...)
+	set.Exclude(parseCIDRs(t, "10.1.0.0/16")[0])
+	set.AddIP(net.ParseIP("172.16.0.1"))
+
+	tests := []struct {
+		Address  string
+		Expected bool
+	}{
+		{"10.0.0.1", true},
+		{"10.255.255.255", true},
+		{"10.1.2.3", false},
+		{"10.2.0.0", true},
+		{"192.168.1.100", true},
+		{"192.168.2.1", false},
+		{"172.16.0.1", true},
+		{"172.16.0.2", false},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+		{"::ffff:10.0.0.1", true},
+	}
+
+	for _, test := range tests {
+		if got := set.Contains(net.ParseIP(test.Address)); got != test.Expected {
+			t.Errorf("Contains(%s) returned %t", test.Address, got)
+		}
+	}
+
+	if NewCIDRSet().Contains(net.ParseIP("10.0.0.1")) || !NewCIDRSet().Empty() {
+		t.Errorf("The empty CIDRSet contained an address")
+	}
+}
+
+func benchmarkCIDRs(b *testing.B) []*net.IPNet {
+	var cidrs []string
+
+	for i := 0; i < 4096; i++ {
+		cidrs = append(cidrs, fmt.Sprintf("10.%d.%d.0/26", i/64, (i%64)*4))
+	}
+	return parseCIDRs(b, cidrs...)
+}
+
+func BenchmarkCIDRSetContains(b *testing.B) {
+	set := NewCIDRSet(benchmarkCIDRs(b)...)
+	ip := net.ParseIP("10.63.252.1")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Contains(ip)
+	}
+}
+
+func BenchmarkLinearContains(b *testing.B) {
+	cidrs := benchmarkCIDRs(b)
+	ip := net.ParseIP("10.63.252.1")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, cidr := range cidrs {
+			if cidr.Contains(ip) {
+				break
+			}
+		}
+	}
+}
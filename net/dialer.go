@@ -0,0 +1,124 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package net
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// RateLimitedDialer makes the connections for the active techniques within a single budget of
+// connections per second and concurrent dials. When the budget is saturated, the callers block
+// until their turn, rather than being refused, so an assessment never exceeds the ceiling.
+type RateLimitedDialer struct {
+	// The time between the tokens of the bucket, where zero disables the rate limit
+	interval time.Duration
+	dials    chan struct{}
+
+	lock  sync.Mutex
+	next  time.Time
+	first time.Time
+	last  time.Time
+	count uint64
+}
+
+// NewRateLimitedDialer returns a RateLimitedDialer that starts at most perSec connections each
+// second, with no more than maxDials in progress. A perSec of zero disables the rate limit.
+func NewRateLimitedDialer(perSec, maxDials int) *RateLimitedDialer {
+	if maxDials <= 0 {
+		maxDials = DefaultMaxActiveConns
+	}
+
+	var interval time.Duration
+	if perSec > 0 {
+		interval = time.Second / time.Duration(perSec)
+	}
+
+	return &RateLimitedDialer{
+		interval: interval,
+		dials:    make(chan struct{}, maxDials),
+	}
+}
+
+// DialContext connects to the address on the named network once the budget allows it. The
+// context can cancel the wait, and the dial itself.
+func (d *RateLimitedDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.DialTimeout(ctx, network, address, 0)
+}
+
+// DialTimeout performs DialContext, while the connection must be established within the timeout
+// once the budget allows it. The time spent waiting for the budget is not part of the timeout.
+func (d *RateLimitedDialer) DialTimeout(ctx context.Context, network, address string, timeout time.Duration) (net.Conn, error) {
+	select {
+	case d.dials <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-d.dials }()
+
+	if err := d.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// wait blocks until the next token of the bucket is issued to the caller.
+func (d *RateLimitedDialer) wait(ctx context.Context) error {
+	d.lock.Lock()
+	now := time.Now()
+	at := now
+	if d.interval > 0 {
+		if d.next.After(now) {
+			at = d.next
+		}
+		d.next = at.Add(d.interval)
+	}
+	d.lock.Unlock()
+
+	if delay := at.Sub(now); delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.count == 0 {
+		d.first = at
+	}
+	d.last = at
+	d.count++
+	return nil
+}
+
+// Dials returns the number of connections attempted through the RateLimitedDialer.
+func (d *RateLimitedDialer) Dials() uint64 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.count
+}
+
+// AverageRate returns the achieved average of connections attempted per second, measured
+// from the first to the last attempt.
+func (d *RateLimitedDialer) AverageRate() float64 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	elapsed := d.last.Sub(d.first)
+	if d.count < 2 || elapsed <= 0 {
+		return float64(d.count)
+	}
+	return float64(d.count-1) / elapsed.Seconds()
+}
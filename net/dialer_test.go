@@ -0,0 +1,64 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package net
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedDialer(t *testing.T) {
+	ln, port := testListener(t)
+	defer ln.Close()
+
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	d := NewRateLimitedDialer(20, 10)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		conn, err := d.DialContext(context.Background(), "tcp", addr)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		conn.Close()
+	}
+
+	// Five connections at 20 per second require at least four intervals of 50ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("The five connections were made in %v, exceeding the rate", elapsed)
+	}
+	if dials := d.Dials(); dials != 5 {
+		t.Errorf("Expected 5 dials to be counted, got %d", dials)
+	}
+	if rate := d.AverageRate(); rate > 21 || rate < 15 {
+		t.Errorf("Expected an average rate close to 20/sec, got %.2f", rate)
+	}
+}
+
+func TestRateLimitedDialerContext(t *testing.T) {
+	ln, port := testListener(t)
+	defer ln.Close()
+
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	// The first dial uses the only token of the next ten seconds
+	d := NewRateLimitedDialer(1, 10)
+	d.interval = 10 * time.Second
+	if conn, err := d.DialContext(context.Background(), "tcp", addr); err == nil {
+		conn.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := d.DialContext(ctx, "tcp", addr); err != context.DeadlineExceeded {
+		t.Errorf("Expected the wait to be cancelled by the context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("The cancelled dial blocked for %v", elapsed)
+	}
+}
@@ -5,11 +5,19 @@ package dns
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"net"
 	"regexp"
 	"strings"
 )
 
+// The limits for DNS hostnames defined in RFC 1035.
+const (
+	MaxLabelLength    = 63
+	MaxHostnameLength = 253
+)
+
 // SUBRE is a regular expression that will match on all subdomains once the domain is appended.
 const SUBRE = "(([a-zA-Z0-9]{1}|[_a-zA-Z0-9]{1}[_a-zA-Z0-9-]{0,61}[a-zA-Z0-9]{1})[.]{1})+"
 
@@ -27,6 +35,64 @@ func AnySubdomainRegex() *regexp.Regexp {
 	return regexp.MustCompile(SUBRE + "[a-zA-Z]{2,61}")
 }
 
+// ValidateHostname returns an error describing why the name is not a valid DNS hostname. The
+// labels can only contain letters, digits and hyphens, without a leading or trailing hyphen, as
+// described in RFC 1123. The single exception is a leading underscore, which marks the service
+// and protocol labels of names such as _sip._tcp.owasp.org, _dmarc.owasp.org, _acme-challenge.owasp.org
+// and selector1._domainkey.owasp.org. A trailing dot is accepted.
+func ValidateHostname(name string) error {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return errors.New("The name is empty")
+	}
+	if len(name) > MaxHostnameLength {
+		return fmt.Errorf("The name exceeds %d characters", MaxHostnameLength)
+	}
+
+	labels := strings.Split(name, ".")
+	for _, label := range labels {
+		if err := validateLabel(label); err != nil {
+			return err
+		}
+	}
+
+	// The top-level label cannot be numeric, which prevents addresses from being accepted
+	if tld := labels[len(labels)-1]; strings.Trim(tld, "0123456789") == "" || tld[0] == '_' {
+		return fmt.Errorf("The top-level label %q is not valid", tld)
+	}
+	return nil
+}
+
+// IsValidHostname returns true when the name passes the ValidateHostname checks.
+func IsValidHostname(name string) bool {
+	return ValidateHostname(name) == nil
+}
+
+func validateLabel(label string) error {
+	if label == "" {
+		return errors.New("The name contains an empty label")
+	}
+	if len(label) > MaxLabelLength {
+		return fmt.Errorf("The label %q exceeds %d characters", label, MaxLabelLength)
+	}
+
+	ldh := label
+	// Service and protocol labels begin with an underscore
+	if ldh[0] == '_' {
+		ldh = ldh[1:]
+	}
+	if ldh == "" || ldh[0] == '-' || ldh[len(ldh)-1] == '-' {
+		return fmt.Errorf("The label %q does not begin and end with a letter or digit", label)
+	}
+
+	for i := 0; i < len(ldh); i++ {
+		if c := ldh[i]; !(c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')) {
+			return fmt.Errorf("The label %q contains the invalid character %q", label, c)
+		}
+	}
+	return nil
+}
+
 // CopyString return a new string variable with the same value as the parameter.
 func CopyString(src string) string {
 	str := make([]byte, len(src))
@@ -103,3 +169,54 @@ func expandIPv6Addr(addr string) string {
 		string(dst[24:28]) + ":" +
 		string(dst[28:])
 }
+
+// ReverseName returns the name within the in-addr.arpa or ip6.arpa zone used to look up the
+// PTR record of the IP address, or an empty string when the address is not valid.
+func ReverseName(addr string) string {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+	if ip == nil {
+		return ""
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return ReverseIP(ip4.String()) + ".in-addr.arpa"
+	}
+	return IPv6NibbleFormat(ip.String()) + ".ip6.arpa"
+}
+
+// ReverseNameToAddr returns the IP address represented by the in-addr.arpa or ip6.arpa name,
+// or an empty string when the name does not represent a complete address.
+func ReverseNameToAddr(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+
+	var addr string
+	if n := strings.TrimSuffix(name, ".in-addr.arpa"); n != name {
+		parts := strings.Split(n, ".")
+		if len(parts) != net.IPv4len {
+			return ""
+		}
+		addr = ReverseIP(n)
+	} else if n := strings.TrimSuffix(name, ".ip6.arpa"); n != name {
+		nibbles := strings.Split(n, ".")
+		if len(nibbles) != 2*net.IPv6len {
+			return ""
+		}
+
+		var b strings.Builder
+		for i := len(nibbles) - 1; i >= 0; i-- {
+			if len(nibbles[i]) != 1 {
+				return ""
+			}
+			b.WriteString(nibbles[i])
+			if i > 0 && i%4 == 0 {
+				b.WriteString(":")
+			}
+		}
+		addr = b.String()
+	}
+
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
@@ -4,6 +4,7 @@
 package dns
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -57,6 +58,43 @@ func TestAnySubdomainRegex(t *testing.T) {
 	}
 }
 
+func TestValidateHostname(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Expected bool
+	}{
+		{"owasp.org", true},
+		{"www.OWASP.org.", true},
+		{"sub-domain.owasp.org", true},
+		{"123.owasp.org", true},
+		{"_sip._tcp.owasp.org", true},
+		{"selector1._domainkey.owasp.org", true},
+		{"_acme-challenge.owasp.org", true},
+		{"", false},
+		{".", false},
+		{"sub..owasp.org", false},
+		{".owasp.org", false},
+		{"-sub.owasp.org", false},
+		{"sub-.owasp.org", false},
+		{"my_host.owasp.org", false},
+		{"__sip.owasp.org", false},
+		{"_.owasp.org", false},
+		{"*.owasp.org", false},
+		{"www.owasp.org/path", false},
+		{"192.168.1.1", false},
+		{"owasp._org", false},
+		{strings.Repeat("a", 64) + ".owasp.org", false},
+		{strings.Repeat("a", 63) + ".owasp.org", true},
+		{strings.Repeat(strings.Repeat("a", 50)+".", 5) + "org", false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateHostname(test.Name); (err == nil) != test.Expected {
+			t.Errorf("ValidateHostname(%q) returned %v", test.Name, err)
+		}
+	}
+}
+
 func TestCopyString(t *testing.T) {
 	tests := []string{"", "owasp.org", "TESTING"}
 
@@ -157,3 +195,31 @@ func TestExpandIPv6Addr(t *testing.T) {
 		}
 	}
 }
+
+func TestReverseName(t *testing.T) {
+	tests := []struct {
+		Address string
+		Name    string
+	}{
+		{"72.237.4.1", "1.4.237.72.in-addr.arpa"},
+		{"2620:0:860:2::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.0.6.8.0.0.0.0.0.0.2.6.2.ip6.arpa"},
+	}
+
+	for _, test := range tests {
+		if r := ReverseName(test.Address); r != test.Name {
+			t.Errorf("%s caused %s to be returned instead of %s", test.Address, r, test.Name)
+		}
+		if r := ReverseNameToAddr(test.Name); r != test.Address {
+			t.Errorf("%s caused %s to be returned instead of %s", test.Name, r, test.Address)
+		}
+	}
+
+	for _, name := range []string{"4.237.72.in-addr.arpa", "1.0.ip6.arpa", "www.owasp.org", "a.b.c.d.in-addr.arpa"} {
+		if r := ReverseNameToAddr(name); r != "" {
+			t.Errorf("%s caused %s to be returned instead of an empty string", name, r)
+		}
+	}
+	if r := ReverseName("not an address"); r != "" {
+		t.Errorf("An invalid address caused %s to be returned", r)
+	}
+}
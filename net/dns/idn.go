@@ -0,0 +1,82 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// The prefix of the labels holding an internationalized label in the punycode form.
+const punycodePrefix = "xn--"
+
+// NormalizeName returns the canonical form of the name used for scope checks and storage:
+// lowercase ASCII without the surrounding dots, where the Unicode labels are converted to
+// punycode (e.g. münchen.example becomes xn--mnchen-3ya.example). The ASCII labels are not
+// validated, so service labels such as _sip and wildcard labels are kept.
+func NormalizeName(name string) (string, error) {
+	n := strings.Trim(strings.TrimSpace(name), ".")
+	if isASCII(n) {
+		return strings.ToLower(n), nil
+	}
+
+	labels := strings.Split(n, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			labels[i] = strings.ToLower(label)
+			continue
+		}
+
+		a, err := idna.Lookup.ToASCII(label)
+		if err != nil {
+			return "", fmt.Errorf("The label %q is not a valid internationalized label: %v", label, err)
+		}
+		labels[i] = a
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// Canonical returns the canonical form of the DNS name used for scope checks and as the key of
+// the graph nodes: the name in lowercase without whitespace and the surrounding dots, where the
+// Unicode labels are converted to punycode. The names with labels that cannot be converted are
+// only lowercased, so they are still rejected by ValidateHostname.
+func Canonical(name string) string {
+	n := strings.Join(strings.Fields(name), "")
+
+	if c, err := NormalizeName(n); err == nil {
+		return c
+	}
+	return strings.Trim(strings.ToLower(n), ".")
+}
+
+// UnicodeName returns the name with the punycode labels converted to the Unicode form for
+// display. The labels that cannot be converted are returned unchanged.
+func UnicodeName(name string) string {
+	if !strings.Contains(strings.ToLower(name), punycodePrefix) {
+		return name
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(strings.ToLower(label), punycodePrefix) {
+			continue
+		}
+
+		if u, err := idna.Display.ToUnicode(label); err == nil {
+			labels[i] = u
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
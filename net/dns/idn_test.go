@@ -0,0 +1,87 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{"www.owasp.org", "www.owasp.org", false},
+		{"WWW.OWASP.Org.", "www.owasp.org", false},
+		{"_sip._tcp.owasp.org", "_sip._tcp.owasp.org", false},
+		{"münchen.example", "xn--mnchen-3ya.example", false},
+		{"MÜNCHEN.Example.", "xn--mnchen-3ya.example", false},
+		{"xn--mnchen-3ya.example", "xn--mnchen-3ya.example", false},
+		{"www.bücher.owasp.org", "www.xn--bcher-kva.owasp.org", false},
+		{"-münchen.example", "", true},
+	}
+
+	for _, test := range tests {
+		name, err := NormalizeName(test.name)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: Expected an error, got %s", test.name, name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: Unexpected error: %v", test.name, err)
+		} else if name != test.expected {
+			t.Errorf("%s: Expected %s, got %s", test.name, test.expected, name)
+		}
+	}
+}
+
+func TestUnicodeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"www.owasp.org", "www.owasp.org"},
+		{"xn--mnchen-3ya.example", "münchen.example"},
+		{"www.xn--bcher-kva.owasp.org", "www.bücher.owasp.org"},
+	}
+
+	for _, test := range tests {
+		if name := UnicodeName(test.name); name != test.expected {
+			t.Errorf("%s: Expected %s, got %s", test.name, test.expected, name)
+		}
+	}
+}
+
+func TestRegisteredDomainUnicode(t *testing.T) {
+	domain, err := RegisteredDomain("www.münchen.example.de")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if domain != "example.de" {
+		t.Errorf("Expected example.de, got %s", domain)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"www.owasp.org", "www.owasp.org"},
+		{" WWW.Owasp.ORG. ", "www.owasp.org"},
+		{"www.\towasp.org\n", "www.owasp.org"},
+		{"..www.owasp.org..", "www.owasp.org"},
+		{"MÜNCHEN.Example.", "xn--mnchen-3ya.example"},
+		{"-MÜNCHEN.example.", "-münchen.example"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if name := Canonical(test.name); name != test.expected {
+			t.Errorf("%q: Expected %q, got %q", test.name, test.expected, name)
+		}
+	}
+}
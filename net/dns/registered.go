@@ -0,0 +1,171 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// The number of names that have their registered domain cached.
+const registeredDomainCacheSize = 10000
+
+// SuffixList derives the registered domains (eTLD+1) from the public suffix list, honoring
+// the suffix settings of an enumeration.
+type SuffixList struct {
+	private bool
+	extra   []string
+
+	lock  sync.Mutex
+	cache *domainCache
+}
+
+// The suffix list used by RegisteredDomain and PublicSuffix, which has the default settings.
+var defaultSuffixes = NewSuffixList(true, nil)
+
+// NewSuffixList returns a SuffixList using the private domains section of the public suffix
+// list, such as blogspot.com, when private is true. The additional suffixes, such as internal
+// zones, are treated as public in addition to the public suffix list.
+func NewSuffixList(private bool, additional []string) *SuffixList {
+	var extra []string
+
+	for _, s := range additional {
+		if s = Canonical(s); s != "" {
+			extra = append(extra, s)
+		}
+	}
+
+	return &SuffixList{
+		private: private,
+		extra:   extra,
+		cache:   newDomainCache(registeredDomainCacheSize),
+	}
+}
+
+// RegisteredDomain returns the registered domain (eTLD+1) of the name using the default suffix
+// settings. The name is normalized by NormalizeName, and the results are cached for the names
+// seen recently.
+func RegisteredDomain(name string) (string, error) {
+	return defaultSuffixes.RegisteredDomain(name)
+}
+
+// PublicSuffix returns the public suffix of the name using the default suffix settings.
+func PublicSuffix(name string) string {
+	return defaultSuffixes.PublicSuffix(name)
+}
+
+// RegisteredDomain returns the registered domain (eTLD+1) of the name. The name is normalized
+// by NormalizeName, and the results are cached for the names seen recently.
+func (sl *SuffixList) RegisteredDomain(name string) (string, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return "", fmt.Errorf("RegisteredDomain: %v", err)
+	}
+	if name == "" {
+		return "", errors.New("RegisteredDomain: Empty name provided")
+	}
+
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	if entry, found := sl.cache.get(name); found {
+		return entry.domain, entry.err
+	}
+
+	domain, err := registeredDomain(name, sl.private, sl.extra)
+	sl.cache.add(name, domain, err)
+	return domain, err
+}
+
+// PublicSuffix returns the public suffix of the name, honoring the same suffix
+// settings as RegisteredDomain.
+func (sl *SuffixList) PublicSuffix(name string) string {
+	return publicSuffix(Canonical(name), sl.private, sl.extra)
+}
+
+func publicSuffix(name string, private bool, extra []string) string {
+	suffix, icann := publicsuffix.PublicSuffix(name)
+	// Walk up to the suffix listed in the ICANN section of the list
+	for !private && !icann && strings.Contains(suffix, ".") {
+		suffix, icann = publicsuffix.PublicSuffix(suffix[strings.Index(suffix, ".")+1:])
+	}
+
+	// The longest matching suffix wins
+	for _, s := range extra {
+		if len(s) > len(suffix) && (name == s || strings.HasSuffix(name, "."+s)) {
+			suffix = s
+		}
+	}
+	return suffix
+}
+
+func registeredDomain(name string, private bool, extra []string) (string, error) {
+	suffix := publicSuffix(name, private, extra)
+
+	if len(name) <= len(suffix) || name[len(name)-len(suffix)-1] != '.' {
+		return "", fmt.Errorf("RegisteredDomain: Cannot derive eTLD+1 for domain %q", name)
+	}
+
+	prefix := name[:len(name)-len(suffix)-1]
+	if i := strings.LastIndex(prefix, "."); i >= 0 {
+		prefix = prefix[i+1:]
+	}
+	if prefix == "" {
+		return "", fmt.Errorf("RegisteredDomain: Cannot derive eTLD+1 for domain %q", name)
+	}
+	return prefix + "." + suffix, nil
+}
+
+// domainCache is a least recently used cache of registered domains.
+type domainCache struct {
+	max     int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type domainCacheEntry struct {
+	name   string
+	domain string
+	err    error
+}
+
+func newDomainCache(max int) *domainCache {
+	return &domainCache{
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *domainCache) get(name string) (*domainCacheEntry, bool) {
+	e, found := c.entries[name]
+	if !found {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+	return e.Value.(*domainCacheEntry), true
+}
+
+func (c *domainCache) add(name, domain string, err error) {
+	if e, found := c.entries[name]; found {
+		c.order.MoveToFront(e)
+		e.Value = &domainCacheEntry{name: name, domain: domain, err: err}
+		return
+	}
+
+	c.entries[name] = c.order.PushFront(&domainCacheEntry{name: name, domain: domain, err: err})
+	// Evict the least recently used entry
+	if c.order.Len() > c.max {
+		if e := c.order.Back(); e != nil {
+			c.order.Remove(e)
+			delete(c.entries, e.Value.(*domainCacheEntry).name)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package dns
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+func TestRegisteredDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		private  bool
+		expected string
+	}{
+		{"www.owasp.org", true, "owasp.org"},
+		{"WWW.OWASP.Org.", true, "owasp.org"},
+		{"owasp.org", true, "owasp.org"},
+		{"news.bbc.co.uk", true, "bbc.co.uk"},
+		{"a.b.example.com.au", true, "example.com.au"},
+		{"foo.blogspot.com", true, "foo.blogspot.com"},
+		{"foo.blogspot.com", false, "blogspot.com"},
+		{"www.foo.blogspot.co.uk", false, "blogspot.co.uk"},
+		{"news.bbc.co.uk", false, "bbc.co.uk"},
+		{"co.uk", true, ""},
+		{"com", false, ""},
+		{"", true, ""},
+	}
+
+	for _, test := range tests {
+		sl := NewSuffixList(test.private, nil)
+
+		// Check twice, so the cached result is also checked
+		for i := 0; i < 2; i++ {
+			domain, err := sl.RegisteredDomain(test.name)
+
+			if test.expected == "" {
+				if err == nil {
+					t.Errorf("RegisteredDomain(%q) returned %q without an error", test.name, domain)
+				}
+			} else if err != nil || domain != test.expected {
+				t.Errorf("RegisteredDomain(%q) with private suffixes %t returned %q, expected %q",
+					test.name, test.private, domain, test.expected)
+			}
+		}
+	}
+}
+
+func TestAdditionalSuffixes(t *testing.T) {
+	sl := NewSuffixList(true, []string{"corp", "Example-CDN.net."})
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"host.dc1.corp", "dc1.corp"},
+		{"www.customer.example-cdn.net", "customer.example-cdn.net"},
+		{"www.owasp.org", "owasp.org"},
+		{"example-cdn.net", ""},
+	}
+
+	for _, test := range tests {
+		domain, err := sl.RegisteredDomain(test.name)
+
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("RegisteredDomain(%q) returned %q without an error", test.name, domain)
+			}
+		} else if err != nil || domain != test.expected {
+			t.Errorf("RegisteredDomain(%q) returned %q, expected %q", test.name, domain, test.expected)
+		}
+	}
+
+	if s := sl.PublicSuffix("www.customer.example-cdn.net"); s != "example-cdn.net" {
+		t.Errorf("PublicSuffix returned %q, expected example-cdn.net", s)
+	}
+
+	// The default suffix settings are not affected by the additional suffixes
+	if domain, err := RegisteredDomain("www.customer.example-cdn.net"); err != nil || domain != "example-cdn.net" {
+		t.Errorf("The additional suffixes were used by the default suffix settings: %q", domain)
+	}
+}
+
+func TestDomainCacheEviction(t *testing.T) {
+	c := newDomainCache(2)
+
+	c.add("a.owasp.org", "owasp.org", nil)
+	c.add("b.owasp.org", "owasp.org", nil)
+	// Make the first entry the most recently used
+	c.get("a.owasp.org")
+	c.add("c.owasp.org", "owasp.org", nil)
+
+	if _, found := c.get("b.owasp.org"); found {
+		t.Errorf("The least recently used entry was not evicted")
+	}
+	if _, found := c.get("a.owasp.org"); !found {
+		t.Errorf("The most recently used entry was evicted")
+	}
+	if len(c.entries) != 2 || c.order.Len() != 2 {
+		t.Errorf("The cache holds %d entries, expected 2", c.order.Len())
+	}
+}
+
+func benchmarkNames() []string {
+	var names []string
+
+	for i := 0; i < 1000; i++ {
+		names = append(names, fmt.Sprintf("host%d.sub%d.example.co.uk", i, i%10))
+	}
+	return names
+}
+
+func BenchmarkRegisteredDomain(b *testing.B) {
+	names := benchmarkNames()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = RegisteredDomain(names[i%len(names)])
+	}
+}
+
+func BenchmarkEffectiveTLDPlusOne(b *testing.B) {
+	names := benchmarkNames()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = publicsuffix.EffectiveTLDPlusOne(names[i%len(names)])
+	}
+}
@@ -4,6 +4,7 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -12,14 +13,15 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
+	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -34,32 +36,113 @@ const (
 	// AcceptLang is the default HTTP Accept-Language header value used by Amass.
 	AcceptLang = "en-US,en;q=0.8"
 
-	defaultTLSConnectTimeout = 3 * time.Second
 	defaultHandshakeDeadline = 5 * time.Second
 )
 
-var (
-	defaultClient *http.Client
+// The defaults used by the HTTP client shared by the data sources.
+const (
+	DefaultClientTimeout   = 30 * time.Second
+	DefaultMaxConnsPerHost = 10
+	DefaultRetries         = 2
 )
 
-func init() {
-	jar, _ := cookiejar.New(nil)
-	defaultClient = &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-				DualStack: true,
-			}).DialContext,
-			MaxIdleConns:          200,
-			IdleConnTimeout:       90 * time.Second,
-			TLSHandshakeTimeout:   20 * time.Second,
-			ExpectContinueTimeout: 20 * time.Second,
-			TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
-		},
-		Jar: jar,
+// ErrResponseTooLarge is returned when a response body exceeds the configured size limit.
+var ErrResponseTooLarge = errors.New("The response body exceeds the size limit")
+
+// The base delay before a failed request is retried, which doubles with each attempt.
+var retryBackoff = 500 * time.Millisecond
+
+// The cookie jar is shared by the clients, so the cookies are kept across the clients.
+var cookieJar, _ = cookiejar.New(nil)
+
+// The Client used by RequestWebPage, for the requests made outside of a System.
+var defaultClient, _ = NewClient(ClientSettings{})
+
+// ClientSettings configures an HTTP Client. Zero values select the defaults.
+type ClientSettings struct {
+	// Proxy is the URL of an HTTP, HTTPS or SOCKS5 proxy (e.g. socks5://127.0.0.1:9050).
+	// The proxy environment variables are used when the URL is empty.
+	Proxy string
+
+	// MaxConnsPerHost limits the connections made to a single host.
+	MaxConnsPerHost int
+
+	// Retries is the number of times a request failing with a transient error is retried.
+	// A negative value disables the retries.
+	Retries int
+
+	// MaxResponseSize is the maximum number of bytes read from a response body. Zero disables the limit.
+	MaxResponseSize int64
+
+	// Timeout bounds each attempt, including reading the response body.
+	Timeout time.Duration
+}
+
+// Client is the HTTP client shared by the data sources. The connections are pooled across
+// the sources, and transient failures are retried after a jittered backoff.
+type Client struct {
+	client  *http.Client
+	retries int
+	maxSize int64
+}
+
+// NewClient returns a Client configured with the provided settings.
+func NewClient(settings ClientSettings) (*Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if settings.Proxy != "" {
+		u, err := url.Parse(settings.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse the proxy URL: %v", err)
+		}
+
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("The proxy scheme %q is not supported", u.Scheme)
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	maxConns := settings.MaxConnsPerHost
+	if maxConns <= 0 {
+		maxConns = DefaultMaxConnsPerHost
+	}
+
+	retries := settings.Retries
+	if retries == 0 {
+		retries = DefaultRetries
+	} else if retries < 0 {
+		retries = 0
+	}
+
+	timeout := settings.Timeout
+	if timeout <= 0 {
+		timeout = DefaultClientTimeout
 	}
+
+	return &Client{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy: proxy,
+				DialContext: (&net.Dialer{
+					Timeout:   30 * time.Second,
+					KeepAlive: 30 * time.Second,
+					DualStack: true,
+				}).DialContext,
+				MaxIdleConns:          200,
+				MaxIdleConnsPerHost:   maxConns,
+				MaxConnsPerHost:       maxConns,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   20 * time.Second,
+				ExpectContinueTimeout: 20 * time.Second,
+				TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+			},
+			Jar: cookieJar,
+		},
+		retries: retries,
+		maxSize: settings.MaxResponseSize,
+	}, nil
 }
 
 // CopyCookies copies cookies from one domain to another. Some of our data
@@ -68,14 +151,14 @@ func init() {
 func CopyCookies(src string, dest string) {
 	srcURL, _ := url.Parse(src)
 	destURL, _ := url.Parse(dest)
-	defaultClient.Jar.SetCookies(destURL, defaultClient.Jar.Cookies(srcURL))
+	cookieJar.SetCookies(destURL, cookieJar.Cookies(srcURL))
 }
 
 // CheckCookie checks if a cookie exists in the cookie jar for a given host
 func CheckCookie(urlString string, cookieName string) bool {
 	cookieURL, _ := url.Parse(urlString)
 	found := false
-	for _, cookie := range defaultClient.Jar.Cookies(cookieURL) {
+	for _, cookie := range cookieJar.Cookies(cookieURL) {
 		if cookie.Name == cookieName {
 			found = true
 			break
@@ -87,82 +170,157 @@ func CheckCookie(urlString string, cookieName string) bool {
 // RequestWebPage returns a string containing the entire response for
 // the urlstring parameter when successful.
 func RequestWebPage(urlstring string, body io.Reader, hvals map[string]string, uid, secret string) (string, error) {
+	return defaultClient.RequestWebPage(context.Background(), urlstring, body, hvals, uid, secret)
+}
+
+// RequestWebPage returns a string containing the entire response for the urlstring parameter
+// when successful. A POST request is sent when the body is not nil. Network errors and the
+// 429 and 5xx status codes are retried, while the context deadline is respected.
+func (c *Client) RequestWebPage(ctx context.Context, urlstring string, body io.Reader, hvals map[string]string, uid, secret string) (string, error) {
 	method := "GET"
+	var payload []byte
 	if body != nil {
 		method = "POST"
+		// The body is buffered so it can be sent again when the request is retried
+		var err error
+		if payload, err = ioutil.ReadAll(body); err != nil {
+			return "", err
+		}
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var page string
+		var retry bool
+
+		page, retry, err = c.request(ctx, method, urlstring, payload, hvals, uid, secret)
+		if err == nil || !retry || attempt >= c.retries {
+			return page, err
+		}
+
+		// Wait for the backoff with full jitter before the next attempt
+		backoff := retryBackoff << uint(attempt)
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return "", ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// request performs a single attempt and reports whether the failure is transient.
+func (c *Client) request(ctx context.Context, method, urlstring string, payload []byte, hvals map[string]string, uid, secret string) (string, bool, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
 	}
+
 	req, err := http.NewRequest(method, urlstring, body)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
+	req = req.WithContext(ctx)
+
 	if uid != "" && secret != "" {
 		req.SetBasicAuth(uid, secret)
 	}
 	req.Header.Set("User-Agent", UserAgent)
 	req.Header.Set("Accept", Accept)
 	req.Header.Set("Accept-Language", AcceptLang)
-	if hvals != nil {
-		for k, v := range hvals {
-			req.Header.Set(k, v)
-		}
+	for k, v := range hvals {
+		req.Header.Set(k, v)
 	}
 
-	resp, err := defaultClient.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", err
-	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", errors.New(resp.Status)
+		// Errors caused by the context are not transient
+		return "", ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Drain the body so the connection can be reused
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+
+		retry := resp.StatusCode == http.StatusTooManyRequests ||
+			(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+		return "", retry, errors.New(resp.Status)
+	}
+
+	reader := io.Reader(resp.Body)
+	if c.maxSize > 0 {
+		reader = io.LimitReader(resp.Body, c.maxSize+1)
 	}
 
-	in, err := ioutil.ReadAll(resp.Body)
-	resp.Body.Close()
-	return string(in), err
+	in, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", ctx.Err() == nil, err
+	}
+	if c.maxSize > 0 && int64(len(in)) > c.maxSize {
+		return "", false, ErrResponseTooLarge
+	}
+	return string(in), false, nil
 }
 
 // PullCertificateNames attempts to pull a cert from one or more ports on an IP.
-func PullCertificateNames(addr string, ports []int) []string {
+// The connections are made through the provided PortChecker, and STARTTLS is
+// negotiated on the SMTP, IMAP and POP3 ports.
+func PullCertificateNames(addr string, ports []int, pc *amassnet.PortChecker) []string {
 	var names []string
 
 	// Check hosts for certificates that contain subdomain names
 	for _, port := range ports {
-		cfg := &tls.Config{InsecureSkipVerify: true}
-		// Set the maximum time allowed for making the connection
-		ctx, cancel := context.WithTimeout(context.Background(), defaultTLSConnectTimeout)
-		defer cancel()
-		// Obtain the connection
-		d := net.Dialer{}
-		conn, err := d.DialContext(ctx, "tcp", addr+":"+strconv.Itoa(port))
-		if err != nil {
-			continue
-		}
-		defer conn.Close()
-
-		c := tls.Client(conn, cfg)
-		// Attempt to acquire the certificate chain
-		errChan := make(chan error, 2)
-		// This goroutine will break us out of the handshake
-		time.AfterFunc(defaultHandshakeDeadline, func() {
-			errChan <- errors.New("Handshake timeout")
-		})
-		// Be sure we do not wait too long in this attempt
-		c.SetDeadline(time.Now().Add(defaultHandshakeDeadline))
-		// The handshake is performed in the goroutine
-		go func() {
-			errChan <- c.Handshake()
-		}()
-		// The error channel returns handshake or timeout error
-		if err = <-errChan; err != nil {
-			continue
-		}
-		// Get the correct certificate in the chain
-		certChain := c.ConnectionState().PeerCertificates
-		cert := certChain[0]
-		// Create the new requests from names found within the cert
-		names = append(names, namesFromCert(cert)...)
+		names = append(names, pullCertificateNames(addr, port, pc)...)
 	}
 	return names
 }
 
+func pullCertificateNames(addr string, port int, pc *amassnet.PortChecker) []string {
+	cfg := &tls.Config{InsecureSkipVerify: true}
+	// Obtain the connection, which is made within the connect timeout of the PortChecker
+	conn, err := pc.Dial(context.Background(), addr, port)
+	if err != nil {
+		return nil
+	}
+	// The connection must be closed before the next port on the host can be checked
+	defer conn.Close()
+
+	// The mail ports only present the certificate after the STARTTLS negotiation
+	if proto, found := starttlsPorts[port]; found {
+		conn.SetDeadline(time.Now().Add(defaultHandshakeDeadline))
+		if err := startTLS(conn, proto); err != nil {
+			return nil
+		}
+	}
+
+	c := tls.Client(conn, cfg)
+	// Attempt to acquire the certificate chain
+	errChan := make(chan error, 2)
+	// This goroutine will break us out of the handshake
+	time.AfterFunc(defaultHandshakeDeadline, func() {
+		errChan <- errors.New("Handshake timeout")
+	})
+	// Be sure we do not wait too long in this attempt
+	c.SetDeadline(time.Now().Add(defaultHandshakeDeadline))
+	// The handshake is performed in the goroutine
+	go func() {
+		errChan <- c.Handshake()
+	}()
+	// The error channel returns handshake or timeout error
+	if err = <-errChan; err != nil {
+		return nil
+	}
+	// Get the correct certificate in the chain
+	certChain := c.ConnectionState().PeerCertificates
+	cert := certChain[0]
+	// Create the new requests from names found within the cert
+	return namesFromCert(cert)
+}
+
 func namesFromCert(cert *x509.Certificate) []string {
 	var cn string
 
@@ -0,0 +1,309 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+	retryBackoff = 10 * time.Millisecond
+}
+
+func TestRequestWebPageRetry(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("Attempt %d sent the body %q", atomic.LoadInt32(&attempts)+1, body)
+		}
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "success")
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(ClientSettings{Retries: 2})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	page, err := c.RequestWebPage(context.Background(), srv.URL, strings.NewReader("payload"), nil, "", "")
+	if err != nil || page != "success" {
+		t.Errorf("Expected the third attempt to succeed, got %q and %v", page, err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Errorf("Expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRequestWebPageNoRetry(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(ClientSettings{Retries: 3})
+	if _, err := c.RequestWebPage(context.Background(), srv.URL, nil, nil, "", ""); err == nil {
+		t.Errorf("The 404 response did not return an error")
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("The 404 response was retried %d times", n-1)
+	}
+}
+
+func TestRequestWebPageContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	retryBackoff = time.Second
+	defer func() { retryBackoff = 10 * time.Millisecond }()
+
+	c, _ := NewClient(ClientSettings{Retries: 5})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.RequestWebPage(ctx, srv.URL, nil, nil, "", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context deadline to be exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("The request ignored the context deadline and took %v", elapsed)
+	}
+}
+
+func TestRequestWebPageSizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("a", 100))
+	}))
+	defer srv.Close()
+
+	c, _ := NewClient(ClientSettings{MaxResponseSize: 50})
+	if _, err := c.RequestWebPage(context.Background(), srv.URL, nil, nil, "", ""); err != ErrResponseTooLarge {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+
+	c, _ = NewClient(ClientSettings{MaxResponseSize: 100})
+	if page, err := c.RequestWebPage(context.Background(), srv.URL, nil, nil, "", ""); err != nil || len(page) != 100 {
+		t.Errorf("The response within the size limit failed: %v", err)
+	}
+}
+
+func TestHTTPProxy(t *testing.T) {
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests sent through a proxy carry the absolute URL of the target
+		if r.URL.Host == "amass.test" {
+			atomic.AddInt32(&proxied, 1)
+		}
+		io.WriteString(w, "proxied")
+	}))
+	defer proxy.Close()
+
+	c, err := NewClient(ClientSettings{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	page, err := c.RequestWebPage(context.Background(), "http://amass.test/", nil, nil, "", "")
+	if err != nil || page != "proxied" {
+		t.Errorf("The request was not sent through the proxy: %q, %v", page, err)
+	}
+	if atomic.LoadInt32(&proxied) != 1 {
+		t.Errorf("The proxy did not receive the request for the target")
+	}
+}
+
+func TestSOCKS5Proxy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "tunneled")
+	}))
+	defer srv.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start the SOCKS5 listener: %v", err)
+	}
+	defer l.Close()
+
+	var connects int32
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn, &connects)
+		}
+	}()
+
+	c, err := NewClient(ClientSettings{Proxy: "socks5://" + l.Addr().String()})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	page, err := c.RequestWebPage(context.Background(), srv.URL, nil, nil, "", "")
+	if err != nil || page != "tunneled" {
+		t.Errorf("The request was not sent through the SOCKS5 proxy: %q, %v", page, err)
+	}
+	if atomic.LoadInt32(&connects) == 0 {
+		t.Errorf("The SOCKS5 proxy did not connect to the target")
+	}
+}
+
+func TestNewClientProxyScheme(t *testing.T) {
+	if _, err := NewClient(ClientSettings{Proxy: "ftp://127.0.0.1:21"}); err == nil {
+		t.Errorf("The unsupported proxy scheme was accepted")
+	}
+}
+
+// serveSOCKS5 handles a single unauthenticated CONNECT request and relays the connection.
+func serveSOCKS5(conn net.Conn, connects *int32) {
+	defer conn.Close()
+
+	// Read the greeting and select the no authentication method
+	buf := make([]byte, 262)
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 0})
+
+	// Read the CONNECT request
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil || buf[1] != 1 {
+		return
+	}
+
+	var host string
+	switch buf[3] {
+	case 1:
+		if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+			return
+		}
+		host = net.IP(buf[:4]).String()
+	case 3:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return
+		}
+		n := int(buf[0])
+		if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+			return
+		}
+		host = string(buf[:n])
+	default:
+		return
+	}
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+	port := binary.BigEndian.Uint16(buf[:2])
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer target.Close()
+	atomic.AddInt32(connects, 1)
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func TestStartTLS(t *testing.T) {
+	tests := []struct {
+		proto   string
+		replies map[string]string
+		greet   string
+		success bool
+	}{
+		{
+			proto: protoSMTP,
+			greet: "220 mail.owasp.org ESMTP\r\n",
+			replies: map[string]string{
+				"EHLO":     "250-mail.owasp.org\r\n250-PIPELINING\r\n250 STARTTLS\r\n",
+				"STARTTLS": "220 Ready to start TLS\r\n",
+			},
+			success: true,
+		},
+		{
+			proto: protoIMAP,
+			greet: "* OK IMAP4rev1 Service Ready\r\n",
+			replies: map[string]string{
+				"a001": "* CAPABILITY IMAP4rev1\r\na001 OK Begin TLS negotiation now\r\n",
+			},
+			success: true,
+		},
+		{
+			proto:   protoPOP3,
+			greet:   "+OK POP3 server ready\r\n",
+			replies: map[string]string{"STLS": "+OK Begin TLS negotiation\r\n"},
+			success: true,
+		},
+		{
+			proto: protoSMTP,
+			greet: "220 mail.owasp.org ESMTP\r\n",
+			replies: map[string]string{
+				"EHLO":     "250 mail.owasp.org\r\n",
+				"STARTTLS": "502 Command not implemented\r\n",
+			},
+			success: false,
+		},
+	}
+
+	for _, test := range tests {
+		client, server := net.Pipe()
+		client.SetDeadline(time.Now().Add(5 * time.Second))
+		go serveStartTLS(server, test.greet, test.replies)
+
+		err := startTLS(client, test.proto)
+		if test.success && err != nil {
+			t.Errorf("The %s STARTTLS negotiation failed: %v", test.proto, err)
+		} else if !test.success && err == nil {
+			t.Errorf("The refused %s STARTTLS negotiation did not return an error", test.proto)
+		}
+		client.Close()
+	}
+}
+
+func serveStartTLS(conn net.Conn, greet string, replies map[string]string) {
+	defer conn.Close()
+
+	io.WriteString(conn, greet)
+	buf := make([]byte, 512)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+
+		cmd := strings.Fields(string(buf[:n]))[0]
+		reply, found := replies[cmd]
+		if !found {
+			return
+		}
+		io.WriteString(conn, reply)
+	}
+}
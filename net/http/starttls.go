@@ -0,0 +1,110 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// The mail protocols that negotiate TLS with STARTTLS after the plaintext greeting.
+const (
+	protoSMTP = "smtp"
+	protoIMAP = "imap"
+	protoPOP3 = "pop3"
+)
+
+// The ports where the certificate is only presented once the session is upgraded with
+// STARTTLS. The implicit TLS ports, such as 465 and 993, start the handshake right away.
+var starttlsPorts = map[int]string{
+	25:  protoSMTP,
+	110: protoPOP3,
+	143: protoIMAP,
+	587: protoSMTP,
+}
+
+// The hostname announced in the SMTP EHLO command.
+const starttlsHostname = "localhost"
+
+// startTLS negotiates the upgrade of the plaintext connection, so the TLS handshake can follow.
+func startTLS(conn net.Conn, proto string) error {
+	r := bufio.NewReader(conn)
+
+	switch proto {
+	case protoSMTP:
+		if err := readSMTPReply(r, "220"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(conn, "EHLO %s\r\n", starttlsHostname); err != nil {
+			return err
+		}
+		if err := readSMTPReply(r, "250"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(conn, "STARTTLS\r\n"); err != nil {
+			return err
+		}
+		return readSMTPReply(r, "220")
+	case protoIMAP:
+		if err := readPrefixedLine(r, "* OK"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(conn, "a001 STARTTLS\r\n"); err != nil {
+			return err
+		}
+		// Untagged responses can precede the tagged completion of the command
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(line, "a001 ") {
+				if !strings.HasPrefix(line, "a001 OK") {
+					return fmt.Errorf("STARTTLS was refused: %s", strings.TrimSpace(line))
+				}
+				return nil
+			}
+		}
+	case protoPOP3:
+		if err := readPrefixedLine(r, "+OK"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(conn, "STLS\r\n"); err != nil {
+			return err
+		}
+		return readPrefixedLine(r, "+OK")
+	}
+
+	return fmt.Errorf("STARTTLS is not supported for %s", proto)
+}
+
+// readSMTPReply reads the possibly multiline SMTP reply and checks for the expected code.
+func readSMTPReply(r *bufio.Reader, code string) error {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if len(line) < 4 || line[:3] != code {
+			return fmt.Errorf("Unexpected SMTP reply: %s", strings.TrimSpace(line))
+		}
+		// The hyphen after the code marks the lines continuing the reply
+		if line[3] != '-' {
+			return nil
+		}
+	}
+}
+
+func readPrefixedLine(r *bufio.Reader, prefix string) error {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, prefix) {
+		return fmt.Errorf("Unexpected reply: %s", strings.TrimSpace(line))
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"math/big"
 	"net"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -14,11 +15,31 @@ import (
 // IPv4RE is a regular expression that will match an IPv4 address.
 const IPv4RE = "((25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)[.]){3}(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)"
 
+// IPv6RE is a regular expression that will match an IPv6 address in the full, compressed
+// and IPv4-mapped notations. The alternatives are ordered so the first match is the longest.
+const IPv6RE = "(" +
+	"([0-9a-fA-F]{1,4}:){6}" + IPv4RE + "|" +
+	"::([fF]{4}(:0{1,4})?:)?" + IPv4RE + "|" +
+	"([0-9a-fA-F]{1,4}:){1,4}:" + IPv4RE + "|" +
+	"([0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}|" +
+	"[0-9a-fA-F]{1,4}:(:[0-9a-fA-F]{1,4}){1,6}|" +
+	"([0-9a-fA-F]{1,4}:){1,2}(:[0-9a-fA-F]{1,4}){1,5}|" +
+	"([0-9a-fA-F]{1,4}:){1,3}(:[0-9a-fA-F]{1,4}){1,4}|" +
+	"([0-9a-fA-F]{1,4}:){1,4}(:[0-9a-fA-F]{1,4}){1,3}|" +
+	"([0-9a-fA-F]{1,4}:){1,5}(:[0-9a-fA-F]{1,4}){1,2}|" +
+	"([0-9a-fA-F]{1,4}:){1,6}:[0-9a-fA-F]{1,4}|" +
+	"([0-9a-fA-F]{1,4}:){1,7}:|" +
+	":(:[0-9a-fA-F]{1,4}){1,7}|" +
+	"::)"
+
+var anyIPRE = regexp.MustCompile(IPv6RE + "|" + IPv4RE)
+
 // ReservedCIDRDescription is the description used for reserved address ranges.
 const ReservedCIDRDescription = "Reserved Network Address Blocks"
 
 // ReservedCIDRs includes all the networks that are reserved for special use.
 var ReservedCIDRs = []string{
+	"0.0.0.0/8",
 	"192.168.0.0/16",
 	"172.16.0.0/12",
 	"10.0.0.0/8",
@@ -31,12 +52,21 @@ var ReservedCIDRs = []string{
 	"192.88.99.0/24",
 	"192.0.0.0/24",
 	"192.0.2.0/24",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
 	"192.94.77.0/24",
 	"192.94.78.0/24",
 	"192.52.193.0/24",
 	"192.12.109.0/24",
 	"192.31.196.0/24",
 	"192.0.0.0/29",
+	"::/128",
+	"::1/128",
+	"100::/64",
+	"2001:db8::/32",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
 }
 
 // The reserved network address ranges
@@ -60,8 +90,71 @@ func IsIPv6(ip net.IP) bool {
 	return strings.Count(ip.String(), ":") >= 2
 }
 
+// IsIPv6Address returns true when the provided string is a valid IPv6 address.
+// IPv4-mapped addresses are considered IPv4, consistent with IsIPv6.
+func IsIPv6Address(addr string) bool {
+	ip := net.ParseIP(strings.TrimSpace(addr))
+
+	return ip != nil && ip.To4() == nil
+}
+
+// FindAllIPs returns the valid IPv4 and IPv6 addresses found within the data, in the order
+// they appear. Matches that are part of longer tokens, such as hex strings, are rejected.
+func FindAllIPs(data string) []net.IP {
+	var ips []net.IP
+
+	for pos := 0; pos < len(data); {
+		loc := anyIPRE.FindStringIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+
+		start, end := pos+loc[0], pos+loc[1]
+		if !ipBoundary(data, start, end) {
+			// Try again from the next character, since an address could start within the token
+			pos = start + 1
+			continue
+		}
+
+		if ip := net.ParseIP(data[start:end]); ip != nil {
+			ips = append(ips, ip)
+		}
+		pos = end
+	}
+
+	return ips
+}
+
+// ipBoundary returns true when the match is not embedded within a longer token.
+func ipBoundary(data string, start, end int) bool {
+	if start > 0 && isAlphanumeric(data[start-1]) {
+		return false
+	}
+	// Reject the end of a longer dotted sequence, such as a version number
+	if start > 1 && data[start-1] == '.' && isAlphanumeric(data[start-2]) {
+		return false
+	}
+	if end < len(data) {
+		if isAlphanumeric(data[end]) {
+			return false
+		}
+		// Reject truncated matches, such as an address followed by additional groups
+		if c := data[end]; (c == ':' || c == '.') && end+1 < len(data) && isAlphanumeric(data[end+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// IsReservedAddress returns true and the netblock when the address falls within one of the
+// ReservedCIDRs, such as the private, loopback, link-local, CGNAT, documentation and multicast
+// ranges of IPv4 and IPv6.
 func IsReservedAddress(addr string) (bool, string) {
-	ip := net.ParseIP(addr)
+	ip := net.ParseIP(strings.TrimSpace(addr))
 	if ip == nil {
 		return false, ""
 	}
@@ -49,6 +49,97 @@ func TestIsIPv6(t *testing.T) {
 	}
 }
 
+func TestIsIPv6Address(t *testing.T) {
+	tests := []struct {
+		Address  string
+		Expected bool
+	}{
+		{"::", true},
+		{"::1", true},
+		{"2001:db8::", true},
+		{"2620:0:860:2:ffff:ffff:ffff:ffff", true},
+		{"64:ff9b::192.0.2.33", true},
+		{"::ffff:192.168.1.1", false},
+		{"192.168.1.1", false},
+		{"2001:db8:::1", false},
+		{"example.com", false},
+	}
+
+	for _, test := range tests {
+		if b := IsIPv6Address(test.Address); b != test.Expected {
+			t.Errorf("Failed on IP address %s", test.Address)
+		}
+	}
+}
+
+func TestIsReservedAddress(t *testing.T) {
+	tests := []struct {
+		Address  string
+		Expected bool
+	}{
+		{"10.1.2.3", true},
+		{"172.20.0.1", true},
+		{"192.168.100.1", true},
+		{"127.0.0.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"198.51.100.7", true},
+		{"203.0.113.7", true},
+		{"239.255.255.250", true},
+		{"::ffff:10.0.0.1", true},
+		{"::1", true},
+		{"fd12:3456:789a::1", true},
+		{"fe80::1", true},
+		{"2001:db8::1", true},
+		{"ff02::1", true},
+		{"8.8.8.8", false},
+		{"104.22.26.77", false},
+		{"2606:4700::6812:1a4d", false},
+		{"example.com", false},
+	}
+
+	for _, test := range tests {
+		if b, _ := IsReservedAddress(test.Address); b != test.Expected {
+			t.Errorf("Failed on IP address %s", test.Address)
+		}
+	}
+}
+
+func TestFindAllIPs(t *testing.T) {
+	tests := []struct {
+		Data     string
+		Expected []string
+	}{
+		{"v=spf1 ip4:192.168.1.1 ip6:2001:db8::/32 ~all", []string{"192.168.1.1", "2001:db8::"}},
+		{"loopback ::1 and unspecified ::", []string{"::1", "::"}},
+		{"2620:0:860:2:ffff:ffff:ffff:ffff", []string{"2620:0:860:2:ffff:ffff:ffff:ffff"}},
+		{"1:2:3::4:5 and fe80::1:2:3:4", []string{"1:2:3::4:5", "fe80::1:2:3:4"}},
+		{"mapped ::ffff:10.0.0.1, embedded 64:ff9b::192.0.2.33", []string{"10.0.0.1", "64:ff9b::c000:221"}},
+		{"[2001:db8::1]:443", []string{"2001:db8::1"}},
+		{"key=abcd:ef01::1234abcdzz", nil},
+		{"version 1.2.3.4.5", nil},
+		{"no addresses here", nil},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, ip := range FindAllIPs(test.Data) {
+			got = append(got, ip.String())
+		}
+
+		if len(got) != len(test.Expected) {
+			t.Errorf("FindAllIPs(%q) returned %v, expected %v", test.Data, got, test.Expected)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.Expected[i] {
+				t.Errorf("FindAllIPs(%q) returned %v, expected %v", test.Data, got, test.Expected)
+				break
+			}
+		}
+	}
+}
+
 func TestFirstLast(t *testing.T) {
 	tests := []struct {
 		CIDR          string
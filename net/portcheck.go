@@ -0,0 +1,190 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package net
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxActiveConns is the default number of concurrent connections made to the target hosts.
+	DefaultMaxActiveConns = 100
+	// DefaultConnectTimeout is the default time allowed for establishing a connection.
+	DefaultConnectTimeout = 5 * time.Second
+)
+
+// HostPort identifies a port found open on a host.
+type HostPort struct {
+	Host string
+	Port int
+}
+
+// PortChecker coordinates the TCP connections made by the active techniques. The number of
+// concurrent connections is limited across all the hosts, and the connections to a single host
+// are made one at a time, so the active techniques do not trip intrusion detection thresholds.
+type PortChecker struct {
+	dialer  *RateLimitedDialer
+	timeout time.Duration
+	slots   chan struct{}
+
+	hostsLock sync.Mutex
+	hosts     map[string]*hostLock
+}
+
+type hostLock struct {
+	lock chan struct{}
+	refs int
+}
+
+// NewPortChecker returns a PortChecker allowing maxConns concurrent connections, each made
+// through the dialer and established within the timeout. Zero values select the defaults,
+// and a nil dialer is replaced by one without a rate limit.
+func NewPortChecker(dialer *RateLimitedDialer, maxConns int, timeout time.Duration) *PortChecker {
+	if maxConns <= 0 {
+		maxConns = DefaultMaxActiveConns
+	}
+	if timeout <= 0 {
+		timeout = DefaultConnectTimeout
+	}
+	if dialer == nil {
+		dialer = NewRateLimitedDialer(0, maxConns)
+	}
+
+	return &PortChecker{
+		dialer:  dialer,
+		timeout: timeout,
+		slots:   make(chan struct{}, maxConns),
+		hosts:   make(map[string]*hostLock),
+	}
+}
+
+// Dial connects to the TCP port on the host once a connection slot is available and no other
+// connection to the host is in progress. The slot is held until the returned net.Conn is closed.
+func (pc *PortChecker) Dial(ctx context.Context, host string, port int) (net.Conn, error) {
+	release, err := pc.acquire(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	// The connections are made within the rate shared by the active techniques
+	conn, err := pc.dialer.DialTimeout(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)), pc.timeout)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return &checkedConn{Conn: conn, release: release}, nil
+}
+
+// CheckPort returns true when a connection to the TCP port on the host is successful.
+func (pc *PortChecker) CheckPort(ctx context.Context, host string, port int) bool {
+	conn, err := pc.Dial(ctx, host, port)
+	if err != nil {
+		return false
+	}
+
+	conn.Close()
+	return true
+}
+
+// CheckHosts checks each of the ports on each of the hosts and returns the open pairs,
+// sorted by host and port.
+func (pc *PortChecker) CheckHosts(ctx context.Context, hosts []string, ports []int) []HostPort {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var open []HostPort
+
+	for _, host := range hosts {
+		wg.Add(1)
+
+		go func(host string) {
+			defer wg.Done()
+
+			for _, port := range ports {
+				if ctx.Err() != nil {
+					return
+				}
+
+				if pc.CheckPort(ctx, host, port) {
+					lock.Lock()
+					open = append(open, HostPort{Host: host, Port: port})
+					lock.Unlock()
+				}
+			}
+		}(host)
+	}
+	wg.Wait()
+
+	sort.Slice(open, func(i, j int) bool {
+		if open[i].Host != open[j].Host {
+			return open[i].Host < open[j].Host
+		}
+		return open[i].Port < open[j].Port
+	})
+	return open
+}
+
+// acquire obtains the lock for the host and then a connection slot, and returns the
+// function that releases both.
+func (pc *PortChecker) acquire(ctx context.Context, host string) (func(), error) {
+	pc.hostsLock.Lock()
+	h, found := pc.hosts[host]
+	if !found {
+		h = &hostLock{lock: make(chan struct{}, 1)}
+		pc.hosts[host] = h
+	}
+	h.refs++
+	pc.hostsLock.Unlock()
+
+	releaseHost := func() {
+		pc.hostsLock.Lock()
+		defer pc.hostsLock.Unlock()
+
+		if h.refs--; h.refs == 0 {
+			delete(pc.hosts, host)
+		}
+	}
+
+	select {
+	case h.lock <- struct{}{}:
+	case <-ctx.Done():
+		releaseHost()
+		return nil, ctx.Err()
+	}
+
+	select {
+	case pc.slots <- struct{}{}:
+	case <-ctx.Done():
+		<-h.lock
+		releaseHost()
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-pc.slots
+			<-h.lock
+			releaseHost()
+		})
+	}, nil
+}
+
+// checkedConn releases the connection slot and host lock when closed.
+type checkedConn struct {
+	net.Conn
+	release func()
+}
+
+func (c *checkedConn) Close() error {
+	err := c.Conn.Close()
+
+	c.release()
+	return err
+}
@@ -0,0 +1,100 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package net
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func testListener(t *testing.T) (net.Listener, int) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start the listener: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	return ln, ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestCheckHosts(t *testing.T) {
+	ln, open := testListener(t)
+	// Obtain a port that is not listening
+	closed, port := testListener(t)
+	closed.Close()
+	defer ln.Close()
+
+	pc := NewPortChecker(nil, 10, time.Second)
+	got := pc.CheckHosts(context.Background(), []string{"127.0.0.1"}, []int{port, open})
+	if len(got) != 1 || got[0].Host != "127.0.0.1" || got[0].Port != open {
+		t.Errorf("Expected only port %d to be open, got %v", open, got)
+	}
+}
+
+func TestPortCheckerLimits(t *testing.T) {
+	ln, port := testListener(t)
+	defer ln.Close()
+
+	pc := NewPortChecker(nil, 1, time.Second)
+	conn, err := pc.Dial(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	// The only connection slot is in use, so other hosts must wait
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if pc.CheckPort(ctx, "localhost", port) {
+		t.Errorf("The connection limit was exceeded")
+	}
+
+	conn.Close()
+	if !pc.CheckPort(context.Background(), "127.0.0.1", port) {
+		t.Errorf("The connection slot was not released")
+	}
+}
+
+func TestPortCheckerHostSerialization(t *testing.T) {
+	ln, port := testListener(t)
+	defer ln.Close()
+
+	pc := NewPortChecker(nil, 10, time.Second)
+	conn, err := pc.Dial(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	// A second connection to the same host must wait for the first to close
+	done := make(chan bool)
+	go func() {
+		done <- pc.CheckPort(context.Background(), "127.0.0.1", port)
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("The connections to the host were not serialized")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	conn.Close()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Errorf("The second connection failed")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("The second connection did not proceed after the first was closed")
+	}
+}
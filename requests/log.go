@@ -0,0 +1,92 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package requests
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Levels of the LogEntry messages.
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+)
+
+var logLevels = map[string]int{
+	LogDebug: 0,
+	LogInfo:  1,
+	LogWarn:  2,
+	LogError: 3,
+}
+
+// LogEntry is the structured message published on the LogTopic.
+type LogEntry struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Service string            `json:"service,omitempty"`
+	EventID string            `json:"event_id,omitempty"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// NewLogEntry returns a LogEntry for the service with the message built from the format
+// and arguments, as done by fmt.Sprintf.
+func NewLogEntry(level, service, format string, args ...interface{}) *LogEntry {
+	return &LogEntry{
+		Time:    time.Now(),
+		Level:   level,
+		Service: service,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// With adds the named field to the entry and returns the entry.
+func (le *LogEntry) With(key string, value interface{}) *LogEntry {
+	if le.Fields == nil {
+		le.Fields = make(map[string]string)
+	}
+
+	le.Fields[key] = fmt.Sprint(value)
+	return le
+}
+
+// String returns the text encoding of the entry, which is the message preceded by the service.
+func (le *LogEntry) String() string {
+	if le.Service == "" {
+		return le.Message
+	}
+	return le.Service + ": " + le.Message
+}
+
+// JSON returns the entry encoded as a single line of JSON.
+func (le *LogEntry) JSON() string {
+	data, err := json.Marshal(le)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// ValidLogLevel returns true when the level is one of the LogEntry levels.
+func ValidLogLevel(level string) bool {
+	_, found := logLevels[strings.ToLower(level)]
+	return found
+}
+
+// LogLevelEnabled returns true when the level is at or above the minimum level. Unknown
+// levels are always enabled, so messages are not lost.
+func LogLevelEnabled(level, min string) bool {
+	l, found := logLevels[strings.ToLower(level)]
+	if !found {
+		return true
+	}
+
+	m, found := logLevels[strings.ToLower(min)]
+	return !found || l >= m
+}
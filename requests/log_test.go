@@ -0,0 +1,45 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package requests
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLogEntry(t *testing.T) {
+	entry := NewLogEntry(LogError, "Data Manager", "%s failed to insert CNAME: %v", "Cayley", "timeout").With("graph", "Cayley")
+
+	if s := entry.String(); s != "Data Manager: Cayley failed to insert CNAME: timeout" {
+		t.Errorf("Unexpected text encoding of the entry: %s", s)
+	}
+
+	var decoded LogEntry
+	if err := json.Unmarshal([]byte(entry.JSON()), &decoded); err != nil {
+		t.Fatalf("Failed to decode the JSON encoding of the entry: %v", err)
+	}
+	if decoded.Level != LogError || decoded.Service != "Data Manager" ||
+		decoded.Message != entry.Message || decoded.Fields["graph"] != "Cayley" {
+		t.Errorf("Unexpected JSON encoding of the entry: %s", entry.JSON())
+	}
+
+	tests := []struct {
+		level    string
+		min      string
+		expected bool
+	}{
+		{LogError, LogWarn, true},
+		{LogWarn, LogWarn, true},
+		{LogInfo, LogWarn, false},
+		{LogDebug, "", true},
+		{LogDebug, LogInfo, false},
+		{"unknown", LogError, true},
+	}
+
+	for _, test := range tests {
+		if got := LogLevelEnabled(test.level, test.min); got != test.expected {
+			t.Errorf("LogLevelEnabled(%q, %q) returned %t", test.level, test.min, got)
+		}
+	}
+}
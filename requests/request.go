@@ -39,11 +39,14 @@ const (
 const (
 	NameRequestTopic   = "amass:namereq"
 	NewNameTopic       = "amass:newname"
+	NameAcceptedTopic  = "amass:acceptedname"
 	AddrRequestTopic   = "amass:addrreq"
 	NewAddrTopic       = "amass:newaddr"
 	SubDiscoveredTopic = "amass:newsub"
 	ResolveNameTopic   = "amass:resolve"
 	NameResolvedTopic  = "amass:resolved"
+	NameDoneTopic      = "amass:namedone"
+	InvalidNameTopic   = "amass:invalidname"
 	ASNRequestTopic    = "amass:asnreq"
 	NewASNTopic        = "amass:newasn"
 	WhoisRequestTopic  = "amass:whoisreq"
@@ -51,24 +54,101 @@ const (
 	LogTopic           = "amass:log"
 	OutputTopic        = "amass:output"
 	SetActiveTopic     = "amass:setactive"
+	RecordSetTopic     = "amass:recordset"
 	ResolveCompleted   = "amass:resolvecomp"
 )
 
+// IsDataTopic returns true when the topic carries the discovered data, rather than the
+// housekeeping events such as the log entries and the activity of the services.
+func IsDataTopic(topic string) bool {
+	switch topic {
+	case NewNameTopic, NameAcceptedTopic, NameResolvedTopic, NewAddrTopic, NewASNTopic, NewWhoisTopic, OutputTopic:
+		return true
+	}
+	return false
+}
+
 // DNSAnswer is the type used by Amass to represent a DNS record.
 type DNSAnswer struct {
 	Name string `json:"name"`
 	Type int    `json:"type"`
 	TTL  int    `json:"TTL"`
 	Data string `json:"data"`
+
+	// Authenticated is true when the answer carried the DNSSEC authenticated-data flag
+	Authenticated bool `json:"authenticated,omitempty"`
+}
+
+// Clone returns a copy of the DNSAnswer.
+func (a *DNSAnswer) Clone() *DNSAnswer {
+	c := *a
+	return &c
+}
+
+// RecordSetChange describes a name whose DNS record set differs from the one previously observed.
+type RecordSetChange struct {
+	Name     string
+	Domain   string
+	Previous string
+	Current  string
+	Tag      string
+	Source   string
 }
 
 // DNSRequest handles data needed throughout Service processing of a DNS name.
 type DNSRequest struct {
-	Name    string
-	Domain  string
-	Records []DNSAnswer
-	Tag     string
-	Source  string
+	Name       string
+	Domain     string
+	Seed       string
+	AuthServer string
+	Records    []DNSAnswer
+	Rcodes     []int
+	Tag        string
+	Source     string
+
+	// The UUID of the enumeration event the request belongs to
+	EventID string
+
+	// The CNAME owner names that led to the request, in the order they were resolved
+	CNAMEPath []string
+
+	// The EDNS client subnet carried by the query (e.g. 198.51.100.0/24), or empty when
+	// the query did not carry a client subnet
+	ClientSubnet string
+
+	// The number of records followed from the name that entered the enumeration, which is
+	// zero for the names provided by the data sources and the user
+	Depth int
+
+	// The custom metadata key-value pairs attached to the stored records, which take
+	// precedence over the pairs provided by the configuration
+	Metadata map[string]string
+}
+
+// Clone returns a copy of the DNSRequest that does not share the slices of the receiver,
+// so the copy can be modified while other subscribers still reference the original.
+func (r *DNSRequest) Clone() *DNSRequest {
+	c := *r
+
+	if r.Records != nil {
+		c.Records = make([]DNSAnswer, len(r.Records))
+		for i := range r.Records {
+			c.Records[i] = *r.Records[i].Clone()
+		}
+	}
+	if r.Rcodes != nil {
+		c.Rcodes = append([]int(nil), r.Rcodes...)
+	}
+	if r.CNAMEPath != nil {
+		c.CNAMEPath = append([]string(nil), r.CNAMEPath...)
+	}
+	if r.Metadata != nil {
+		c.Metadata = make(map[string]string, len(r.Metadata))
+		for k, v := range r.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	return &c
 }
 
 // AddrRequest handles data needed throughout Service processing of a network address.
@@ -77,6 +157,16 @@ type AddrRequest struct {
 	Domain  string
 	Tag     string
 	Source  string
+
+	// True when the address was only mentioned in the collected data, such as TXT records,
+	// rather than resolved from the A or AAAA record of an in-scope name
+	Incidental bool
+
+	// True when the address should not trigger the reverse DNS sweep, as configured for its IP version
+	NoReverse bool
+
+	// The UUID of the enumeration event the request belongs to
+	EventID string
 }
 
 // ASNRequest handles all autonomous system information needed by Amass.
@@ -91,6 +181,9 @@ type ASNRequest struct {
 	Netblocks      stringset.Set
 	Tag            string
 	Source         string
+
+	// The UUID of the enumeration event the request belongs to
+	EventID string
 }
 
 // WhoisRequest handles data needed throughout Service processing of reverse whois.
@@ -110,6 +203,41 @@ type Output struct {
 	Addresses []AddressInfo `json:"addresses"`
 	Tag       string        `json:"tag"`
 	Source    string        `json:"source"`
+	Sources   []SourceInfo  `json:"sources,omitempty"`
+	Records   []RecordInfo  `json:"records,omitempty"`
+	Ports     []int         `json:"ports,omitempty"`
+	FirstSeen time.Time     `json:"first_seen"`
+	LastSeen  time.Time     `json:"last_seen"`
+
+	// PassiveOnly is true when the name was never confirmed by active DNS resolution
+	PassiveOnly bool `json:"passive_only,omitempty"`
+}
+
+// RecordInfo stores a DNS record found for the name in the Output type.
+type RecordInfo struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+// SourceInfo stores the attribution for a data source that reported the name in the Output type.
+type SourceInfo struct {
+	Source string `json:"source"`
+	Tag    string `json:"tag"`
+	Count  int    `json:"count"`
+}
+
+// ASNSummary is the rollup of the names discovered within an autonomous system.
+type ASNSummary struct {
+	ASN         int                `json:"asn"`
+	Description string             `json:"description"`
+	Names       int                `json:"names"`
+	Netblocks   []*NetblockSummary `json:"netblocks"`
+}
+
+// NetblockSummary is the rollup of the names discovered within a netblock.
+type NetblockSummary struct {
+	CIDR  string `json:"cidr"`
+	Names int    `json:"names"`
 }
 
 // AddressInfo stores all network addressing info for the Output type.
@@ -119,6 +247,8 @@ type AddressInfo struct {
 	CIDRStr     string     `json:"cidr"`
 	ASN         int        `json:"asn"`
 	Description string     `json:"desc"`
+	FirstSeen   time.Time  `json:"first_seen"`
+	LastSeen    time.Time  `json:"last_seen"`
 }
 
 // TrustedTag returns true when the tag parameter is of a type that should be trusted even
@@ -129,3 +259,13 @@ func TrustedTag(tag string) bool {
 	}
 	return false
 }
+
+// PassiveTag returns true when the tag parameter is of a type that identifies names without
+// sending DNS queries to the infrastructure of the target.
+func PassiveTag(tag string) bool {
+	switch tag {
+	case API, ARCHIVE, CERT, EXTERNAL, RIR, SCRAPE:
+		return true
+	}
+	return false
+}
@@ -31,3 +31,54 @@ func TestTrustedTag(t *testing.T) {
 		}
 	}
 }
+
+func TestPassiveTag(t *testing.T) {
+	tests := []struct {
+		Value    string
+		Expected bool
+	}{
+		{NONE, false},
+		{ALT, false},
+		{GUESS, false},
+		{ARCHIVE, true},
+		{API, true},
+		{AXFR, false},
+		{BRUTE, false},
+		{CERT, true},
+		{DNS, false},
+		{EXTERNAL, true},
+		{RIR, true},
+		{SCRAPE, true},
+	}
+
+	for _, test := range tests {
+		if r := PassiveTag(test.Value); r != test.Expected {
+			t.Errorf("%s returned %t instead of %t", test.Value, r, test.Expected)
+		}
+	}
+}
+
+func TestDNSRequestClone(t *testing.T) {
+	req := &DNSRequest{
+		Name:     "www.owasp.org",
+		Domain:   "owasp.org",
+		Records:  []DNSAnswer{{Name: "www.owasp.org", Type: 1, TTL: 300, Data: "192.0.2.10"}},
+		Rcodes:   []int{0},
+		Tag:      DNS,
+		Source:   "DNS",
+		Metadata: map[string]string{"ticket": "SEC-1"},
+	}
+
+	c := req.Clone()
+	c.Name = "WWW.OWASP.ORG"
+	c.Records[0].Data = "192.0.2.11"
+	c.Rcodes[0] = 2
+	c.Metadata["ticket"] = "SEC-2"
+
+	if req.Name != "www.owasp.org" || req.Records[0].Data != "192.0.2.10" || req.Rcodes[0] != 0 || req.Metadata["ticket"] != "SEC-1" {
+		t.Errorf("Modifying the clone changed the original request: %+v", req)
+	}
+	if c.Domain != req.Domain || c.Records[0].TTL != 300 || c.Tag != req.Tag || c.Source != req.Source {
+		t.Errorf("The clone did not copy the fields of the request: %+v", c)
+	}
+}
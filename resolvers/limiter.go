@@ -0,0 +1,116 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MinWildcardQPS is the lowest queries per second limit that lets a DNS wildcard test send the
+// query types for each unlikely name within the second the test waits between the names.
+var MinWildcardQPS = len(wildcardQueryTypes)
+
+// QueryLimiter bounds the total DNS queries per second sent by the resolver pool, the wildcard
+// tests and the zone walking. When the budget is used up, the callers block until their turn.
+type QueryLimiter struct {
+	// The time between the tokens of the bucket, where zero disables the rate limit
+	interval time.Duration
+
+	lock  sync.Mutex
+	next  time.Time
+	first time.Time
+	last  time.Time
+	count uint64
+
+	// The queries counted within the current second, and the highest count of any second
+	second    time.Time
+	perSecond uint64
+	peak      uint64
+}
+
+// NewQueryLimiter returns a QueryLimiter that lets at most perSec queries through each second.
+// A perSec of zero disables the rate limit, while the queries are still counted.
+func NewQueryLimiter(perSec int) *QueryLimiter {
+	var interval time.Duration
+	if perSec > 0 {
+		interval = time.Second / time.Duration(perSec)
+	}
+
+	return &QueryLimiter{interval: interval}
+}
+
+// Wait blocks until the next token of the bucket is issued to the caller, or the context expires.
+func (l *QueryLimiter) Wait(ctx context.Context) error {
+	l.lock.Lock()
+	now := time.Now()
+	at := now
+	if l.interval > 0 {
+		if l.next.After(now) {
+			at = l.next
+		}
+		l.next = at.Add(l.interval)
+	}
+	l.lock.Unlock()
+
+	if delay := at.Sub(now); delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.count == 0 {
+		l.first = at
+	}
+	l.last = at
+	l.count++
+
+	if sec := at.Truncate(time.Second); !sec.Equal(l.second) {
+		l.second = sec
+		l.perSecond = 0
+	}
+	l.perSecond++
+	if l.perSecond > l.peak {
+		l.peak = l.perSecond
+	}
+	return nil
+}
+
+// Queries returns the number of DNS queries let through the QueryLimiter.
+func (l *QueryLimiter) Queries() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.count
+}
+
+// AverageRate returns the achieved average of queries per second, measured from the first
+// to the last query.
+func (l *QueryLimiter) AverageRate() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	elapsed := l.last.Sub(l.first)
+	if l.count < 2 || elapsed <= 0 {
+		return float64(l.count)
+	}
+	return float64(l.count-1) / elapsed.Seconds()
+}
+
+// PeakRate returns the highest number of queries let through within a single second.
+func (l *QueryLimiter) PeakRate() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.peak
+}
@@ -0,0 +1,56 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueryLimiter(t *testing.T) {
+	l := NewQueryLimiter(20)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Failed to obtain a token: %v", err)
+		}
+	}
+
+	// Five queries at 20 per second require at least four intervals of 50ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("The five queries were let through in %v, exceeding the rate", elapsed)
+	}
+	if queries := l.Queries(); queries != 5 {
+		t.Errorf("Expected 5 queries to be counted, got %d", queries)
+	}
+	if rate := l.AverageRate(); rate > 21 || rate < 15 {
+		t.Errorf("Expected an average rate close to 20/sec, got %.2f", rate)
+	}
+	if peak := l.PeakRate(); peak == 0 || peak > 5 {
+		t.Errorf("Expected a peak rate of at most 5/sec, got %d", peak)
+	}
+}
+
+func TestQueryLimiterContext(t *testing.T) {
+	// The first query uses the only token of the next ten seconds
+	l := NewQueryLimiter(1)
+	l.interval = 10 * time.Second
+	l.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the wait to be cancelled by the context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("The cancelled wait took %v", elapsed)
+	}
+	if queries := l.Queries(); queries != 1 {
+		t.Errorf("Expected the cancelled query not to be counted, got %d", queries)
+	}
+}
@@ -25,8 +25,9 @@ func RemoveLastDot(name string) string {
 func queryMessage(id uint16, name string, qtype uint16) *dns.Msg {
 	m := &dns.Msg{
 		MsgHdr: dns.MsgHdr{
-			Authoritative:     false,
-			AuthenticatedData: false,
+			Authoritative: false,
+			// Requests the resolver to report whether the answer passed DNSSEC validation
+			AuthenticatedData: true,
 			CheckingDisabled:  false,
 			RecursionDesired:  true,
 			Opcode:            dns.OpcodeQuery,
@@ -120,6 +121,7 @@ func getXfrRequests(en *dns.Envelope, domain string) []*requests.DNSRequest {
 		default:
 			continue
 		}
+		record.TTL = int(a.Header().Ttl)
 
 		if r, found := reqs[record.Name]; found {
 			r.Records = append(r.Records, record)
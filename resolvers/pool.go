@@ -26,7 +26,9 @@ type ResolverPool struct {
 	Resolvers []Resolver
 	Done      chan struct{}
 	// Logger for error messages
-	Log          *log.Logger
+	Log *log.Logger
+	// Limiter bounds the queries per second sent by the pool, including the wildcard tests
+	Limiter      *QueryLimiter
 	wildcardLock sync.Mutex
 	wildcards    map[string]*wildcard
 	// Domains discovered by the SubdomainToDomain function
@@ -98,6 +100,7 @@ func NewResolverPool(res []Resolver, logger *log.Logger) *ResolverPool {
 		Resolvers:   res,
 		Done:        make(chan struct{}, 2),
 		Log:         logger,
+		Limiter:     NewQueryLimiter(0),
 		wildcards:   make(map[string]*wildcard),
 		domainCache: make(map[string]struct{}),
 	}
@@ -301,6 +304,11 @@ func (rp *ResolverPool) Resolve(ctx context.Context, name, qtype string, priorit
 			continue
 		}
 
+		// Each attempt counts toward the queries per second shared by all the DNS queries
+		if e := rp.Limiter.Wait(ctx); e != nil {
+			return []requests.DNSAnswer{}, false, &ResolveError{Err: e.Error(), Rcode: ResolverErrRcode}
+		}
+
 		count++
 		success := true
 		ans, again, err = r.Resolve(ctx, name, qtype, priority)
@@ -596,6 +596,8 @@ func (r *BaseResolver) processMessage(m *dns.Msg) {
 			Type: int(req.Qtype),
 			TTL:  0,
 			Data: strings.TrimSpace(a),
+			// The resolver only sets the flag when the records passed DNSSEC validation
+			Authenticated: m.AuthenticatedData,
 		})
 	}
 
@@ -7,6 +7,8 @@ import (
 	"flag"
 	"os"
 	"testing"
+
+	amassnet "github.com/OWASP/Amass/v3/net"
 )
 
 const TestDomain string = "owasp-amass.com"
@@ -41,7 +43,7 @@ func TestZoneTransfer(t *testing.T) {
 		{"vpn.axfr.owasp-amass.com"},
 		{"youll-never-find-this.axfr.owasp-amass.com"},
 	}
-	a, err := ZoneTransfer(TestDomain, TestDomain, "ns1.owasp-amass.com")
+	a, err := ZoneTransfer(TestDomain, TestDomain, "ns1.owasp-amass.com", amassnet.NewPortChecker(nil, 0, 0), NewQueryLimiter(0))
 	if err != nil {
 		t.Errorf("Error in creating ZoneTransfer: %v", err)
 	}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -51,8 +52,8 @@ func (rp *ResolverPool) GetWildcardType(ctx context.Context, req *requests.DNSRe
 }
 
 func (rp *ResolverPool) hasWildcard(ctx context.Context, req *requests.DNSRequest) int {
-	req.Name = strings.ToLower(strings.Trim(req.Name, "."))
-	req.Domain = strings.ToLower(strings.Trim(req.Domain, "."))
+	req.Name = amassdns.Canonical(req.Name)
+	req.Domain = amassdns.Canonical(req.Domain)
 
 	base := len(strings.Split(req.Domain, "."))
 	labels := strings.Split(req.Name, ".")
@@ -6,26 +6,25 @@ package resolvers
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"strings"
 	"time"
 
+	amassnet "github.com/OWASP/Amass/v3/net"
 	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/miekg/dns"
 )
 
 // ZoneTransfer attempts a DNS zone transfer using the server identified in the parameters.
-// The returned slice contains all the records discovered from the zone transfer.
-func ZoneTransfer(sub, domain, server string) ([]*requests.DNSRequest, error) {
+// The returned slice contains all the records discovered from the zone transfer, the connection
+// is made through the PortChecker, and the query counts toward the rate of the limiter.
+func ZoneTransfer(sub, domain, server string, pc *amassnet.PortChecker, limiter *QueryLimiter) ([]*requests.DNSRequest, error) {
 	var results []*requests.DNSRequest
 
-	// Set the maximum time allowed for making the connection
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-	defer cancel()
-
-	d := net.Dialer{}
-	conn, err := d.DialContext(ctx, "tcp", server+":53")
+	// The connection is made through the PortChecker shared by the active techniques
+	conn, err := pc.Dial(context.Background(), server, 53)
 	if err != nil {
 		return results, fmt.Errorf("Zone xfr error: Failed to obtain TCP connection to %s: %v", server+":53", err)
 	}
@@ -39,6 +38,7 @@ func ZoneTransfer(sub, domain, server string) ([]*requests.DNSRequest, error) {
 	m := &dns.Msg{}
 	m.SetAxfr(dns.Fqdn(sub))
 
+	limiter.Wait(context.Background())
 	in, err := xfr.In(m, "")
 	if err != nil {
 		return results, fmt.Errorf("DNS zone transfer error: %s: %v", server+":53", err)
@@ -57,8 +57,30 @@ func ZoneTransfer(sub, domain, server string) ([]*requests.DNSRequest, error) {
 	return results, nil
 }
 
-// NsecTraversal attempts to retrieve a DNS zone using NSEC-walking.
-func NsecTraversal(domain, server string) ([]*requests.DNSRequest, error) {
+// ParseZoneFile reads the records of a DNS zone file, as provided by the $ORIGIN and $TTL
+// directives. The origin is used for the relative names appearing before any $ORIGIN directive.
+// The returned slice contains a request for each name owning records of the handled types.
+func ParseZoneFile(r io.Reader, origin, filename string) ([]*requests.DNSRequest, error) {
+	en := new(dns.Envelope)
+	zp := dns.NewZoneParser(r, origin, filename)
+
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		en.RR = append(en.RR, rr)
+	}
+	if err := zp.Err(); err != nil {
+		return nil, fmt.Errorf("Zone file error: %v", err)
+	}
+
+	reqs := getXfrRequests(en, "")
+	for _, req := range reqs {
+		req.Source = "DNS Zone File"
+	}
+	return reqs, nil
+}
+
+// NsecTraversal attempts to retrieve a DNS zone using NSEC-walking, where the queries are sent
+// within the rate of the limiter.
+func NsecTraversal(domain, server string, limiter *QueryLimiter) ([]*requests.DNSRequest, error) {
 	var results []*requests.DNSRequest
 
 	d := &net.Dialer{}
@@ -78,6 +100,7 @@ loop:
 			id := dns.Id()
 			msg := walkMsg(id, attempt, dns.TypeA)
 
+			limiter.Wait(context.Background())
 			co.SetWriteDeadline(time.Now().Add(2 * time.Second))
 			if err := co.WriteMsg(msg); err != nil {
 				continue
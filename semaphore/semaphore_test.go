@@ -4,6 +4,7 @@
 package semaphore
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -58,3 +59,62 @@ func TestTimedSemaphore(t *testing.T) {
 	sem.Stop()
 	time.Sleep(time.Second)
 }
+
+func TestWeightedSemaphore(t *testing.T) {
+	sem := NewWeightedSemaphore(10)
+
+	if err := sem.Acquire(context.Background(), 8); err != nil {
+		t.Fatalf("Failed to acquire the semaphore when it should be available: %v", err)
+	}
+	if sem.TryAcquire(3) {
+		t.Errorf("Acquired the semaphore when it should not be available")
+	}
+	if !sem.TryAcquire(2) {
+		t.Errorf("Failed to acquire the semaphore when it should be available")
+	}
+	if l := sem.Len(); l != 10 {
+		t.Errorf("Expected 10 resource counts to be held, got %d", l)
+	}
+
+	// The acquisition blocks until the context expires
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(ctx, 1); err == nil {
+		t.Errorf("Acquired the semaphore when it should not be available")
+	}
+	if w := sem.Waiters(); w != 0 {
+		t.Errorf("Expected the expired acquisition to stop waiting, got %d waiters", w)
+	}
+
+	// The large acquisition is served before the small one that arrived later
+	order := make(chan int, 2)
+	go func() {
+		if err := sem.Acquire(context.Background(), 5); err == nil {
+			order <- 5
+		}
+	}()
+	for sem.Waiters() != 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	go func() {
+		if err := sem.Acquire(context.Background(), 1); err == nil {
+			order <- 1
+		}
+	}()
+	for sem.Waiters() != 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sem.Release(5)
+	if first := <-order; first != 5 {
+		t.Errorf("Expected the waiting acquisitions to be served in order, got %d first", first)
+	}
+	sem.Release(1)
+	if second := <-order; second != 1 {
+		t.Errorf("Expected the small acquisition to be served next, got %d", second)
+	}
+
+	if err := sem.Acquire(context.Background(), 11); err == nil {
+		t.Errorf("Acquired more resource counts than the size of the semaphore")
+	}
+}
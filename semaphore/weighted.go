@@ -0,0 +1,137 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package semaphore
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// WeightedSemaphore implements a counting semaphore where each acquisition can obtain a
+// different number of resource counts, and blocked acquisitions honor the context.
+// The waiting acquisitions are served in order, so large requests are not starved by small ones.
+type WeightedSemaphore struct {
+	sync.Mutex
+	size    int
+	cur     int
+	waiters list.List
+}
+
+type waiter struct {
+	num   int
+	ready chan struct{}
+}
+
+// NewWeightedSemaphore returns a WeightedSemaphore initialized to max resource counts.
+func NewWeightedSemaphore(max int) *WeightedSemaphore {
+	return &WeightedSemaphore{size: max}
+}
+
+// Acquire blocks until num resource counts have been obtained or the context expires.
+// On failure, the context error is returned and no resource counts are held.
+func (w *WeightedSemaphore) Acquire(ctx context.Context, num int) error {
+	if num > w.size {
+		return fmt.Errorf("Acquire: %d resource counts exceed the size %d of the semaphore", num, w.size)
+	}
+
+	w.Lock()
+	if w.cur+num <= w.size && w.waiters.Len() == 0 {
+		w.cur += num
+		w.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	elem := w.waiters.PushBack(waiter{num: num, ready: ready})
+	w.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		w.Lock()
+		select {
+		case <-ready:
+			// The counts were obtained right as the context expired, so give them back
+			w.cur -= num
+			w.notifyWaiters()
+		default:
+			isFront := w.waiters.Front() == elem
+			w.waiters.Remove(elem)
+			// The waiters behind the front may now fit
+			if isFront && w.size > w.cur {
+				w.notifyWaiters()
+			}
+		}
+		w.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire attempts to obtain num resource counts without blocking.
+// The method returns true when successful in acquiring the resource counts.
+func (w *WeightedSemaphore) TryAcquire(num int) bool {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.cur+num <= w.size && w.waiters.Len() == 0 {
+		w.cur += num
+		return true
+	}
+	return false
+}
+
+// Release causes num resource counts to be released.
+func (w *WeightedSemaphore) Release(num int) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.cur -= num
+	if w.cur < 0 {
+		panic("semaphore: released more resource counts than held")
+	}
+	w.notifyWaiters()
+}
+
+// Len returns the number of resource counts currently held.
+func (w *WeightedSemaphore) Len() int {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.cur
+}
+
+// Waiters returns the number of acquisitions blocked waiting for resource counts.
+func (w *WeightedSemaphore) Waiters() int {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.waiters.Len()
+}
+
+// Size returns the maximum number of resource counts of the semaphore.
+func (w *WeightedSemaphore) Size() int {
+	return w.size
+}
+
+func (w *WeightedSemaphore) notifyWaiters() {
+	for {
+		next := w.waiters.Front()
+		if next == nil {
+			break
+		}
+
+		wt := next.Value.(waiter)
+		if w.cur+wt.num > w.size {
+			// The waiters are served in order
+			break
+		}
+
+		w.cur += wt.num
+		w.waiters.Remove(next)
+		close(wt.ready)
+	}
+}
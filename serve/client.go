@@ -0,0 +1,121 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package serve
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Client submits and follows enumeration jobs on an Amass daemon.
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// Dial connects to the daemon at the address. TLS is used when the configuration is provided,
+// and the token is sent with each call when not empty.
+func Dial(addr string, tlsConfig *tls.Config, token string) (*Client, error) {
+	opts := []grpc.DialOption{
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	}
+
+	if tlsConfig != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(&tokenCredentials{
+			token:  token,
+			secure: tlsConfig != nil,
+		}))
+	}
+
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Close terminates the connection with the daemon.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// SubmitEnumeration starts an enumeration job and returns the job ID.
+func (c *Client) SubmitEnumeration(ctx context.Context, req *SubmitRequest) (string, error) {
+	var resp SubmitResponse
+
+	if err := c.conn.Invoke(ctx, fullMethod("SubmitEnumeration"), req, &resp); err != nil {
+		return "", err
+	}
+	return resp.JobID, nil
+}
+
+// GetStatus returns the state of the job.
+func (c *Client) GetStatus(ctx context.Context, id string) (*JobStatus, error) {
+	var js JobStatus
+
+	if err := c.conn.Invoke(ctx, fullMethod("GetStatus"), &JobRequest{JobID: id}, &js); err != nil {
+		return nil, err
+	}
+	return &js, nil
+}
+
+// Cancel stops the enumeration of the job.
+func (c *Client) Cancel(ctx context.Context, id string) (*JobStatus, error) {
+	var js JobStatus
+
+	if err := c.conn.Invoke(ctx, fullMethod("Cancel"), &JobRequest{JobID: id}, &js); err != nil {
+		return nil, err
+	}
+	return &js, nil
+}
+
+// StreamResults calls the function for each finding of the job starting at the offset, until
+// the job ends or the context expires.
+func (c *Client) StreamResults(ctx context.Context, id string, offset int64, f func(*Result)) error {
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], fullMethod("StreamResults"))
+	if err != nil {
+		return err
+	}
+
+	if err := stream.SendMsg(&StreamRequest{JobID: id, Offset: offset}); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		r := new(Result)
+		if err := stream.RecvMsg(r); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		f(r)
+	}
+}
+
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+// GetRequestMetadata implements the credentials.PerRPCCredentials interface.
+func (t *tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity implements the credentials.PerRPCCredentials interface.
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return t.secure
+}
@@ -0,0 +1,450 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package serve
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/enum"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+)
+
+// The states of an enumeration job.
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobCancelled = "cancelled"
+	JobFailed    = "failed"
+)
+
+const (
+	// DefaultMaxResults is the number of findings buffered for each job.
+	DefaultMaxResults = 100000
+
+	// DefaultJobRetention is how long a job is kept after it ends, so the remaining
+	// findings can still be streamed.
+	DefaultJobRetention = time.Hour
+)
+
+// Errors returned by the JobManager.
+var (
+	ErrJobNotFound      = errors.New("The job was not found")
+	ErrTooManyJobs      = errors.New("The maximum number of running jobs has been reached")
+	ErrDraining         = errors.New("The daemon is shutting down and no longer accepts jobs")
+	ErrActiveNotAllowed = errors.New("Active techniques are not allowed by the daemon")
+)
+
+// JobStatus is a snapshot of the state of an enumeration job.
+type JobStatus struct {
+	ID       string    `json:"job_id"`
+	State    string    `json:"state"`
+	Domains  []string  `json:"domains"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+
+	// The number of findings produced by the enumeration
+	Names int64 `json:"names"`
+
+	// The number of findings still buffered, and the number of the oldest findings dropped
+	// after the buffer was full
+	Buffered int   `json:"buffered"`
+	Dropped  int64 `json:"dropped"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Result is a finding of a job, along with its position within all the findings of the job.
+// Streaming can resume from the offset following the last result received.
+type Result struct {
+	Offset int64            `json:"offset"`
+	Time   time.Time        `json:"time"`
+	Output *requests.Output `json:"output"`
+}
+
+type job struct {
+	sync.Mutex
+	id       string
+	cfg      *config.Config
+	enum     *enum.Enumeration
+	state    string
+	err      error
+	started  time.Time
+	finished time.Time
+	cancel   bool
+
+	// The buffered findings, where results[0] is at offset first
+	results []*Result
+	first   int64
+	dropped int64
+
+	// Closed and replaced each time findings are added or the job ends
+	notify chan struct{}
+}
+
+// JobManager runs the enumeration jobs submitted to the daemon. The jobs share the
+// resolver pool, data sources and graph databases of the System, while each job is
+// isolated by the event UUID of its configuration.
+type JobManager struct {
+	sync.Mutex
+	sys         services.System
+	maxJobs     int
+	maxResults  int
+	retention   time.Duration
+	allowActive bool
+	draining    bool
+	jobs        map[string]*job
+
+	// Closed and replaced each time a job ends
+	ended chan struct{}
+}
+
+// NewJobManager returns a JobManager that runs at most maxJobs jobs at a time and buffers
+// up to maxResults findings per job. A value of zero removes the limit on the jobs.
+func NewJobManager(sys services.System, maxJobs, maxResults int) *JobManager {
+	if maxResults <= 0 {
+		maxResults = DefaultMaxResults
+	}
+
+	return &JobManager{
+		sys:        sys,
+		maxJobs:    maxJobs,
+		maxResults: maxResults,
+		retention:  DefaultJobRetention,
+		jobs:       make(map[string]*job),
+		ended:      make(chan struct{}),
+	}
+}
+
+// AllowActive determines if jobs can enable the active techniques, such as zone transfers
+// and certificate grabbing.
+func (m *JobManager) AllowActive(allow bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.allowActive = allow
+}
+
+// Submit starts an enumeration using the configuration, and returns the job ID, which is
+// the event UUID of the enumeration.
+func (m *JobManager) Submit(cfg *config.Config) (string, error) {
+	if len(cfg.Domains()) == 0 {
+		return "", errors.New("No root domain names were provided")
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if m.draining {
+		return "", ErrDraining
+	}
+	if cfg.Active && !m.allowActive {
+		return "", ErrActiveNotAllowed
+	}
+
+	m.prune()
+	if m.maxJobs > 0 && m.running() >= m.maxJobs {
+		return "", ErrTooManyJobs
+	}
+
+	id := cfg.UUID.String()
+	if _, found := m.jobs[id]; found {
+		return "", errors.New("A job with the same event UUID already exists")
+	}
+
+	e := enum.NewEnumeration(m.sys)
+	if e == nil {
+		return "", errors.New("The system did not provide the Data Manager service")
+	}
+	e.Config = cfg
+
+	j := &job{
+		id:      id,
+		cfg:     cfg,
+		enum:    e,
+		state:   JobRunning,
+		started: time.Now(),
+		notify:  make(chan struct{}),
+	}
+	m.jobs[id] = j
+
+	go m.run(j)
+	return id, nil
+}
+
+// newConfig builds the configuration of a job from the submitted request.
+func (m *JobManager) newConfig(req *SubmitRequest) (*config.Config, error) {
+	cfg := config.NewConfig()
+	if req.Config != "" {
+		if err := cfg.LoadSettingsData([]byte(req.Config)); err != nil {
+			return nil, err
+		}
+	}
+	cfg.AddDomains(req.Domains)
+	// The findings are sent to the clients, so the log messages go to the daemon
+	cfg.Log = m.sys.Config().Log
+	return cfg, nil
+}
+
+func (m *JobManager) run(j *job) {
+	done := make(chan error, 1)
+	go func() {
+		done <- j.enum.Start(context.Background())
+	}()
+
+	// The findings are buffered regardless of the clients connected
+	for out := range j.enum.Results() {
+		j.add(out, m.maxResults)
+	}
+
+	err := <-done
+	defer m.jobEnded()
+
+	j.Lock()
+	defer j.Unlock()
+
+	j.finished = time.Now()
+	switch {
+	case err != nil:
+		j.state = JobFailed
+		j.err = err
+	case j.cancel:
+		j.state = JobCancelled
+	default:
+		j.state = JobCompleted
+	}
+	j.broadcast()
+}
+
+// jobEnded wakes up the callers waiting for the running jobs to end.
+func (m *JobManager) jobEnded() {
+	m.Lock()
+	defer m.Unlock()
+
+	close(m.ended)
+	m.ended = make(chan struct{})
+}
+
+func (j *job) add(out *requests.Output, max int) {
+	j.Lock()
+	defer j.Unlock()
+
+	j.results = append(j.results, &Result{
+		Offset: j.first + int64(len(j.results)),
+		Time:   time.Now(),
+		Output: out,
+	})
+	if len(j.results) > max {
+		// The oldest findings are dropped, since clients most likely received them already
+		j.results[0] = nil
+		j.results = j.results[1:]
+		j.first++
+		j.dropped++
+	}
+	j.broadcast()
+}
+
+// broadcast wakes up the clients streaming the findings. The job must be locked.
+func (j *job) broadcast() {
+	close(j.notify)
+	j.notify = make(chan struct{})
+}
+
+func (j *job) status() *JobStatus {
+	j.Lock()
+	defer j.Unlock()
+
+	s := &JobStatus{
+		ID:       j.id,
+		State:    j.state,
+		Domains:  j.cfg.Domains(),
+		Started:  j.started,
+		Finished: j.finished,
+		Names:    j.first + int64(len(j.results)),
+		Buffered: len(j.results),
+		Dropped:  j.dropped,
+	}
+	if j.err != nil {
+		s.Error = j.err.Error()
+	}
+	return s
+}
+
+// Status returns the state of the job.
+func (m *JobManager) Status(id string) (*JobStatus, error) {
+	j := m.job(id)
+	if j == nil {
+		return nil, ErrJobNotFound
+	}
+
+	return j.status(), nil
+}
+
+// Cancel stops the enumeration of the job, while the findings buffered remain available.
+func (m *JobManager) Cancel(id string) (*JobStatus, error) {
+	j := m.job(id)
+	if j == nil {
+		return nil, ErrJobNotFound
+	}
+
+	j.Lock()
+	if j.state == JobRunning {
+		j.cancel = true
+	}
+	j.Unlock()
+
+	j.enum.Stop()
+	return j.status(), nil
+}
+
+// Stream sends the findings of the job, starting at the offset, until the job ends or the
+// context expires. When the findings at the offset have already been dropped, streaming
+// starts at the oldest finding still buffered.
+func (m *JobManager) Stream(ctx context.Context, id string, offset int64, send func(*Result) error) error {
+	j := m.job(id)
+	if j == nil {
+		return ErrJobNotFound
+	}
+
+	for {
+		j.Lock()
+		if offset < j.first {
+			offset = j.first
+		}
+
+		var batch []*Result
+		if idx := offset - j.first; idx < int64(len(j.results)) {
+			batch = append(batch, j.results[idx:]...)
+		}
+		ended := j.state != JobRunning
+		wait := j.notify
+		j.Unlock()
+
+		for _, r := range batch {
+			if err := send(r); err != nil {
+				return err
+			}
+			offset = r.Offset + 1
+		}
+		if ended {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Page returns up to limit findings of the job starting at the offset, which were buffered
+// after the since time when it is not zero. The offset to request the following page is
+// returned, along with true when the job has ended and no findings follow the page.
+func (m *JobManager) Page(id string, offset int64, limit int, since time.Time) ([]*Result, int64, bool, error) {
+	j := m.job(id)
+	if j == nil {
+		return nil, offset, false, ErrJobNotFound
+	}
+
+	j.Lock()
+	defer j.Unlock()
+
+	if offset < j.first {
+		offset = j.first
+	}
+
+	var page []*Result
+	next := offset
+	for idx := offset - j.first; idx < int64(len(j.results)); idx++ {
+		if limit > 0 && len(page) >= limit {
+			break
+		}
+
+		r := j.results[idx]
+		next = r.Offset + 1
+		if since.IsZero() || r.Time.After(since) {
+			page = append(page, r)
+		}
+	}
+
+	done := j.state != JobRunning && next-j.first >= int64(len(j.results))
+	return page, next, done, nil
+}
+
+// Drain refuses new jobs and waits for the running jobs to end, until the context expires.
+func (m *JobManager) Drain(ctx context.Context) error {
+	m.Lock()
+	m.draining = true
+	m.Unlock()
+
+	for {
+		m.Lock()
+		running := m.running()
+		wait := m.ended
+		m.Unlock()
+
+		if running == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// Stop cancels all the running jobs.
+func (m *JobManager) Stop() {
+	m.Lock()
+	var ids []string
+	for id := range m.jobs {
+		ids = append(ids, id)
+	}
+	m.Unlock()
+
+	for _, id := range ids {
+		m.Cancel(id)
+	}
+}
+
+func (m *JobManager) job(id string) *job {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.jobs[id]
+}
+
+// running returns the number of jobs that have not ended. The manager must be locked.
+func (m *JobManager) running() int {
+	var num int
+
+	for _, j := range m.jobs {
+		j.Lock()
+		if j.state == JobRunning {
+			num++
+		}
+		j.Unlock()
+	}
+	return num
+}
+
+// prune removes the jobs that ended before the retention period. The manager must be locked.
+func (m *JobManager) prune() {
+	for id, j := range m.jobs {
+		j.Lock()
+		expired := j.state != JobRunning && time.Since(j.finished) > m.retention
+		j.Unlock()
+
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
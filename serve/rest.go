@@ -0,0 +1,213 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package serve
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// The number of findings returned by a results request without a limit
+	defaultPageSize = 1000
+
+	// The largest configuration payload accepted when submitting a job
+	maxSubmitSize = 1 << 20
+)
+
+// ResultsPage is the reply of the REST API results endpoint.
+type ResultsPage struct {
+	Results []*Result `json:"results"`
+
+	// The offset to request the following page
+	NextOffset int64 `json:"next_offset"`
+
+	// True when the job has ended and no findings follow this page
+	Done bool `json:"done"`
+}
+
+type restError struct {
+	Error string `json:"error"`
+}
+
+// restHandler serves the HTTP+JSON API for the JobManager.
+type restHandler struct {
+	jobs  *JobManager
+	token string
+}
+
+// NewRESTHandler returns the http.Handler of the REST API, which provides the following endpoints:
+//
+//	POST   /enumerations              Submit a job, using a SubmitRequest as the body
+//	GET    /enumerations/{id}         Return the JobStatus
+//	GET    /enumerations/{id}/results Return a ResultsPage, using the offset, limit and since parameters
+//	DELETE /enumerations/{id}         Cancel the job
+//
+// When the token is not empty, the clients must send it in the Authorization header as "Bearer <token>".
+func NewRESTHandler(jobs *JobManager, token string) http.Handler {
+	return &restHandler{
+		jobs:  jobs,
+		token: token,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *restHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSONError(w, http.StatusUnauthorized, "The authorization token is not valid")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	if parts[0] != "enumerations" || len(parts) > 3 {
+		writeJSONError(w, http.StatusNotFound, "The endpoint was not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		h.submit(w, r)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		h.status(w, parts[1])
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		h.cancel(w, parts[1])
+	case len(parts) == 3 && parts[2] == "results" && r.Method == http.MethodGet:
+		h.results(w, r, parts[1])
+	case len(parts) == 3 && parts[2] != "results":
+		writeJSONError(w, http.StatusNotFound, "The endpoint was not found")
+	default:
+		writeJSONError(w, http.StatusMethodNotAllowed, fmt.Sprintf("The %s method is not allowed", r.Method))
+	}
+}
+
+func (h *restHandler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		return true
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+func (h *restHandler) submit(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxSubmitSize))
+	if err != nil {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+
+	var req SubmitRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Failed to parse the request: %v", err))
+		return
+	}
+
+	cfg, err := h.jobs.newConfig(&req)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.jobs.Submit(cfg)
+	switch err {
+	case nil:
+		w.Header().Set("Location", "/enumerations/"+id)
+		writeJSON(w, http.StatusCreated, &SubmitResponse{JobID: id})
+	case ErrTooManyJobs:
+		writeJSONError(w, http.StatusTooManyRequests, err.Error())
+	case ErrDraining:
+		writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+	case ErrActiveNotAllowed:
+		writeJSONError(w, http.StatusForbidden, err.Error())
+	default:
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+func (h *restHandler) status(w http.ResponseWriter, id string) {
+	js, err := h.jobs.Status(id)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, js)
+}
+
+func (h *restHandler) cancel(w http.ResponseWriter, id string) {
+	js, err := h.jobs.Cancel(id)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, js)
+}
+
+func (h *restHandler) results(w http.ResponseWriter, r *http.Request, id string) {
+	q := r.URL.Query()
+
+	var offset int64
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeJSONError(w, http.StatusBadRequest, "The offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	limit := defaultPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "The limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	var since time.Time
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "The since parameter must be in the RFC 3339 format")
+			return
+		}
+		since = t
+	}
+
+	results, next, done, err := h.jobs.Page(id, offset, limit, since)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if results == nil {
+		results = []*Result{}
+	}
+
+	writeJSON(w, http.StatusOK, &ResultsPage{
+		Results:    results,
+		NextOffset: next,
+		Done:       done,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, &restError{Error: msg})
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func restRequest(t *testing.T, h http.Handler, method, path, body string, v interface{}) int {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer secret")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if v != nil && rec.Code < 300 {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("Failed to parse the reply of %s %s: %v", method, path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestRESTHandler(t *testing.T) {
+	sys := newTestSystem(t, "www."+domainTest, "mail."+domainTest, "ftp."+domainTest)
+	defer sys.srcs[0].Stop()
+
+	jobs := NewJobManager(sys, 0, 0)
+	defer jobs.Stop()
+	h := NewRESTHandler(jobs, "secret")
+
+	// Requests without the token must be rejected
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/enumerations/unknown", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the request without the token to be rejected, got %d", rec.Code)
+	}
+
+	// Active techniques require the daemon to allow them
+	active := `{"config": "mode = active\n", "domains": ["owasp.org"]}`
+	if code := restRequest(t, h, http.MethodPost, "/enumerations", active, nil); code != http.StatusForbidden {
+		t.Errorf("Expected the job enabling active techniques to be refused, got %d", code)
+	}
+
+	var sub SubmitResponse
+	passive := `{"config": "mode = passive\n", "domains": ["owasp.org"]}`
+	if code := restRequest(t, h, http.MethodPost, "/enumerations", passive, &sub); code != http.StatusCreated {
+		t.Fatalf("Failed to submit the job: %d", code)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		var js JobStatus
+		if restRequest(t, h, http.MethodGet, "/enumerations/"+sub.JobID, "", &js); js.Names == 3 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// The findings are returned in pages
+	var first ResultsPage
+	if code := restRequest(t, h, http.MethodGet, "/enumerations/"+sub.JobID+"/results?limit=2", "", &first); code != http.StatusOK {
+		t.Fatalf("Failed to request the results: %d", code)
+	}
+	if len(first.Results) != 2 || first.NextOffset != 2 || first.Done {
+		t.Errorf("Unexpected first page of results: %+v", first)
+	}
+
+	var second ResultsPage
+	restRequest(t, h, http.MethodGet, "/enumerations/"+sub.JobID+"/results?offset=2&limit=2", "", &second)
+	if len(second.Results) != 1 || second.Results[0].Offset != 2 || second.NextOffset != 3 {
+		t.Errorf("Unexpected second page of results: %+v", second)
+	}
+
+	// The findings buffered before the since time are filtered out
+	var empty ResultsPage
+	since := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	restRequest(t, h, http.MethodGet, "/enumerations/"+sub.JobID+"/results?since="+since, "", &empty)
+	if len(empty.Results) != 0 || empty.NextOffset != 3 {
+		t.Errorf("Expected all the findings to be filtered by the since time, got %+v", empty)
+	}
+
+	var js JobStatus
+	if code := restRequest(t, h, http.MethodDelete, "/enumerations/"+sub.JobID, "", &js); code != http.StatusOK {
+		t.Errorf("Failed to cancel the job: %d", code)
+	}
+	if code := restRequest(t, h, http.MethodGet, "/enumerations/unknown", "", nil); code != http.StatusNotFound {
+		t.Errorf("Expected the unknown job to be reported, got %d", code)
+	}
+}
+
+func TestJobManagerDrain(t *testing.T) {
+	sys := newTestSystem(t, "www."+domainTest)
+	defer sys.srcs[0].Stop()
+
+	m := NewJobManager(sys, 0, 0)
+	id, err := m.Submit(newTestJobConfig(domainTest))
+	if err != nil {
+		t.Fatalf("Failed to submit the job: %v", err)
+	}
+
+	// The running job must hold up the drain until it ends
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if err := m.Drain(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the drain to wait for the running job, got %v", err)
+	}
+	if _, err := m.Submit(newTestJobConfig(domainTest)); err != ErrDraining {
+		t.Errorf("Expected the job to be refused while draining, got %v", err)
+	}
+
+	m.Cancel(id)
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel2()
+	if err := m.Drain(ctx2); err != nil {
+		t.Errorf("The drain did not end after the job was cancelled: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package serve
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/graph/db"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const domainTest = "owasp.org"
+
+// testSystem is a System with a single stubbed data source and no DNS resolution.
+type testSystem struct {
+	cfg    *config.Config
+	graphs []*graph.Graph
+	core   []services.Service
+	srcs   []services.Service
+}
+
+func (ts *testSystem) Config() *config.Config                 { return ts.cfg }
+func (ts *testSystem) Pool() resolvers.Resolver               { return nil }
+func (ts *testSystem) QueryLimiter() *resolvers.QueryLimiter  { return nil }
+func (ts *testSystem) Dialer() *amassnet.RateLimitedDialer    { return nil }
+func (ts *testSystem) PortChecker() *amassnet.PortChecker     { return nil }
+func (ts *testSystem) HTTPClient() *amasshttp.Client          { return nil }
+func (ts *testSystem) Suffixes() *amassdns.SuffixList         { return ts.cfg.Suffixes() }
+func (ts *testSystem) AddSource(srv services.Service) error   { return nil }
+func (ts *testSystem) AddAndStart(srv services.Service) error { return nil }
+func (ts *testSystem) DataSources() []services.Service        { return ts.srcs }
+func (ts *testSystem) CoreServices() []services.Service       { return ts.core }
+func (ts *testSystem) GraphDatabases() []*graph.Graph         { return ts.graphs }
+func (ts *testSystem) Shutdown() error                        { return nil }
+
+// stubSource is a data source that returns the same names for every domain.
+type stubSource struct {
+	services.BaseService
+
+	names []string
+}
+
+// OnDNSRequest implements the Service interface.
+func (s *stubSource) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
+	for _, name := range s.names {
+		services.PublishName(ctx, &requests.DNSRequest{
+			Name:   name,
+			Domain: req.Domain,
+			Tag:    requests.API,
+			Source: s.String(),
+		})
+	}
+}
+
+func newTestSystem(t *testing.T, names ...string) *testSystem {
+	sys := &testSystem{
+		cfg:    config.NewConfig(),
+		graphs: []*graph.Graph{graph.NewGraph(db.NewCayleyGraphMemory())},
+	}
+	sys.core = []services.Service{services.NewDataManagerService(sys)}
+
+	src := &stubSource{names: names}
+	src.BaseService = *services.NewBaseService(src, "Stub", sys)
+	if err := src.Start(); err != nil {
+		t.Fatalf("Failed to start the stubbed data source: %v", err)
+	}
+	sys.srcs = []services.Service{src}
+	return sys
+}
+
+func newTestJobConfig(domains ...string) *config.Config {
+	cfg := config.NewConfig()
+	cfg.Passive = true
+	cfg.AddDomains(domains)
+	return cfg
+}
+
+func TestJobManager(t *testing.T) {
+	sys := newTestSystem(t, "www."+domainTest, "mail."+domainTest)
+	defer sys.srcs[0].Stop()
+
+	m := NewJobManager(sys, 1, 1)
+	if _, err := m.Submit(newTestJobConfig()); err == nil {
+		t.Errorf("A job without root domain names was submitted")
+	}
+
+	id, err := m.Submit(newTestJobConfig(domainTest))
+	if err != nil {
+		t.Fatalf("Failed to submit the job: %v", err)
+	}
+	if _, err := m.Submit(newTestJobConfig(domainTest)); err != ErrTooManyJobs {
+		t.Errorf("Expected the job limit to be enforced, got %v", err)
+	}
+
+	// Wait for both findings, so the oldest is dropped from the buffer of one
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if s, _ := m.Status(id); s.Names == 2 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	s, err := m.Cancel(id)
+	if err != nil {
+		t.Fatalf("Failed to cancel the job: %v", err)
+	}
+	if s.Names != 2 || s.Buffered != 1 || s.Dropped != 1 {
+		t.Errorf("Unexpected status after the findings were buffered: %+v", s)
+	}
+
+	// The stream starts at the oldest finding still buffered and ends with the job
+	var results []*Result
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := m.Stream(ctx, id, 0, func(r *Result) error {
+		results = append(results, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("The stream failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Offset != 1 {
+		t.Errorf("Expected only the finding at offset 1 to be streamed, got %d findings", len(results))
+	}
+	if s, _ := m.Status(id); s.State != JobCancelled || s.Finished.IsZero() {
+		t.Errorf("Expected the job to be cancelled, got %+v", s)
+	}
+
+	if _, err := m.Status("unknown"); err != ErrJobNotFound {
+		t.Errorf("Expected the unknown job to be reported, got %v", err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	sys := newTestSystem(t, "www."+domainTest)
+	defer sys.srcs[0].Stop()
+
+	jobs := NewJobManager(sys, 0, 0)
+	defer jobs.Stop()
+
+	srv := NewServer(jobs, Options{Token: "secret"})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Calls without the token must be rejected
+	anon, err := Dial(lis.Addr().String(), nil, "")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer anon.Close()
+	if _, err := anon.SubmitEnumeration(ctx, &SubmitRequest{Domains: []string{domainTest}}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected the call without the token to be rejected, got %v", err)
+	}
+
+	c, err := Dial(lis.Addr().String(), nil, "secret")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer c.Close()
+
+	id, err := c.SubmitEnumeration(ctx, &SubmitRequest{
+		Config:  "mode = passive\n",
+		Domains: []string{domainTest},
+	})
+	if err != nil {
+		t.Fatalf("Failed to submit the job: %v", err)
+	}
+
+	found := make(chan string, 10)
+	go c.StreamResults(ctx, id, 0, func(r *Result) {
+		found <- r.Output.Name
+	})
+
+	select {
+	case name := <-found:
+		if name != "www."+domainTest {
+			t.Errorf("Unexpected finding streamed: %s", name)
+		}
+	case <-ctx.Done():
+		t.Fatalf("The finding was not streamed")
+	}
+
+	if _, err := c.Cancel(ctx, id); err != nil {
+		t.Errorf("Failed to cancel the job: %v", err)
+	}
+	if _, err := c.GetStatus(ctx, "unknown"); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected the unknown job to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package serve
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The messages are encoded as JSON, so clients use the application/grpc+json content type.
+const codecName = "json"
+
+const serviceName = "amass.Amass"
+
+// SubmitRequest is the message of the SubmitEnumeration method.
+type SubmitRequest struct {
+	// The contents of an INI configuration file for the enumeration
+	Config string `json:"config"`
+
+	// Root domain names added to those of the configuration
+	Domains []string `json:"domains"`
+}
+
+// SubmitResponse is the reply of the SubmitEnumeration method.
+type SubmitResponse struct {
+	JobID string `json:"job_id"`
+}
+
+// JobRequest is the message of the GetStatus and Cancel methods.
+type JobRequest struct {
+	JobID string `json:"job_id"`
+}
+
+// StreamRequest is the message of the StreamResults method.
+type StreamRequest struct {
+	JobID string `json:"job_id"`
+
+	// The offset of the first finding to be sent
+	Offset int64 `json:"offset"`
+}
+
+// Options are the settings of the gRPC Server.
+type Options struct {
+	// Enables TLS for all the connections when provided
+	TLSConfig *tls.Config
+
+	// When provided, the clients must send the token in the authorization metadata
+	// as "Bearer <token>"
+	Token string
+}
+
+// Server exposes the JobManager through a gRPC API.
+type Server struct {
+	jobs  *JobManager
+	token string
+	srv   *grpc.Server
+}
+
+// NewServer returns a Server for the JobManager that has not started serving yet.
+func NewServer(jobs *JobManager, opts Options) *Server {
+	s := &Server{
+		jobs:  jobs,
+		token: opts.Token,
+	}
+
+	sopts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	}
+	if opts.TLSConfig != nil {
+		sopts = append(sopts, grpc.Creds(credentials.NewTLS(opts.TLSConfig)))
+	}
+
+	s.srv = grpc.NewServer(sopts...)
+	s.srv.RegisterService(&serviceDesc, s)
+	return s
+}
+
+// Serve accepts connections on the listener until Stop is called.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.srv.Serve(lis)
+}
+
+// Stop closes the listeners and connections, while the jobs keep running.
+func (s *Server) Stop() {
+	s.srv.Stop()
+}
+
+// SubmitEnumeration starts an enumeration job and returns the job ID.
+func (s *Server) SubmitEnumeration(ctx context.Context, req *SubmitRequest) (*SubmitResponse, error) {
+	cfg, err := s.jobs.newConfig(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	id, err := s.jobs.Submit(cfg)
+	switch err {
+	case nil:
+		return &SubmitResponse{JobID: id}, nil
+	case ErrTooManyJobs:
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case ErrDraining:
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case ErrActiveNotAllowed:
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil, status.Error(codes.InvalidArgument, err.Error())
+}
+
+// GetStatus returns the state of the job.
+func (s *Server) GetStatus(ctx context.Context, req *JobRequest) (*JobStatus, error) {
+	js, err := s.jobs.Status(req.JobID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return js, nil
+}
+
+// Cancel stops the enumeration of the job.
+func (s *Server) Cancel(ctx context.Context, req *JobRequest) (*JobStatus, error) {
+	js, err := s.jobs.Cancel(req.JobID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return js, nil
+}
+
+// StreamResults sends the findings of the job as they are validated, until the job ends.
+// The job keeps running when the client disconnects.
+func (s *Server) StreamResults(req *StreamRequest, stream grpc.ServerStream) error {
+	err := s.jobs.Stream(stream.Context(), req.JobID, req.Offset, func(r *Result) error {
+		return stream.SendMsg(r)
+	})
+
+	switch err {
+	case nil:
+		return nil
+	case ErrJobNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	case context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return err
+}
+
+func (s *Server) authorized(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "The authorization token was not provided")
+	}
+
+	for _, value := range md.Get("authorization") {
+		token := strings.TrimSpace(strings.TrimPrefix(value, "Bearer "))
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "The authorization token is not valid")
+}
+
+func (s *Server) unaryAuth(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authorized(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamAuth(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorized(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// amassServer is the interface implemented by Server for the gRPC service description.
+type amassServer interface {
+	SubmitEnumeration(context.Context, *SubmitRequest) (*SubmitResponse, error)
+	GetStatus(context.Context, *JobRequest) (*JobStatus, error)
+	Cancel(context.Context, *JobRequest) (*JobStatus, error)
+	StreamResults(*StreamRequest, grpc.ServerStream) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*amassServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitEnumeration",
+			Handler: unaryHandler("SubmitEnumeration", func() interface{} { return new(SubmitRequest) },
+				func(srv amassServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return srv.SubmitEnumeration(ctx, in.(*SubmitRequest))
+				}),
+		},
+		{
+			MethodName: "GetStatus",
+			Handler: unaryHandler("GetStatus", func() interface{} { return new(JobRequest) },
+				func(srv amassServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return srv.GetStatus(ctx, in.(*JobRequest))
+				}),
+		},
+		{
+			MethodName: "Cancel",
+			Handler: unaryHandler("Cancel", func() interface{} { return new(JobRequest) },
+				func(srv amassServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return srv.Cancel(ctx, in.(*JobRequest))
+				}),
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamResults",
+			Handler:       streamResultsHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "serve/server.go",
+}
+
+func fullMethod(name string) string {
+	return "/" + serviceName + "/" + name
+}
+
+type unaryMethod func(srv amassServer, ctx context.Context, in interface{}) (interface{}, error)
+
+// unaryHandler builds the gRPC handler that decodes the message and applies the interceptor,
+// as the code generated by protoc would do.
+func unaryHandler(name string, newMsg func() interface{}, method unaryMethod) func(interface{},
+	context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+		interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		in := newMsg()
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return method(srv.(amassServer), ctx, in)
+		}
+
+		info := &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: fullMethod(name),
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return method(srv.(amassServer), ctx, req)
+		}
+		return interceptor(ctx, in, info, handler)
+	}
+}
+
+func streamResultsHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(StreamRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(amassServer).StreamResults(in, stream)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
@@ -14,7 +14,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -67,7 +66,7 @@ func (a *AlienVault) OnDNSRequest(ctx context.Context, req *requests.DNSRequest)
 
 	a.CheckRateLimit()
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", a.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, a.String(), "Querying for %s subdomains", req.Domain))
 	a.executeDNSQuery(ctx, req)
 
 	a.CheckRateLimit()
@@ -99,9 +98,9 @@ func (a *AlienVault) executeDNSQuery(ctx context.Context, req *requests.DNSReque
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, a.String())
 
 	u := a.getURL(req.Domain) + "passive_dns"
-	page, err := http.RequestWebPage(u, nil, a.getHeaders(), "", "")
+	page, err := a.System().HTTPClient().RequestWebPage(ctx, u, nil, a.getHeaders(), "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", u, err))
 		return
 	}
 	// Extract the subdomain names and IP addresses from the passive DNS information
@@ -112,11 +111,11 @@ func (a *AlienVault) executeDNSQuery(ctx context.Context, req *requests.DNSReque
 		} `json:"passive_dns"`
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", u, err))
 		return
 	} else if len(m.Subdomains) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The query returned zero results", a.String(), u))
+			requests.NewLogEntry(requests.LogInfo, a.String(), "%s: The query returned zero results", u))
 		return
 	}
 
@@ -132,7 +131,7 @@ func (a *AlienVault) executeDNSQuery(ctx context.Context, req *requests.DNSReque
 	}
 
 	for name := range names {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   name,
 			Domain: req.Domain,
 			Tag:    a.SourceType,
@@ -166,9 +165,9 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 
 	headers := a.getHeaders()
 	u := a.getURL(req.Domain) + "url_list"
-	page, err := http.RequestWebPage(u, nil, headers, "", "")
+	page, err := a.System().HTTPClient().RequestWebPage(ctx, u, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", u, err))
 		return
 	}
 	// Extract the subdomain names and IP addresses from the URL information
@@ -188,11 +187,11 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 		} `json:"url_list"`
 	}
 	if err := json.Unmarshal([]byte(page), &urls); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", u, err))
 		return
 	} else if len(urls.URLs) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The query returned zero results", a.String(), u))
+			requests.NewLogEntry(requests.LogInfo, a.String(), "%s: The query returned zero results", u))
 		return
 	}
 
@@ -214,20 +213,20 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 		for cur := urls.PageNum + 1; cur <= pages; cur++ {
 			a.CheckRateLimit()
 			pageURL := u + "?page=" + strconv.Itoa(cur)
-			page, err = http.RequestWebPage(pageURL, nil, headers, "", "")
+			page, err = a.System().HTTPClient().RequestWebPage(ctx, pageURL, nil, headers, "", "")
 			if err != nil {
 				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-					fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
+					requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", pageURL, err))
 				break
 			}
 
 			if err := json.Unmarshal([]byte(page), &urls); err != nil {
 				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-					fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
+					requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", pageURL, err))
 				break
 			} else if len(urls.URLs) == 0 {
 				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-					fmt.Sprintf("%s: %s: The query returned zero results", a.String(), pageURL),
+					requests.NewLogEntry(requests.LogInfo, a.String(), "%s: The query returned zero results", pageURL),
 				)
 				break
 			}
@@ -246,7 +245,7 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 	}
 
 	for name := range names {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   name,
 			Domain: req.Domain,
 			Tag:    a.SourceType,
@@ -280,10 +279,10 @@ func (a *AlienVault) executeWhoisQuery(ctx context.Context, req *requests.WhoisR
 		bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, a.String())
 
 		pageURL := a.getReverseWhoisURL(email)
-		page, err := http.RequestWebPage(pageURL, nil, headers, "", "")
+		page, err := a.System().HTTPClient().RequestWebPage(ctx, pageURL, nil, headers, "", "")
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
+				requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", pageURL, err))
 			continue
 		}
 
@@ -293,7 +292,7 @@ func (a *AlienVault) executeWhoisQuery(ctx context.Context, req *requests.WhoisR
 		var domains []record
 		if err := json.Unmarshal([]byte(page), &domains); err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
+				requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", pageURL, err))
 			continue
 		}
 		for _, d := range domains {
@@ -306,7 +305,7 @@ func (a *AlienVault) executeWhoisQuery(ctx context.Context, req *requests.WhoisR
 
 	if len(newDomains) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: Reverse whois failed to discover new domain names for %s", a.String(), req.Domain),
+			requests.NewLogEntry(requests.LogError, a.String(), "Reverse whois failed to discover new domain names for %s", req.Domain),
 		)
 		return
 	}
@@ -331,9 +330,9 @@ func (a *AlienVault) queryWhoisForEmails(ctx context.Context, req *requests.Whoi
 
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, a.String())
 
-	page, err := http.RequestWebPage(u, nil, a.getHeaders(), "", "")
+	page, err := a.System().HTTPClient().RequestWebPage(ctx, u, nil, a.getHeaders(), "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", u, err))
 		return emails.Slice()
 	}
 
@@ -346,11 +345,11 @@ func (a *AlienVault) queryWhoisForEmails(ctx context.Context, req *requests.Whoi
 		} `json:"data"`
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", u, err))
 		return emails.Slice()
 	} else if m.Count == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The query returned zero results", a.String(), u))
+			requests.NewLogEntry(requests.LogInfo, a.String(), "%s: The query returned zero results", u))
 		return emails.Slice()
 	}
 
@@ -5,7 +5,6 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
@@ -63,12 +62,12 @@ func (a *Arquivo) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	names, err := crawl(ctx, a.baseURL, a.domain, req.Name, req.Domain)
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", a.String(), err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, a.String(), "%v", err))
 		return
 	}
 
 	for _, name := range names {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   cleanName(name),
 			Domain: req.Domain,
 			Tag:    a.SourceType,
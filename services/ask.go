@@ -5,14 +5,12 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -63,7 +61,7 @@ func (a *Ask) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 		return
 	}
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", a.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, a.String(), "Querying for %s subdomains", req.Domain))
 
 	num := a.limit / a.quantity
 	for i := 0; i < num; i++ {
@@ -75,14 +73,14 @@ func (a *Ask) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 			bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, a.String())
 
 			u := a.urlByPageNum(req.Domain, i)
-			page, err := http.RequestWebPage(u, nil, nil, "", "")
+			page, err := a.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 			if err != nil {
-				bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
+				bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, a.String(), "%s: %v", u, err))
 				return
 			}
 
 			for _, sd := range re.FindAllString(page, -1) {
-				bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+				PublishName(ctx, &requests.DNSRequest{
 					Name:   cleanName(sd),
 					Domain: req.Domain,
 					Tag:    a.SourceType,
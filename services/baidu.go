@@ -6,14 +6,12 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -64,7 +62,7 @@ func (b *Baidu) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 		return
 	}
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", b.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, b.String(), "Querying for %s subdomains", req.Domain))
 
 	num := b.limit / b.quantity
 	for i := 0; i < num; i++ {
@@ -76,14 +74,14 @@ func (b *Baidu) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 			bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, b.String())
 
 			u := b.urlByPageNum(req.Domain, i)
-			page, err := http.RequestWebPage(u, nil, nil, "", "")
+			page, err := b.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 			if err != nil {
-				bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", b.String(), u, err))
+				bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, b.String(), "%s: %v", u, err))
 				return
 			}
 
 			for _, sd := range re.FindAllString(page, -1) {
-				bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+				PublishName(ctx, &requests.DNSRequest{
 					Name:   cleanName(sd),
 					Domain: req.Domain,
 					Tag:    b.Type(),
@@ -96,9 +94,9 @@ func (b *Baidu) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	b.CheckRateLimit()
 	// Check for related sites known by Baidu
 	u := b.urlForRelatedSites(req.Domain)
-	page, err := http.RequestWebPage(u, nil, nil, "", "")
+	page, err := b.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", b.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, b.String(), "%s: %v", u, err))
 		return
 	}
 
@@ -115,7 +113,7 @@ func (b *Baidu) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	for _, element := range rs.Data {
 		if d := cfg.WhichDomain(element.Domain); d != "" {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   element.Domain,
 				Domain: d,
 				Tag:    b.Type(),
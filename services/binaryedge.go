@@ -6,12 +6,10 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -75,11 +73,11 @@ func (be *BinaryEdge) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 	be.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, be.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", be.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, be.String(), "Querying for %s subdomains", req.Domain))
 
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := be.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", be.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, be.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the subdomain names from the REST API results
@@ -92,7 +90,7 @@ func (be *BinaryEdge) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 
 	for _, name := range subs.Subdomains {
 		if re.MatchString(name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   name,
 				Domain: req.Domain,
 				Tag:    be.SourceType,
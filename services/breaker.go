@@ -0,0 +1,195 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// graphBreaker skips the writes to a graph database after consecutive failures or timeouts,
+// and lets a single probe write through once per interval to find out if the database has
+// recovered. The graph databases are identified by name.
+type graphBreaker struct {
+	sync.Mutex
+	states map[string]*breakerState
+}
+
+type breakerState struct {
+	// The consecutive failures reported for the database
+	failures int
+
+	// Set while the writes are skipped, along with the time of the next probe write
+	open  bool
+	probe time.Time
+
+	skipped uint64
+}
+
+// state returns the state of the graph database, and must be called with the lock held.
+func (b *graphBreaker) state(name string) *breakerState {
+	if b.states == nil {
+		b.states = make(map[string]*breakerState)
+	}
+
+	s, found := b.states[name]
+	if !found {
+		s = new(breakerState)
+		b.states[name] = s
+	}
+	return s
+}
+
+// allow returns true when the write can be applied to the graph database.
+func (b *graphBreaker) allow(name string, interval time.Duration) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	s := b.state(name)
+	if !s.open {
+		return true
+	}
+
+	now := time.Now()
+	if now.Before(s.probe) {
+		s.skipped++
+		return false
+	}
+	// Only one probe write is let through for each interval
+	s.probe = now.Add(interval)
+	return true
+}
+
+// failure records the failed write, and returns true when the failure trips the breaker.
+func (b *graphBreaker) failure(name string, threshold int, interval time.Duration) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	s := b.state(name)
+	s.failures++
+	if s.open {
+		// The probe failed, so the writes remain skipped for another interval
+		s.probe = time.Now().Add(interval)
+		return false
+	}
+	if threshold <= 0 || s.failures < threshold {
+		return false
+	}
+
+	s.open = true
+	s.probe = time.Now().Add(interval)
+	return true
+}
+
+// success records the successful write, and returns true when the write closes the breaker.
+func (b *graphBreaker) success(name string) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	s := b.state(name)
+	s.failures = 0
+	if !s.open {
+		return false
+	}
+
+	s.open = false
+	return true
+}
+
+func (b *graphBreaker) skippedWrites() map[string]uint64 {
+	b.Lock()
+	defer b.Unlock()
+
+	skipped := make(map[string]uint64)
+	for name, s := range b.states {
+		if s.skipped > 0 {
+			skipped[name] = s.skipped
+		}
+	}
+	return skipped
+}
+
+// SkippedWrites returns the number of writes skipped for each graph database, while the
+// database was considered unavailable after consecutive failures.
+func (dms *DataManagerService) SkippedWrites() map[string]uint64 {
+	return dms.breaker.skippedWrites()
+}
+
+// breakerWrite applies the function to the graph database, unless the writes to the database
+// are being skipped. The errors returned by the function, and the writes not completed within
+// the config timeout, count toward the config failure threshold.
+func (dms *DataManagerService) breakerWrite(ctx context.Context, g *graph.Graph, f func(g *graph.Graph) error) {
+	cfg, ok := ctx.Value(requests.ContextConfig).(*config.Config)
+	if !ok || cfg == nil || (cfg.GraphFailureThreshold <= 0 && cfg.GraphWriteTimeout <= 0) {
+		f(g)
+		return
+	}
+
+	name := g.String()
+	if !dms.breaker.allow(name, cfg.GraphProbeInterval) {
+		return
+	}
+
+	var err error
+	if !writeWithTimeout(cfg.GraphWriteTimeout, func() { err = f(g) }) {
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogWarn, dms.String(), "%s write did not complete within %s", g, cfg.GraphWriteTimeout).With("graph", g))
+		dms.graphFailure(ctx, name)
+		return
+	}
+	if err != nil {
+		dms.graphFailure(ctx, name)
+		return
+	}
+
+	if dms.breaker.success(name) {
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogInfo, dms.String(), "%s writes were enabled again after a successful probe", g).With("graph", g))
+	}
+}
+
+// graphFailure records the failure of the named graph database, and reports the trip of the breaker.
+func (dms *DataManagerService) graphFailure(ctx context.Context, name string) {
+	cfg, ok := ctx.Value(requests.ContextConfig).(*config.Config)
+	if !ok || cfg == nil {
+		return
+	}
+
+	if dms.breaker.failure(name, cfg.GraphFailureThreshold, cfg.GraphProbeInterval) {
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogWarn, dms.String(), "%s writes are skipped after %d consecutive failures",
+				name, cfg.GraphFailureThreshold).With("graph", name))
+	}
+}
+
+// writeWithTimeout runs the function and returns false when it does not complete within the
+// timeout. The function keeps running in the background after the timeout.
+func writeWithTimeout(timeout time.Duration, f func()) bool {
+	if timeout <= 0 {
+		f()
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-t.C:
+		return false
+	}
+}
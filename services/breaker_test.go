@@ -0,0 +1,145 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/graph/db"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+// flakyDatabase fails the node insertions while the failing flag is set.
+type flakyDatabase struct {
+	db.GraphDatabase
+	failing int32
+	inserts int32
+}
+
+func (f *flakyDatabase) String() string { return "Flaky Graph" }
+
+func (f *flakyDatabase) InsertNode(id, ntype string) (db.Node, error) {
+	atomic.AddInt32(&f.inserts, 1)
+	if atomic.LoadInt32(&f.failing) == 1 {
+		return nil, errors.New("the database is unavailable")
+	}
+	return f.GraphDatabase.InsertNode(id, ntype)
+}
+
+func TestGraphBreaker(t *testing.T) {
+	flaky := &flakyDatabase{GraphDatabase: db.NewCayleyGraphMemory(), failing: 1}
+	healthy := db.NewCayleyGraphMemory()
+
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.GraphFailureThreshold = 3
+	cfg.GraphProbeInterval = 500 * time.Millisecond
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+	h.Sys.AddGraph(graph.NewGraph(flaky))
+	h.Sys.AddGraph(graph.NewGraph(healthy))
+
+	dms := services.NewDataManagerService(h.Sys)
+	process := func(i int) {
+		name := fmt.Sprintf("host%d.owasp.org", i)
+
+		err := servicetest.ProcessDNSRequest(h.Ctx, dms, &requests.DNSRequest{
+			Name:    name,
+			Domain:  "owasp.org",
+			Records: []requests.DNSAnswer{{Name: name, Type: int(dns.TypeA), Data: fmt.Sprintf("192.0.2.%d", i)}},
+			Tag:     requests.DNS,
+			Source:  "DNS",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 1; i <= 5; i++ {
+		process(i)
+	}
+	if dms.SkippedWrites()["Flaky Graph"] == 0 {
+		t.Errorf("The writes to the failing graph database were not skipped after the threshold")
+	}
+	if !graphHasName(healthy, "host5.owasp.org") {
+		t.Errorf("The healthy graph database did not receive the writes")
+	}
+
+	// The skipped writes do not reach the database
+	inserts := atomic.LoadInt32(&flaky.inserts)
+	process(6)
+	if n := atomic.LoadInt32(&flaky.inserts); n != inserts {
+		t.Errorf("The failing graph database received %d writes while skipped", n-inserts)
+	}
+
+	// The database recovers, and the probe write enables the writes again
+	atomic.StoreInt32(&flaky.failing, 0)
+	time.Sleep(2 * cfg.GraphProbeInterval)
+	process(7)
+	process(8)
+	if !graphHasName(flaky, "host8.owasp.org") {
+		t.Errorf("The recovered graph database did not receive the writes after the probe")
+	}
+}
+
+func graphHasName(g db.GraphDatabase, name string) bool {
+	_, err := g.ReadNode(name, "fqdn")
+	return err == nil
+}
+
+// recordFailingDatabase fails the A record edge insertions, while the names can still be stored.
+type recordFailingDatabase struct {
+	db.GraphDatabase
+}
+
+func (r *recordFailingDatabase) String() string { return "Record Failing Graph" }
+
+func (r *recordFailingDatabase) InsertEdge(edge *db.Edge) error {
+	if edge.Predicate == "a_record" {
+		return errors.New("the database is unavailable")
+	}
+	return r.GraphDatabase.InsertEdge(edge)
+}
+
+func TestRecordAnnotationsFollowInserts(t *testing.T) {
+	failing := graph.NewGraph(&recordFailingDatabase{GraphDatabase: db.NewCayleyGraphMemory()})
+	healthy := graph.NewGraph(db.NewCayleyGraphMemory())
+
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+	h.Sys.AddGraph(failing)
+	h.Sys.AddGraph(healthy)
+
+	dms := services.NewDataManagerService(h.Sys)
+	err := servicetest.ProcessDNSRequest(h.Ctx, dms, &requests.DNSRequest{
+		Name:    "www.owasp.org",
+		Domain:  "owasp.org",
+		Records: []requests.DNSAnswer{{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "104.22.26.77"}},
+		Tag:     requests.DNS,
+		Source:  "DNS",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := healthy.ReadRecordSourceCount("www.owasp.org", "A", "104.22.26.77"); got != 1 {
+		t.Errorf("Expected the record source in the graph storing the record, got %d", got)
+	}
+	if got := failing.ReadRecordSourceCount("www.owasp.org", "A", "104.22.26.77"); got != 0 {
+		t.Errorf("The record source was inserted into the graph that failed to store the record")
+	}
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -58,17 +57,17 @@ func (b *BufferOver) OnDNSRequest(ctx context.Context, req *requests.DNSRequest)
 	b.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, b.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", b.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, b.String(), "Querying for %s subdomains", req.Domain))
 
 	url := b.getURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := b.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", b.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, b.String(), "%s: %v", url, err))
 		return
 	}
 
 	for _, sd := range re.FindAllString(page, -1) {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   cleanName(sd),
 			Domain: req.Domain,
 			Tag:    b.SourceType,
@@ -14,7 +14,6 @@ import (
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
 	"github.com/OWASP/Amass/v3/net/dns"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -61,7 +60,7 @@ func (c *Censys) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	c.CheckRateLimit()
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", c.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, c.String(), "Querying for %s subdomains", req.Domain))
 
 	if c.API != nil && c.API.Key != "" && c.API.Secret != "" {
 		c.apiQuery(ctx, req.Domain)
@@ -99,9 +98,9 @@ func (c *Censys) apiQuery(ctx context.Context, domain string) {
 		u := c.apiURL()
 		body := bytes.NewBuffer(jsonStr)
 		headers := map[string]string{"Content-Type": "application/json"}
-		resp, err := http.RequestWebPage(u, body, headers, c.API.Key, c.API.Secret)
+		resp, err := c.System().HTTPClient().RequestWebPage(ctx, u, body, headers, c.API.Key, c.API.Secret)
 		if err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), u, err))
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, c.String(), "%s: %v", u, err))
 			break
 		}
 		// Extract the subdomain names from the certificate information
@@ -116,11 +115,11 @@ func (c *Censys) apiQuery(ctx context.Context, domain string) {
 			} `json:"results"`
 		}
 		if err := json.Unmarshal([]byte(resp), &m); err != nil || m.Status != "ok" {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), u, err))
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, c.String(), "%s: %v", u, err))
 			break
 		} else if len(m.Results) == 0 {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: %s: The query returned zero results", c.String(), u),
+				requests.NewLogEntry(requests.LogInfo, c.String(), "%s: The query returned zero results", u),
 			)
 			break
 		}
@@ -131,7 +130,7 @@ func (c *Censys) apiQuery(ctx context.Context, domain string) {
 				n = dns.RemoveAsteriskLabel(n)
 
 				if cfg.IsDomainInScope(n) {
-					bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+					PublishName(ctx, &requests.DNSRequest{
 						Name:   n,
 						Domain: domain,
 						Tag:    c.SourceType,
@@ -170,14 +169,14 @@ func (c *Censys) executeQuery(ctx context.Context, domain string) {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, c.String())
 
 	url = c.webURL(domain)
-	page, err = http.RequestWebPage(url, nil, nil, "", "")
+	page, err = c.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, c.String(), "%s: %v", url, err))
 		return
 	}
 
 	for _, sd := range re.FindAllString(page, -1) {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   dns.RemoveAsteriskLabel(cleanName(sd)),
 			Domain: domain,
 			Tag:    c.SourceType,
@@ -6,14 +6,12 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/url"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
 	"github.com/OWASP/Amass/v3/net/dns"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -62,12 +60,12 @@ func (c *CertSpotter) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, c.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", c.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, c.String(), "Querying for %s subdomains", req.Domain))
 
 	url := c.getURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := c.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, c.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the subdomain names from the certificate information
@@ -81,7 +79,7 @@ func (c *CertSpotter) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 	for _, result := range m {
 		for _, name := range result.Names {
 			if re.MatchString(name) {
-				bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+				PublishName(ctx, &requests.DNSRequest{
 					Name:   dns.RemoveAsteriskLabel(name),
 					Domain: req.Domain,
 					Tag:    c.SourceType,
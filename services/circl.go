@@ -7,13 +7,11 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
-	"fmt"
 	"strings"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -73,13 +71,13 @@ func (c *CIRCL) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	c.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, c.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", c.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, c.String(), "Querying for %s subdomains", req.Domain))
 
 	url := c.restURL(req.Domain)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, c.API.Username, c.API.Password)
+	page, err := c.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, c.API.Username, c.API.Password)
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, c.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -105,7 +103,7 @@ func (c *CIRCL) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	for name := range unique {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   name,
 			Domain: req.Domain,
 			Tag:    c.SourceType,
@@ -14,7 +14,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -47,7 +46,7 @@ func (c *CommonCrawl) OnStart() error {
 	c.BaseService.OnStart()
 
 	// Get all of the index API URLs
-	page, err := http.RequestWebPage(commonCrawlIndexListURL, nil, nil, "", "")
+	page, err := c.System().HTTPClient().RequestWebPage(context.Background(), commonCrawlIndexListURL, nil, nil, "", "")
 	if err != nil {
 		c.System().Config().Log.Printf("%s: Failed to obtain the index list: %v", c.String(), err)
 		return fmt.Errorf("%s: Failed to obtain the index list: %v", c.String(), err)
@@ -89,7 +88,7 @@ func (c *CommonCrawl) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 		return
 	}
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", c.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, c.String(), "Querying for %s subdomains", req.Domain))
 
 	filter := stringset.NewStringFilter()
 	for _, index := range c.indexURLs {
@@ -101,15 +100,15 @@ func (c *CommonCrawl) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 			bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, c.String())
 
 			u := c.getURL(req.Domain, index)
-			page, err := http.RequestWebPage(u, nil, nil, "", "")
+			page, err := c.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 			if err != nil {
-				bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), u, err))
+				bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, c.String(), "%s: %v", u, err))
 				continue
 			}
 
 			for _, url := range c.parseJSON(page) {
 				if name := re.FindString(url); name != "" && !filter.Duplicate(name) {
-					bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+					PublishName(ctx, &requests.DNSRequest{
 						Name:   name,
 						Domain: req.Domain,
 						Tag:    c.SourceType,
@@ -6,12 +6,10 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/OWASP/Amass/v3/eventbus"
 	"github.com/OWASP/Amass/v3/net/dns"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 	"github.com/jmoiron/sqlx"
@@ -67,7 +65,7 @@ func (c *Crtsh) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	c.CheckRateLimit()
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", c.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, c.String(), "Querying for %s subdomains", req.Domain))
 
 	// Fall back to scraping the web page if the database connection failed
 	if !c.haveConnection {
@@ -96,7 +94,7 @@ func (c *Crtsh) executeQuery(ctx context.Context, domain string) {
 		ORDER BY ci.NAME_VALUE`, pattern)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: Query pattern %s: %v", c.String(), pattern, err))
+			requests.NewLogEntry(requests.LogError, c.String(), "Query pattern %s: %v", pattern, err))
 		return
 	}
 
@@ -109,7 +107,7 @@ func (c *Crtsh) executeQuery(ctx context.Context, domain string) {
 	}
 
 	for name := range names {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   name,
 			Domain: domain,
 			Tag:    c.SourceType,
@@ -125,9 +123,9 @@ func (c *Crtsh) scrape(ctx context.Context, domain string) {
 	}
 
 	url := c.getURL(domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := c.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, c.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -141,7 +139,7 @@ func (c *Crtsh) scrape(ctx context.Context, domain string) {
 		return
 	}
 	for _, line := range results {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   line.Name,
 			Domain: domain,
 			Tag:    c.SourceType,
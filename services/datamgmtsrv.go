@@ -5,38 +5,239 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/graph"
 	"github.com/OWASP/Amass/v3/net"
 	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
 	"github.com/OWASP/Amass/v3/semaphore"
+	"github.com/OWASP/Amass/v3/stringset"
 	"github.com/miekg/dns"
-	"golang.org/x/net/publicsuffix"
 )
 
+// Formats supported by the DataManagerService Export method.
+const (
+	ExportJSONL = "jsonl"
+	ExportCSV   = "csv"
+)
+
+// ExportFilter restricts the records written by the DataManagerService Export method.
+type ExportFilter struct {
+	// Only export records for names within these domains
+	Domains []string
+
+	// Only export records of these types (e.g. A, CNAME, NETBLOCK)
+	Types []string
+
+	// Only export DNS records observed by at least this number of distinct data sources
+	MinSources int
+}
+
+// The policies for the DNS requests that do not provide the root domain name.
+const (
+	// EmptyDomainAllow processes the requests with the empty domain
+	EmptyDomainAllow = "allow"
+	// EmptyDomainDrop drops the requests
+	EmptyDomainDrop = "drop"
+	// EmptyDomainDerive sets the domain to the registered domain of the name
+	EmptyDomainDerive = "derive"
+)
+
+// The number of out of scope CNAME targets resolved at the same time
+const maxCNAMETargetLookups = 25
+
+// The maximum number of names kept in the CNAME path carried by the requests
+const maxCNAMEPathLen = 10
+
+// The number of DNS requests processed by the DataManagerService at the same time
+const maxDataManagerRequests = 1
+
+// cnameTarget holds the addresses of an out of scope CNAME target, and the in scope names
+// waiting for the lookup to complete.
+type cnameTarget struct {
+	resolved bool
+	addrs    []string
+	pending  []cnameOwner
+}
+
+type cnameOwner struct {
+	ctx  context.Context
+	name string
+}
+
 // DataManagerService is the Service that handles all data collected
 // within the architecture. This is achieved by watching all the RESOLVED events.
 type DataManagerService struct {
 	BaseService
 
-	maxRequests semaphore.Semaphore
+	maxRequests *semaphore.WeightedSemaphore
+
+	// The requests being processed, along with the time of the last request
+	processing int32
+	lastActive int64
+
+	// The graph database writes and failures used for the health status
+	health healthStats
+
+	// Skips the writes to the graph databases failing repeatedly
+	breaker graphBreaker
+
+	frontierLock sync.Mutex
+	frontier     *Frontier
+	frontierPath string
+
+	// The writer receiving the stored records as JSON lines
+	streamLock sync.Mutex
+	stream     *recordStream
+
+	// The number of names and records dropped due to the config denylist
+	denied uint64
+
+	// The number of reserved and private addresses that were not enumerated
+	internal uint64
+
+	// The number of requests dropped for not providing the root domain name
+	emptyDomain uint64
+
+	// The number of names not re-published, since the records were not DNSSEC authenticated
+	unauthenticated uint64
+
+	// The number of derived names dropped for exceeding the maximum fan-out of the request
+	fanOutDropped uint64
+
+	// The number of records whose data points to the owner name
+	selfReferences uint64
+
+	// The number of records stored for each record type
+	statsLock     sync.Mutex
+	storedRecords map[string]uint64
+
+	// The labels answered with the same CNAME target below each zone within each event, and the
+	// number of names not stored after the wildcard CNAME was collapsed
+	wildcardLock      sync.Mutex
+	wildcardCNAMEs    map[string]*wildcardCNAME
+	wildcardCollapsed uint64
+
+	// The number of records stored for each root domain name, and the domains that reached
+	// the config cap, keyed by the event
+	recordsLock   sync.Mutex
+	domainRecords map[string]int
+	cappedDomains stringset.Set
+
+	// The out of scope CNAME targets resolved for linking their addresses to the in scope names
+	targetLock   sync.Mutex
+	cnameTargets map[string]*cnameTarget
+	targetSem    *semaphore.WeightedSemaphore
+
+	// The distinct names observed for the addresses that have not yet crossed
+	// the config threshold for address enrichment, keyed by the event
+	addrLock  sync.Mutex
+	addrNames map[string]stringset.Set
+
+	// The publishes observed when requested by the config
+	tap publishTap
+
+	// The zones and NS targets already republished for the zone within each event
+	nsTargets *stringset.StringFilter
+
+	// The geofeed URLs already fetched
+	geofeeds *stringset.StringFilter
+
+	// Starts the spans for the requests processed, when provided
+	tracerLock sync.Mutex
+	tracer     Tracer
+
+	// Tags the CNAME, NS and MX targets with the verdicts of a reputation service, when provided
+	reputationLock    sync.Mutex
+	reputation        ReputationChecker
+	reputationChecked *stringset.StringFilter
+	reputationSem     *semaphore.WeightedSemaphore
 }
 
 // NewDataManagerService returns he object initialized, but not yet started.
 func NewDataManagerService(sys System) *DataManagerService {
-	dms := &DataManagerService{maxRequests: semaphore.NewSimpleSemaphore(1)}
+	dms := &DataManagerService{
+		maxRequests: semaphore.NewWeightedSemaphore(maxDataManagerRequests),
+		nsTargets:   stringset.NewStringFilter(),
+		geofeeds:    stringset.NewStringFilter(),
+	}
 
 	dms.BaseService = *NewBaseService(dms, "Data Manager", sys)
 	return dms
 }
 
+// Denied returns the number of names and records that were dropped due to the config denylist.
+func (dms *DataManagerService) Denied() uint64 {
+	return atomic.LoadUint64(&dms.denied)
+}
+
+// Internal returns the number of reserved and private addresses that were not sent out for
+// further enumeration.
+func (dms *DataManagerService) Internal() uint64 {
+	return atomic.LoadUint64(&dms.internal)
+}
+
+// EmptyDomain returns the number of requests that were dropped for not providing the root
+// domain name, as selected by the config empty domain policy.
+func (dms *DataManagerService) EmptyDomain() uint64 {
+	return atomic.LoadUint64(&dms.emptyDomain)
+}
+
+// Unauthenticated returns the number of names that were not re-published, since the
+// configuration requires DNSSEC authenticated records.
+func (dms *DataManagerService) Unauthenticated() uint64 {
+	return atomic.LoadUint64(&dms.unauthenticated)
+}
+
+// FanOutDropped returns the number of derived names that were dropped for exceeding the
+// config maximum fan-out of a request.
+func (dms *DataManagerService) FanOutDropped() uint64 {
+	return atomic.LoadUint64(&dms.fanOutDropped)
+}
+
+// Capped returns true when the root domain name reached the config cap on the records stored
+// for the event of the context.
+func (dms *DataManagerService) Capped(ctx context.Context, domain string) bool {
+	dms.recordsLock.Lock()
+	defer dms.recordsLock.Unlock()
+
+	return dms.cappedDomains != nil && dms.cappedDomains.Has(eventKey(ctx, strings.ToLower(domain)))
+}
+
+// OnStop implements the Service interface.
+func (dms *DataManagerService) OnStop() error {
+	dms.frontierLock.Lock()
+	defer dms.frontierLock.Unlock()
+
+	if dms.frontier == nil {
+		return nil
+	}
+
+	err := dms.frontier.Close()
+	dms.frontier = nil
+	return err
+}
+
 // OnDNSRequest implements the Service interface.
 func (dms *DataManagerService) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
@@ -44,58 +245,123 @@ func (dms *DataManagerService) OnDNSRequest(ctx context.Context, req *requests.D
 		return
 	}
 
-	curIdx := 0
-	maxIdx := 6
-	delays := []int{25, 50, 75, 100, 150, 250, 500}
-
-	t := time.NewTicker(time.Second)
-	defer t.Stop()
-loop:
-	for {
-		select {
-		case <-dms.Quit():
-			return
-		case <-t.C:
-			bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
-		default:
-			if !dms.maxRequests.TryAcquire(1) {
-				time.Sleep(time.Duration(delays[curIdx]) * time.Millisecond)
-				if curIdx < maxIdx {
-					curIdx++
-				}
-				continue loop
+	actx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The service is reported as active while the request waits for the semaphore,
+	// and the wait ends once the service is stopped
+	go func() {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-actx.Done():
+				return
+			case <-dms.Quit():
+				cancel()
+				return
+			case <-t.C:
+				dms.SetActive(ctx)
 			}
-
-			curIdx = 0
-			go dms.processDNSRequest(ctx, req)
-			return
 		}
+	}()
+
+	if err := dms.maxRequests.Acquire(actx, 1); err != nil {
+		return
 	}
+	go dms.processDNSRequest(ctx, req)
 }
 
 func (dms *DataManagerService) processDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	defer dms.maxRequests.Release(1)
 
+	atomic.AddInt32(&dms.processing, 1)
+	atomic.StoreInt64(&dms.lastActive, time.Now().UnixNano())
+	defer atomic.AddInt32(&dms.processing, -1)
+
 	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
 	if bus == nil {
 		return
 	}
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+	dms.SetActive(ctx)
+	// The request is shared with the other subscribers, so the normalization is applied to a copy
+	req = req.Clone()
+	// Internationalized names are stored in the punycode form
+	req.Name = amassdns.Canonical(req.Name)
+	req.Domain = amassdns.Canonical(req.Domain)
+	// The records are canonicalized before the policies and inserts compare their names and data
+	for i, r := range req.Records {
+		req.Records[i].Name = amassdns.Canonical(r.Name)
+		// The case of TXT data is kept, since encoded payloads are case sensitive
+		if t := uint16(r.Type); t != dns.TypeTXT && t != dns.TypeSPF {
+			req.Records[i].Data = amassdns.Canonical(r.Data)
+		}
+	}
+	// The data is attributed to the event of the request, rather than the context config
+	ctx = withEventID(ctx, req.EventID)
+
+	ctx, span := dms.startSpan(ctx, req)
+	if span != nil {
+		defer span.End()
+	}
+	// The name is no longer pending once the records have been handled
+	defer dms.clearFrontier(ctx, req.Name)
+
+	if cfg := ctx.Value(requests.ContextConfig).(*config.Config); cfg != nil {
+		if !dms.applyEmptyDomainPolicy(cfg, req) {
+			atomic.AddUint64(&dms.emptyDomain, 1)
+			return
+		}
+		if cfg.Denylisted(req.Name) {
+			atomic.AddUint64(&dms.denied, 1)
+			return
+		}
+
+		req.Records = dms.allowedRecords(cfg, req.Records)
+		req.Records = dms.selfReferencedRecords(ctx, cfg, req)
+		req.Records = dms.cappedRecords(ctx, cfg, req)
+		if dms.collapseWildcardCNAME(ctx, cfg, req) {
+			return
+		}
+	}
+	setRecordAttributes(span, req.Records)
+
+	ctx, writer := dms.withGraphWriter(ctx)
+	defer writer.Close()
+	ctx, fo := dms.withFanOut(ctx)
+	defer dms.releaseFanOut(ctx, fo)
+
+	dms.insertRcodes(ctx, req)
+	dms.insertSeed(ctx, req)
+	dms.insertQueryOrigin(ctx, req)
+	dms.insertConfirmation(ctx, req)
+	dms.insertAuthServer(ctx, req)
+	dms.insertRecordSet(ctx, req)
+	dms.insertMultiPTR(ctx, req)
+	dms.clampTTLs(ctx, req)
+	dms.insertAuthenticated(ctx, req)
+	dms.insertUnicodeName(ctx, req)
 
 	// Check for CNAME records first
 	for i, r := range req.Records {
-		req.Records[i].Name = strings.Trim(strings.ToLower(r.Name), ".")
-		req.Records[i].Data = strings.Trim(strings.ToLower(r.Data), ".")
-
 		if uint16(r.Type) == dns.TypeCNAME {
 			dms.insertCNAME(ctx, req, i)
+			dms.insertSourceCount(ctx, req, 1)
 			// Do not enter more than the CNAME record
 			return
 		}
 	}
+	dms.insertCNAMEPath(ctx, req)
+	dms.insertClientSubnet(ctx, req)
 
+	var num int
 	for i, r := range req.Records {
-		bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+		// The remaining records are not stored after the enumeration deadline
+		if ctx.Err() != nil {
+			break
+		}
+		dms.SetActive(ctx)
 
 		switch uint16(r.Type) {
 		case dns.TypeA:
@@ -114,33 +380,667 @@ func (dms *DataManagerService) processDNSRequest(ctx context.Context, req *reque
 			dms.insertTXT(ctx, req, i)
 		case dns.TypeSPF:
 			dms.insertSPF(ctx, req, i)
+		default:
+			continue
+		}
+		num++
+	}
+
+	dms.insertSourceCount(ctx, req, num)
+}
+
+// ImportZoneFile stores the records of the DNS zone file for the names within the configured
+// scope, as done for the records obtained through DNS queries. The origin is used for the
+// relative names appearing before any $ORIGIN directive. Returns the number of names imported.
+func (dms *DataManagerService) ImportZoneFile(ctx context.Context, r io.Reader, origin, filename string) (int, error) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	if cfg == nil {
+		return 0, errors.New("ImportZoneFile: The context did not provide the configuration")
+	}
+
+	reqs, err := resolvers.ParseZoneFile(r, origin, filename)
+	if err != nil {
+		return 0, err
+	}
+
+	var num int
+	for _, req := range reqs {
+		if ctx.Err() != nil {
+			return num, ctx.Err()
+		}
+
+		domain := cfg.WhichDomain(req.Name)
+		if domain == "" {
+			continue
+		}
+
+		req.Domain = domain
+		if err := dms.maxRequests.Acquire(ctx, 1); err != nil {
+			return num, err
+		}
+		dms.processDNSRequest(ctx, req)
+		num++
+	}
+	return num, nil
+}
+
+// clampTTLs limits the TTLs of the records to the range selected by the configuration, before
+// the records are stored.
+func (dms *DataManagerService) clampTTLs(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	for i, r := range req.Records {
+		ttl, clamped := cfg.ClampTTL(r.TTL)
+		if !clamped {
+			continue
+		}
+
+		req.Records[i].TTL = ttl
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityLow,
+			requests.NewLogEntry(requests.LogInfo, dms.String(), "Clamped the TTL %d of the %s record for %s to %d",
+				r.TTL, dns.TypeToString[uint16(r.Type)], r.Name, ttl).With("ttl", r.TTL))
+	}
+}
+
+// insertUnicodeName stores the Unicode form of the internationalized name for display.
+func (dms *DataManagerService) insertUnicodeName(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	unicode := amassdns.UnicodeName(req.Name)
+	if unicode == req.Name {
+		return
+	}
+
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertUnicodeName(req.Name, unicode, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the Unicode name: %v", g, err).With("graph", g))
+			return err
+		}
+		return nil
+	})
+}
+
+// applyEmptyDomainPolicy handles the request without a root domain name as selected by the
+// configuration, and returns false when the request must be dropped.
+func (dms *DataManagerService) applyEmptyDomainPolicy(cfg *config.Config, req *requests.DNSRequest) bool {
+	if req.Domain != "" {
+		return true
+	}
+
+	switch strings.ToLower(cfg.EmptyDomainPolicy) {
+	case EmptyDomainDrop:
+		return false
+	case EmptyDomainDerive:
+		domain, err := dms.System().Suffixes().RegisteredDomain(req.Name)
+		if err != nil || domain == "" {
+			return false
+		}
+
+		req.Domain = domain
+	}
+	return true
+}
+
+// cappedRecords returns the records that can be stored without the root domain name of the
+// request exceeding the config cap. The domain is flagged when the cap is reached.
+func (dms *DataManagerService) cappedRecords(ctx context.Context, cfg *config.Config, req *requests.DNSRequest) []requests.DNSAnswer {
+	if cfg.MaxRecordsPerDomain <= 0 || len(req.Records) == 0 {
+		return req.Records
+	}
+
+	domain := strings.ToLower(req.Domain)
+	if domain == "" {
+		domain = strings.ToLower(cfg.WhichDomain(req.Name))
+	}
+	if domain == "" {
+		return req.Records
+	}
+
+	dms.recordsLock.Lock()
+	if dms.domainRecords == nil {
+		dms.domainRecords = make(map[string]int)
+		dms.cappedDomains = stringset.New()
+	}
+
+	key := eventKey(ctx, domain)
+	allowed := cfg.MaxRecordsPerDomain - dms.domainRecords[key]
+	if allowed > len(req.Records) {
+		allowed = len(req.Records)
+	} else if allowed < 0 {
+		allowed = 0
+	}
+	dms.domainRecords[key] += allowed
+
+	// Only the request that reaches the cap flags the domain
+	var capped bool
+	if allowed < len(req.Records) && !dms.cappedDomains.Has(key) {
+		dms.cappedDomains.Insert(key)
+		capped = true
+	}
+	dms.recordsLock.Unlock()
+
+	if bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus); capped && bus != nil {
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogWarn, dms.String(),
+				"The domain %s reached the cap of %d records, further records are dropped",
+				domain, cfg.MaxRecordsPerDomain).With("domain", domain))
+
+		dms.writeGraphs(ctx, func(g *graph.Graph) error {
+			if err := g.MarkCappedDomain(domain); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to flag the capped domain: %v", g, err).With("graph", g))
+				return err
+			}
+			return nil
+		})
+	}
+	return req.Records[:allowed]
+}
+
+// allowedRecords returns the records that do not have a name or target matching the config denylist,
+// leaving out the record types skipped by the configuration.
+func (dms *DataManagerService) allowedRecords(cfg *config.Config, records []requests.DNSAnswer) []requests.DNSAnswer {
+	var allowed []requests.DNSAnswer
+
+	for _, r := range records {
+		if cfg.SkippedRecordType(dns.TypeToString[uint16(r.Type)]) {
+			continue
+		}
+		if cfg.Denylisted(r.Name) {
+			atomic.AddUint64(&dms.denied, 1)
+			continue
+		}
+
+		switch uint16(r.Type) {
+		case dns.TypeCNAME, dns.TypePTR, dns.TypeNS, dns.TypeMX, dns.TypeSRV:
+			if cfg.Denylisted(r.Data) {
+				atomic.AddUint64(&dms.denied, 1)
+				continue
+			}
+		}
+
+		allowed = append(allowed, r)
+	}
+	return allowed
+}
+
+// OnASNRequest implements the Service interface.
+func (dms *DataManagerService) OnASNRequest(ctx context.Context, req *requests.ASNRequest) {
+	if req.Address == "" || req.Prefix == "" || req.Description == "" {
+		return
+	}
+
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	desc := normalizeASNDescription(req.Description, cfg.FoldASNDescriptions)
+	if desc == "" {
+		return
+	}
+	ctx = withEventID(ctx, req.EventID)
+
+	for _, g := range dms.System().GraphDatabases() {
+		dms.breakerWrite(ctx, g, func(g *graph.Graph) error {
+			dms.health.written()
+			err := g.InsertInfrastructure(req.ASN, desc,
+				req.Address, req.Prefix, req.Source, req.Tag, eventID(ctx))
+			if err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert infrastructure data: %v", g, err).With("graph", g),
+				)
+				return err
+			}
+
+			if cfg.StoreRawASNDescriptions {
+				if err := g.InsertRawASDescription(strconv.Itoa(req.ASN), req.Description); err != nil {
+					dms.health.failed()
+					dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+						requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the raw AS description: %v", g, err).With("graph", g),
+					)
+				}
+			}
+			return nil
+		})
+	}
+
+	dms.SetActive(ctx)
+}
+
+// normalizeASNDescription removes control characters, invalid encodings and
+// extra whitespace from the AS description, and optionally case-folds it.
+func normalizeASNDescription(desc string, fold bool) string {
+	desc = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError {
+			return -1
+		}
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, desc)
+
+	desc = strings.Join(strings.Fields(desc), " ")
+	if fold {
+		desc = strings.ToLower(desc)
+	}
+	return desc
+}
+
+// ResumeFrontier publishes the names left pending in the frontier file by an interrupted
+// enumeration and returns the number of names published.
+func (dms *DataManagerService) ResumeFrontier(ctx context.Context) int {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return 0
+	}
+
+	f := dms.getFrontier(cfg)
+	if f == nil {
+		return 0
+	}
+
+	pending := f.Pending()
+	for _, req := range pending {
+		dms.publishName(ctx, req)
+	}
+	return len(pending)
+}
+
+func (dms *DataManagerService) getFrontier(cfg *config.Config) *Frontier {
+	if cfg.FrontierPath == "" {
+		return nil
+	}
+
+	dms.frontierLock.Lock()
+	defer dms.frontierLock.Unlock()
+
+	// Only attempt to open each frontier file once
+	if cfg.FrontierPath == dms.frontierPath {
+		return dms.frontier
+	}
+	if dms.frontier != nil {
+		dms.frontier.Close()
+		dms.frontier = nil
+	}
+
+	dms.frontierPath = cfg.FrontierPath
+	f, err := NewFrontier(cfg.FrontierPath)
+	if err != nil {
+		cfg.Log.Printf("%s: Failed to open the frontier file: %v", dms.String(), err)
+		return nil
+	}
+
+	dms.frontier = f
+	return f
+}
+
+// republish publishes the name derived from the records, unless the policies of the enumeration
+// exclude the name. The authenticated parameter reports whether the record the name was derived
+// from passed DNSSEC validation.
+func (dms *DataManagerService) republish(ctx context.Context, req *requests.DNSRequest, authenticated bool) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	if cfg.RequireDNSSEC && !authenticated {
+		atomic.AddUint64(&dms.unauthenticated, 1)
+		return
+	}
+
+	if cfg.Denylisted(req.Name) {
+		atomic.AddUint64(&dms.denied, 1)
+		return
+	}
+
+	if dms.allowFanOut(ctx, req) {
+		dms.publishName(ctx, req)
+	}
+}
+
+// NameDone clears the name from the frontier once the DNS service has finished with the name,
+// whether or not the name resolved.
+func (dms *DataManagerService) NameDone(ctx context.Context, name string) {
+	dms.clearFrontier(ctx, name)
+}
+
+func (dms *DataManagerService) addFrontier(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	if f := dms.getFrontier(cfg); f != nil {
+		if err := f.Add(req); err != nil {
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "Failed to add %s to the frontier: %v", req.Name, err))
+		}
+	}
+}
+
+func (dms *DataManagerService) clearFrontier(ctx context.Context, name string) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	if f := dms.getFrontier(cfg); f != nil {
+		if err := f.Remove(name); err != nil {
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "Failed to remove %s from the frontier: %v", name, err))
+		}
+	}
+}
+
+func (dms *DataManagerService) insertRcodes(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	name := amassdns.Canonical(req.Name)
+	if name == "" {
+		return
+	}
+
+	for _, code := range req.Rcodes {
+		// Do not record the response codes made up by the resolvers
+		rcode, found := dns.RcodeToString[code]
+		if !found {
+			continue
+		}
+
+		dms.writeGraphs(ctx, func(g *graph.Graph) error {
+			if err := g.InsertRcode(name, rcode, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the response code: %v", g, err).With("graph", g))
+				return err
+			}
+			return nil
+		})
+	}
+}
+
+// insertRecordSet stores the signature of the request record set and publishes a
+// RecordSetChange when it differs from the signature stored previously for the name.
+func (dms *DataManagerService) insertRecordSet(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	name := amassdns.Canonical(req.Name)
+	sig := recordSetSignature(req.Records)
+	if name == "" || sig == "" {
+		return
+	}
+
+	var prev string
+	for _, g := range dms.System().GraphDatabases() {
+		dms.breakerWrite(ctx, g, func(g *graph.Graph) error {
+			dms.health.written()
+			p, err := g.InsertRecordSetSignature(name, sig, req.Source, req.Tag, eventID(ctx))
+			if err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the record set signature: %v", g, err).With("graph", g))
+				return err
+			}
+			if prev == "" {
+				prev = p
+			}
+			return nil
+		})
+	}
+
+	if prev != "" && prev != sig {
+		dms.publish(ctx, requests.RecordSetTopic, eventbus.PriorityLow, &requests.RecordSetChange{
+			Name:     name,
+			Domain:   req.Domain,
+			Previous: prev,
+			Current:  sig,
+			Tag:      req.Tag,
+			Source:   req.Source,
+		})
+	}
+}
+
+// recordSetSignature returns a stable hash of the DNS records. The records are normalized
+// and sorted, and the TTLs are ignored, so cosmetic differences produce the same signature.
+func recordSetSignature(records []requests.DNSAnswer) string {
+	set := stringset.New()
+
+	for _, r := range records {
+		name := amassdns.Canonical(r.Name)
+		data := strings.Trim(strings.ToLower(strings.Join(strings.Fields(r.Data), " ")), ".")
+		if data == "" {
+			continue
+		}
+
+		set.Insert(fmt.Sprintf("%s\t%d\t%s", name, r.Type, data))
+	}
+	if set.Len() == 0 {
+		return ""
+	}
+
+	entries := set.Slice()
+	sort.Strings(entries)
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestSeed returns the seed domain that led to the request, so names derived from the
+// request can be annotated with the same seed.
+func requestSeed(req *requests.DNSRequest) string {
+	if req.Seed != "" {
+		return req.Seed
+	}
+	return req.Domain
+}
+
+func (dms *DataManagerService) insertSeed(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	name := amassdns.Canonical(req.Name)
+	seed := strings.ToLower(requestSeed(req))
+	if name == "" || seed == "" {
+		return
+	}
+
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertSeed(name, seed, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the seed domain: %v", g, err).With("graph", g))
+			return err
 		}
+		return nil
+	})
+}
+
+// insertQueryOrigin flags the names that entered the enumeration directly, so the query entry
+// points can be distinguished from the canonical names derived from the records.
+func (dms *DataManagerService) insertQueryOrigin(ctx context.Context, req *requests.DNSRequest) {
+	if req.Depth != 0 {
+		return
 	}
+
+	name := amassdns.Canonical(req.Name)
+	if name == "" {
+		return
+	}
+
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.MarkQueryOrigin(name, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to mark the query origin: %v", g, err).With("graph", g))
+			return err
+		}
+		return nil
+	})
+}
+
+// insertConfirmation flags the names only reported by passive sources, and confirms the names
+// once records obtained through active DNS resolution arrive.
+func (dms *DataManagerService) insertConfirmation(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
+		return
+	}
+
+	name := amassdns.Canonical(req.Name)
+	if name == "" {
+		return
+	}
+
+	confirmed := !requests.PassiveTag(req.Tag)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertConfirmation(name, confirmed, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to update the passive-only flag: %v", g, err).With("graph", g))
+			return err
+		}
+		return nil
+	})
+}
+
+func (dms *DataManagerService) insertAuthServer(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil || req.AuthServer == "" {
+		return
+	}
+
+	name := amassdns.Canonical(req.Name)
+	if name == "" {
+		return
+	}
+
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertAuthServer(name, req.AuthServer, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the authoritative server: %v", g, err).With("graph", g))
+			return err
+		}
+		return nil
+	})
+}
+
+// insertClientSubnet stores the addresses of the request keyed by the EDNS client subnet the
+// query carried, since CDNs and geo-DNS answer differently depending on the subnet.
+func (dms *DataManagerService) insertClientSubnet(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil || req.ClientSubnet == "" {
+		return
+	}
+
+	var addrs []string
+	for _, r := range req.Records {
+		if t := uint16(r.Type); (t == dns.TypeA || t == dns.TypeAAAA) && r.Data != "" {
+			addrs = append(addrs, r.Data)
+		}
+	}
+	if len(addrs) == 0 {
+		return
+	}
+
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		for _, addr := range addrs {
+			if err := g.InsertClientSubnetAddress(req.Name, req.ClientSubnet, addr, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the client subnet address: %v", g, err).With("graph", g))
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-// OnASNRequest implements the Service interface.
-func (dms *DataManagerService) OnASNRequest(ctx context.Context, req *requests.ASNRequest) {
-	if req.Address == "" || req.Prefix == "" || req.Description == "" {
+// insertAuthenticated marks the records that carried the DNSSEC authenticated-data flag.
+func (dms *DataManagerService) insertAuthenticated(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil {
 		return
 	}
 
+	for _, r := range req.Records {
+		if !r.Authenticated || r.Name == "" || r.Data == "" {
+			continue
+		}
+
+		rrtype := dns.TypeToString[uint16(r.Type)]
+		if rrtype == "" {
+			continue
+		}
+
+		// The writes can be applied after the loop has moved on
+		name, data := r.Name, r.Data
+		dms.writeGraphs(ctx, func(g *graph.Graph) error {
+			if err := g.InsertAuthenticatedRecord(name, rrtype, data, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to mark the authenticated record: %v", g, err).With("graph", g))
+				return err
+			}
+			return nil
+		})
+	}
+}
+
+func (dms *DataManagerService) insertSourceCount(ctx context.Context, req *requests.DNSRequest, num int) {
 	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
 	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
-	if cfg == nil || bus == nil {
+	if cfg == nil || bus == nil || num <= 0 {
 		return
 	}
 
-	for _, g := range dms.System().GraphDatabases() {
-		err := g.InsertInfrastructure(req.ASN, req.Description,
-			req.Address, req.Prefix, req.Source, req.Tag, cfg.UUID.String())
-		if err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: %s failed to insert infrastructure data: %v", dms.String(), g, err),
-			)
-		}
+	name := amassdns.Canonical(req.Name)
+	if name == "" || req.Source == "" {
+		return
 	}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertSourceCount(name, req.Source, req.Tag, eventID(ctx), num); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the source attribution: %v", g, err).With("graph", g))
+			return err
+		}
+		return nil
+	})
 }
 
 func (dms *DataManagerService) insertCNAME(ctx context.Context, req *requests.DNSRequest, recidx int) {
@@ -150,12 +1050,12 @@ func (dms *DataManagerService) insertCNAME(ctx context.Context, req *requests.DN
 		return
 	}
 
-	target := resolvers.RemoveLastDot(req.Records[recidx].Data)
+	target := amassdns.Canonical(req.Records[recidx].Data)
 	if target == "" {
 		return
 	}
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(target)
+	domain, err := dms.System().Suffixes().RegisteredDomain(target)
 	if err != nil {
 		return
 	}
@@ -165,80 +1065,342 @@ func (dms *DataManagerService) insertCNAME(ctx context.Context, req *requests.DN
 		return
 	}
 
-	for _, g := range dms.System().GraphDatabases() {
-		if err := g.InsertCNAME(req.Name, target, req.Source, req.Tag, cfg.UUID.String()); err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s failed to insert CNAME: %v", g, err))
+	// The raw target is kept, while the cloud service allows the targets to be grouped
+	service := cfg.CloudService(target)
+	cdn := cfg.CDNByName(target)
+
+	stored := dms.recordStored(ctx, req, graph.RecordCNAME, req.Name, target)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		err := g.InsertCNAME(req.Name, target, req.Source, req.Tag, eventID(ctx))
+		if err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert CNAME: %v", g, err).With("graph", g))
 		}
-	}
+		if service != "" {
+			if err := g.InsertCloudService(target, service, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the cloud service: %v", g, err).With("graph", g))
+			}
+		}
+		if cdn != "" {
+			if err := g.InsertCDN(req.Name, cdn, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to tag the CDN provider: %v", g, err).With("graph", g))
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return stored(g)
+	})
+
+	dms.linkCNAMETarget(ctx, req.Name, target)
+	dms.checkReputation(ctx, target)
 
 	// Important - Allows chained CNAME records to be resolved until an A/AAAA record
-	bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-		Name:   target,
-		Domain: domain,
-		Tag:    requests.DNS,
-		Source: "DNS",
-	})
+	dms.republish(ctx, &requests.DNSRequest{
+		Name:      target,
+		Domain:    domain,
+		Seed:      requestSeed(req),
+		Depth:     req.Depth + 1,
+		Tag:       requests.DNS,
+		Source:    "DNS",
+		CNAMEPath: extendCNAMEPath(req),
+	}, req.Records[recidx].Authenticated)
+
+	dms.SetActive(ctx)
+}
+
+// extendCNAMEPath returns the CNAME path of the request followed by the request name. Once
+// the path reaches maxCNAMEPathLen, the intermediate names are no longer added, so the owner
+// name is kept and the terminal name is still appended when the path is stored.
+func extendCNAMEPath(req *requests.DNSRequest) []string {
+	if len(req.CNAMEPath) >= maxCNAMEPathLen {
+		return req.CNAMEPath
+	}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+	path := make([]string, len(req.CNAMEPath), len(req.CNAMEPath)+1)
+	copy(path, req.CNAMEPath)
+	return append(path, req.Name)
 }
 
-func (dms *DataManagerService) insertA(ctx context.Context, req *requests.DNSRequest, recidx int) {
+// insertCNAMEPath stores the CNAME path that led to the name, once the chain terminates
+// with A or AAAA records.
+func (dms *DataManagerService) insertCNAMEPath(ctx context.Context, req *requests.DNSRequest) {
+	if len(req.CNAMEPath) == 0 {
+		return
+	}
+
+	var terminal bool
+	for _, r := range req.Records {
+		if t := uint16(r.Type); t == dns.TypeA || t == dns.TypeAAAA {
+			terminal = true
+			break
+		}
+	}
+	if !terminal {
+		return
+	}
+
+	path := append(append([]string(nil), req.CNAMEPath...), req.Name)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertCNAMEPath(req.Name, path, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the CNAME path: %v", g, err).With("graph", g))
+			return err
+		}
+		return nil
+	})
+}
+
+// linkCNAMETarget links the addresses of the out of scope CNAME target to the in scope name,
+// when the configuration requests it. Each target is only resolved once.
+func (dms *DataManagerService) linkCNAMETarget(ctx context.Context, name, target string) {
 	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
-	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
-	if cfg == nil || bus == nil {
+	if cfg == nil || !cfg.LinkCNAMETargets || cfg.IsDomainInScope(target) || !cfg.IsDomainInScope(name) {
 		return
 	}
 
-	addr := strings.TrimSpace(req.Records[recidx].Data)
-	if addr == "" {
+	target = strings.ToLower(target)
+	// The lookup outlives the request, so the writes cannot use the graph writer of the request
+	ctx = context.WithValue(ctx, graphWritesKey{}, (*graphWriter)(nil))
+
+	dms.targetLock.Lock()
+	if dms.cnameTargets == nil {
+		dms.cnameTargets = make(map[string]*cnameTarget)
+		dms.targetSem = semaphore.NewWeightedSemaphore(maxCNAMETargetLookups)
+	}
+
+	t, found := dms.cnameTargets[target]
+	if !found {
+		t = new(cnameTarget)
+		dms.cnameTargets[target] = t
+	}
+
+	resolved := t.resolved
+	addrs := t.addrs
+	if !resolved {
+		t.pending = append(t.pending, cnameOwner{ctx: ctx, name: name})
+	}
+	dms.targetLock.Unlock()
+
+	if resolved {
+		dms.insertTargetAddrs(ctx, name, addrs)
+	} else if !found {
+		go dms.resolveCNAMETarget(ctx, target)
+	}
+}
+
+func (dms *DataManagerService) resolveCNAMETarget(ctx context.Context, target string) {
+	var addrs []string
+
+	if err := dms.targetSem.Acquire(ctx, 1); err != nil {
+		dms.targetLock.Lock()
+		// The names waiting for the target are no longer linked, and a later CNAME record
+		// for the target attempts the lookup again
+		delete(dms.cnameTargets, target)
+		dms.targetLock.Unlock()
 		return
 	}
+	if pool := dms.System().Pool(); pool != nil {
+		for _, qtype := range []string{"A", "AAAA"} {
+			answers, _, err := pool.Resolve(ctx, target, qtype, resolvers.PriorityLow)
+			if err != nil {
+				continue
+			}
 
-	for _, g := range dms.System().GraphDatabases() {
-		if err := g.InsertA(req.Name, addr, req.Source, req.Tag, cfg.UUID.String()); err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s failed to insert A record: %v", g, err))
+			for _, a := range answers {
+				if t := uint16(a.Type); t != dns.TypeA && t != dns.TypeAAAA {
+					continue
+				}
+				if addr := strings.TrimSpace(a.Data); addr != "" {
+					addrs = append(addrs, addr)
+				}
+			}
 		}
 	}
+	dms.targetSem.Release(1)
+
+	dms.targetLock.Lock()
+	t := dms.cnameTargets[target]
+	t.resolved = true
+	t.addrs = addrs
+	pending := t.pending
+	t.pending = nil
+	dms.targetLock.Unlock()
+
+	for _, owner := range pending {
+		dms.insertTargetAddrs(owner.ctx, owner.name, addrs)
+	}
+}
+
+func (dms *DataManagerService) insertTargetAddrs(ctx context.Context, name string, addrs []string) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil || len(addrs) == 0 {
+		return
+	}
 
-	bus.Publish(requests.NewAddrTopic, eventbus.PriorityHigh, &requests.AddrRequest{
-		Address: addr,
-		Domain:  req.Domain,
-		Tag:     req.Tag,
-		Source:  req.Source,
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		for _, addr := range addrs {
+			if err := g.InsertTargetAddress(name, addr, "DNS", requests.DNS, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to link the CNAME target address: %v", g, err).With("graph", g))
+				return err
+			}
+		}
+		return nil
 	})
+}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+func (dms *DataManagerService) insertA(ctx context.Context, req *requests.DNSRequest, recidx int) {
+	if addr := strings.TrimSpace(req.Records[recidx].Data); addr != "" {
+		dms.insertAddress(ctx, req, addr, graph.RecordA)
+	}
 }
 
 func (dms *DataManagerService) insertAAAA(ctx context.Context, req *requests.DNSRequest, recidx int) {
+	if addr := strings.TrimSpace(req.Records[recidx].Data); addr != "" {
+		dms.insertAddress(ctx, req, addr, graph.RecordAAAA)
+	}
+}
+
+// insertAddress stores the A or AAAA record of the request name along with the address
+// annotations selected by the configuration, and sends the address out for enumeration.
+func (dms *DataManagerService) insertAddress(ctx context.Context, req *requests.DNSRequest, addr, rrtype string) {
 	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
 	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
 	if cfg == nil || bus == nil {
 		return
 	}
 
-	addr := strings.TrimSpace(req.Records[recidx].Data)
-	if addr == "" {
+	internal := isInternalAddr(cfg, addr)
+	cdn := cfg.CDNByAddress(addr)
+
+	stored := dms.recordStored(ctx, req, rrtype, req.Name, addr)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		var err error
+		if rrtype == graph.RecordAAAA {
+			err = g.InsertAAAA(req.Name, addr, req.Source, req.Tag, eventID(ctx))
+		} else {
+			err = g.InsertA(req.Name, addr, req.Source, req.Tag, eventID(ctx))
+		}
+		if err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert %s record: %v", g, rrtype, err).With("graph", g))
+		}
+		if internal {
+			if err := g.MarkInternalAddress(addr); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to mark the internal address: %v", g, err).With("graph", g))
+			}
+		}
+		if cfg.ClassifyNames {
+			if _, err := g.UpdateNameExposure(req.Name); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to tag the name exposure: %v", g, err).With("graph", g))
+			}
+		}
+		if cdn != "" {
+			if err := g.InsertCDN(req.Name, cdn, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to tag the CDN provider: %v", g, err).With("graph", g))
+			}
+		}
+		if cfg.LinkKnownPorts {
+			if err := g.LinkAddressPorts(req.Name, addr); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to link the open ports: %v", g, err).With("graph", g))
+			}
+		}
+		if cfg.ConfirmFCrDNS {
+			dms.updateFCrDNS(ctx, g, req.Name, addr)
+		}
+		if err != nil {
+			return err
+		}
+		return stored(g)
+	})
+
+	dms.publishAddr(ctx, cfg, req.Name, &requests.AddrRequest{
+		Address:   addr,
+		Domain:    req.Domain,
+		Tag:       req.Tag,
+		Source:    req.Source,
+		NoReverse: !cfg.ReverseAllowed(addr),
+	})
+
+	dms.SetActive(ctx)
+}
+
+// publishAddr sends the address out for further enumeration, unless the configuration
+// requires addresses to be stored as leaves of the graph or the address is internal. When a
+// minimum number of names is configured, the address is only sent once enough distinct names
+// have resolved to it.
+func (dms *DataManagerService) publishAddr(ctx context.Context, cfg *config.Config, name string, req *requests.AddrRequest) {
+	if isInternalAddr(cfg, req.Address) {
+		atomic.AddUint64(&dms.internal, 1)
+		return
+	}
+	if cfg.LeafAddresses {
+		return
+	}
+	if cfg.MinAddrNames > 1 && !dms.addrThresholdMet(ctx, req.Address, name, cfg.MinAddrNames) {
 		return
 	}
 
-	for _, g := range dms.System().GraphDatabases() {
-		if err := g.InsertAAAA(req.Name, addr, req.Source, req.Tag, cfg.UUID.String()); err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s failed to insert AAAA record: %v", g, err))
-		}
+	dms.publish(ctx, requests.NewAddrTopic, eventbus.PriorityHigh, req)
+}
+
+// isInternalAddr returns true when the address is reserved or private, and the configuration
+// excludes such addresses from the enumeration.
+func isInternalAddr(cfg *config.Config, addr string) bool {
+	if !cfg.ExcludeReservedAddrs {
+		return false
 	}
 
-	bus.Publish(requests.NewAddrTopic, eventbus.PriorityHigh, &requests.AddrRequest{
-		Address: addr,
-		Domain:  req.Domain,
-		Tag:     req.Tag,
-		Source:  req.Source,
-	})
+	reserved, _ := net.IsReservedAddress(addr)
+	return reserved
+}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+// addrThresholdMet records the name for the address and returns true once the address has
+// been observed for the minimum number of distinct names within the event.
+func (dms *DataManagerService) addrThresholdMet(ctx context.Context, addr, name string, min int) bool {
+	dms.addrLock.Lock()
+	defer dms.addrLock.Unlock()
+
+	if dms.addrNames == nil {
+		dms.addrNames = make(map[string]stringset.Set)
+	}
+
+	key := eventKey(ctx, addr)
+	names, found := dms.addrNames[key]
+	if found && names == nil {
+		// The address crossed the threshold previously
+		return true
+	}
+	if !found {
+		names = stringset.New()
+		dms.addrNames[key] = names
+	}
+
+	names.Insert(strings.ToLower(name))
+	if names.Len() < min {
+		return false
+	}
+	// The names are no longer needed once the threshold has been crossed
+	dms.addrNames[key] = nil
+	return true
 }
 
 func (dms *DataManagerService) insertPTR(ctx context.Context, req *requests.DNSRequest, recidx int) {
@@ -248,7 +1410,7 @@ func (dms *DataManagerService) insertPTR(ctx context.Context, req *requests.DNSR
 		return
 	}
 
-	target := resolvers.RemoveLastDot(req.Records[recidx].Data)
+	target := amassdns.Canonical(req.Records[recidx].Data)
 	if target == "" {
 		return
 	}
@@ -259,21 +1421,79 @@ func (dms *DataManagerService) insertPTR(ctx context.Context, req *requests.DNSR
 		return
 	}
 
-	for _, g := range dms.System().GraphDatabases() {
-		if err := g.InsertPTR(req.Name, target, req.Source, req.Tag, cfg.UUID.String()); err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s failed to insert PTR record: %v", g, err))
+	addr := amassdns.ReverseNameToAddr(req.Name)
+	stored := dms.recordStored(ctx, req, graph.RecordPTR, req.Name, target)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		err := g.InsertPTR(req.Name, target, req.Source, req.Tag, eventID(ctx))
+		if err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert PTR record: %v", g, err).With("graph", g))
 		}
-	}
+		if cfg.ConfirmFCrDNS && addr != "" {
+			dms.updateFCrDNS(ctx, g, target, addr)
+		}
+		if err != nil {
+			return err
+		}
+		return stored(g)
+	})
 
-	bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+	dms.republish(ctx, &requests.DNSRequest{
 		Name:   target,
 		Domain: domain,
+		Seed:   requestSeed(req),
+		Depth:  req.Depth + 1,
 		Tag:    requests.DNS,
 		Source: req.Source,
-	})
+	}, req.Records[recidx].Authenticated)
+
+	dms.SetActive(ctx)
+}
+
+// updateFCrDNS marks the name and address pair as forward-confirmed reverse DNS once both the
+// forward and the reverse records are stored in the graph, regardless of which arrived first.
+func (dms *DataManagerService) updateFCrDNS(ctx context.Context, g *graph.Graph, name, addr string) {
+	if _, err := g.UpdateFCrDNS(name, addr); err != nil {
+		dms.health.failed()
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to mark the FCrDNS confirmation: %v", g, err).With("graph", g))
+	}
+}
+
+// insertMultiPTR flags a reverse DNS name answered by multiple PTR records, since the
+// multiplicity indicates shared hosting or round-robin configurations.
+func (dms *DataManagerService) insertMultiPTR(ctx context.Context, req *requests.DNSRequest) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil || !cfg.MultiPTR {
+		return
+	}
+
+	targets := stringset.New()
+	for _, r := range req.Records {
+		if uint16(r.Type) != dns.TypePTR ||
+			!strings.EqualFold(amassdns.Canonical(r.Name), req.Name) {
+			continue
+		}
+
+		if target := strings.ToLower(amassdns.Canonical(r.Data)); target != "" {
+			targets.Insert(target)
+		}
+	}
+	if targets.Len() < 2 {
+		return
+	}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertMultiPTR(req.Name, targets.Slice(), req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the multiple PTR targets: %v", g, err).With("graph", g))
+			return err
+		}
+		return nil
+	})
 }
 
 func (dms *DataManagerService) insertSRV(ctx context.Context, req *requests.DNSRequest, recidx int) {
@@ -283,29 +1503,35 @@ func (dms *DataManagerService) insertSRV(ctx context.Context, req *requests.DNSR
 		return
 	}
 
-	service := resolvers.RemoveLastDot(req.Records[recidx].Name)
-	target := resolvers.RemoveLastDot(req.Records[recidx].Data)
+	service := amassdns.Canonical(req.Records[recidx].Name)
+	target := amassdns.Canonical(req.Records[recidx].Data)
 	if target == "" || service == "" {
 		return
 	}
 
-	for _, g := range dms.System().GraphDatabases() {
-		if err := g.InsertSRV(req.Name, service, target, req.Source, req.Tag, cfg.UUID.String()); err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s failed to insert SRV record: %v", g, err))
+	stored := dms.recordStored(ctx, req, graph.RecordSRV, service, target)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertSRV(req.Name, service, target, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert SRV record: %v", g, err).With("graph", g))
+			return err
 		}
-	}
+		return stored(g)
+	})
 
 	if domain := cfg.WhichDomain(target); domain != "" {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		dms.republish(ctx, &requests.DNSRequest{
 			Name:   target,
 			Domain: domain,
+			Seed:   requestSeed(req),
+			Depth:  req.Depth + 1,
 			Tag:    req.Tag,
 			Source: req.Source,
-		})
+		}, req.Records[recidx].Authenticated)
 	}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+	dms.SetActive(ctx)
 }
 
 func (dms *DataManagerService) insertNS(ctx context.Context, req *requests.DNSRequest, recidx int) {
@@ -321,7 +1547,7 @@ func (dms *DataManagerService) insertNS(ctx context.Context, req *requests.DNSRe
 		return
 	}
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(target)
+	domain, err := dms.System().Suffixes().RegisteredDomain(target)
 	if err != nil {
 		return
 	}
@@ -331,23 +1557,32 @@ func (dms *DataManagerService) insertNS(ctx context.Context, req *requests.DNSRe
 		return
 	}
 
-	for _, g := range dms.System().GraphDatabases() {
-		if err := g.InsertNS(req.Name, target, req.Source, req.Tag, cfg.UUID.String()); err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s failed to insert NS record: %v", g, err))
+	stored := dms.recordStored(ctx, req, graph.RecordNS, req.Name, target)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertNS(req.Name, target, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert NS record: %v", g, err).With("graph", g))
+			return err
 		}
-	}
+		return stored(g)
+	})
+	dms.checkReputation(ctx, target)
 
-	if target != domain {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+	// The targets shared by the records of a zone are only republished once for the zone
+	if target != domain && (!cfg.DedupNSTargets ||
+		dms.nsTargets.InsertIfAbsent(eventKey(ctx, req.Name+" "+amassdns.Canonical(target)))) {
+		dms.republish(ctx, &requests.DNSRequest{
 			Name:   target,
 			Domain: domain,
+			Seed:   requestSeed(req),
+			Depth:  req.Depth + 1,
 			Tag:    requests.DNS,
 			Source: "DNS",
-		})
+		}, req.Records[recidx].Authenticated)
 	}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+	dms.SetActive(ctx)
 }
 
 func (dms *DataManagerService) insertMX(ctx context.Context, req *requests.DNSRequest, recidx int) {
@@ -357,12 +1592,12 @@ func (dms *DataManagerService) insertMX(ctx context.Context, req *requests.DNSRe
 		return
 	}
 
-	target := resolvers.RemoveLastDot(req.Records[recidx].Data)
+	target := amassdns.Canonical(req.Records[recidx].Data)
 	if target == "" {
 		return
 	}
 
-	domain, err := publicsuffix.EffectiveTLDPlusOne(target)
+	domain, err := dms.System().Suffixes().RegisteredDomain(target)
 	if err != nil {
 		return
 	}
@@ -372,23 +1607,30 @@ func (dms *DataManagerService) insertMX(ctx context.Context, req *requests.DNSRe
 		return
 	}
 
-	for _, g := range dms.System().GraphDatabases() {
-		if err := g.InsertMX(req.Name, target, req.Source, req.Tag, cfg.UUID.String()); err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s failed to insert MX record: %v", g, err))
+	stored := dms.recordStored(ctx, req, graph.RecordMX, req.Name, target)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		if err := g.InsertMX(req.Name, target, req.Source, req.Tag, eventID(ctx)); err != nil {
+			dms.health.failed()
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+				requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert MX record: %v", g, err).With("graph", g))
+			return err
 		}
-	}
+		return stored(g)
+	})
+	dms.checkReputation(ctx, target)
 
 	if target != domain {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		dms.republish(ctx, &requests.DNSRequest{
 			Name:   target,
 			Domain: domain,
+			Seed:   requestSeed(req),
+			Depth:  req.Depth + 1,
 			Tag:    requests.DNS,
 			Source: "DNS",
-		})
+		}, req.Records[recidx].Authenticated)
 	}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+	dms.SetActive(ctx)
 }
 
 func (dms *DataManagerService) insertTXT(ctx context.Context, req *requests.DNSRequest, recidx int) {
@@ -401,7 +1643,28 @@ func (dms *DataManagerService) insertTXT(ctx context.Context, req *requests.DNSR
 		return
 	}
 
-	dms.findNamesAndAddresses(ctx, req.Records[recidx].Data, req.Domain)
+	raw := req.Records[recidx].Data
+	if cfg.StoreRawTXT && raw != "" {
+		dms.writeGraphs(ctx, func(g *graph.Graph) error {
+			if err := g.InsertRawTXT(req.Name, raw, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the raw TXT data: %v", g, err).With("graph", g))
+				return err
+			}
+			return nil
+		})
+	}
+
+	data := sanitizeTXT(raw, strings.EqualFold(cfg.TXTControlChars, "escape"))
+	authenticated := req.Records[recidx].Authenticated
+	dms.findNamesAndAddresses(ctx, data, req, authenticated)
+
+	if cfg.DecodeBase64TXT {
+		for _, payload := range base64TXTPayloads(data) {
+			dms.findNamesAndAddresses(ctx, payload, req, authenticated)
+		}
+	}
 }
 
 func (dms *DataManagerService) insertSPF(ctx context.Context, req *requests.DNSRequest, recidx int) {
@@ -414,28 +1677,124 @@ func (dms *DataManagerService) insertSPF(ctx context.Context, req *requests.DNSR
 		return
 	}
 
-	dms.findNamesAndAddresses(ctx, req.Records[recidx].Data, req.Domain)
+	data := sanitizeTXT(req.Records[recidx].Data, strings.EqualFold(cfg.TXTControlChars, "escape"))
+	dms.findNamesAndAddresses(ctx, data, req, req.Records[recidx].Authenticated)
+}
+
+// sanitizeTXT replaces the control characters in the TXT data, such as embedded newlines and null
+// bytes, with spaces or escapes. The characters are kept as separators, so the surrounding text
+// is not joined into malformed names.
+func sanitizeTXT(data string, escape bool) string {
+	var b strings.Builder
+
+	var sep bool
+	for _, r := range data {
+		if !unicode.IsControl(r) {
+			b.WriteRune(r)
+			sep = false
+			continue
+		}
+
+		if escape {
+			if !sep {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "\\x%02x ", r)
+			sep = true
+		} else if !sep {
+			b.WriteByte(' ')
+			sep = true
+		}
+	}
+	return b.String()
+}
+
+// Only the long TXT tokens are decoded, since short tokens are often valid base64 by chance
+var base64TXTTokenRE = regexp.MustCompile(`[A-Za-z0-9+/_-]{16,}={0,2}`)
+
+// base64TXTPayloads returns the printable text decoded from the base64 tokens within the TXT data.
+// The separators of URLs in the text are replaced with spaces, so the hostnames can be extracted.
+func base64TXTPayloads(data string) []string {
+	var payloads []string
+
+	urlSeparators := strings.NewReplacer("://", " ", "/", " ", "?", " ", "#", " ")
+	for _, token := range base64TXTTokenRE.FindAllString(data, -1) {
+		for _, enc := range []*base64.Encoding{base64.StdEncoding,
+			base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+			decoded, err := enc.DecodeString(token)
+			if err != nil || !printableText(decoded) {
+				continue
+			}
+
+			payloads = append(payloads, urlSeparators.Replace(string(decoded)))
+			break
+		}
+	}
+	return payloads
+}
+
+// printableText returns true when the data is valid UTF-8 without control characters other than whitespace.
+func printableText(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+
+	for _, r := range string(data) {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
 }
 
-func (dms *DataManagerService) findNamesAndAddresses(ctx context.Context, data, domain string) {
+func (dms *DataManagerService) findNamesAndAddresses(ctx context.Context, data string, req *requests.DNSRequest, authenticated bool) {
 	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
 	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
 	if cfg == nil || bus == nil {
 		return
 	}
 
-	ipre := regexp.MustCompile(net.IPv4RE)
-	for _, ip := range ipre.FindAllString(data, -1) {
-		bus.Publish(requests.NewAddrTopic, eventbus.PriorityHigh, &requests.AddrRequest{
-			Address: ip,
-			Domain:  domain,
-			Tag:     requests.DNS,
-			Source:  "DNS",
+	// The geofeed URLs are found before the data is lowercased, since the paths are case sensitive
+	dms.insertGeofeeds(ctx, cfg, data, req)
+	data = strings.ToLower(data)
+
+	for _, ip := range net.FindAllIPs(data) {
+		// The unspecified address is commonly found in text that is not an address
+		if ip.IsUnspecified() {
+			continue
+		}
+
+		addr := ip.String()
+		if !cfg.AggressiveExpansion && !isInternalAddr(cfg, addr) {
+			dms.writeGraphs(ctx, func(g *graph.Graph) error {
+				if err := g.InsertUnexpandedAddress(addr, "DNS", requests.DNS, eventID(ctx)); err != nil {
+					dms.health.failed()
+					dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+						requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the unexpanded address: %v", g, err).With("graph", g))
+					return err
+				}
+				return nil
+			})
+		}
+
+		dms.publishAddr(ctx, cfg, req.Name, &requests.AddrRequest{
+			Address:    addr,
+			Domain:     req.Domain,
+			Tag:        requests.DNS,
+			Source:     "DNS",
+			Incidental: true,
+			NoReverse:  !cfg.ReverseAllowed(addr),
 		})
 	}
 
 	subre := amassdns.AnySubdomainRegex()
-	for _, name := range subre.FindAllString(data, -1) {
+	for _, loc := range subre.FindAllStringIndex(data, -1) {
+		// Reject the matches embedded in longer tokens, such as DKIM keys and verification blobs
+		if !validTXTName(data, loc[0], loc[1]) {
+			continue
+		}
+
+		name := data[loc[0]:loc[1]]
 		if !cfg.IsDomainInScope(name) {
 			continue
 		}
@@ -445,13 +1804,148 @@ func (dms *DataManagerService) findNamesAndAddresses(ctx context.Context, data,
 			continue
 		}
 
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		dms.republish(ctx, &requests.DNSRequest{
 			Name:   name,
 			Domain: domain,
+			Seed:   requestSeed(req),
+			Depth:  req.Depth + 1,
 			Tag:    requests.DNS,
 			Source: "DNS",
-		})
+		}, authenticated)
+	}
+
+	dms.SetActive(ctx)
+}
+
+// validTXTName returns true when the name matched within the TXT record data is not part of a
+// longer alphanumeric or base64 run, and every label of the name is a sane DNS label. The URL
+// delimiters, such as '/', ':' and '=', end a run so names within URLs are still accepted.
+func validTXTName(data string, start, end int) bool {
+	if start > 0 && txtRunChar(data[start-1]) {
+		return false
+	}
+	if end < len(data) && txtRunChar(data[end]) {
+		return false
+	}
+
+	for _, label := range strings.Split(data[start:end], ".") {
+		if label == "" || len(label) > 63 ||
+			strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+func txtRunChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') || c == '+' || c == '-' || c == '_'
+}
+
+// Export writes the records stored in the primary graph database to the io.Writer using the
+// requested format. The optional filter restricts the records exported by domain, record type and
+// the number of data sources that observed the DNS records.
+// Infrastructure records are only exported for addresses resolved by the names within the domains.
+func (dms *DataManagerService) Export(ctx context.Context, w io.Writer, format string, filter *ExportFilter) error {
+	graphs := dms.System().GraphDatabases()
+	if len(graphs) == 0 {
+		return errors.New("Export: No graph databases are available")
+	}
+	if filter == nil {
+		filter = new(ExportFilter)
+	}
+
+	var write func(*graph.Record) error
+	switch strings.ToLower(format) {
+	case ExportJSONL:
+		enc := json.NewEncoder(w)
+		write = func(rec *graph.Record) error {
+			return enc.Encode(rec)
+		}
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		defer cw.Flush()
+
+		if err := cw.Write([]string{"type", "name", "data", "first_seen", "last_seen"}); err != nil {
+			return err
+		}
+		write = func(rec *graph.Record) error {
+			return cw.Write([]string{rec.Type, rec.Name, rec.Data, rec.FirstSeen, rec.LastSeen})
+		}
+	default:
+		return fmt.Errorf("Export: Unsupported format: %s", format)
+	}
+
+	addrs := stringset.New()
+	netblocks := stringset.New()
+	for _, rec := range exportOrder(graphs[0].Records(filter.Types...)) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if filter.MinSources > 0 && rec.Type != graph.RecordNetblock &&
+			rec.Type != graph.RecordASN && rec.Sources < filter.MinSources {
+			continue
+		}
+
+		if len(filter.Domains) > 0 {
+			switch rec.Type {
+			case graph.RecordNetblock:
+				if !addrs.Has(rec.Data) {
+					continue
+				}
+				netblocks.Insert(rec.Name)
+			case graph.RecordASN:
+				if !netblocks.Has(rec.Data) {
+					continue
+				}
+			default:
+				if !exportNameInScope(rec.Name, filter.Domains) {
+					continue
+				}
+				if rec.Type == graph.RecordA || rec.Type == graph.RecordAAAA {
+					addrs.Insert(rec.Data)
+				}
+			}
+		}
+
+		if err := write(rec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportOrder places the infrastructure records after the DNS records they depend on.
+func exportOrder(records []*graph.Record) []*graph.Record {
+	var names, netblocks, asns []*graph.Record
+
+	for _, rec := range records {
+		switch rec.Type {
+		case graph.RecordNetblock:
+			netblocks = append(netblocks, rec)
+		case graph.RecordASN:
+			asns = append(asns, rec)
+		default:
+			names = append(names, rec)
+		}
 	}
 
-	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, dms.String())
+	return append(append(names, netblocks...), asns...)
+}
+
+func exportNameInScope(name string, domains []string) bool {
+	n := amassdns.Canonical(name)
+
+	for _, d := range domains {
+		d = amassdns.Canonical(d)
+
+		if n == d || strings.HasSuffix(n, "."+d) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,54 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestNormalizeASNDescription(t *testing.T) {
+	messy := "  GOOGLE\t-  Google\x00 LLC,\r\n US \xff "
+
+	if got := normalizeASNDescription(messy, false); got != "GOOGLE - Google LLC, US" {
+		t.Errorf("Unexpected normalized description: %q", got)
+	}
+	if got := normalizeASNDescription(messy, true); got != "google - google llc, us" {
+		t.Errorf("Unexpected case-folded description: %q", got)
+	}
+
+	// Descriptions that only differ by formatting must normalize to the same value
+	if normalizeASNDescription("GOOGLE - Google LLC, US", false) != normalizeASNDescription(messy, false) {
+		t.Errorf("The descriptions were not normalized consistently")
+	}
+}
+
+func TestExtendCNAMEPath(t *testing.T) {
+	req := &requests.DNSRequest{Name: "www.owasp.org"}
+
+	for i := 0; i < maxCNAMEPathLen+5; i++ {
+		req = &requests.DNSRequest{
+			Name:      fmt.Sprintf("hop%d.owasp.org", i),
+			CNAMEPath: extendCNAMEPath(req),
+		}
+	}
+
+	if len(req.CNAMEPath) != maxCNAMEPathLen {
+		t.Errorf("Expected the path to be bounded at %d names, got %d", maxCNAMEPathLen, len(req.CNAMEPath))
+	}
+	if req.CNAMEPath[0] != "www.owasp.org" {
+		t.Errorf("Expected the owner name to be kept in the bounded path, got %s", req.CNAMEPath[0])
+	}
+}
+
+func TestSanitizeTXT(t *testing.T) {
+	if s := sanitizeTXT("a\n\x00b", false); s != "a b" {
+		t.Errorf("The control characters were not stripped: %q", s)
+	}
+	if s := sanitizeTXT("a\n\x00b", true); s != `a \x0a \x00 b` {
+		t.Errorf("The control characters were not escaped: %q", s)
+	}
+}
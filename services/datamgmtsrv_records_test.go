@@ -0,0 +1,398 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+func TestDataManagerRecords(t *testing.T) {
+	tests := []struct {
+		label   string
+		name    string
+		records []requests.DNSAnswer
+		edges   []servicetest.Insert
+		names   []string
+		addrs   []string
+	}{
+		{
+			label:   "A record",
+			name:    "www.owasp.org",
+			records: []requests.DNSAnswer{{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "104.22.26.77"}},
+			edges:   []servicetest.Insert{{Subject: "www.owasp.org", Predicate: "a_record", Object: "104.22.26.77"}},
+			addrs:   []string{"104.22.26.77"},
+		},
+		{
+			label:   "AAAA record",
+			name:    "www.owasp.org",
+			records: []requests.DNSAnswer{{Name: "www.owasp.org", Type: int(dns.TypeAAAA), Data: "2606:4700:10::6816:1a4d"}},
+			edges:   []servicetest.Insert{{Subject: "www.owasp.org", Predicate: "aaaa_record", Object: "2606:4700:10::6816:1a4d"}},
+			addrs:   []string{"2606:4700:10::6816:1a4d"},
+		},
+		{
+			label:   "CNAME record",
+			name:    "static.owasp.org",
+			records: []requests.DNSAnswer{{Name: "static.owasp.org", Type: int(dns.TypeCNAME), Data: "www.owasp.org."}},
+			edges:   []servicetest.Insert{{Subject: "static.owasp.org", Predicate: "cname_record", Object: "www.owasp.org"}},
+			names:   []string{"www.owasp.org"},
+		},
+		{
+			label:   "PTR record",
+			name:    "10.2.0.192.in-addr.arpa",
+			records: []requests.DNSAnswer{{Name: "10.2.0.192.in-addr.arpa", Type: int(dns.TypePTR), Data: "www.owasp.org."}},
+			edges:   []servicetest.Insert{{Subject: "10.2.0.192.in-addr.arpa", Predicate: "ptr_record", Object: "www.owasp.org"}},
+			names:   []string{"www.owasp.org"},
+		},
+		{
+			label:   "Out of scope PTR record",
+			name:    "10.2.0.192.in-addr.arpa",
+			records: []requests.DNSAnswer{{Name: "10.2.0.192.in-addr.arpa", Type: int(dns.TypePTR), Data: "www.example.com."}},
+		},
+		{
+			label:   "NS record",
+			name:    "owasp.org",
+			records: []requests.DNSAnswer{{Name: "owasp.org", Type: int(dns.TypeNS), Data: "ns1.owasp.org."}},
+			edges:   []servicetest.Insert{{Subject: "owasp.org", Predicate: "ns_record", Object: "ns1.owasp.org"}},
+			names:   []string{"ns1.owasp.org"},
+		},
+		{
+			label:   "MX record",
+			name:    "owasp.org",
+			records: []requests.DNSAnswer{{Name: "owasp.org", Type: int(dns.TypeMX), Data: "mail.owasp.org."}},
+			edges:   []servicetest.Insert{{Subject: "owasp.org", Predicate: "mx_record", Object: "mail.owasp.org"}},
+			names:   []string{"mail.owasp.org"},
+		},
+		{
+			label:   "SRV record",
+			name:    "owasp.org",
+			records: []requests.DNSAnswer{{Name: "_sip._tcp.owasp.org", Type: int(dns.TypeSRV), Data: "sip.owasp.org."}},
+			edges: []servicetest.Insert{
+				{Subject: "_sip._tcp.owasp.org", Predicate: "service", Object: "owasp.org"},
+				{Subject: "_sip._tcp.owasp.org", Predicate: "srv_record", Object: "sip.owasp.org"},
+			},
+			names: []string{"sip.owasp.org"},
+		},
+		{
+			label:   "TXT record",
+			name:    "owasp.org",
+			records: []requests.DNSAnswer{{Name: "owasp.org", Type: int(dns.TypeTXT), Data: "v=spf1 include:mail.owasp.org ip4:104.22.27.77 ~all"}},
+			names:   []string{"mail.owasp.org"},
+			addrs:   []string{"104.22.27.77"},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := config.NewConfig()
+		cfg.AddDomain("owasp.org")
+
+		h := servicetest.NewHarness(cfg)
+		names := servicetest.CaptureTopic(h.Bus, requests.NewNameTopic)
+		addrs := servicetest.CaptureTopic(h.Bus, requests.NewAddrTopic)
+
+		dms := services.NewDataManagerService(h.Sys)
+		err := servicetest.ProcessDNSRequest(h.Ctx, dms, &requests.DNSRequest{
+			Name:    test.name,
+			Domain:  cfg.WhichDomain(test.name),
+			Records: test.records,
+			Tag:     requests.DNS,
+			Source:  "DNS",
+		})
+		if err != nil {
+			t.Errorf("%s: %v", test.label, err)
+			h.Close()
+			continue
+		}
+
+		for _, edge := range test.edges {
+			if !hasEdge(h.Sys.DB(), edge) {
+				t.Errorf("%s: Expected the %s edge from %s to %s", test.label, edge.Predicate, edge.Subject, edge.Object)
+			}
+		}
+		if len(test.edges) == 0 {
+			for _, pred := range []string{"ptr_record", "cname_record", "ns_record", "mx_record", "srv_record"} {
+				if edges := h.Sys.DB().Edges(pred); len(edges) > 0 {
+					t.Errorf("%s: Unexpected %s edges were inserted: %v", test.label, pred, edges)
+				}
+			}
+		}
+
+		names.Wait(len(test.names), time.Second)
+		var published []string
+		for _, req := range names.DNSRequests() {
+			published = append(published, req.Name)
+		}
+		if !sameStrings(published, test.names) {
+			t.Errorf("%s: Expected the names %v to be published, got %v", test.label, test.names, published)
+		}
+
+		addrs.Wait(len(test.addrs), time.Second)
+		published = nil
+		for _, req := range addrs.AddrRequests() {
+			published = append(published, req.Address)
+		}
+		if !sameStrings(published, test.addrs) {
+			t.Errorf("%s: Expected the addresses %v to be published, got %v", test.label, test.addrs, published)
+		}
+
+		h.Close()
+	}
+}
+
+func TestDataManagerClampTTL(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.MaxTTL = 86400
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+	logs := servicetest.CaptureTopic(h.Bus, requests.LogTopic)
+
+	req := &requests.DNSRequest{
+		Name:   "www.owasp.org",
+		Domain: "owasp.org",
+		Records: []requests.DNSAnswer{
+			{Name: "www.owasp.org", Type: int(dns.TypeA), TTL: 4000000000, Data: "192.0.2.10"},
+			{Name: "www.owasp.org", Type: int(dns.TypeA), TTL: -1, Data: "192.0.2.11"},
+			{Name: "www.owasp.org", Type: int(dns.TypeA), TTL: 300, Data: "192.0.2.12"},
+		},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	}
+
+	dms := services.NewDataManagerService(h.Sys)
+	if err := servicetest.ProcessDNSRequest(h.Ctx, dms, req); err != nil {
+		t.Fatal(err)
+	}
+
+	// The records of the shared request are left untouched
+	for i, expected := range []int{4000000000, -1, 300} {
+		if ttl := req.Records[i].TTL; ttl != expected {
+			t.Errorf("Expected the TTL of record %d to remain %d, got %d", i, expected, ttl)
+		}
+	}
+
+	logs.Wait(2, time.Second)
+	var clamped []string
+	for _, entry := range logs.LogEntries() {
+		if strings.HasPrefix(entry.Message, "Clamped the TTL") {
+			clamped = append(clamped, entry.Message)
+		}
+	}
+	if !sameStrings(clamped, []string{
+		"Clamped the TTL 4000000000 of the A record for www.owasp.org to 86400",
+		"Clamped the TTL -1 of the A record for www.owasp.org to 0",
+	}) {
+		t.Errorf("Expected the 2 clamped TTLs to be logged, got %v", clamped)
+	}
+}
+
+func TestDataManagerSkipSPF(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.SkipSPF = true
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+	names := servicetest.CaptureTopic(h.Bus, requests.NewNameTopic)
+
+	dms := services.NewDataManagerService(h.Sys)
+	err := servicetest.ProcessDNSRequest(h.Ctx, dms, &requests.DNSRequest{
+		Name:   "owasp.org",
+		Domain: "owasp.org",
+		Records: []requests.DNSAnswer{
+			{Name: "owasp.org", Type: int(dns.TypeSPF), Data: "v=spf1 include:spf.owasp.org ~all"},
+			{Name: "owasp.org", Type: int(dns.TypeTXT), Data: "v=spf1 include:txt.owasp.org ~all"},
+		},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names.Wait(2, time.Second)
+	var published []string
+	for _, req := range names.DNSRequests() {
+		published = append(published, req.Name)
+	}
+	if !sameStrings(published, []string{"txt.owasp.org"}) {
+		t.Errorf("Expected only the TXT record to be processed, got the names %v", published)
+	}
+}
+
+func TestDataManagerDedupNSTargets(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.DedupNSTargets = true
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+	names := servicetest.CaptureTopic(h.Bus, requests.NewNameTopic)
+
+	ns := func(zone string, targets ...string) []requests.DNSAnswer {
+		var records []requests.DNSAnswer
+		for _, target := range targets {
+			records = append(records, requests.DNSAnswer{Name: zone, Type: int(dns.TypeNS), Data: target})
+		}
+		return records
+	}
+
+	dms := services.NewDataManagerService(h.Sys)
+	for _, req := range []*requests.DNSRequest{
+		{Name: "owasp.org", Records: ns("owasp.org", "ns1.owasp.org.", "ns2.owasp.org.", "NS1.owasp.org")},
+		{Name: "dev.owasp.org", Records: ns("dev.owasp.org", "ns1.owasp.org.", "ns2.owasp.org.")},
+		{Name: "owasp.org", Records: ns("owasp.org", "ns1.owasp.org.", "ns3.owasp.org.")},
+	} {
+		req.Domain = "owasp.org"
+		req.Tag = requests.DNS
+		req.Source = "DNS"
+		if err := servicetest.ProcessDNSRequest(h.Ctx, dms, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"ns1.owasp.org", "ns2.owasp.org", "ns1.owasp.org", "ns2.owasp.org", "ns3.owasp.org"}
+	names.Wait(len(expected)+1, time.Second)
+	var published []string
+	for _, req := range names.DNSRequests() {
+		published = append(published, req.Name)
+	}
+	if !sameStrings(published, expected) {
+		t.Errorf("Expected each NS target to be republished once per zone, got %v", published)
+	}
+
+	for _, edge := range []servicetest.Insert{
+		{Subject: "owasp.org", Predicate: "ns_record", Object: "ns3.owasp.org"},
+		{Subject: "dev.owasp.org", Predicate: "ns_record", Object: "ns1.owasp.org"},
+		{Subject: "dev.owasp.org", Predicate: "ns_record", Object: "ns2.owasp.org"},
+	} {
+		if !hasEdge(h.Sys.DB(), edge) {
+			t.Errorf("Expected the %s edge from %s to %s", edge.Predicate, edge.Subject, edge.Object)
+		}
+	}
+	if n := len(h.Sys.DB().Edges("ns_record")); n < 7 {
+		t.Errorf("Expected each of the 7 NS records to be stored, got %d edges", n)
+	}
+
+	// Another enumeration sharing the data manager republishes the targets for the zone
+	h.Process(t, dms, &requests.DNSRequest{
+		Name:    "owasp.org",
+		Domain:  "owasp.org",
+		Records: ns("owasp.org", "ns1.owasp.org."),
+		Tag:     requests.DNS,
+		Source:  "DNS",
+		EventID: "other-event",
+	})
+	if !servicetest.Drain(h.Bus, servicetest.WaitTimeout) {
+		t.Fatal("The events published on the bus were not dispatched")
+	}
+	if reqs := names.DNSRequests(); len(reqs) != len(expected)+1 || reqs[len(reqs)-1].Name != "ns1.owasp.org" {
+		t.Errorf("The NS target was not republished for the other event: %v", reqs)
+	}
+}
+
+func TestDataManagerPublishTap(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		cfg := config.NewConfig()
+		cfg.AddDomain("owasp.org")
+		cfg.DebugPublishes = debug
+
+		h := servicetest.NewHarness(cfg)
+		dms := services.NewDataManagerService(h.Sys)
+		err := servicetest.ProcessDNSRequest(h.Ctx, dms, &requests.DNSRequest{
+			Name:    "www.owasp.org",
+			Domain:  "owasp.org",
+			Records: []requests.DNSAnswer{{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "104.22.26.77"}},
+			Tag:     requests.DNS,
+			Source:  "DNS",
+		})
+		h.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		published := dms.Published()
+		if !debug {
+			if len(published) != 0 {
+				t.Errorf("The publishes were observed without the debug option: %v", published)
+			}
+			continue
+		}
+
+		if n := published[services.PublishKey{Topic: requests.NewAddrTopic, Priority: eventbus.PriorityHigh}]; n != 1 {
+			t.Errorf("Expected one publish on the NewAddr topic, got %d", n)
+		}
+		// The activity publications are limited to one each ActivityInterval
+		if n := published[services.PublishKey{Topic: requests.SetActiveTopic, Priority: eventbus.PriorityCritical}]; n != 1 {
+			t.Errorf("Expected the SetActive topic to be published once for the request and the record, got %d", n)
+		}
+		for key := range published {
+			if key.Topic == requests.LogTopic || key.Topic == requests.NewNameTopic {
+				t.Errorf("Unexpected publish on the %s topic", key.Topic)
+			}
+		}
+	}
+}
+
+func TestDataManagerGeofeedTXT(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+
+	dms := services.NewDataManagerService(h.Sys)
+	h.Process(t, dms, &requests.DNSRequest{
+		Name:   "owasp.org",
+		Domain: "owasp.org",
+		Records: []requests.DNSAnswer{
+			{Name: "owasp.org", Type: int(dns.TypeTXT), Data: "Geofeed https://www.owasp.org/Feeds/IP.csv"},
+			{Name: "owasp.org", Type: int(dns.TypeTXT), Data: "v=spf1 include:_spf.owasp.org ~all"},
+		},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	})
+
+	expected := []string{"https://www.owasp.org/Feeds/IP.csv"}
+	if got := h.Sys.Graph().ReadGeofeeds("owasp.org"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the geofeed URLs %v, got %v", expected, got)
+	}
+}
+
+func hasEdge(rdb *servicetest.RecordingDB, edge servicetest.Insert) bool {
+	for _, e := range rdb.Edges(edge.Predicate) {
+		if e.Subject == edge.Subject && e.Object == edge.Object {
+			return true
+		}
+	}
+	return false
+}
+
+func sameStrings(got, expected []string) bool {
+	if len(got) != len(expected) {
+		return false
+	}
+
+	got = append([]string(nil), got...)
+	expected = append([]string(nil), expected...)
+	sort.Strings(got)
+	sort.Strings(expected)
+	for i := range got {
+		if got[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
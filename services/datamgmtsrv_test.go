@@ -0,0 +1,1300 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/OWASP/Amass/v3/stringset"
+	"github.com/miekg/dns"
+)
+
+// dataManagerTest is a DataManagerService for the owasp.org domain, the requests handled by
+// the service and the checks performed once the requests were handled.
+type dataManagerTest struct {
+	label string
+	// configure changes the configuration before the harness is created
+	configure func(cfg *config.Config)
+	// setup prepares the harness before the service is created
+	setup    func(t *testing.T, h *servicetest.Harness)
+	requests []*requests.DNSRequest
+	check    func(t *testing.T, env *dataManagerEnv)
+}
+
+// dataManagerEnv is the harness of a dataManagerTest, along with the service and the names
+// and addresses published by the service.
+type dataManagerEnv struct {
+	*servicetest.Harness
+	dms   *services.DataManagerService
+	names *servicetest.Capture
+	addrs *servicetest.Capture
+}
+
+func (env *dataManagerEnv) process(t *testing.T, reqs ...*requests.DNSRequest) {
+	t.Helper()
+
+	env.Process(t, env.dms, reqs...)
+}
+
+// drain waits for the events published so far to reach the subscribers.
+func (env *dataManagerEnv) drain(t *testing.T) {
+	t.Helper()
+
+	if !servicetest.Drain(env.Bus, servicetest.WaitTimeout) {
+		t.Fatal("The events published on the bus were not dispatched")
+	}
+}
+
+// nameRequests returns the name requests published so far.
+func (env *dataManagerEnv) nameRequests(t *testing.T) []*requests.DNSRequest {
+	t.Helper()
+
+	env.drain(t)
+	return env.names.DNSRequests()
+}
+
+// publishedNames returns the names published so far.
+func (env *dataManagerEnv) publishedNames(t *testing.T) []string {
+	t.Helper()
+
+	var names []string
+	for _, req := range env.nameRequests(t) {
+		names = append(names, req.Name)
+	}
+	return names
+}
+
+// addrRequests returns the address requests published so far.
+func (env *dataManagerEnv) addrRequests(t *testing.T) []*requests.AddrRequest {
+	t.Helper()
+
+	env.drain(t)
+	return env.addrs.AddrRequests()
+}
+
+// publishedAddrs returns the addresses published so far.
+func (env *dataManagerEnv) publishedAddrs(t *testing.T) []string {
+	t.Helper()
+
+	var addrs []string
+	for _, req := range env.addrRequests(t) {
+		addrs = append(addrs, req.Address)
+	}
+	return addrs
+}
+
+func runDataManagerTests(t *testing.T, tests []dataManagerTest) {
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.AddDomain("owasp.org")
+			if test.configure != nil {
+				test.configure(cfg)
+			}
+
+			h := servicetest.NewHarness(cfg)
+			defer h.Close()
+			if test.setup != nil {
+				test.setup(t, h)
+			}
+
+			env := &dataManagerEnv{
+				Harness: h,
+				names:   servicetest.CaptureTopic(h.Bus, requests.NewNameTopic),
+				addrs:   servicetest.CaptureTopic(h.Bus, requests.NewAddrTopic),
+			}
+			env.dms = services.NewDataManagerService(h.Sys)
+
+			env.process(t, test.requests...)
+			if test.check != nil {
+				test.check(t, env)
+			}
+		})
+	}
+}
+
+// dnsRequest returns the request for the name within owasp.org answered by the DNS service.
+func dnsRequest(name string, records ...requests.DNSAnswer) *requests.DNSRequest {
+	return &requests.DNSRequest{
+		Name:    name,
+		Domain:  "owasp.org",
+		Records: records,
+		Tag:     requests.DNS,
+		Source:  "DNS",
+	}
+}
+
+func answer(name string, rrtype uint16, data string) requests.DNSAnswer {
+	return requests.DNSAnswer{Name: name, Type: int(rrtype), Data: data}
+}
+
+func TestDataManagerService(t *testing.T) {
+	tests := []dataManagerTest{
+		{
+			label: "Export",
+			check: checkExport,
+		},
+		{
+			label: "TXT names within blobs",
+			requests: []*requests.DNSRequest{
+				dnsRequest("owasp.org",
+					answer("owasp.org", dns.TypeTXT, "v=dkim1; k=rsa; p=migfma0gcsqgsib3dqebaquaa4gnadcbiqkbgqc+xk2.owasp.org/q7vkz0gq"+
+						"3zlyq2xxmgtrzz9byoaqt0dvmwsuybkbksbqzrgy4vo8krxdtdsbyq2kxfrdbuzgqa7e8.owasp.org9ab=="),
+					answer("owasp.org", dns.TypeTXT, "v=spf1 include:_spf.owasp.org ~all"),
+					// The names within URLs are delimited by the URL syntax
+					answer("owasp.org", dns.TypeTXT, "verify=https://www.owasp.org/verify?token=a1b2"),
+					answer("owasp.org", dns.TypeTXT, "ms=https://login.owasp.org"),
+				),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				expected := []string{"_spf.owasp.org", "www.owasp.org", "login.owasp.org"}
+				if names := env.publishedNames(t); !sameStrings(names, expected) {
+					t.Errorf("Expected only the names %v to be published, got %v", expected, names)
+				}
+			},
+		},
+		{
+			label: "Seed through CNAME",
+			requests: []*requests.DNSRequest{{
+				Name:    "www.example.com",
+				Domain:  "example.com",
+				Records: []requests.DNSAnswer{answer("www.example.com", dns.TypeCNAME, "www.example.net")},
+				Tag:     requests.DNS,
+				Source:  "DNS",
+			}},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				reqs := env.nameRequests(t)
+				if len(reqs) != 1 || reqs[0].Name != "www.example.net" || reqs[0].Seed != "example.com" {
+					t.Fatalf("The CNAME target was not published with the seed: %v", reqs)
+				}
+
+				// Resolve the target as the DNS service would
+				target := reqs[0]
+				target.Records = []requests.DNSAnswer{answer("www.example.net", dns.TypeA, "93.184.216.34")}
+				env.process(t, target)
+
+				g := env.Sys.Graph()
+				for _, name := range []string{"www.example.com", "www.example.net"} {
+					if seeds := g.ReadSeeds(name); len(seeds) != 1 || seeds[0] != "example.com" {
+						t.Errorf("Expected %s to be stored with the example.com seed, got %v", name, seeds)
+					}
+				}
+			},
+		},
+		{
+			label: "Record set signature",
+			check: checkRecordSetSignature,
+		},
+		{
+			label: "Denylist",
+			setup: func(t *testing.T, h *servicetest.Harness) {
+				if err := h.Sys.Config().SetDenylist([]string{`(^|\.)pingdom\.com$`, `^noise\.`}); err != nil {
+					t.Fatalf("Failed to set the denylist: %v", err)
+				}
+			},
+			requests: []*requests.DNSRequest{
+				dnsRequest("status.owasp.org", answer("status.owasp.org", dns.TypeCNAME, "stats.pingdom.com")),
+				dnsRequest("stats.pingdom.com", answer("stats.pingdom.com", dns.TypeA, "23.22.39.120")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				if records := g.NameRecords("status.owasp.org"); len(records) != 0 {
+					t.Errorf("The denylisted CNAME target was stored: %v", records)
+				}
+				if records := g.NameRecords("stats.pingdom.com"); len(records) != 0 {
+					t.Errorf("The denylisted name was stored: %v", records)
+				}
+				if denied := env.dms.Denied(); denied != 2 {
+					t.Errorf("Expected 2 denied records, got %d", denied)
+				}
+
+				// Names derived from the records must not be re-published either
+				env.process(t, dnsRequest("owasp.org", answer("owasp.org", dns.TypeTXT, "v=spf1 include:noise.owasp.org ~all")))
+				if names := env.publishedNames(t); len(names) != 0 {
+					t.Errorf("The denylisted names %v were published", names)
+				}
+				if denied := env.dms.Denied(); denied != 3 {
+					t.Errorf("Expected 3 denied names and records, got %d", denied)
+				}
+			},
+		},
+		{
+			label:     "Link known ports",
+			configure: func(cfg *config.Config) { cfg.LinkKnownPorts = true },
+			setup: func(t *testing.T, h *servicetest.Harness) {
+				err := h.Sys.Graph().InsertOpenPort("104.22.26.77", 443, "Scan", "active", h.Sys.Config().UUID.String())
+				if err != nil {
+					t.Fatalf("Failed to insert the open port: %v", err)
+				}
+			},
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeA, "104.22.26.77")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				if ports := env.Sys.Graph().ReadNamePorts("www.owasp.org"); len(ports) != 1 || ports[0] != 443 {
+					t.Errorf("The name was not linked to the open ports of the address: %v", ports)
+				}
+			},
+		},
+		{
+			label: "Authoritative server",
+			// The server is stored as the target of the NS record before answering the zone transfer
+			requests: []*requests.DNSRequest{
+				dnsRequest("owasp.org", answer("owasp.org", dns.TypeNS, "ns1.owasp.org.")),
+				zoneTransferRequest("NS1.OWASP.ORG."),
+				zoneTransferRequest(""),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				servers := env.Sys.Graph().ReadAuthServers("www.owasp.org")
+				if len(servers) != 1 || servers[0] != "ns1.owasp.org" {
+					t.Errorf("The authoritative server was not stored: %v", servers)
+				}
+			},
+		},
+		{
+			label:     "Multiple PTR records",
+			configure: func(cfg *config.Config) { cfg.MultiPTR = true },
+			requests: []*requests.DNSRequest{{
+				Name:   "77.26.22.104.in-addr.arpa",
+				Domain: "owasp.org",
+				Records: []requests.DNSAnswer{
+					answer("77.26.22.104.in-addr.arpa", dns.TypePTR, "www.owasp.org."),
+					// The targets are stored in the canonical form
+					answer("77.26.22.104.in-addr.arpa", dns.TypePTR, "Mail.OWASP.org."),
+					answer("77.26.22.104.in-addr.arpa.", dns.TypePTR, "shared.hosting.net."),
+				},
+				Tag:    requests.DNS,
+				Source: "Reverse DNS",
+			}},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				if !g.IsMultiPTR("77.26.22.104.in-addr.arpa") {
+					t.Errorf("The multiple PTR records were not flagged")
+				}
+
+				expected := []string{"mail.owasp.org", "shared.hosting.net", "www.owasp.org"}
+				if targets := g.ReadPTRTargets("77.26.22.104.in-addr.arpa"); !reflect.DeepEqual(targets, expected) {
+					t.Errorf("Expected the PTR targets %v, got %v", expected, targets)
+				}
+				if names := env.publishedNames(t); !sameStrings(names, []string{"www.owasp.org", "mail.owasp.org"}) {
+					t.Errorf("Expected only the in-scope PTR targets to be published, got %v", names)
+				}
+			},
+		},
+		{
+			label: "Cloud service",
+			requests: []*requests.DNSRequest{
+				dnsRequest("static.owasp.org", answer("static.owasp.org", dns.TypeCNAME, "owasp-static-a1b2c3.s3.amazonaws.com.")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				if service := g.ReadCloudService("owasp-static-a1b2c3.s3.amazonaws.com"); service != "aws-s3" {
+					t.Errorf("Expected the CNAME target to be tagged as aws-s3, got %q", service)
+				}
+				if service := g.ReadCloudService("static.owasp.org"); service != "" {
+					t.Errorf("The CNAME owner was tagged as the cloud service %q", service)
+				}
+			},
+		},
+		{
+			label: "CDN providers",
+			setup: func(t *testing.T, h *servicetest.Harness) {
+				if err := h.Sys.Config().AddCDN("examplecdn", "45.60.0.0/16"); err != nil {
+					t.Fatalf("AddCDN failed: %v", err)
+				}
+			},
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeA, "45.60.12.34")),
+				dnsRequest("static.owasp.org", answer("static.owasp.org", dns.TypeCNAME, "static.owasp.org.cdn.cloudflare.net.")),
+				dnsRequest("origin.owasp.org", answer("origin.owasp.org", dns.TypeA, "8.8.8.8")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				for name, expected := range map[string]string{
+					"www.owasp.org":    "examplecdn",
+					"static.owasp.org": "cloudflare",
+					"origin.owasp.org": "",
+				} {
+					if provider := env.Sys.Graph().ReadCDN(name); provider != expected {
+						t.Errorf("Expected %s to be tagged with the CDN provider %q, got %q", name, expected, provider)
+					}
+				}
+			},
+		},
+		{
+			label:     "Require DNSSEC",
+			configure: func(cfg *config.Config) { cfg.RequireDNSSEC = true },
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org", requests.DNSAnswer{
+					Name: "www.owasp.org", Type: int(dns.TypeCNAME), Data: "signed.owasp.org.", Authenticated: true,
+				}),
+				dnsRequest("mail.owasp.org", answer("mail.owasp.org", dns.TypeCNAME, "unsigned.owasp.org.")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				if names := env.publishedNames(t); !sameStrings(names, []string{"signed.owasp.org"}) {
+					t.Errorf("Expected only the target of the authenticated record to be re-published, got %v", names)
+				}
+				if n := env.dms.Unauthenticated(); n != 1 {
+					t.Errorf("Expected 1 name suppressed for not being authenticated, got %d", n)
+				}
+
+				g := env.Sys.Graph()
+				if !g.IsAuthenticatedRecord("www.owasp.org", "CNAME", "signed.owasp.org") {
+					t.Errorf("The authenticated record was not marked in the graph")
+				}
+				if g.IsAuthenticatedRecord("mail.owasp.org", "CNAME", "unsigned.owasp.org") {
+					t.Errorf("The record without the authenticated-data flag was marked in the graph")
+				}
+			},
+		},
+		{
+			label: "Maximum records per domain",
+			configure: func(cfg *config.Config) {
+				cfg.AddDomain("example.com")
+				cfg.MaxRecordsPerDomain = 2
+			},
+			requests: []*requests.DNSRequest{
+				dnsRequest("a.owasp.org", answer("a.owasp.org", dns.TypeA, "8.8.8.1")),
+				dnsRequest("b.owasp.org", answer("b.owasp.org", dns.TypeA, "8.8.8.2")),
+				dnsRequest("c.owasp.org", answer("c.owasp.org", dns.TypeA, "8.8.8.3")),
+				dnsRequest("d.owasp.org", answer("d.owasp.org", dns.TypeA, "8.8.8.4")),
+				{
+					Name:    "www.example.com",
+					Domain:  "example.com",
+					Records: []requests.DNSAnswer{answer("www.example.com", dns.TypeA, "8.8.8.5")},
+					Tag:     requests.DNS,
+					Source:  "DNS",
+				},
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				for _, name := range []string{"a.owasp.org", "b.owasp.org", "www.example.com"} {
+					if records := g.NameRecords(name); len(records) == 0 {
+						t.Errorf("The record for %s was dropped before the cap was reached", name)
+					}
+				}
+				for _, name := range []string{"c.owasp.org", "d.owasp.org"} {
+					if records := g.NameRecords(name); len(records) != 0 {
+						t.Errorf("The record for %s was stored after the cap was reached: %v", name, records)
+					}
+				}
+
+				if !env.dms.Capped(env.Ctx, "owasp.org") || !g.IsCappedDomain("owasp.org") {
+					t.Errorf("The domain owasp.org was not flagged as capped")
+				}
+				if env.dms.Capped(env.Ctx, "example.com") || g.IsCappedDomain("example.com") {
+					t.Errorf("The domain example.com was flagged as capped")
+				}
+
+				// The records of another enumeration sharing the data manager count toward its own cap
+				other := dnsRequest("e.owasp.org", answer("e.owasp.org", dns.TypeA, "8.8.8.6"))
+				other.EventID = "other-event"
+				env.process(t, other)
+				if records := g.NameRecords("e.owasp.org"); len(records) == 0 {
+					t.Errorf("The record for the other event was dropped by the cap of the first event")
+				}
+			},
+		},
+		{
+			label:     "Link CNAME targets",
+			configure: func(cfg *config.Config) { cfg.LinkCNAMETargets = true },
+			setup: func(t *testing.T, h *servicetest.Harness) {
+				h.Sys.SetPool(&stubResolver{addrs: map[string]string{"owasp.cdn.example.net": "192.0.2.10"}})
+			},
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeCNAME, "owasp.cdn.example.net.")),
+				dnsRequest("static.owasp.org", answer("static.owasp.org", dns.TypeCNAME, "owasp.cdn.example.net.")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				// The CNAME target is resolved after the requests were handled
+				if !env.Sys.DB().WaitEdges("target_address", 2, servicetest.WaitTimeout) {
+					t.Fatalf("The CNAME target addresses were not linked")
+				}
+
+				for _, name := range []string{"www.owasp.org", "static.owasp.org"} {
+					if addrs := env.Sys.Graph().ReadTargetAddresses(name); len(addrs) != 1 || addrs[0] != "192.0.2.10" {
+						t.Errorf("Expected the CNAME target address to be linked to %s, got %v", name, addrs)
+					}
+				}
+				if env.Sys.Config().IsDomainInScope("owasp.cdn.example.net") {
+					t.Errorf("The CNAME target domain was added to the scope")
+				}
+			},
+		},
+		{
+			label: "Import zone file",
+			check: checkImportZoneFile,
+		},
+		{
+			label: "Internationalized names",
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.bücher.owasp.org", answer("www.bücher.owasp.org", dns.TypeA, "192.0.2.10")),
+				dnsRequest("www.xn--bcher-kva.owasp.org", answer("www.xn--bcher-kva.owasp.org", dns.TypeA, "192.0.2.10")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				if records := g.NameRecords("www.bücher.owasp.org"); len(records) != 0 {
+					t.Errorf("The Unicode form of the name was stored: %v", records)
+				}
+				if records := g.NameRecords("www.xn--bcher-kva.owasp.org"); len(records) != 1 {
+					t.Errorf("Expected a single A record for the punycode name, got %v", records)
+				}
+				if u := g.ReadUnicodeName("www.xn--bcher-kva.owasp.org"); u != "www.bücher.owasp.org" {
+					t.Errorf("Expected the Unicode form to be stored for display, got %q", u)
+				}
+			},
+		},
+		{
+			label: "Shared DNS request",
+			check: checkSharedDNSRequest,
+		},
+		fanOutTest(services.FanOutQueue),
+		fanOutTest(services.FanOutDrop),
+		{
+			label:     "Record HMAC",
+			configure: func(cfg *config.Config) { cfg.RecordHMACKey = "forensics" },
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeA, "192.0.2.10")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				key := []byte(env.Sys.Config().RecordHMACKey)
+				if !g.VerifyRecord(key, graph.RecordA, "www.owasp.org", "192.0.2.10") {
+					t.Errorf("The HMAC of the stored record did not verify")
+				}
+
+				// A record altered after the collection does not have a matching HMAC
+				if err := g.InsertA("www.owasp.org", "192.0.2.66", "DNS", requests.DNS, env.Sys.Config().UUID.String()); err != nil {
+					t.Fatalf("Failed to insert the A record: %v", err)
+				}
+				if unverified := g.UnverifiedRecords(key); len(unverified) != 1 || unverified[0].Data != "192.0.2.66" {
+					t.Errorf("Expected the tampered record to fail the verification, got %v", unverified)
+				}
+			},
+		},
+		{
+			label: "Passive-only names",
+			check: checkPassiveOnlyNames,
+		},
+		{
+			label:     "Leaf addresses",
+			configure: func(cfg *config.Config) { cfg.LeafAddresses = true },
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org",
+					answer("www.owasp.org", dns.TypeA, "104.22.26.77"),
+					answer("www.owasp.org", dns.TypeAAAA, "2606:4700:10::6816:1a4d"),
+				),
+				dnsRequest("owasp.org", answer("owasp.org", dns.TypeTXT, "v=spf1 ip4:192.168.1.1 ~all")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				if records := env.Sys.Graph().NameRecords("www.owasp.org"); len(records) != 2 {
+					t.Errorf("Expected the A and AAAA records to be stored, got %v", records)
+				}
+				if addrs := env.publishedAddrs(t); len(addrs) != 0 {
+					t.Errorf("The addresses %v were published with leaf addresses enabled", addrs)
+				}
+			},
+		},
+		{
+			label: "Reserved addresses",
+			requests: []*requests.DNSRequest{
+				dnsRequest("intranet.owasp.org",
+					answer("intranet.owasp.org", dns.TypeA, "10.1.2.3"),
+					answer("intranet.owasp.org", dns.TypeAAAA, "fd00::1"),
+				),
+				dnsRequest("owasp.org", answer("owasp.org", dns.TypeTXT, "v=spf1 ip4:127.0.0.1 ip4:104.22.26.77 ~all")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				if records := g.NameRecords("intranet.owasp.org"); len(records) != 2 {
+					t.Errorf("Expected the A and AAAA records to be stored, got %v", records)
+				}
+				for _, addr := range []string{"10.1.2.3", "fd00::1"} {
+					if !g.IsInternalAddress(addr) {
+						t.Errorf("The address %s was not tagged as internal", addr)
+					}
+				}
+
+				if addrs := env.publishedAddrs(t); !sameStrings(addrs, []string{"104.22.26.77"}) {
+					t.Errorf("Expected only the public address to be published, got %v", addrs)
+				}
+				if internal := env.dms.Internal(); internal != 3 {
+					t.Errorf("Expected 3 internal addresses to be counted, got %d", internal)
+				}
+			},
+		},
+		{
+			label:     "Classify names",
+			configure: func(cfg *config.Config) { cfg.ClassifyNames = true },
+			requests: []*requests.DNSRequest{
+				dnsRequest("intranet.owasp.org",
+					answer("intranet.owasp.org", dns.TypeA, "10.1.2.3"),
+					answer("intranet.owasp.org", dns.TypeA, "10.1.2.4"),
+				),
+				dnsRequest("vpn.owasp.org", answer("vpn.owasp.org", dns.TypeA, "10.1.2.5")),
+				dnsRequest("vpn.owasp.org", answer("vpn.owasp.org", dns.TypeA, "104.22.26.77")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				if exposure := g.ReadNameExposure("intranet.owasp.org"); exposure != graph.InternalName {
+					t.Errorf("Expected the name with only 10.x addresses to be internal, got %q", exposure)
+				}
+				if exposure := g.ReadNameExposure("vpn.owasp.org"); exposure != graph.ExternalName {
+					t.Errorf("Expected the name with a public address to be external, got %q", exposure)
+				}
+			},
+		},
+		{
+			label:     "Decode base64 TXT",
+			configure: func(cfg *config.Config) { cfg.DecodeBase64TXT = true },
+			requests: []*requests.DNSRequest{
+				dnsRequest("owasp.org", answer("owasp.org", dns.TypeTXT,
+					"verification="+base64.StdEncoding.EncodeToString([]byte(`{"callback":"https://login.owasp.org/oauth"}`)))),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				if names := env.publishedNames(t); !sameStrings(names, []string{"login.owasp.org"}) {
+					t.Errorf("Expected the name within the base64 encoded TXT payload to be published, got %v", names)
+				}
+			},
+		},
+		txtControlCharsTest("strip"),
+		txtControlCharsTest("escape"),
+		{
+			label: "CNAME path",
+			check: checkCNAMEPath,
+		},
+		{
+			label: "Query origin",
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeCNAME, "web.owasp.org")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				reqs := env.nameRequests(t)
+				if len(reqs) != 1 || reqs[0].Depth != 1 {
+					t.Fatalf("Expected the CNAME target to be published with a depth of 1, got %v", reqs)
+				}
+
+				req := reqs[0]
+				req.Records = []requests.DNSAnswer{answer(req.Name, dns.TypeA, "192.0.2.41")}
+				env.process(t, req)
+
+				g := env.Sys.Graph()
+				if !g.IsQueryOrigin("www.owasp.org") {
+					t.Errorf("The queried name was not marked as the query origin")
+				}
+				if g.IsQueryOrigin("web.owasp.org") {
+					t.Errorf("The canonical name was marked as a query origin")
+				}
+			},
+		},
+		{
+			label:     "FCrDNS",
+			configure: func(cfg *config.Config) { cfg.ConfirmFCrDNS = true },
+			check:     checkFCrDNS,
+		},
+		incidentalAddrsTest(false),
+		incidentalAddrsTest(true),
+		{
+			label:     "Minimum address names",
+			configure: func(cfg *config.Config) { cfg.MinAddrNames = 2 },
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeA, "104.22.26.77")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				// The address observed for a single name is stored, but not enriched
+				if addrs := env.publishedAddrs(t); len(addrs) != 0 {
+					t.Errorf("The addresses %v were enriched before crossing the threshold", addrs)
+				}
+				if records := env.Sys.Graph().NameRecords("www.owasp.org"); len(records) != 1 {
+					t.Errorf("The A record was not stored: %v", records)
+				}
+
+				// The names observed by another enumeration sharing the data manager do not count
+				other := dnsRequest("ftp.owasp.org", answer("ftp.owasp.org", dns.TypeA, "104.22.26.77"))
+				other.EventID = "other-event"
+				env.process(t, other)
+				if addrs := env.publishedAddrs(t); len(addrs) != 0 {
+					t.Errorf("The addresses %v were enriched by the names of another event", addrs)
+				}
+
+				env.process(t, dnsRequest("mail.owasp.org", answer("mail.owasp.org", dns.TypeA, "104.22.26.77")))
+				if addrs := env.publishedAddrs(t); !sameStrings(addrs, []string{"104.22.26.77"}) {
+					t.Errorf("Expected the address to be enriched after the second name resolved to it, got %v", addrs)
+				}
+			},
+		},
+		{
+			label:     "Reverse DNS by IP version",
+			configure: func(cfg *config.Config) { cfg.ReverseIPv6 = false },
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org",
+					answer("www.owasp.org", dns.TypeA, "104.22.26.77"),
+					answer("www.owasp.org", dns.TypeAAAA, "2606:4700:10::6816:1a4d"),
+				),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				noReverse := make(map[string]bool)
+				for _, req := range env.addrRequests(t) {
+					noReverse[req.Address] = req.NoReverse
+				}
+
+				if reverse, found := noReverse["104.22.26.77"]; !found || reverse {
+					t.Errorf("The IPv4 address did not trigger the reverse DNS sweep: %v", noReverse)
+				}
+				if !noReverse["2606:4700:10::6816:1a4d"] {
+					t.Errorf("The IPv6 address triggered the reverse DNS sweep: %v", noReverse)
+				}
+			},
+		},
+		emptyDomainTest(services.EmptyDomainAllow, true, ""),
+		emptyDomainTest(services.EmptyDomainDrop, false, ""),
+		emptyDomainTest(services.EmptyDomainDerive, true, "owasp.org"),
+		{
+			label: "Empty domain derived with the public suffixes",
+			configure: func(cfg *config.Config) {
+				cfg.EmptyDomainPolicy = services.EmptyDomainDerive
+				cfg.PublicSuffixes = []string{"owasp.org"}
+			},
+			requests: []*requests.DNSRequest{{
+				Name:    "www.owasp.org",
+				Records: []requests.DNSAnswer{answer("www.owasp.org", dns.TypeA, "104.22.26.77")},
+				Tag:     requests.DNS,
+				Source:  "DNS",
+			}},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				if reqs := env.addrRequests(t); len(reqs) != 1 || reqs[0].Domain != "www.owasp.org" {
+					t.Errorf("Expected the domain to honor the public suffixes of the system, got %v", reqs)
+				}
+			},
+		},
+		{
+			label: "Client subnet addresses",
+			requests: []*requests.DNSRequest{
+				clientSubnetRequest("198.51.100.0/24", "192.0.2.10"),
+				clientSubnetRequest("203.0.113.7/24", "192.0.2.20"),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				expected := map[string][]string{
+					"198.51.100.0/24": {"192.0.2.10"},
+					"203.0.113.0/24":  {"192.0.2.20"},
+				}
+				if got := env.Sys.Graph().ReadClientSubnetAddresses("cdn.owasp.org"); !reflect.DeepEqual(got, expected) {
+					t.Errorf("Expected the client subnet addresses %v, got %v", expected, got)
+				}
+			},
+		},
+		{
+			label: "Record source count",
+			requests: []*requests.DNSRequest{
+				sourceRequest("DNS"),
+				sourceRequest("Crtsh"),
+				sourceRequest("HackerTarget"),
+				sourceRequest("DNS"),
+				dnsRequest("ftp.owasp.org", answer("ftp.owasp.org", dns.TypeA, "104.22.27.77")),
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				if got := env.Sys.Graph().ReadRecordSourceCount("www.owasp.org", "A", "104.22.26.77"); got != 3 {
+					t.Errorf("Expected the source count to reach 3, got %d", got)
+				}
+
+				buf := new(bytes.Buffer)
+				filter := &services.ExportFilter{Types: []string{"A"}, MinSources: 2}
+				if err := env.dms.Export(context.Background(), buf, services.ExportJSONL, filter); err != nil {
+					t.Fatalf("Failed to export the records: %v", err)
+				}
+				if got := buf.String(); !strings.Contains(got, `"sources":3`) || strings.Contains(got, "ftp.owasp.org") {
+					t.Errorf("Unexpected records exported with the minimum sources filter: %s", got)
+				}
+			},
+		},
+		{
+			label: "Record metadata",
+			configure: func(cfg *config.Config) {
+				cfg.RecordMetadata = map[string]string{"ticket": "SEC-1", "campaign": "q3"}
+			},
+			requests: []*requests.DNSRequest{
+				dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeA, "104.22.26.77")),
+				{
+					Name:     "ftp.owasp.org",
+					Domain:   "owasp.org",
+					Records:  []requests.DNSAnswer{answer("ftp.owasp.org", dns.TypeA, "104.22.27.77")},
+					Tag:      requests.DNS,
+					Source:   "DNS",
+					Metadata: map[string]string{"campaign": "q4"},
+				},
+			},
+			check: func(t *testing.T, env *dataManagerEnv) {
+				g := env.Sys.Graph()
+				expected := env.Sys.Config().RecordMetadata
+				if got := g.ReadRecordMetadata("www.owasp.org", "A", "104.22.26.77"); !reflect.DeepEqual(got, expected) {
+					t.Errorf("Expected the configured metadata %v on the A record, got %v", expected, got)
+				}
+				// The metadata of the request takes precedence
+				expected = map[string]string{"ticket": "SEC-1", "campaign": "q4"}
+				if got := g.ReadRecordMetadata("ftp.owasp.org", "A", "104.22.27.77"); !reflect.DeepEqual(got, expected) {
+					t.Errorf("Expected the metadata %v on the A record, got %v", expected, got)
+				}
+			},
+		},
+	}
+
+	runDataManagerTests(t, tests)
+}
+
+func zoneTransferRequest(server string) *requests.DNSRequest {
+	return &requests.DNSRequest{
+		Name:       "www.owasp.org",
+		Domain:     "owasp.org",
+		AuthServer: server,
+		Records:    []requests.DNSAnswer{answer("www.owasp.org", dns.TypeA, "104.22.26.77")},
+		Tag:        requests.AXFR,
+		Source:     "DNS Zone XFR",
+	}
+}
+
+func clientSubnetRequest(subnet, addr string) *requests.DNSRequest {
+	req := dnsRequest("cdn.owasp.org", answer("cdn.owasp.org", dns.TypeA, addr))
+
+	req.ClientSubnet = subnet
+	return req
+}
+
+func sourceRequest(source string) *requests.DNSRequest {
+	req := dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeA, "104.22.26.77"))
+
+	req.Source = source
+	return req
+}
+
+func fanOutTest(policy string) dataManagerTest {
+	var records []requests.DNSAnswer
+	for i := 0; i < 50; i++ {
+		records = append(records, answer("owasp.org", dns.TypeNS, fmt.Sprintf("ns%d.owasp.org.", i)))
+	}
+
+	return dataManagerTest{
+		label: "Maximum fan-out with the " + policy + " policy",
+		configure: func(cfg *config.Config) {
+			cfg.MaxFanOut = 10
+			cfg.FanOutPolicy = policy
+		},
+		check: func(t *testing.T, env *dataManagerEnv) {
+			start := time.Now()
+			env.process(t, dnsRequest("owasp.org", records...))
+
+			if policy == services.FanOutDrop {
+				if names := env.publishedNames(t); len(names) != 10 {
+					t.Errorf("Expected the remaining names to be dropped, got %d published", len(names))
+				}
+				if dropped := env.dms.FanOutDropped(); dropped != 40 {
+					t.Errorf("Expected 40 dropped names, got %d", dropped)
+				}
+				return
+			}
+
+			// The queued names are released up to the maximum once a second
+			for num := 10; num <= 50; num += 10 {
+				if !env.names.Wait(num, servicetest.WaitTimeout) {
+					t.Fatalf("Expected %d names to be published eventually, got %d", num, env.names.Len())
+				}
+				if elapsed, min := time.Since(start), time.Duration(num/10-1)*time.Second; elapsed < min {
+					t.Errorf("%d names were published within %v", num, elapsed)
+				}
+			}
+		},
+	}
+}
+
+func txtControlCharsTest(policy string) dataManagerTest {
+	raw := "v=spf1 include:a.owasp.org\ninclude:b.owasp.org\x00c.owasp.org\r\n~all"
+
+	return dataManagerTest{
+		label: "TXT control characters with the " + policy + " policy",
+		configure: func(cfg *config.Config) {
+			cfg.TXTControlChars = policy
+			cfg.StoreRawTXT = true
+		},
+		requests: []*requests.DNSRequest{
+			dnsRequest("owasp.org", answer("owasp.org", dns.TypeTXT, raw)),
+		},
+		check: func(t *testing.T, env *dataManagerEnv) {
+			expected := []string{"a.owasp.org", "b.owasp.org", "c.owasp.org"}
+			if names := stringset.New(env.publishedNames(t)...); !sameStrings(names.Slice(), expected) {
+				t.Errorf("Expected the names %v to be extracted, got %v", expected, names.Slice())
+			}
+			if txt := env.Sys.Graph().ReadRawTXT("owasp.org"); len(txt) != 1 || txt[0] != raw {
+				t.Errorf("The raw TXT data was not preserved: %q", txt)
+			}
+		},
+	}
+}
+
+func incidentalAddrsTest(aggressive bool) dataManagerTest {
+	return dataManagerTest{
+		label:     fmt.Sprintf("Incidental addresses with aggressive expansion %t", aggressive),
+		configure: func(cfg *config.Config) { cfg.AggressiveExpansion = aggressive },
+		requests: []*requests.DNSRequest{
+			dnsRequest("owasp.org", answer("owasp.org", dns.TypeTXT, "v=spf1 ip4:104.22.27.77 ~all")),
+		},
+		check: func(t *testing.T, env *dataManagerEnv) {
+			g := env.Sys.Graph()
+			if reqs := env.addrRequests(t); len(reqs) != 1 || reqs[0].Address != "104.22.27.77" || !reqs[0].Incidental {
+				t.Fatalf("The address within the TXT record was not published as incidental: %v", reqs)
+			}
+			if g.IsUnexpandedAddress("104.22.27.77") == aggressive {
+				t.Errorf("The address within the TXT record was not tagged as expected")
+			}
+
+			// The address resolved from the name is no longer considered unexpanded
+			env.process(t, dnsRequest("owasp.org", answer("owasp.org", dns.TypeA, "104.22.27.77")))
+			if reqs := env.addrRequests(t); len(reqs) != 2 || reqs[1].Incidental {
+				t.Errorf("The resolved address was not published as expected: %v", reqs)
+			}
+			if g.IsUnexpandedAddress("104.22.27.77") {
+				t.Errorf("The resolved address is still tagged as unexpanded")
+			}
+		},
+	}
+}
+
+func emptyDomainTest(policy string, published bool, domain string) dataManagerTest {
+	req := dnsRequest("www.owasp.org", answer("www.owasp.org", dns.TypeA, "104.22.26.77"))
+	req.Domain = ""
+
+	return dataManagerTest{
+		label:     "Empty domain with the " + policy + " policy",
+		configure: func(cfg *config.Config) { cfg.EmptyDomainPolicy = policy },
+		requests:  []*requests.DNSRequest{req},
+		check: func(t *testing.T, env *dataManagerEnv) {
+			reqs := env.addrRequests(t)
+			if published && (len(reqs) != 1 || reqs[0].Domain != domain) {
+				t.Errorf("Expected the address to be published with the domain %q, got %v", domain, reqs)
+			}
+			if !published && len(reqs) != 0 {
+				t.Errorf("The address was published: %v", reqs)
+			}
+
+			if records := env.Sys.Graph().NameRecords("www.owasp.org"); (len(records) > 0) != published {
+				t.Errorf("The records stored did not match the policy: %v", records)
+			}
+			if dropped := env.dms.EmptyDomain() > 0; dropped == published {
+				t.Errorf("The request was counted as dropped: %t", dropped)
+			}
+		},
+	}
+}
+
+func checkExport(t *testing.T, env *dataManagerEnv) {
+	g := env.Sys.Graph()
+	uuid := env.Sys.Config().UUID.String()
+
+	if err := g.InsertCNAME("www.owasp.org", "owasp.org", "DNS", "dns", uuid); err != nil {
+		t.Fatalf("Failed to insert the CNAME record: %v", err)
+	}
+	if err := g.InsertA("owasp.org", "104.22.26.77", "DNS", "dns", uuid); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	if err := g.InsertA("www.example.com", "93.184.216.34", "DNS", "dns", uuid); err != nil {
+		t.Fatalf("Failed to insert the A record: %v", err)
+	}
+	err := g.InsertInfrastructure(13335, "CLOUDFLARENET", "104.22.26.77", "104.22.16.0/20", "RIR", "rir", uuid)
+	if err != nil {
+		t.Fatalf("Failed to insert the infrastructure data: %v", err)
+	}
+
+	export := func(format string, filter *services.ExportFilter, expected []string) {
+		buf := new(bytes.Buffer)
+		if err := env.dms.Export(context.Background(), buf, format, filter); err != nil {
+			t.Fatalf("Failed to export the records: %v", err)
+		}
+
+		got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(got) != len(expected) {
+			t.Fatalf("Expected %d lines, got %d:\n%s", len(expected), len(got), buf.String())
+		}
+		for i, line := range expected {
+			if !strings.HasPrefix(got[i], line) {
+				t.Errorf("Line %d: got %s, expected %s", i+1, got[i], line)
+			}
+		}
+	}
+
+	// The names also carry the times they were first and last seen
+	export(services.ExportJSONL, &services.ExportFilter{Domains: []string{"owasp.org"}}, []string{
+		`{"type":"A","name":"owasp.org","data":"104.22.26.77","first_seen":"`,
+		`{"type":"CNAME","name":"www.owasp.org","data":"owasp.org","first_seen":"`,
+		`{"type":"NETBLOCK","name":"104.22.16.0/20","data":"104.22.26.77"}`,
+		`{"type":"ASN","name":"13335","data":"104.22.16.0/20"}`,
+	})
+	export(services.ExportCSV, &services.ExportFilter{Types: []string{"a"}}, []string{
+		"type,name,data,first_seen,last_seen",
+		"A,owasp.org,104.22.26.77,",
+		"A,www.example.com,93.184.216.34,",
+	})
+
+	if err := env.dms.Export(context.Background(), new(bytes.Buffer), "xml", nil); err == nil {
+		t.Errorf("Export did not return an error for an unsupported format")
+	}
+}
+
+func checkRecordSetSignature(t *testing.T, env *dataManagerEnv) {
+	changes := servicetest.CaptureTopic(env.Bus, requests.RecordSetTopic)
+	first := requests.DNSAnswer{Name: "www.owasp.org", Type: int(dns.TypeA), TTL: 300, Data: "104.22.26.77"}
+	second := requests.DNSAnswer{Name: "www.owasp.org", Type: int(dns.TypeA), TTL: 300, Data: "104.22.27.77"}
+
+	g := env.Sys.Graph()
+	env.process(t, dnsRequest("www.owasp.org", first))
+	sig := g.ReadRecordSetSignature("www.owasp.org")
+	if sig == "" {
+		t.Fatal("The record set signature was not stored")
+	}
+
+	// Cosmetic differences must not change the signature
+	env.process(t, dnsRequest("www.owasp.org",
+		requests.DNSAnswer{Name: "WWW.OWASP.ORG.", Type: int(dns.TypeA), TTL: 60, Data: "104.22.26.77."}))
+	if got := g.ReadRecordSetSignature("www.owasp.org"); got != sig {
+		t.Errorf("A cosmetic difference changed the signature")
+	}
+
+	env.process(t, dnsRequest("www.owasp.org", second, first))
+	env.drain(t)
+	msgs := changes.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("Expected a single record set change, got %v", msgs)
+	}
+
+	change := msgs[0].(*requests.RecordSetChange)
+	if change.Name != "www.owasp.org" || change.Previous != sig || change.Current == sig {
+		t.Errorf("Unexpected record set change: %+v", change)
+	}
+	if got := g.ReadRecordSetSignature("www.owasp.org"); got != change.Current {
+		t.Errorf("The new signature was not stored")
+	}
+}
+
+// stubResolver answers the A queries for the names with the provided addresses.
+type stubResolver struct {
+	resolvers.Resolver
+
+	addrs map[string]string
+}
+
+func (r *stubResolver) Resolve(ctx context.Context, name, qtype string, priority int) ([]requests.DNSAnswer, bool, error) {
+	if addr, found := r.addrs[name]; found && qtype == "A" {
+		return []requests.DNSAnswer{{Name: name, Type: int(dns.TypeA), Data: addr}}, false, nil
+	}
+	return nil, false, &resolvers.ResolveError{Err: "No records were found", Rcode: dns.RcodeNameError}
+}
+
+const testZoneFile = `$ORIGIN owasp.org.
+$TTL 3600
+@       IN NS    ns1
+@       IN MX    10 mail.owasp.org.
+ns1     IN A     192.0.2.1
+www 300 IN A     192.0.2.10
+www     IN AAAA  2001:db8::10
+mail    IN A     192.0.2.20
+static  IN CNAME www
+$ORIGIN example.com.
+www     IN A     198.51.100.1
+`
+
+func checkImportZoneFile(t *testing.T, env *dataManagerEnv) {
+	num, err := env.dms.ImportZoneFile(env.Ctx, strings.NewReader(testZoneFile), "", "test.zone")
+	if err != nil {
+		t.Fatalf("Failed to import the zone file: %v", err)
+	}
+	if num != 5 {
+		t.Errorf("Expected 5 names imported from the zone file, got %d", num)
+	}
+
+	g := env.Sys.Graph()
+	expected := map[string][]requests.RecordInfo{
+		"owasp.org": {
+			{Type: graph.RecordMX, Data: "mail.owasp.org"},
+			{Type: graph.RecordNS, Data: "ns1.owasp.org"},
+		},
+		"static.owasp.org": {{Type: graph.RecordCNAME, Data: "www.owasp.org"}},
+	}
+	for name, records := range expected {
+		if got := g.NameRecords(name); fmt.Sprint(got) != fmt.Sprint(records) {
+			t.Errorf("Expected the records %v for %s, got %v", records, name, got)
+		}
+	}
+
+	for name, addrs := range map[string][]string{
+		"ns1.owasp.org":  {"192.0.2.1"},
+		"www.owasp.org":  {"192.0.2.10", "2001:db8::10"},
+		"mail.owasp.org": {"192.0.2.20"},
+	} {
+		var got []string
+		for _, r := range g.NameRecords(name) {
+			if r.Type == graph.RecordA || r.Type == graph.RecordAAAA {
+				got = append(got, r.Data)
+			}
+		}
+		sort.Strings(got)
+
+		if fmt.Sprint(got) != fmt.Sprint(addrs) {
+			t.Errorf("Expected the addresses %v for %s, got %v", addrs, name, got)
+		}
+	}
+
+	if records := g.NameRecords("www.example.com"); len(records) != 0 {
+		t.Errorf("The out of scope name was imported from the zone file: %v", records)
+	}
+	if _, err := env.dms.ImportZoneFile(env.Ctx, strings.NewReader("www IN A 192.0.2.10\n"), "", "bad.zone"); err == nil {
+		t.Errorf("Expected an error for the relative name without an origin")
+	}
+}
+
+func checkSharedDNSRequest(t *testing.T, env *dataManagerEnv) {
+	topic := "test:shared"
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	env.Bus.Subscribe(topic, func(req *requests.DNSRequest) {
+		defer wg.Done()
+
+		if err := servicetest.ProcessDNSRequest(env.Ctx, env.dms, req); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// The other subscriber reads the request while the data manager handles it
+	var changed bool
+	env.Bus.Subscribe(topic, func(req *requests.DNSRequest) {
+		defer wg.Done()
+
+		for i := 0; i < 1000; i++ {
+			for _, r := range req.Records {
+				if r.Name != "WWW.Owasp.org." || r.Data != "Static.Owasp.org." {
+					changed = true
+				}
+			}
+		}
+	})
+
+	req := &requests.DNSRequest{
+		Name:    "WWW.Owasp.org.",
+		Domain:  "owasp.org",
+		Records: []requests.DNSAnswer{answer("WWW.Owasp.org.", dns.TypeCNAME, "Static.Owasp.org.")},
+		Tag:     requests.DNS,
+		Source:  "DNS",
+	}
+	env.Bus.Publish(topic, eventbus.PriorityHigh, req)
+	wg.Wait()
+
+	if changed || req.Name != "WWW.Owasp.org." {
+		t.Errorf("The data manager modified the shared request: %+v", req)
+	}
+	if !env.Sys.Graph().IsCNAMENode("www.owasp.org") {
+		t.Errorf("The data manager did not store the normalized CNAME record")
+	}
+}
+
+func checkPassiveOnlyNames(t *testing.T, env *dataManagerEnv) {
+	g := env.Sys.Graph()
+	process := func(tag, source string) {
+		req := dnsRequest("passive.owasp.org", answer("passive.owasp.org", dns.TypeA, "192.0.2.20"))
+		req.Tag = tag
+		req.Source = source
+
+		env.process(t, req)
+	}
+
+	process(requests.CERT, "Crtsh")
+	if !g.IsPassiveOnly("passive.owasp.org") {
+		t.Errorf("The name reported by a passive source was not flagged as passive-only")
+	}
+
+	process(requests.DNS, "DNS")
+	if g.IsPassiveOnly("passive.owasp.org") {
+		t.Errorf("The name was still flagged as passive-only after the DNS record arrived")
+	}
+
+	// Passive sources reporting the name again do not revert the confirmation
+	process(requests.API, "VirusTotal")
+	if g.IsPassiveOnly("passive.owasp.org") {
+		t.Errorf("The confirmed name was flagged as passive-only again")
+	}
+}
+
+func checkCNAMEPath(t *testing.T, env *dataManagerEnv) {
+	chain := []string{"www.owasp.org", "cdn.owasp.org", "edge.owasp.org", "origin.owasp.org"}
+
+	req := dnsRequest(chain[0])
+	for i, name := range chain {
+		if req.Name != name {
+			t.Fatalf("Expected %s to be resolved next, got %s", name, req.Name)
+		}
+
+		req.Records = []requests.DNSAnswer{answer(name, dns.TypeA, "192.0.2.40")}
+		if i < len(chain)-1 {
+			req.Records = []requests.DNSAnswer{answer(name, dns.TypeCNAME, chain[i+1])}
+		}
+
+		env.process(t, req)
+		if i == len(chain)-1 {
+			break
+		}
+
+		reqs := env.nameRequests(t)
+		if len(reqs) != i+1 {
+			t.Fatalf("The CNAME target of %s was not published", name)
+		}
+		req = reqs[i]
+	}
+
+	g := env.Sys.Graph()
+	paths := g.ReadCNAMEPaths("origin.owasp.org")
+	if len(paths) != 1 || !reflect.DeepEqual(paths[0], chain) {
+		t.Errorf("Expected the terminal name to store the path %v, got %v", chain, paths)
+	}
+	for _, name := range chain[:len(chain)-1] {
+		if p := g.ReadCNAMEPaths(name); len(p) != 0 {
+			t.Errorf("The intermediate name %s stored the paths %v", name, p)
+		}
+	}
+}
+
+func checkFCrDNS(t *testing.T, env *dataManagerEnv) {
+	g := env.Sys.Graph()
+	process := func(name string, rrtype uint16, data string) {
+		env.process(t, dnsRequest(name, answer(name, rrtype, data)))
+	}
+
+	// The forward record arrives before the reverse record
+	process("www.owasp.org", dns.TypeA, "192.0.2.50")
+	if g.IsFCrDNSConfirmed("www.owasp.org", "192.0.2.50") {
+		t.Errorf("The pair was confirmed before the PTR record was observed")
+	}
+	process("50.2.0.192.in-addr.arpa", dns.TypePTR, "www.owasp.org.")
+	if !g.IsFCrDNSConfirmed("www.owasp.org", "192.0.2.50") {
+		t.Errorf("The pair was not confirmed by the matching PTR record")
+	}
+
+	// The reverse record arrives before the forward record
+	process("1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa", dns.TypePTR, "mail.owasp.org.")
+	process("mail.owasp.org", dns.TypeAAAA, "2001:db8::1")
+	if !g.IsFCrDNSConfirmed("mail.owasp.org", "2001:db8::1") {
+		t.Errorf("The IPv6 pair was not confirmed when the PTR record arrived first")
+	}
+
+	// The PTR record pointing at another name does not confirm the pair
+	process("dev.owasp.org", dns.TypeA, "192.0.2.51")
+	process("51.2.0.192.in-addr.arpa", dns.TypePTR, "www.owasp.org.")
+	if g.IsFCrDNSConfirmed("dev.owasp.org", "192.0.2.51") || g.IsFCrDNSConfirmed("www.owasp.org", "192.0.2.51") {
+		t.Errorf("A pair was confirmed without the forward and reverse records agreeing")
+	}
+}
+
+func TestEventAttribution(t *testing.T) {
+	cfgA := config.NewConfig()
+	cfgA.AddDomain("owasp.org")
+	sysA := servicetest.NewSystem(cfgA)
+	defer sysA.Shutdown()
+
+	cfgB := config.NewConfig()
+	cfgB.AddDomain("owasp.org")
+	sysB := servicetest.NewSystem(cfgB)
+	defer sysB.Shutdown()
+	// Both enumerations store their findings in the same graph database
+	sysB.AddGraph(sysA.Graph())
+
+	uuidA := cfgA.UUID.String()
+	uuidB := cfgB.UUID.String()
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var addrEvents []string
+	enumerate := func(sys *servicetest.System, prefix string, misrouted *requests.DNSRequest) {
+		defer wg.Done()
+
+		bus := eventbus.NewEventBus(1000)
+		defer bus.Stop()
+		bus.Subscribe(requests.NewAddrTopic, func(req *requests.AddrRequest) {
+			lock.Lock()
+			addrEvents = append(addrEvents, prefix+" "+req.EventID)
+			lock.Unlock()
+		})
+
+		ctx := servicetest.NewContext(sys.Config(), bus)
+		dms := services.NewDataManagerService(sys)
+		reqs := []*requests.DNSRequest{misrouted}
+		for i := 0; i < 20; i++ {
+			name := fmt.Sprintf("%s%d.owasp.org", prefix, i)
+
+			reqs = append(reqs, dnsRequest(name, answer(name, dns.TypeA, fmt.Sprintf("104.22.26.%d", i+1))))
+		}
+		for _, req := range reqs {
+			if req == nil {
+				continue
+			}
+			if err := servicetest.ProcessDNSRequest(ctx, dms, req); err != nil {
+				t.Error(err)
+			}
+		}
+		servicetest.Drain(bus, servicetest.WaitTimeout)
+	}
+
+	// A request belonging to the second enumeration arrives with the context of the first
+	misrouted := dnsRequest("misrouted.owasp.org", answer("misrouted.owasp.org", dns.TypeA, "104.22.26.100"))
+	misrouted.EventID = uuidB
+
+	wg.Add(2)
+	go enumerate(sysA, "a", misrouted)
+	go enumerate(sysB, "b", nil)
+	wg.Wait()
+
+	g := sysA.Graph()
+	check := func(uuid, prefix string) {
+		names := stringset.New(g.EventFQDNs(uuid)...)
+		// The root domain and the public suffix are shared by both events
+		names.Remove("owasp.org")
+		names.Remove("org")
+
+		for i := 0; i < 20; i++ {
+			if name := fmt.Sprintf("%s%d.owasp.org", prefix, i); !names.Has(name) {
+				t.Errorf("%s was not attributed to the event %s", name, uuid)
+			}
+		}
+		for _, name := range names.Slice() {
+			if !strings.HasPrefix(name, prefix) && !(uuid == uuidB && name == "misrouted.owasp.org") {
+				t.Errorf("%s was attributed to the wrong event %s", name, uuid)
+			}
+		}
+	}
+	check(uuidA, "a")
+	check(uuidB, "b")
+	if names := stringset.New(g.EventFQDNs(uuidB)...); !names.Has("misrouted.owasp.org") {
+		t.Errorf("The request was not attributed to the event it belongs to")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(addrEvents) == 0 {
+		t.Errorf("No address requests were published")
+	}
+	for _, e := range addrEvents {
+		if e != "a "+uuidA && e != "b "+uuidB && e != "a "+uuidB {
+			t.Errorf("The address request was published with the wrong event: %s", e)
+		}
+	}
+}
@@ -13,7 +13,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -70,7 +69,7 @@ func (d *DNSDB) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	d.CheckRateLimit()
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", d.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, d.String(), "Querying for %s subdomains", req.Domain))
 
 	headers := map[string]string{
 		"X-API-Key":    d.API.Key,
@@ -79,14 +78,14 @@ func (d *DNSDB) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	url := d.getURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := d.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, d.String(), "%s: %v", url, err))
 		return
 	}
 
 	for _, name := range d.parse(ctx, page, req.Domain) {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   name,
 			Domain: req.Domain,
 			Tag:    requests.API,
@@ -6,9 +6,6 @@ package services
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
-	"net"
-	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
@@ -16,7 +13,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	amasshttp "github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -64,19 +60,19 @@ func (d *DNSDumpster) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 	d.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, d.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", d.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, d.String(), "Querying for %s subdomains", req.Domain))
 
 	u := "https://dnsdumpster.com/"
-	page, err := amasshttp.RequestWebPage(u, nil, nil, "", "")
+	page, err := d.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, d.String(), "%s: %v", u, err))
 		return
 	}
 
 	token := d.getCSRFToken(page)
 	if token == "" {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to obtain the CSRF token", d.String(), u))
+			requests.NewLogEntry(requests.LogError, d.String(), "%s: Failed to obtain the CSRF token", u))
 		return
 	}
 
@@ -85,12 +81,12 @@ func (d *DNSDumpster) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 
 	page, err = d.postForm(ctx, token, req.Domain)
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, d.String(), "%s: %v", u, err))
 		return
 	}
 
 	for _, sd := range re.FindAllString(page, -1) {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   cleanName(sd),
 			Domain: req.Domain,
 			Tag:    d.SourceType,
@@ -114,53 +110,24 @@ func (d *DNSDumpster) postForm(ctx context.Context, token, domain string) (strin
 		return "", fmt.Errorf("%s failed to obtain the EventBus from Context", d.String())
 	}
 
-	dial := net.Dialer{}
-	client := &http.Client{
-		Transport: &http.Transport{
-			DialContext:         dial.DialContext,
-			TLSHandshakeTimeout: 10 * time.Second,
-		},
-	}
 	params := url.Values{
 		"csrfmiddlewaretoken": {token},
 		"targetip":            {domain},
 	}
-
-	req, err := http.NewRequest("POST", "https://dnsdumpster.com/", strings.NewReader(params.Encode()))
-	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: Failed to setup the POST request: %v", d.String(), err))
-		return "", err
-	}
-	// The CSRF token needs to be sent as a cookie
-	cookie := &http.Cookie{
-		Name:   "csrftoken",
-		Domain: "dnsdumpster.com",
-		Value:  token,
-	}
-	req.AddCookie(cookie)
-
-	req.Header.Set("User-Agent", amasshttp.UserAgent)
-	req.Header.Set("Accept", amasshttp.Accept)
-	req.Header.Set("Accept-Language", amasshttp.AcceptLang)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Referer", "https://dnsdumpster.com")
-	req.Header.Set("X-CSRF-Token", token)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: The POST request failed: %v", d.String(), err))
-		return "", err
+	headers := map[string]string{
+		// The CSRF token needs to be sent as a cookie
+		"Cookie":       "csrftoken=" + token,
+		"Content-Type": "application/x-www-form-urlencoded",
+		"Referer":      "https://dnsdumpster.com",
+		"X-CSRF-Token": token,
 	}
-	defer resp.Body.Close()
 
-	// Now, grab the entire page
-	in, err := ioutil.ReadAll(resp.Body)
+	page, err := d.System().HTTPClient().RequestWebPage(ctx,
+		"https://dnsdumpster.com/", strings.NewReader(params.Encode()), headers, "", "")
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("Failed to read response body: %v", err))
+			requests.NewLogEntry(requests.LogError, d.String(), "The POST request failed: %v", err))
 		return "", err
 	}
-	return string(in), nil
+	return page, nil
 }
@@ -63,6 +63,8 @@ func (ds *DNSService) processDNSRequest(ctx context.Context, req *requests.DNSRe
 	if cfg == nil || bus == nil {
 		return
 	}
+	// The name is reported as done, whether or not it resolved
+	defer bus.Publish(requests.NameDoneTopic, eventbus.PriorityLow, req.Name)
 
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, ds.String())
 
@@ -71,7 +73,7 @@ func (ds *DNSService) processDNSRequest(ctx context.Context, req *requests.DNSRe
 		return
 	}
 
-	req.Records = ds.queryInitialTypes(ctx, req)
+	req.Records, req.Rcodes = ds.queryInitialTypes(ctx, req)
 
 	if len(req.Records) == 0 {
 		// Check if this unresolved name should be output by the enumeration
@@ -89,24 +91,39 @@ func (ds *DNSService) processDNSRequest(ctx context.Context, req *requests.DNSRe
 	ds.resolvedName(ctx, req)
 }
 
-func (ds *DNSService) queryInitialTypes(ctx context.Context, req *requests.DNSRequest) []requests.DNSAnswer {
+func (ds *DNSService) queryInitialTypes(ctx context.Context, req *requests.DNSRequest) ([]requests.DNSAnswer, []int) {
+	var rcodes []int
 	var answers []requests.DNSAnswer
 	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
 	if bus == nil {
-		return answers
+		return answers, rcodes
 	}
 
 	for _, t := range InitialQueryTypes {
 		bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, ds.String())
 
-		if a, _, err := ds.System().Pool().Resolve(ctx, req.Name, t, resolvers.PriorityLow); err == nil {
+		a, _, err := ds.System().Pool().Resolve(ctx, req.Name, t, resolvers.PriorityLow)
+		rcodes = append(rcodes, resolverRcode(err))
+		if err == nil {
 			answers = append(answers, a...)
 		} else {
 			ds.handleResolverError(ctx, err)
 		}
 	}
 
-	return answers
+	return answers, rcodes
+}
+
+// resolverRcode returns the response code associated with the error returned by a resolver.
+func resolverRcode(err error) int {
+	if err == nil {
+		return dns.RcodeSuccess
+	}
+
+	if re, ok := err.(*resolvers.ResolveError); ok {
+		return re.Rcode
+	}
+	return resolvers.ResolverErrRcode
 }
 
 func (ds *DNSService) handleResolverError(ctx context.Context, err error) {
@@ -118,7 +135,7 @@ func (ds *DNSService) handleResolverError(ctx context.Context, err error) {
 	rcode := (err.(*resolvers.ResolveError)).Rcode
 	if cfg.Verbose || rcode == resolvers.NotAvailableRcode || rcode == dns.RcodeRefused ||
 		rcode == dns.RcodeServerFailure || rcode == dns.RcodeNotImplemented {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("DNS: %v", err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, ds.String(), "%v", err))
 	}
 }
 
@@ -165,11 +182,13 @@ func (ds *DNSService) basicQueries(ctx context.Context, req *requests.DNSRequest
 		return
 	}
 
-	answers := ds.queryInitialTypes(ctx, req)
+	answers, rcodes := ds.queryInitialTypes(ctx, req)
 
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, ds.String())
 	// Obtain the DNS answers for the NS records related to the domain
-	if ans, _, err := ds.System().Pool().Resolve(ctx, req.Name, "NS", resolvers.PriorityHigh); err == nil {
+	ans, _, err := ds.System().Pool().Resolve(ctx, req.Name, "NS", resolvers.PriorityHigh)
+	rcodes = append(rcodes, resolverRcode(err))
+	if err == nil {
 		for _, a := range ans {
 			pieces := strings.Split(a.Data, ",")
 			a.Data = pieces[len(pieces)-1]
@@ -186,7 +205,9 @@ func (ds *DNSService) basicQueries(ctx context.Context, req *requests.DNSRequest
 
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, ds.String())
 	// Obtain the DNS answers for the MX records related to the domain
-	if ans, _, err := ds.System().Pool().Resolve(ctx, req.Name, "MX", resolvers.PriorityHigh); err == nil {
+	ans, _, err = ds.System().Pool().Resolve(ctx, req.Name, "MX", resolvers.PriorityHigh)
+	rcodes = append(rcodes, resolverRcode(err))
+	if err == nil {
 		for _, a := range ans {
 			answers = append(answers, a)
 		}
@@ -196,7 +217,9 @@ func (ds *DNSService) basicQueries(ctx context.Context, req *requests.DNSRequest
 
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, ds.String())
 	// Obtain the DNS answers for the SOA records related to the domain
-	if ans, _, err := ds.System().Pool().Resolve(ctx, req.Name, "SOA", resolvers.PriorityHigh); err == nil {
+	ans, _, err = ds.System().Pool().Resolve(ctx, req.Name, "SOA", resolvers.PriorityHigh)
+	rcodes = append(rcodes, resolverRcode(err))
+	if err == nil {
 		answers = append(answers, ans...)
 	} else {
 		ds.handleResolverError(ctx, err)
@@ -204,7 +227,9 @@ func (ds *DNSService) basicQueries(ctx context.Context, req *requests.DNSRequest
 
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, ds.String())
 	// Obtain the DNS answers for the SPF records related to the domain
-	if ans, _, err := ds.System().Pool().Resolve(ctx, req.Name, "SPF", resolvers.PriorityHigh); err == nil {
+	ans, _, err = ds.System().Pool().Resolve(ctx, req.Name, "SPF", resolvers.PriorityHigh)
+	rcodes = append(rcodes, resolverRcode(err))
+	if err == nil {
 		answers = append(answers, ans...)
 	} else {
 		ds.handleResolverError(ctx, err)
@@ -215,7 +240,10 @@ func (ds *DNSService) basicQueries(ctx context.Context, req *requests.DNSRequest
 		ds.resolvedName(ctx, &requests.DNSRequest{
 			Name:    req.Name,
 			Domain:  req.Domain,
+			Seed:    req.Seed,
+			Depth:   req.Depth,
 			Records: answers,
+			Rcodes:  rcodes,
 			Tag:     requests.DNS,
 			Source:  "DNS",
 		})
@@ -231,18 +259,19 @@ func (ds *DNSService) attemptZoneXFR(ctx context.Context, sub, domain, server st
 
 	addr, err := ds.nameserverAddr(ctx, server)
 	if addr == "" {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("DNS: Zone XFR failed: %v", err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, ds.String(), "Zone XFR failed: %v", err))
 		return
 	}
 
-	reqs, err := resolvers.ZoneTransfer(sub, domain, addr)
+	reqs, err := resolvers.ZoneTransfer(sub, domain, addr, ds.System().PortChecker(), ds.System().QueryLimiter())
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("DNS: Zone XFR failed: %s: %v", server, err))
+			requests.NewLogEntry(requests.LogError, ds.String(), "Zone XFR failed: %s: %v", server, err))
 		return
 	}
 
 	for _, req := range reqs {
+		req.AuthServer = server
 		ds.resolvedName(ctx, req)
 	}
 }
@@ -256,14 +285,14 @@ func (ds *DNSService) attemptZoneWalk(ctx context.Context, domain, server string
 
 	addr, err := ds.nameserverAddr(ctx, server)
 	if addr == "" {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("DNS: Zone Walk failed: %v", err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, ds.String(), "Zone Walk failed: %v", err))
 		return
 	}
 
-	reqs, err := resolvers.NsecTraversal(domain, addr)
+	reqs, err := resolvers.NsecTraversal(domain, addr, ds.System().QueryLimiter())
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("DNS: Zone Walk failed: %s: %v", server, err))
+			requests.NewLogEntry(requests.LogError, ds.String(), "Zone Walk failed: %s: %v", server, err))
 		return
 	}
 
@@ -298,7 +327,10 @@ func (ds *DNSService) queryServiceNames(ctx context.Context, req *requests.DNSRe
 			ds.resolvedName(ctx, &requests.DNSRequest{
 				Name:    srvName,
 				Domain:  req.Domain,
+				Seed:    req.Seed,
+				Depth:   req.Depth + 1,
 				Records: a,
+				Rcodes:  []int{dns.RcodeSuccess},
 				Tag:     requests.DNS,
 				Source:  "DNS",
 			})
@@ -5,7 +5,6 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
@@ -14,7 +13,6 @@ import (
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
 	"github.com/OWASP/Amass/v3/net/dns"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -62,18 +60,18 @@ func (e *Entrust) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	e.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, e.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", e.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, e.String(), "Querying for %s subdomains", req.Domain))
 
 	u := e.getURL(req.Domain)
-	page, err := http.RequestWebPage(u, nil, nil, "", "")
+	page, err := e.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", e.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, e.String(), "%s: %v", u, err))
 		return
 	}
 	content := strings.Replace(page, "u003d", " ", -1)
 
 	for _, sd := range re.FindAllString(content, -1) {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   cleanName(sd),
 			Domain: req.Domain,
 			Tag:    e.SourceType,
@@ -83,7 +81,7 @@ func (e *Entrust) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	for _, name := range e.extractReversedSubmatches(page) {
 		if match := re.FindString(name); match != "" {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   cleanName(match),
 				Domain: req.Domain,
 				Tag:    e.SourceType,
@@ -0,0 +1,60 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+type eventIDKey struct{}
+
+// withEventID returns a context carrying the event UUID of the request being processed, which
+// takes precedence over the UUID of the context config. An empty UUID leaves the context as is.
+func withEventID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, eventIDKey{}, id)
+}
+
+// eventID returns the event UUID the data is attributed to, preferring the UUID of the request
+// being processed over the UUID of the context config.
+func eventID(ctx context.Context) string {
+	if id, ok := ctx.Value(eventIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	if cfg, ok := ctx.Value(requests.ContextConfig).(*config.Config); ok && cfg != nil {
+		return cfg.UUID.String()
+	}
+	return ""
+}
+
+// eventKey returns the key scoped to the event the data is attributed to, since the service
+// can be shared by several enumerations.
+func eventKey(ctx context.Context, key string) string {
+	return eventID(ctx) + " " + key
+}
+
+// stampEventID populates the event UUID of the requests published without one.
+func stampEventID(ctx context.Context, args ...interface{}) {
+	for _, arg := range args {
+		switch req := arg.(type) {
+		case *requests.DNSRequest:
+			if req.EventID == "" {
+				req.EventID = eventID(ctx)
+			}
+		case *requests.AddrRequest:
+			if req.EventID == "" {
+				req.EventID = eventID(ctx)
+			}
+		case *requests.ASNRequest:
+			if req.EventID == "" {
+				req.EventID = eventID(ctx)
+			}
+		}
+	}
+}
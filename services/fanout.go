@@ -0,0 +1,118 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// The policies for the derived names beyond the maximum fan-out of a request.
+const (
+	// FanOutQueue releases the remaining names gradually
+	FanOutQueue = "queue"
+	// FanOutDrop drops the remaining names
+	FanOutDrop = "drop"
+)
+
+// fanOutInterval is the time between the releases of the queued names.
+var fanOutInterval = time.Second
+
+type fanOutKey struct{}
+
+// fanOut limits the number of names re-published while processing a single request.
+type fanOut struct {
+	sync.Mutex
+	count    int
+	queued   []*requests.DNSRequest
+	released bool
+}
+
+// withFanOut returns a context carrying the fanOut for the request. The fanOut must be released
+// once the request is processed.
+func (dms *DataManagerService) withFanOut(ctx context.Context) (context.Context, *fanOut) {
+	fo := new(fanOut)
+
+	return context.WithValue(ctx, fanOutKey{}, fo), fo
+}
+
+// allowFanOut returns true when the derived name can be published right away. Otherwise, the
+// name is queued or dropped based on the configured policy.
+func (dms *DataManagerService) allowFanOut(ctx context.Context, req *requests.DNSRequest) bool {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	fo, ok := ctx.Value(fanOutKey{}).(*fanOut)
+	if cfg == nil || bus == nil || !ok || cfg.MaxFanOut <= 0 {
+		return true
+	}
+
+	fo.Lock()
+	defer fo.Unlock()
+
+	// The names derived after the request was processed are no longer limited
+	if fo.released {
+		return true
+	}
+
+	fo.count++
+	if fo.count <= cfg.MaxFanOut {
+		return true
+	}
+
+	if strings.ToLower(cfg.FanOutPolicy) == FanOutDrop {
+		atomic.AddUint64(&dms.fanOutDropped, 1)
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityLow,
+			requests.NewLogEntry(requests.LogWarn, dms.String(), "Dropped %s, since the request exceeded the maximum fan-out of %d names",
+				req.Name, cfg.MaxFanOut).With("name", req.Name))
+		return false
+	}
+
+	fo.queued = append(fo.queued, req)
+	return false
+}
+
+// releaseFanOut publishes the names queued for the request, up to the maximum fan-out each interval.
+func (dms *DataManagerService) releaseFanOut(ctx context.Context, fo *fanOut) {
+	fo.Lock()
+	queued := fo.queued
+	fo.queued = nil
+	fo.released = true
+	fo.Unlock()
+
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	if cfg == nil || len(queued) == 0 {
+		return
+	}
+
+	go func() {
+		t := time.NewTicker(fanOutInterval)
+		defer t.Stop()
+
+		for len(queued) > 0 {
+			select {
+			case <-dms.Quit():
+				return
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+
+			num := cfg.MaxFanOut
+			if num > len(queued) {
+				num = len(queued)
+			}
+			for _, req := range queued[:num] {
+				dms.publishName(ctx, req)
+			}
+			queued = queued[num:]
+		}
+	}()
+}
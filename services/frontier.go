@@ -0,0 +1,233 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// Frontier persists the names re-published by the data manager until they have been
+// processed, so the names can be re-published when an interrupted enumeration resumes.
+type Frontier struct {
+	sync.Mutex
+
+	path    string
+	file    *os.File
+	pending map[string]*requests.DNSRequest
+}
+
+type frontierEntry struct {
+	Done   bool   `json:"done,omitempty"`
+	Name   string `json:"name"`
+	Domain string `json:"domain,omitempty"`
+	Seed   string `json:"seed,omitempty"`
+	Depth  int    `json:"depth,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+// NewFrontier returns a Frontier that persists the pending names in the file at the
+// provided path. Names left pending in an existing file are loaded.
+func NewFrontier(path string) (*Frontier, error) {
+	if path == "" {
+		return nil, errors.New("NewFrontier: The frontier file path is empty")
+	}
+
+	f := &Frontier{
+		path:    path,
+		pending: make(map[string]*requests.DNSRequest),
+	}
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	// Rewrite the file with only the names that are still pending
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	f.file = file
+	return f, nil
+}
+
+// Add records the name of the request as pending.
+func (f *Frontier) Add(req *requests.DNSRequest) error {
+	name := amassdns.Canonical(req.Name)
+	if name == "" {
+		return nil
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	if _, found := f.pending[name]; found {
+		return nil
+	}
+
+	entry := &frontierEntry{
+		Name:   name,
+		Domain: req.Domain,
+		Seed:   req.Seed,
+		Depth:  req.Depth,
+		Tag:    req.Tag,
+		Source: req.Source,
+	}
+	if err := f.write(entry); err != nil {
+		return err
+	}
+
+	f.pending[name] = entryToRequest(entry)
+	return nil
+}
+
+// Remove clears the name from the pending names.
+func (f *Frontier) Remove(name string) error {
+	name = amassdns.Canonical(name)
+
+	f.Lock()
+	defer f.Unlock()
+
+	if _, found := f.pending[name]; !found {
+		return nil
+	}
+
+	if err := f.write(&frontierEntry{Done: true, Name: name}); err != nil {
+		return err
+	}
+
+	delete(f.pending, name)
+	return nil
+}
+
+// Pending returns the requests for the names that have not been processed, sorted by name.
+func (f *Frontier) Pending() []*requests.DNSRequest {
+	f.Lock()
+	defer f.Unlock()
+
+	var reqs []*requests.DNSRequest
+	for _, req := range f.pending {
+		r := *req
+		reqs = append(reqs, &r)
+	}
+
+	sort.Slice(reqs, func(i, j int) bool {
+		return reqs[i].Name < reqs[j].Name
+	})
+	return reqs
+}
+
+// Close releases the file used by the Frontier.
+func (f *Frontier) Close() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *Frontier) write(entry *frontierEntry) error {
+	if f.file == nil {
+		return errors.New("Frontier: The file has been closed")
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.file.Write(append(data, '\n'))
+	return err
+}
+
+func (f *Frontier) load() error {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry frontierEntry
+
+		// Skip lines that were only partially written when the enumeration was interrupted
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Name == "" {
+			continue
+		}
+
+		if entry.Done {
+			delete(f.pending, entry.Name)
+			continue
+		}
+		f.pending[entry.Name] = entryToRequest(&entry)
+	}
+
+	return scanner.Err()
+}
+
+func (f *Frontier) compact() error {
+	tmp := f.path + ".tmp"
+
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	f.file = file
+	for _, req := range f.pending {
+		err = f.write(&frontierEntry{
+			Name:   req.Name,
+			Domain: req.Domain,
+			Seed:   req.Seed,
+			Depth:  req.Depth,
+			Tag:    req.Tag,
+			Source: req.Source,
+		})
+		if err != nil {
+			break
+		}
+	}
+	f.file = nil
+
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, f.path)
+}
+
+func entryToRequest(entry *frontierEntry) *requests.DNSRequest {
+	return &requests.DNSRequest{
+		Name:   entry.Name,
+		Domain: entry.Domain,
+		Seed:   entry.Seed,
+		Depth:  entry.Depth,
+		Tag:    entry.Tag,
+		Source: entry.Source,
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+func TestFrontierResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "frontier")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "frontier.jsonl")
+	f, err := services.NewFrontier(path)
+	if err != nil {
+		t.Fatalf("Failed to create the frontier: %v", err)
+	}
+
+	for _, name := range []string{"a.owasp.org", "b.owasp.org", "c.owasp.org"} {
+		if err := f.Add(&requests.DNSRequest{Name: name, Domain: "owasp.org", Tag: requests.DNS, Source: "DNS"}); err != nil {
+			t.Fatalf("Failed to add %s to the frontier: %v", name, err)
+		}
+	}
+	if err := f.Remove("b.owasp.org"); err != nil {
+		t.Fatalf("Failed to remove the name from the frontier: %v", err)
+	}
+	// Simulate the enumeration being interrupted
+	f.Close()
+
+	f, err = services.NewFrontier(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen the frontier: %v", err)
+	}
+	defer f.Close()
+
+	pending := f.Pending()
+	if len(pending) != 2 || pending[0].Name != "a.owasp.org" || pending[1].Name != "c.owasp.org" {
+		t.Fatalf("Unexpected pending names: %v", pending)
+	}
+	if pending[0].Domain != "owasp.org" || pending[0].Source != "DNS" {
+		t.Errorf("The pending request was not restored: %v", pending[0])
+	}
+}
+
+func TestDataManagerResumeFrontier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "frontier")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.FrontierPath = filepath.Join(dir, "frontier.jsonl")
+	cfg.MaxFanOut = 1
+	cfg.FanOutPolicy = services.FanOutDrop
+
+	sys := servicetest.NewSystem(cfg)
+	defer sys.Shutdown()
+	first := eventbus.NewEventBus(1000)
+	defer first.Stop()
+
+	dms := services.NewDataManagerService(sys)
+	for _, req := range []*requests.DNSRequest{
+		{
+			Name:    "www.owasp.org",
+			Records: []requests.DNSAnswer{{Name: "www.owasp.org", Type: int(dns.TypeCNAME), Data: "web.owasp.org"}},
+		},
+		{
+			Name:    "mail.owasp.org",
+			Records: []requests.DNSAnswer{{Name: "mail.owasp.org", Type: int(dns.TypeCNAME), Data: "unresolved.owasp.org"}},
+		},
+		{
+			// The name dropped by the fan-out limit is not added to the frontier
+			Name: "owasp.org",
+			Records: []requests.DNSAnswer{
+				{Name: "owasp.org", Type: int(dns.TypeNS), Data: "ns1.owasp.org"},
+				{Name: "owasp.org", Type: int(dns.TypeNS), Data: "ns2.owasp.org"},
+			},
+		},
+	} {
+		req.Domain = "owasp.org"
+		req.Tag = requests.DNS
+		req.Source = "DNS"
+
+		if err := servicetest.ProcessDNSRequest(servicetest.NewContext(cfg, first), dms, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Simulate the enumeration being interrupted before the CNAME targets were processed
+	dms.OnStop()
+
+	bus := eventbus.NewEventBus(1000)
+	defer bus.Stop()
+	ctx := servicetest.NewContext(cfg, bus)
+	resumed := servicetest.CaptureTopic(bus, requests.NewNameTopic)
+
+	dms = services.NewDataManagerService(sys)
+	defer dms.OnStop()
+	if num := dms.ResumeFrontier(ctx); num != 3 {
+		t.Fatalf("Expected three names to be resumed, got %d", num)
+	}
+	if !servicetest.Drain(bus, servicetest.WaitTimeout) {
+		t.Fatal("The resumed names were not dispatched")
+	}
+
+	var names []string
+	for _, req := range resumed.DNSRequests() {
+		names = append(names, req.Name)
+	}
+	expected := []string{"ns1.owasp.org", "unresolved.owasp.org", "web.owasp.org"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected the pending names %v to be resumed, got %v", expected, names)
+	}
+
+	// Processing the target must clear it from the frontier
+	err = servicetest.ProcessDNSRequest(ctx, dms, &requests.DNSRequest{
+		Name:   "web.owasp.org",
+		Domain: "owasp.org",
+		Records: []requests.DNSAnswer{
+			{Name: "web.owasp.org", Type: int(dns.TypeA), Data: "104.22.26.77"},
+		},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The names that did not resolve are cleared once the DNS service is done with them
+	dms.NameDone(ctx, "ns1.owasp.org")
+	dms.NameDone(ctx, "unresolved.owasp.org")
+	if num := dms.ResumeFrontier(ctx); num != 0 {
+		t.Errorf("Expected the frontier to be empty, got %d pending names", num)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// The maximum number of geofeed entries stored from a single geofeed
+const maxGeofeedEntries = 10000
+
+// The geofeed URLs are either introduced by the 'geofeed' keyword, as in the RFC 9092 remarks,
+// or mention the geofeed in the URL itself. The URLs are required to use HTTPS.
+var geofeedRE = regexp.MustCompile(`(?i)(geofeed[\s:=]+)?(https://[^\s"'<>;,]+)`)
+
+// geofeedEntry is a netblock listed by a RFC 8805 geofeed, along with the location.
+type geofeedEntry struct {
+	Netblock string
+	Location string
+}
+
+// findGeofeedURLs returns the geofeed URLs referenced within the record data.
+func findGeofeedURLs(data string) []string {
+	var urls []string
+
+	for _, m := range geofeedRE.FindAllStringSubmatch(data, -1) {
+		u := strings.TrimRight(m[2], ".)]")
+		if m[1] == "" && !strings.Contains(strings.ToLower(u), "geofeed") {
+			continue
+		}
+		if parsed, err := url.Parse(u); err != nil || parsed.Host == "" {
+			continue
+		}
+
+		var dup bool
+		for _, prev := range urls {
+			if prev == u {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// parseGeofeed returns the entries of the RFC 8805 geofeed CSV data. The comments and the lines
+// without a valid prefix are skipped.
+func parseGeofeed(data string, max int) []*geofeedEntry {
+	var entries []*geofeedEntry
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() && len(entries) < max {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		_, ipnet, err := net.ParseCIDR(strings.TrimSpace(fields[0]))
+		if err != nil {
+			continue
+		}
+
+		var loc []string
+		for _, f := range fields[1:] {
+			loc = append(loc, strings.TrimSpace(f))
+		}
+		// The location fields are kept in the geofeed order, without the empty trailing fields
+		entries = append(entries, &geofeedEntry{
+			Netblock: ipnet.String(),
+			Location: strings.TrimRight(strings.Join(loc, ","), ","),
+		})
+	}
+	return entries
+}
+
+// insertGeofeeds stores the geofeed URLs referenced by the record data with the owner name,
+// and fetches each geofeed once when requested by the config.
+func (dms *DataManagerService) insertGeofeeds(ctx context.Context, cfg *config.Config, data string, req *requests.DNSRequest) {
+	for _, u := range findGeofeedURLs(data) {
+		feed := u
+
+		dms.writeGraphs(ctx, func(g *graph.Graph) error {
+			if err := g.InsertGeofeed(req.Name, feed, "DNS", requests.DNS, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the geofeed URL: %v", g, err).With("graph", g))
+				return err
+			}
+			return nil
+		})
+
+		if cfg.FetchGeofeeds && !dms.geofeeds.Duplicate(feed) {
+			// The fetch outlives the request, so the writes cannot use the graph writer of the request
+			go dms.fetchGeofeed(context.WithValue(ctx, graphWritesKey{}, (*graphWriter)(nil)), feed)
+		}
+	}
+}
+
+// fetchGeofeed requests the geofeed and stores the netblocks listed with their locations.
+func (dms *DataManagerService) fetchGeofeed(ctx context.Context, feed string) {
+	data, err := dms.System().HTTPClient().RequestWebPage(ctx, feed, nil, nil, "", "")
+	if err != nil {
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityLow,
+			requests.NewLogEntry(requests.LogWarn, dms.String(), "Failed to fetch the geofeed %s: %v", feed, err))
+		return
+	}
+
+	entries := parseGeofeed(data, maxGeofeedEntries)
+	dms.writeGraphs(ctx, func(g *graph.Graph) error {
+		for _, e := range entries {
+			if err := g.InsertGeofeedNetblock(e.Netblock, feed, e.Location, "DNS", requests.DNS, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the geofeed netblock: %v", g, err).With("graph", g))
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindGeofeedURLs(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected []string
+	}{
+		{"geofeed=https://owasp.org/feed.csv", []string{"https://owasp.org/feed.csv"}},
+		{"see https://owasp.org/geofeed.csv.", []string{"https://owasp.org/geofeed.csv"}},
+		{"google-site-verification https://owasp.org/index.html", nil},
+		{"geofeed http://owasp.org/feed.csv", nil},
+	}
+
+	for _, test := range tests {
+		if got := findGeofeedURLs(test.data); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%q: Expected %v, got %v", test.data, test.expected, got)
+		}
+	}
+}
+
+func TestParseGeofeed(t *testing.T) {
+	data := "# prefix,country,region,city,postal\n" +
+		"192.0.2.0/24,US,US-CA,San Francisco,\n" +
+		"2001:db8::/32,NL,NL-NH,Amsterdam,1012\n" +
+		"not a prefix,US,,,\n" +
+		"198.51.100.7/24,GB,,,\n"
+
+	entries := parseGeofeed(data, 10)
+	expected := []*geofeedEntry{
+		{Netblock: "192.0.2.0/24", Location: "US,US-CA,San Francisco"},
+		{Netblock: "2001:db8::/32", Location: "NL,NL-NH,Amsterdam,1012"},
+		{Netblock: "198.51.100.0/24", Location: "GB"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Unexpected geofeed entries: %v", entries)
+	}
+
+	if entries := parseGeofeed(data, 1); len(entries) != 1 {
+		t.Errorf("Expected the geofeed entries to be capped at 1, got %d", len(entries))
+	}
+}
@@ -6,7 +6,6 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -67,23 +65,23 @@ func (g *GitHub) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 		return
 	}
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", g.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, g.String(), "Querying for %s subdomains", req.Domain))
 
 	nameFilter := stringset.NewStringFilter()
 	// This function publishes new subdomain names discovered at the provided URL
 	fetchNames := func(u string) {
 		bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, g.String())
 
-		page, err := http.RequestWebPage(u, nil, nil, "", "")
+		page, err := g.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 		if err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", g.String(), u, err))
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, g.String(), "%s: %v", u, err))
 			return
 		}
 
 		// Extract the subdomain names from the page
 		for _, sd := range re.FindAllString(page, -1) {
 			if name := cleanName(sd); name != "" && !nameFilter.Duplicate(name) {
-				bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+				PublishName(ctx, &requests.DNSRequest{
 					Name:   name,
 					Domain: req.Domain,
 					Tag:    g.Type(),
@@ -108,9 +106,9 @@ loop:
 
 		u := g.restDNSURL(req.Domain, i)
 		// Perform the search using the GitHub API
-		page, err := http.RequestWebPage(u, nil, headers, "", "")
+		page, err := g.System().HTTPClient().RequestWebPage(ctx, u, nil, headers, "", "")
 		if err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", g.String(), u, err))
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, g.String(), "%s: %v", u, err))
 			break loop
 		}
 		// Extract items from the REST API search results
@@ -5,14 +5,12 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -71,7 +69,7 @@ func (g *Google) executeQuery(ctx context.Context, domain string, numwilds int)
 
 	if numwilds == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("Querying %s for %s subdomains", g.String(), domain))
+			requests.NewLogEntry(requests.LogInfo, g.String(), "Querying for %s subdomains", domain))
 	}
 
 	num := g.limit / g.quantity
@@ -84,14 +82,14 @@ func (g *Google) executeQuery(ctx context.Context, domain string, numwilds int)
 			bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, g.String())
 
 			u := g.urlByPageNum(domain, i, numwilds)
-			page, err := http.RequestWebPage(u, nil, nil, "", "")
+			page, err := g.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 			if err != nil {
-				bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", g.String(), u, err))
+				bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, g.String(), "%s: %v", u, err))
 				return
 			}
 
 			for _, name := range re.FindAllString(page, -1) {
-				bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+				PublishName(ctx, &requests.DNSRequest{
 					Name:   cleanName(name),
 					Domain: domain,
 					Tag:    g.SourceType,
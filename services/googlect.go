@@ -5,14 +5,12 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 	"regexp"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -67,7 +65,7 @@ func (g *GoogleCT) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 		return
 	}
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", g.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, g.String(), "Querying for %s subdomains", req.Domain))
 
 	var token string
 	for {
@@ -78,14 +76,14 @@ func (g *GoogleCT) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 			"Connection": "close",
 			"Referer":    "https://transparencyreport.google.com/https/certificates",
 		}
-		page, err := http.RequestWebPage(u, nil, headers, "", "")
+		page, err := g.System().HTTPClient().RequestWebPage(ctx, u, nil, headers, "", "")
 		if err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", g.String(), u, err))
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, g.String(), "%s: %v", u, err))
 			break
 		}
 
 		for _, name := range re.FindAllString(page, -1) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   name,
 				Domain: req.Domain,
 				Tag:    g.SourceType,
@@ -0,0 +1,130 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// The modes for fanning out the writes of a request to the graph databases.
+const (
+	// GraphWritesSequential writes to one graph database after another
+	GraphWritesSequential = "sequential"
+	// GraphWritesConcurrent writes to all the graph databases at once, one write at a time
+	GraphWritesConcurrent = "concurrent"
+	// GraphWritesOrdered writes to the graph databases concurrently in the request order
+	GraphWritesOrdered = "ordered"
+)
+
+type graphWritesKey struct{}
+
+// graphWriter fans out the writes made while processing a single request to the graph databases.
+type graphWriter struct {
+	mode   string
+	graphs []*graph.Graph
+	queues []chan func(*graph.Graph)
+	wg     sync.WaitGroup
+}
+
+func newGraphWriter(mode string, graphs []*graph.Graph) *graphWriter {
+	w := &graphWriter{
+		mode:   mode,
+		graphs: graphs,
+	}
+
+	if mode == GraphWritesOrdered {
+		// Each graph database receives the writes through a queue, so all of them apply
+		// the writes in the same order, while a slow database does not hold up the others
+		for _, g := range graphs {
+			queue := make(chan func(*graph.Graph), 100)
+
+			w.queues = append(w.queues, queue)
+			w.wg.Add(1)
+			go w.processWrites(g, queue)
+		}
+	}
+
+	return w
+}
+
+// Write applies the function to each of the graph databases.
+func (w *graphWriter) Write(f func(g *graph.Graph)) {
+	switch w.mode {
+	case GraphWritesOrdered:
+		for _, queue := range w.queues {
+			queue <- f
+		}
+	case GraphWritesConcurrent:
+		// The graph helpers read and then update the stored values, so each graph database
+		// finishes the write before receiving the next one, and only the graphs run at once
+		var wg sync.WaitGroup
+
+		for _, g := range w.graphs {
+			wg.Add(1)
+			go func(g *graph.Graph) {
+				defer wg.Done()
+				f(g)
+			}(g)
+		}
+		wg.Wait()
+	default:
+		for _, g := range w.graphs {
+			f(g)
+		}
+	}
+}
+
+// Close waits for the writes to complete on all the graph databases.
+func (w *graphWriter) Close() {
+	for _, queue := range w.queues {
+		close(queue)
+	}
+	w.wg.Wait()
+}
+
+func (w *graphWriter) processWrites(g *graph.Graph, queue chan func(*graph.Graph)) {
+	defer w.wg.Done()
+
+	for f := range queue {
+		f(g)
+	}
+}
+
+// withGraphWriter returns a context carrying a graphWriter for the request, based on the
+// configured fan-out mode. The graphWriter must be closed once the request is processed.
+func (dms *DataManagerService) withGraphWriter(ctx context.Context) (context.Context, *graphWriter) {
+	mode := GraphWritesSequential
+	if cfg := ctx.Value(requests.ContextConfig).(*config.Config); cfg != nil && cfg.GraphWrites != "" {
+		mode = cfg.GraphWrites
+	}
+
+	w := newGraphWriter(mode, dms.System().GraphDatabases())
+	return context.WithValue(ctx, graphWritesKey{}, w), w
+}
+
+// writeGraphs applies the function to each of the graph databases, using the graphWriter of the
+// request when available. The error returned by the function counts as a failure of the graph
+// database.
+func (dms *DataManagerService) writeGraphs(ctx context.Context, f func(g *graph.Graph) error) {
+	write := func(g *graph.Graph) {
+		dms.breakerWrite(ctx, g, func(g *graph.Graph) error {
+			dms.health.written()
+			return f(g)
+		})
+	}
+
+	if w, ok := ctx.Value(graphWritesKey{}).(*graphWriter); ok && w != nil {
+		w.Write(write)
+		return
+	}
+
+	for _, g := range dms.System().GraphDatabases() {
+		write(g)
+	}
+}
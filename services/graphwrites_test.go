@@ -0,0 +1,89 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+func TestGraphWriterOrdered(t *testing.T) {
+	graphs := []*graph.Graph{
+		graph.NewGraph(db.NewCayleyGraphMemory()),
+		graph.NewGraph(db.NewCayleyGraphMemory()),
+	}
+
+	var lock sync.Mutex
+	order := make(map[*graph.Graph][]int)
+
+	w := newGraphWriter(GraphWritesOrdered, graphs)
+	for i := 0; i < 50; i++ {
+		rec := i
+
+		w.Write(func(g *graph.Graph) {
+			// The databases respond at different speeds
+			time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond)
+
+			lock.Lock()
+			order[g] = append(order[g], rec)
+			lock.Unlock()
+		})
+	}
+	w.Close()
+
+	for _, g := range graphs {
+		if len(order[g]) != 50 {
+			t.Fatalf("Expected 50 writes, got %d", len(order[g]))
+		}
+
+		for i, rec := range order[g] {
+			if rec != i {
+				t.Errorf("Write %d was applied at position %d", rec, i)
+				break
+			}
+		}
+	}
+}
+
+func TestGraphWriterConcurrent(t *testing.T) {
+	graphs := []*graph.Graph{
+		graph.NewGraph(db.NewCayleyGraphMemory()),
+		graph.NewGraph(db.NewCayleyGraphMemory()),
+	}
+
+	var lock sync.Mutex
+	active := make(map[*graph.Graph]int)
+	counts := make(map[*graph.Graph]int)
+
+	w := newGraphWriter(GraphWritesConcurrent, graphs)
+	for i := 0; i < 50; i++ {
+		w.Write(func(g *graph.Graph) {
+			lock.Lock()
+			active[g]++
+			if active[g] > 1 {
+				t.Errorf("The graph received overlapping writes")
+			}
+			lock.Unlock()
+
+			time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond)
+
+			lock.Lock()
+			active[g]--
+			counts[g]++
+			lock.Unlock()
+		})
+	}
+	w.Close()
+
+	for _, g := range graphs {
+		if counts[g] != 50 {
+			t.Errorf("Expected 50 writes, got %d", counts[g])
+		}
+	}
+}
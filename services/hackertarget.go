@@ -12,7 +12,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -61,17 +60,17 @@ func (h *HackerTarget) OnDNSRequest(ctx context.Context, req *requests.DNSReques
 	h.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, h.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", h.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, h.String(), "Querying for %s subdomains", req.Domain))
 
 	url := h.getDNSURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := h.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", h.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, h.String(), "%s: %v", url, err))
 		return
 	}
 
 	for _, sd := range re.FindAllString(page, -1) {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   cleanName(sd),
 			Domain: req.Domain,
 			Tag:    h.SourceType,
@@ -101,23 +100,23 @@ func (h *HackerTarget) OnASNRequest(ctx context.Context, req *requests.ASNReques
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, h.String())
 
 	url := h.getASNURL(req.Address)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := h.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", h.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, h.String(), "%s: %v", url, err))
 		return
 	}
 
 	fields := strings.Split(page, ",")
 	if len(fields) < 4 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the response", h.String(), url))
+			requests.NewLogEntry(requests.LogError, h.String(), "%s: Failed to parse the response", url))
 		return
 	}
 
 	asn, err := strconv.Atoi(strings.Trim(fields[1], "\""))
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the origin response: %v", h.String(), url, err),
+			requests.NewLogEntry(requests.LogError, h.String(), "%s: Failed to parse the origin response: %v", url, err),
 		)
 		return
 	}
@@ -0,0 +1,155 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The number of seconds covered by the recent graph database writes of the HealthStatus
+const healthWindow = 60
+
+// HealthStatus is a snapshot of the liveness and load of the DataManagerService.
+type HealthStatus struct {
+	// False once the service has been stopped
+	Alive bool `json:"alive"`
+
+	// True while DNS requests are being processed or waiting to be processed
+	Busy bool `json:"busy"`
+
+	// The DNS requests being processed, out of the maximum processed at the same time
+	Processing  int     `json:"processing"`
+	MaxRequests int     `json:"max_requests"`
+	Utilization float64 `json:"utilization"`
+
+	// The DNS requests queued for the service and the requests waiting for the semaphore
+	Queued  int `json:"queued"`
+	Waiting int `json:"waiting"`
+
+	// The graph database writes and failures during the last minute
+	Writes          uint64  `json:"recent_writes"`
+	InsertErrors    uint64  `json:"recent_insert_errors"`
+	InsertErrorRate float64 `json:"insert_error_rate"`
+
+	LastActive time.Time `json:"last_active"`
+}
+
+// healthStats counts the graph database writes and failures for each second of the window,
+// along with the totals since the service was created.
+type healthStats struct {
+	sync.Mutex
+	buckets [healthWindow]healthBucket
+	writes  uint64
+	errors  uint64
+}
+
+type healthBucket struct {
+	sec    int64
+	writes uint64
+	errors uint64
+}
+
+func (h *healthStats) bucket() *healthBucket {
+	now := time.Now().Unix()
+
+	b := &h.buckets[now%healthWindow]
+	if b.sec != now {
+		*b = healthBucket{sec: now}
+	}
+	return b
+}
+
+func (h *healthStats) written() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.bucket().writes++
+	h.writes++
+}
+
+func (h *healthStats) failed() {
+	h.Lock()
+	defer h.Unlock()
+
+	h.bucket().errors++
+	h.errors++
+}
+
+func (h *healthStats) totals() (uint64, uint64) {
+	h.Lock()
+	defer h.Unlock()
+
+	return h.writes, h.errors
+}
+
+func (h *healthStats) recent() (uint64, uint64) {
+	h.Lock()
+	defer h.Unlock()
+
+	var writes, errors uint64
+	now := time.Now().Unix()
+	for _, b := range h.buckets {
+		if now-b.sec < healthWindow {
+			writes += b.writes
+			errors += b.errors
+		}
+	}
+	return writes, errors
+}
+
+// Health returns the liveness and load of the service.
+func (dms *DataManagerService) Health() *HealthStatus {
+	hs := &HealthStatus{
+		Alive:       true,
+		Processing:  int(atomic.LoadInt32(&dms.processing)),
+		MaxRequests: maxDataManagerRequests,
+		Queued:      dms.RequestLen(),
+		Waiting:     dms.maxRequests.Waiters(),
+	}
+
+	select {
+	case <-dms.Quit():
+		hs.Alive = false
+	default:
+	}
+
+	hs.Busy = hs.Processing > 0 || hs.Waiting > 0 || hs.Queued > 0
+	hs.Utilization = float64(hs.Processing) / float64(hs.MaxRequests)
+
+	hs.Writes, hs.InsertErrors = dms.health.recent()
+	if hs.Writes > 0 {
+		hs.InsertErrorRate = float64(hs.InsertErrors) / float64(hs.Writes)
+	}
+
+	if last := atomic.LoadInt64(&dms.lastActive); last > 0 {
+		hs.LastActive = time.Unix(0, last)
+	}
+	return hs
+}
+
+// NewHealthHandler returns an http.Handler replying with the HealthStatus of the service
+// as JSON. The status code is 503 once the service has been stopped.
+func NewHealthHandler(dms *DataManagerService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		hs := dms.Health()
+		code := http.StatusOK
+		if !hs.Alive {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(hs)
+	})
+}
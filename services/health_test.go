@@ -0,0 +1,97 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/graph/db"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+// blockingDatabase holds the node insertions until the release channel is closed.
+type blockingDatabase struct {
+	db.GraphDatabase
+	release chan struct{}
+}
+
+func (b *blockingDatabase) InsertNode(id, ntype string) (db.Node, error) {
+	<-b.release
+	return b.GraphDatabase.InsertNode(id, ntype)
+}
+
+func healthRequest(t *testing.T, h http.Handler) (int, *services.HealthStatus) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var hs services.HealthStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &hs); err != nil {
+		t.Fatalf("Failed to parse the health status: %v", err)
+	}
+	return rec.Code, &hs
+}
+
+func TestHealthHandler(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+	// The request keeps processing until the graph database is released by the test
+	blocking := &blockingDatabase{GraphDatabase: db.NewCayleyGraphMemory(), release: make(chan struct{})}
+	h.Sys.AddGraph(graph.NewGraph(blocking))
+
+	dms := services.NewDataManagerService(h.Sys)
+	handler := services.NewHealthHandler(dms)
+	if code, hs := healthRequest(t, handler); code != http.StatusOK || !hs.Alive || hs.Busy {
+		t.Errorf("Expected the idle service to be reported, got %d %+v", code, hs)
+	}
+
+	dms.OnDNSRequest(h.Ctx, &requests.DNSRequest{
+		Name:   "www.owasp.org",
+		Domain: "owasp.org",
+		Records: []requests.DNSAnswer{
+			{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "104.22.26.77"},
+		},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	})
+
+	var busy *services.HealthStatus
+	for i := 0; i < 50; i++ {
+		if _, busy = healthRequest(t, handler); busy.Processing > 0 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !busy.Busy || busy.Processing != 1 {
+		t.Errorf("Expected the processing request to be reported, got %+v", busy)
+	}
+
+	close(blocking.release)
+	var idle *services.HealthStatus
+	for i := 0; i < 50; i++ {
+		if _, idle = healthRequest(t, handler); !idle.Busy && !idle.LastActive.IsZero() {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if idle.Busy || idle.Processing != 0 || idle.LastActive.IsZero() || idle.Writes == 0 {
+		t.Errorf("Expected the service to be idle after processing the request, got %+v", idle)
+	}
+
+	dms.Stop()
+	if code, hs := healthRequest(t, handler); code != http.StatusServiceUnavailable || hs.Alive {
+		t.Errorf("Expected the stopped service to be reported, got %d %+v", code, hs)
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -58,9 +57,9 @@ func (i *IPAPI) OnAddrRequest(ctx context.Context, req *requests.AddrRequest) {
 
 	url := i.restAddrURL(req.Address)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := i.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", i.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, i.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the IP address information from the REST API results
@@ -12,7 +12,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/eventbus"
 	amassnet "github.com/OWASP/Amass/v3/net"
-	amasshttp "github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -58,10 +57,10 @@ func (i *IPToASN) OnASNRequest(ctx context.Context, req *requests.ASNRequest) {
 
 	i.CheckRateLimit()
 
-	r, err := i.getASInfo(req.Address)
+	r, err := i.getASInfo(ctx, req.Address)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: %v", i.String(), req.Address, err))
+			requests.NewLogEntry(requests.LogError, i.String(), "%s: %v", req.Address, err))
 		return
 	}
 
@@ -69,11 +68,11 @@ func (i *IPToASN) OnASNRequest(ctx context.Context, req *requests.ASNRequest) {
 	bus.Publish(requests.NewASNTopic, eventbus.PriorityHigh, r)
 }
 
-func (i *IPToASN) getASInfo(addr string) (*requests.ASNRequest, error) {
+func (i *IPToASN) getASInfo(ctx context.Context, addr string) (*requests.ASNRequest, error) {
 	u := i.getURL(addr)
 
 	headers := map[string]string{"Accept": "application/json"}
-	page, err := amasshttp.RequestWebPage(u, nil, headers, "", "")
+	page, err := i.System().HTTPClient().RequestWebPage(ctx, u, nil, headers, "", "")
 	if err != nil {
 		return nil, fmt.Errorf("%s: %s: %v", i.String(), u, err)
 	}
@@ -11,7 +11,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -63,12 +62,12 @@ func (i *IPv4Info) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	i.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, i.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", i.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, i.String(), "Querying for %s subdomains", req.Domain))
 
 	url := i.getURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := i.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", i.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, i.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -76,9 +75,9 @@ func (i *IPv4Info) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, i.String())
 
 	url = i.ipSubmatch(page, req.Domain)
-	page, err = http.RequestWebPage(url, nil, nil, "", "")
+	page, err = i.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", i.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, i.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -86,9 +85,9 @@ func (i *IPv4Info) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, i.String())
 
 	url = i.domainSubmatch(page, req.Domain)
-	page, err = http.RequestWebPage(url, nil, nil, "", "")
+	page, err = i.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", i.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, i.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -96,14 +95,14 @@ func (i *IPv4Info) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, i.String())
 
 	url = i.subdomainSubmatch(page, req.Domain)
-	page, err = http.RequestWebPage(url, nil, nil, "", "")
+	page, err = i.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", i.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, i.String(), "%s: %v", url, err))
 		return
 	}
 
 	for _, sd := range re.FindAllString(page, -1) {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   cleanName(sd),
 			Domain: req.Domain,
 			Tag:    i.SourceType,
@@ -4,13 +4,18 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/graph"
 	"github.com/OWASP/Amass/v3/graph/db"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/resolvers"
 )
 
@@ -18,9 +23,17 @@ import (
 type LocalSystem struct {
 	sync.Mutex
 
-	cfg    *config.Config
-	pool   resolvers.Resolver
-	graphs []*graph.Graph
+	cfg      *config.Config
+	pool     resolvers.Resolver
+	limiter  *resolvers.QueryLimiter
+	dialer   *amassnet.RateLimitedDialer
+	checker  *amassnet.PortChecker
+	client   *amasshttp.Client
+	suffixes *amassdns.SuffixList
+	graphs   []*graph.Graph
+
+	// Marks the local graph database as being written by the system
+	writerLock *db.WriterLock
 
 	// The various services running within the system
 	coreSrvs    []Service
@@ -46,10 +59,36 @@ func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 		return nil, errors.New("The system was unable to build the pool of resolvers")
 	}
 
+	// A single budget governs the DNS queries sent by the resolvers, the wildcard tests and the zone walking
+	limiter := resolvers.NewQueryLimiter(c.MaxDNSQPS)
+	pool.Limiter = limiter
+
+	// A single budget governs the connections made to the target hosts by the active techniques
+	dialer := amassnet.NewRateLimitedDialer(c.ActiveConnsPerSec, c.MaxActiveConns)
+	checker := amassnet.NewPortChecker(dialer, c.MaxActiveConns, time.Duration(c.ConnectTimeout)*time.Second)
+
+	// A single HTTP client pools the connections made by all the data sources
+	client, err := amasshttp.NewClient(amasshttp.ClientSettings{
+		Proxy:           c.HTTPProxy,
+		MaxConnsPerHost: c.HTTPMaxConnsPerHost,
+		Retries:         c.HTTPRetries,
+		MaxResponseSize: c.HTTPMaxResponseSize,
+		Timeout:         time.Duration(c.HTTPTimeout) * time.Second,
+	})
+	if err != nil {
+		pool.Stop()
+		return nil, err
+	}
+
 	sys := &LocalSystem{
-		cfg:  c,
-		pool: pool,
-		done: make(chan struct{}, 2),
+		cfg:      c,
+		pool:     pool,
+		limiter:  limiter,
+		dialer:   dialer,
+		checker:  checker,
+		client:   client,
+		suffixes: c.Suffixes(),
+		done:     make(chan struct{}, 2),
 	}
 
 	// Setup the correct graph database handler
@@ -81,6 +120,31 @@ func (l *LocalSystem) Pool() resolvers.Resolver {
 	return l.pool
 }
 
+// QueryLimiter implements the System interface.
+func (l *LocalSystem) QueryLimiter() *resolvers.QueryLimiter {
+	return l.limiter
+}
+
+// Dialer implements the System interface.
+func (l *LocalSystem) Dialer() *amassnet.RateLimitedDialer {
+	return l.dialer
+}
+
+// PortChecker implements the System interface.
+func (l *LocalSystem) PortChecker() *amassnet.PortChecker {
+	return l.checker
+}
+
+// HTTPClient implements the System interface.
+func (l *LocalSystem) HTTPClient() *amasshttp.Client {
+	return l.client
+}
+
+// Suffixes implements the System interface.
+func (l *LocalSystem) Suffixes() *amassdns.SuffixList {
+	return l.suffixes
+}
+
 // AddSource implements the System interface.
 func (l *LocalSystem) AddSource(srv Service) error {
 	l.Lock()
@@ -128,17 +192,18 @@ func (l *LocalSystem) Shutdown() error {
 		close(l.done)
 	}
 
-	for _, src := range l.DataSources() {
-		src.Stop()
-	}
-
-	for _, srv := range l.CoreServices() {
-		srv.Stop()
+	// The services still handling requests after the grace period are stopped regardless
+	grace := l.Config().TimeoutGrace
+	srvs := append([]Service{}, l.DataSources()...)
+	srvs = append(srvs, l.CoreServices()...)
+	for _, srv := range StopServices(srvs, grace) {
+		l.Config().Log.Printf("%s was stopped while handling requests %s after the shutdown began", srv.String(), grace)
 	}
 
 	for _, g := range l.GraphDatabases() {
 		g.Close()
 	}
+	l.writerLock.Release()
 
 	l.pool.Stop()
 	return nil
@@ -166,7 +231,34 @@ func (l *LocalSystem) setupGraphDBs() error {
 	if g == nil {
 		return errors.New("Failed to create the graph")
 	}
+	g.MergeHosts = l.Config().MergeHosts
+	g.Suffixes = l.suffixes
+	l.enableBatching(g)
 	l.graphs = append(l.graphs, g)
+
+	lock, err := db.AcquireWriterLock(l.Config().Dir)
+	if err != nil {
+		return err
+	}
+	l.writerLock = lock
+
+	// The SQLite file receives the same writes as the primary graph database
+	if path := l.Config().SQLitePath; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(config.OutputDirectory(l.Config().Dir), path)
+		}
+
+		sqlite := db.NewSQLiteGraph(path)
+		if sqlite == nil {
+			return errors.New("Failed to create the SQLite graph")
+		}
+
+		sg := graph.NewGraph(sqlite)
+		sg.MergeHosts = l.Config().MergeHosts
+		sg.Suffixes = l.suffixes
+		l.enableBatching(sg)
+		l.graphs = append(l.graphs, sg)
+	}
 	/*
 		if l.Config().DataOptsWriter != nil {
 			l.graphs = append(l.graphs,
@@ -175,6 +267,14 @@ func (l *LocalSystem) setupGraphDBs() error {
 	return nil
 }
 
+// enableBatching causes the graph to insert the edges in batches, when the configuration sets
+// the batch size. The pending edges are applied when the graph is closed during the shutdown.
+func (l *LocalSystem) enableBatching(g *graph.Graph) {
+	if size := l.Config().GraphBatchSize; size > 0 {
+		g.EnableBatching(context.Background(), size, l.Config().GraphBatchWindow)
+	}
+}
+
 // Select the correct core services to be used in the System.
 func (l *LocalSystem) initCoreServices() error {
 	l.coreSrvs = []Service{
@@ -13,7 +13,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -65,12 +64,12 @@ func (m *Mnemonic) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	m.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, m.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", m.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, m.String(), "Querying for %s subdomains", req.Domain))
 
 	url := m.getDNSURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := m.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", m.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, m.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -100,7 +99,7 @@ func (m *Mnemonic) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	for name := range names {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   name,
 			Domain: req.Domain,
 			Tag:    m.SourceType,
@@ -0,0 +1,134 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/stringset"
+)
+
+// NameFilter receives the names published on the NewNameTopic before the rest of the pipeline,
+// so the names are normalized, checked for validity and scope, and deduplicated only once for
+// the event. The names that survive are published on the NameAcceptedTopic. Since the data
+// sources and the data manager publish the names on the same topic, the config blacklist is
+// enforced here for every path the names are discovered through.
+type NameFilter struct {
+	cfg   *config.Config
+	bus   *eventbus.EventBus
+	names *stringset.StringFilter
+
+	// The duplicate names dropped for each data source
+	dupLock    sync.Mutex
+	duplicates map[string]uint64
+
+	// The names dropped for being invalid, out of scope or blacklisted
+	invalid     uint64
+	outOfScope  uint64
+	blacklisted uint64
+}
+
+// NewNameFilter returns a NameFilter publishing the accepted names on the event bus.
+func NewNameFilter(cfg *config.Config, bus *eventbus.EventBus) *NameFilter {
+	return &NameFilter{
+		cfg:        cfg,
+		bus:        bus,
+		names:      stringset.NewStringFilter(),
+		duplicates: make(map[string]uint64),
+	}
+}
+
+// OnNewName is the NewNameTopic callback that republishes the accepted names.
+func (nf *NameFilter) OnNewName(req *requests.DNSRequest) {
+	if nf.Accept(req) && nf.bus != nil {
+		nf.bus.Publish(requests.NameAcceptedTopic, eventbus.PriorityHigh, req)
+	}
+}
+
+// OnInvalidName is the InvalidNameTopic callback that counts the names dropped before they
+// were published, for failing the hostname validation.
+func (nf *NameFilter) OnInvalidName(req *requests.DNSRequest) {
+	atomic.AddUint64(&nf.invalid, 1)
+}
+
+// Accept normalizes the name and domain of the request, and returns true when the name is
+// a valid hostname within the scope, not blacklisted, that was not seen before during the event. A name seen
+// before is accepted once more when first reported by a trusted source.
+func (nf *NameFilter) Accept(req *requests.DNSRequest) bool {
+	if req == nil {
+		return false
+	}
+
+	name, err := amassdns.NormalizeName(amassdns.RemoveAsteriskLabel(req.Name))
+	if err == nil {
+		err = amassdns.ValidateHostname(name)
+	}
+	if err != nil || name == "" {
+		atomic.AddUint64(&nf.invalid, 1)
+		return false
+	}
+
+	domain := amassdns.Canonical(req.Domain)
+	if nf.cfg != nil {
+		if !nf.cfg.IsDomainInScope(name) {
+			atomic.AddUint64(&nf.outOfScope, 1)
+			return false
+		}
+		if nf.cfg.Blacklisted(name) {
+			atomic.AddUint64(&nf.blacklisted, 1)
+			return false
+		}
+		if domain == "" {
+			domain = nf.cfg.WhichDomain(name)
+		}
+	}
+	if domain == "" {
+		atomic.AddUint64(&nf.outOfScope, 1)
+		return false
+	}
+
+	if nf.names.Duplicate(name + strconv.FormatBool(requests.TrustedTag(req.Tag))) {
+		nf.dupLock.Lock()
+		nf.duplicates[req.Source]++
+		nf.dupLock.Unlock()
+		return false
+	}
+
+	req.Name = name
+	req.Domain = domain
+	return true
+}
+
+// Duplicates returns the number of duplicate names dropped for each data source.
+func (nf *NameFilter) Duplicates() map[string]uint64 {
+	nf.dupLock.Lock()
+	defer nf.dupLock.Unlock()
+
+	dups := make(map[string]uint64, len(nf.duplicates))
+	for src, num := range nf.duplicates {
+		dups[src] = num
+	}
+	return dups
+}
+
+// Invalid returns the number of names dropped for not being valid DNS hostnames.
+func (nf *NameFilter) Invalid() uint64 {
+	return atomic.LoadUint64(&nf.invalid)
+}
+
+// OutOfScope returns the number of names dropped for being outside the scope.
+func (nf *NameFilter) OutOfScope() uint64 {
+	return atomic.LoadUint64(&nf.outOfScope)
+}
+
+// Blacklisted returns the number of names dropped for matching the config blacklist.
+func (nf *NameFilter) Blacklisted() uint64 {
+	return atomic.LoadUint64(&nf.blacklisted)
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestNameFilter(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain(domainTest)
+	bus := eventbus.NewEventBus(1000)
+	defer bus.Stop()
+
+	var lock sync.Mutex
+	var accepted []string
+	bus.Subscribe(requests.NameAcceptedTopic, func(req *requests.DNSRequest) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		accepted = append(accepted, req.Name+" "+req.Domain)
+	})
+
+	nf := NewNameFilter(cfg, bus)
+	for _, req := range []*requests.DNSRequest{
+		{Name: "www.owasp.org", Domain: domainTest, Tag: requests.API, Source: "SourceA"},
+		{Name: "WWW.OWASP.org.", Domain: domainTest, Tag: requests.API, Source: "SourceA"},
+		{Name: "www.owasp.org", Domain: domainTest, Tag: requests.SCRAPE, Source: "SourceB"},
+		{Name: "www.owasp.org", Domain: domainTest, Tag: requests.CERT, Source: "SourceC"},
+		{Name: "*.dev.owasp.org", Tag: requests.API, Source: "SourceA"},
+		{Name: "www.example.com", Domain: "example.com", Tag: requests.API, Source: "SourceA"},
+		{Name: "bad_name!.owasp.org", Domain: domainTest, Tag: requests.API, Source: "SourceA"},
+	} {
+		nf.OnNewName(req)
+	}
+
+	expected := map[string]bool{
+		"www.owasp.org owasp.org": true,
+		"dev.owasp.org owasp.org": true,
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		lock.Lock()
+		num := len(accepted)
+		lock.Unlock()
+
+		if num >= len(expected)+1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	// The name reported again by a trusted source is accepted once more
+	if len(accepted) != 3 {
+		t.Errorf("Expected 3 accepted names, got %v", accepted)
+	}
+	for _, a := range accepted {
+		if !expected[a] {
+			t.Errorf("Unexpected accepted name: %s", a)
+		}
+	}
+
+	dups := nf.Duplicates()
+	if dups["SourceA"] != 1 || dups["SourceB"] != 1 || len(dups) != 2 {
+		t.Errorf("Unexpected duplicate counts for the sources: %v", dups)
+	}
+	if nf.OutOfScope() != 1 {
+		t.Errorf("Expected one name out of scope, got %d", nf.OutOfScope())
+	}
+	if nf.Invalid() != 1 {
+		t.Errorf("Expected one invalid name, got %d", nf.Invalid())
+	}
+}
+
+func TestNameFilterBlacklist(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain(domainTest)
+	cfg.Blacklist = []string{"admin.owasp.org", "*.dev.owasp.org", `/^test\d+\./`}
+
+	nf := NewNameFilter(cfg, nil)
+	for _, test := range []struct {
+		name     string
+		accepted bool
+	}{
+		{"www.owasp.org", true},
+		{"admin.owasp.org", false},
+		{"login.admin.owasp.org", false},
+		{"dev.owasp.org", true},
+		{"api.dev.owasp.org", false},
+		{"test42.owasp.org", false},
+		{"test.owasp.org", true},
+	} {
+		// The data manager publishes the CNAME and SRV targets with the DNS tag
+		req := &requests.DNSRequest{Name: test.name, Domain: domainTest, Tag: requests.DNS, Source: "DNS"}
+
+		if got := nf.Accept(req); got != test.accepted {
+			t.Errorf("Accept(%q) returned %t, expected %t", test.name, got, test.accepted)
+		}
+	}
+
+	if nf.Blacklisted() != 4 {
+		t.Errorf("Expected four blacklisted names, got %d", nf.Blacklisted())
+	}
+}
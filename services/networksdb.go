@@ -6,7 +6,6 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net"
 	"net/url"
 	"regexp"
@@ -16,7 +15,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -111,16 +109,16 @@ func (n *NetworksDB) executeASNAddrQuery(ctx context.Context, addr string) {
 	}
 
 	u := n.getIPURL(addr)
-	page, err := http.RequestWebPage(u, nil, nil, "", "")
+	page, err := n.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return
 	}
 
 	matches := networksdbOrgLinkRE.FindStringSubmatch(page)
 	if matches == nil || len(matches) < 2 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to extract the organization info href", n.String(), u),
+			requests.NewLogEntry(requests.LogError, n.String(), "%s: Failed to extract the organization info href", u),
 		)
 		return
 	}
@@ -129,9 +127,9 @@ func (n *NetworksDB) executeASNAddrQuery(ctx context.Context, addr string) {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, n.String())
 
 	u = networksdbBaseURL + matches[1]
-	page, err = http.RequestWebPage(u, nil, nil, "", "")
+	page, err = n.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return
 	}
 
@@ -145,7 +143,7 @@ func (n *NetworksDB) executeASNAddrQuery(ctx context.Context, addr string) {
 	matches = networksdbASNRE.FindStringSubmatch(page)
 	if matches == nil || len(matches) < 2 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The regular expression failed to extract the ASN", n.String(), u),
+			requests.NewLogEntry(requests.LogError, n.String(), "%s: The regular expression failed to extract the ASN", u),
 		)
 		return
 	}
@@ -153,7 +151,7 @@ func (n *NetworksDB) executeASNAddrQuery(ctx context.Context, addr string) {
 	asn, err := strconv.Atoi(strings.TrimSpace(matches[1]))
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to extract a valid ASN", n.String(), u),
+			requests.NewLogEntry(requests.LogError, n.String(), "%s: Failed to extract a valid ASN", u),
 		)
 		return
 	}
@@ -175,16 +173,16 @@ func (n *NetworksDB) executeASNQuery(ctx context.Context, asn int, addr string,
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, n.String())
 
 	u := n.getASNURL(asn)
-	page, err := http.RequestWebPage(u, nil, nil, "", "")
+	page, err := n.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return
 	}
 
 	matches := networksdbASNameRE.FindStringSubmatch(page)
 	if matches == nil || len(matches) < 2 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: The regular expression failed to extract the AS name", n.String()),
+			requests.NewLogEntry(requests.LogError, n.String(), "The regular expression failed to extract the AS name"),
 		)
 		return
 	}
@@ -193,7 +191,7 @@ func (n *NetworksDB) executeASNQuery(ctx context.Context, asn int, addr string,
 	matches = networksdbCCRE.FindStringSubmatch(page)
 	if matches == nil || len(matches) < 2 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: The regular expression failed to extract the country code", n.String()),
+			requests.NewLogEntry(requests.LogError, n.String(), "The regular expression failed to extract the country code"),
 		)
 		return
 	}
@@ -245,7 +243,7 @@ func (n *NetworksDB) executeAPIASNAddrQuery(ctx context.Context, addr string) {
 	_, id := n.apiIPQuery(ctx, addr)
 	if id == "" {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to obtain IP address information", n.String(), addr),
+			requests.NewLogEntry(requests.LogError, n.String(), "%s: Failed to obtain IP address information", addr),
 		)
 		return
 	}
@@ -256,7 +254,7 @@ func (n *NetworksDB) executeAPIASNAddrQuery(ctx context.Context, addr string) {
 	asns := n.apiOrgInfoQuery(ctx, id)
 	if len(asns) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to obtain ASNs associated with the organization", n.String(), id),
+			requests.NewLogEntry(requests.LogError, n.String(), "%s: Failed to obtain ASNs associated with the organization", id),
 		)
 		return
 	}
@@ -270,7 +268,7 @@ loop:
 		cidrs = n.apiNetblocksQuery(ctx, a)
 		if len(cidrs) == 0 {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: %d: Failed to obtain netblocks associated with the ASN", n.String(), a),
+				requests.NewLogEntry(requests.LogError, n.String(), "%d: Failed to obtain netblocks associated with the ASN", a),
 			)
 		}
 
@@ -286,7 +284,7 @@ loop:
 
 	if asn == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to obtain the ASN associated with the IP address", n.String(), addr),
+			requests.NewLogEntry(requests.LogError, n.String(), "%s: Failed to obtain the ASN associated with the IP address", addr),
 		)
 		return
 	}
@@ -303,7 +301,7 @@ func (n *NetworksDB) executeAPIASNQuery(ctx context.Context, asn int, addr strin
 		netblocks.Union(n.apiNetblocksQuery(ctx, asn))
 		if len(netblocks) == 0 {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: %d: Failed to obtain netblocks associated with the ASN", n.String(), asn),
+				requests.NewLogEntry(requests.LogError, n.String(), "%d: Failed to obtain netblocks associated with the ASN", asn),
 			)
 			return
 		}
@@ -329,7 +327,7 @@ func (n *NetworksDB) executeAPIASNQuery(ctx context.Context, asn int, addr strin
 	req := n.apiASNInfoQuery(ctx, asn)
 	if req == nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %d: Failed to obtain ASN information", n.String(), asn),
+			requests.NewLogEntry(requests.LogError, n.String(), "%d: Failed to obtain ASN information", asn),
 		)
 		return
 	}
@@ -354,9 +352,9 @@ func (n *NetworksDB) apiIPQuery(ctx context.Context, addr string) (string, strin
 	u := n.getAPIIPURL()
 	params := url.Values{"ip": {addr}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(u, body, n.getHeaders(), "", "")
+	page, err := n.System().HTTPClient().RequestWebPage(ctx, u, body, n.getHeaders(), "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return "", ""
 	}
 
@@ -373,14 +371,14 @@ func (n *NetworksDB) apiIPQuery(ctx context.Context, addr string) (string, strin
 		} `json:"results"`
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return "", ""
 	} else if m.Error != "" {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %s", n.String(), u, m.Error))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %s", u, m.Error))
 		return "", ""
 	} else if m.Total == 0 || len(m.Results) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The request returned zero results", n.String(), u),
+			requests.NewLogEntry(requests.LogInfo, n.String(), "%s: The request returned zero results", u),
 		)
 		return "", ""
 	}
@@ -404,9 +402,9 @@ func (n *NetworksDB) apiOrgInfoQuery(ctx context.Context, id string) []int {
 	u := n.getAPIOrgInfoURL()
 	params := url.Values{"id": {id}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(u, body, n.getHeaders(), "", "")
+	page, err := n.System().HTTPClient().RequestWebPage(ctx, u, body, n.getHeaders(), "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return []int{}
 	}
 
@@ -418,14 +416,14 @@ func (n *NetworksDB) apiOrgInfoQuery(ctx context.Context, id string) []int {
 		} `json:"results"`
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return []int{}
 	} else if m.Error != "" {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %s", n.String(), u, m.Error))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %s", u, m.Error))
 		return []int{}
 	} else if m.Total == 0 || len(m.Results[0].ASNs) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The request returned zero results", n.String(), u),
+			requests.NewLogEntry(requests.LogInfo, n.String(), "%s: The request returned zero results", u),
 		)
 		return []int{}
 	}
@@ -449,9 +447,9 @@ func (n *NetworksDB) apiASNInfoQuery(ctx context.Context, asn int) *requests.ASN
 	u := n.getAPIASNInfoURL()
 	params := url.Values{"asn": {strconv.Itoa(asn)}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(u, body, n.getHeaders(), "", "")
+	page, err := n.System().HTTPClient().RequestWebPage(ctx, u, body, n.getHeaders(), "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return nil
 	}
 
@@ -467,14 +465,14 @@ func (n *NetworksDB) apiASNInfoQuery(ctx context.Context, asn int) *requests.ASN
 		} `json:"results"`
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return nil
 	} else if m.Error != "" {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %s", n.String(), u, m.Error))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %s", u, m.Error))
 		return nil
 	} else if m.Total == 0 || len(m.Results) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The request returned zero results", n.String(), u),
+			requests.NewLogEntry(requests.LogInfo, n.String(), "%s: The request returned zero results", u),
 		)
 		return nil
 	}
@@ -506,9 +504,9 @@ func (n *NetworksDB) apiNetblocksQuery(ctx context.Context, asn int) stringset.S
 	u := n.getAPINetblocksURL()
 	params := url.Values{"asn": {strconv.Itoa(asn)}}
 	body := strings.NewReader(params.Encode())
-	page, err := http.RequestWebPage(u, body, n.getHeaders(), "", "")
+	page, err := n.System().HTTPClient().RequestWebPage(ctx, u, body, n.getHeaders(), "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return netblocks
 	}
 
@@ -520,14 +518,14 @@ func (n *NetworksDB) apiNetblocksQuery(ctx context.Context, asn int) stringset.S
 		} `json:"results"`
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", n.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %v", u, err))
 		return netblocks
 	} else if m.Error != "" {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %s", n.String(), u, m.Error))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, n.String(), "%s: %s", u, m.Error))
 		return netblocks
 	} else if m.Total == 0 || len(m.Results) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The request returned zero results", n.String(), u),
+			requests.NewLogEntry(requests.LogInfo, n.String(), "%s: The request returned zero results", u),
 		)
 		return netblocks
 	}
@@ -6,12 +6,10 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -69,13 +67,13 @@ func (pt *PassiveTotal) OnDNSRequest(ctx context.Context, req *requests.DNSReque
 	pt.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, pt.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", pt.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, pt.String(), "Querying for %s subdomains", req.Domain))
 
 	url := pt.restURL(req.Domain)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, pt.API.Username, pt.API.Key)
+	page, err := pt.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, pt.API.Username, pt.API.Key)
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", pt.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, pt.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the subdomain names from the REST API results
@@ -90,7 +88,7 @@ func (pt *PassiveTotal) OnDNSRequest(ctx context.Context, req *requests.DNSReque
 	for _, s := range subs.Subdomains {
 		name := s + "." + req.Domain
 		if re.MatchString(name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   name,
 				Domain: req.Domain,
 				Tag:    pt.SourceType,
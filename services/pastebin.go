@@ -8,7 +8,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -50,25 +49,25 @@ func (p *Pastebin) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	p.CheckRateLimit()
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", p.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, p.String(), "Querying for %s subdomains", req.Domain))
 
-	ids, err := p.extractIDs(req.Domain)
+	ids, err := p.extractIDs(ctx, req.Domain)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: %v", p.String(), req.Domain, err))
+			requests.NewLogEntry(requests.LogError, p.String(), "%s: %v", req.Domain, err))
 		return
 	}
 
 	for _, id := range ids {
 		url := p.webURLDumpData(id)
-		page, err := http.RequestWebPage(url, nil, nil, "", "")
+		page, err := p.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 		if err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", p.String(), url, err))
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, p.String(), "%s: %v", url, err))
 			return
 		}
 
 		for _, name := range re.FindAllString(page, -1) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   name,
 				Domain: req.Domain,
 				Tag:    p.SourceType,
@@ -79,9 +78,9 @@ func (p *Pastebin) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 }
 
 // Extract the IDs from the pastebin Web response.
-func (p *Pastebin) extractIDs(domain string) ([]string, error) {
+func (p *Pastebin) extractIDs(ctx context.Context, domain string) ([]string, error) {
 	url := p.webURLDumpIDs(domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := p.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
 		return nil, err
 	}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -58,13 +57,13 @@ func (p *PTRArchive) OnDNSRequest(ctx context.Context, req *requests.DNSRequest)
 	p.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, p.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", p.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, p.String(), "Querying for %s subdomains", req.Domain))
 
 	url := p.getURL(req.Domain)
 	fakeCookie := map[string]string{"Cookie": "test=12345"}
-	page, err := http.RequestWebPage(url, nil, fakeCookie, "", "")
+	page, err := p.System().HTTPClient().RequestWebPage(ctx, url, nil, fakeCookie, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", p.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, p.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -74,7 +73,7 @@ func (p *PTRArchive) OnDNSRequest(ctx context.Context, req *requests.DNSRequest)
 			continue
 		}
 
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   name,
 			Domain: req.Domain,
 			Tag:    p.SourceType,
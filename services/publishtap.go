@@ -0,0 +1,89 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// PublishKey identifies the event bus topic and priority of a publish observed by the tap.
+type PublishKey struct {
+	Topic    string
+	Priority int
+}
+
+// publishTap counts the publishes made by the DataManagerService when the config
+// requests the publishes to be observed for debugging.
+type publishTap struct {
+	sync.Mutex
+	counts map[PublishKey]uint64
+}
+
+func (t *publishTap) observe(topic string, priority int) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.counts == nil {
+		t.counts = make(map[PublishKey]uint64)
+	}
+	t.counts[PublishKey{Topic: topic, Priority: priority}]++
+}
+
+func (t *publishTap) snapshot() map[PublishKey]uint64 {
+	t.Lock()
+	defer t.Unlock()
+
+	counts := make(map[PublishKey]uint64, len(t.counts))
+	for k, v := range t.counts {
+		counts[k] = v
+	}
+	return counts
+}
+
+// Published returns the number of publishes observed for each topic and priority. The
+// publishes are only observed when the config debug publishes option is enabled.
+func (dms *DataManagerService) Published() map[PublishKey]uint64 {
+	return dms.tap.snapshot()
+}
+
+// publish sends the arguments out on the event bus topic, and makes the publish observable
+// by the tap when requested by the config.
+func (dms *DataManagerService) publish(ctx context.Context, topic string, priority int, args ...interface{}) {
+	bus, ok := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if !ok || bus == nil {
+		return
+	}
+
+	if cfg, ok := ctx.Value(requests.ContextConfig).(*config.Config); ok && cfg != nil && cfg.DebugPublishes {
+		dms.tap.observe(topic, priority)
+		if cfg.Log != nil {
+			cfg.Log.Printf("%s: Published on %s with priority %d", dms.String(), topic, priority)
+		}
+	}
+	if topic == requests.LogTopic {
+		for _, arg := range args {
+			if entry, ok := arg.(*requests.LogEntry); ok {
+				spanError(ctx, entry)
+			}
+		}
+	}
+	stampEventID(ctx, args...)
+	bus.Publish(topic, priority, args...)
+}
+
+// publishName sends the name out on the NewNameTopic once it has been validated. The name is
+// added to the frontier right before the publication, so the DNS service cannot finish with
+// the name before it is pending.
+func (dms *DataManagerService) publishName(ctx context.Context, req *requests.DNSRequest) {
+	stampEventID(ctx, req)
+	if validName(ctx, req) {
+		dms.addFrontier(ctx, req)
+		dms.publish(ctx, requests.NewNameTopic, eventbus.PriorityHigh, req)
+	}
+}
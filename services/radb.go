@@ -14,7 +14,6 @@ import (
 	"time"
 
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
 	"github.com/OWASP/Amass/v3/stringset"
@@ -115,9 +114,9 @@ func (r *RADb) executeASNAddrQuery(ctx context.Context, addr string) {
 
 	url := r.getIPURL("arin", addr)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := r.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -131,11 +130,11 @@ func (r *RADb) executeASNAddrQuery(ctx context.Context, addr string) {
 		} `json:"cidr0_cidrs"`
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return
 	} else if m.ClassName != "ip network" || len(m.CIDRs) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The request returned zero results", r.String(), url),
+			requests.NewLogEntry(requests.LogInfo, r.String(), "%s: The request returned zero results", url),
 		)
 		return
 	}
@@ -177,9 +176,9 @@ func (r *RADb) executeASNQuery(ctx context.Context, asn int, addr, prefix string
 
 	url := r.getASNURL("arin", strconv.Itoa(asn))
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := r.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -192,11 +191,11 @@ func (r *RADb) executeASNQuery(ctx context.Context, asn int, addr, prefix string
 		}
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return
 	} else if m.ClassName != "autnum" {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The query returned incorrect results", r.String(), url),
+			requests.NewLogEntry(requests.LogWarn, r.String(), "%s: The query returned incorrect results", url),
 		)
 		return
 	}
@@ -228,7 +227,7 @@ func (r *RADb) executeASNQuery(ctx context.Context, asn int, addr, prefix string
 
 	if len(blocks) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The query returned zero netblocks", r.String(), url),
+			requests.NewLogEntry(requests.LogInfo, r.String(), "%s: The query returned zero netblocks", url),
 		)
 		return
 	}
@@ -264,9 +263,9 @@ func (r *RADb) netblocks(ctx context.Context, asn int) stringset.Set {
 
 	url := r.getNetblocksURL(strconv.Itoa(asn))
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := r.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return netblocks
 	}
 
@@ -282,7 +281,7 @@ func (r *RADb) netblocks(ctx context.Context, asn int) stringset.Set {
 		} `json:"arin_originas0_networkSearchResults"`
 	}
 	if err := json.Unmarshal([]byte(page), &m); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return netblocks
 	}
 
@@ -311,7 +310,7 @@ func (r *RADb) netblocks(ctx context.Context, asn int) stringset.Set {
 
 	if len(netblocks) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: Failed to acquire netblocks for ASN %d", r.String(), asn),
+			requests.NewLogEntry(requests.LogError, r.String(), "Failed to acquire netblocks for ASN %d", asn),
 		)
 	}
 	return netblocks
@@ -336,14 +335,14 @@ func (r *RADb) ipToASN(ctx context.Context, cidr string) int {
 		answers, _, err := r.System().Pool().Resolve(ctx, radbWhoisURL, "A", resolvers.PriorityCritical)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: %s: %v", r.String(), radbWhoisURL, err))
+				requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", radbWhoisURL, err))
 			return 0
 		}
 
 		ip := answers[0].Data
 		if ip == "" {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: Failed to resolve %s", r.String(), radbWhoisURL))
+				requests.NewLogEntry(requests.LogError, r.String(), "Failed to resolve %s", radbWhoisURL))
 			return 0
 		}
 		r.addr = ip
@@ -355,7 +354,7 @@ func (r *RADb) ipToASN(ctx context.Context, cidr string) int {
 	d := net.Dialer{}
 	conn, err := d.DialContext(ctx, "tcp", r.addr+":43")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", r.String(), err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%v", err))
 		return 0
 	}
 	defer conn.Close()
@@ -0,0 +1,186 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// StreamedRecord is the JSON line written for each record stored by the DataManagerService.
+type StreamedRecord struct {
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Data      string `json:"data"`
+	Domain    string `json:"domain,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+// recordStream writes the stored records as JSON lines, one record at a time.
+type recordStream struct {
+	sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+func newRecordStream(w io.Writer) *recordStream {
+	return &recordStream{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (rs *recordStream) write(rec *StreamedRecord) error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	if err := rs.enc.Encode(rec); err != nil {
+		return err
+	}
+	// Buffered writers are flushed, so each record is available as soon as it is stored
+	if f, ok := rs.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (dms *DataManagerService) getRecordStream(cfg *config.Config) *recordStream {
+	if !cfg.StreamRecords {
+		return nil
+	}
+
+	w := cfg.RecordStream
+	if w == nil {
+		w = os.Stdout
+	}
+
+	dms.streamLock.Lock()
+	defer dms.streamLock.Unlock()
+
+	if dms.stream == nil || dms.stream.w != w {
+		dms.stream = newRecordStream(w)
+	}
+	return dms.stream
+}
+
+// recordStored returns the function handling the record once it has been inserted into a graph
+// database. The record is counted and streamed once, along with the first successful insert,
+// while the annotations are stored in each of the graph databases holding the record.
+func (dms *DataManagerService) recordStored(ctx context.Context, req *requests.DNSRequest, rtype, name, data string) func(g *graph.Graph) error {
+	var once sync.Once
+
+	return func(g *graph.Graph) error {
+		once.Do(func() {
+			dms.countRecord(rtype)
+			dms.streamRecord(ctx, req, rtype, name, data)
+		})
+
+		if err := dms.signRecord(ctx, g, req, rtype, name, data); err != nil {
+			return err
+		}
+		if err := dms.insertRecordSource(ctx, g, req, rtype, name, data); err != nil {
+			return err
+		}
+		return dms.insertRecordMetadata(ctx, g, req, rtype, name, data)
+	}
+}
+
+// signRecord stores the HMAC of the record, when the configuration provides the key.
+func (dms *DataManagerService) signRecord(ctx context.Context, g *graph.Graph, req *requests.DNSRequest, rtype, name, data string) error {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil || cfg.RecordHMACKey == "" {
+		return nil
+	}
+
+	key := []byte(cfg.RecordHMACKey)
+	if err := g.InsertRecordHMAC(key, rtype, name, data, req.Source, req.Tag, eventID(ctx)); err != nil {
+		dms.health.failed()
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the record HMAC: %v", g, err).With("graph", g))
+		return err
+	}
+	return nil
+}
+
+// insertRecordSource attributes the record to the data source of the request, so the number
+// of distinct data sources that corroborate the record is tracked.
+func (dms *DataManagerService) insertRecordSource(ctx context.Context, g *graph.Graph, req *requests.DNSRequest, rtype, name, data string) error {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil || req.Source == "" {
+		return nil
+	}
+
+	if err := g.InsertRecordSource(name, rtype, data, req.Source, req.Tag, eventID(ctx)); err != nil {
+		dms.health.failed()
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the record source: %v", g, err).With("graph", g))
+		return err
+	}
+	return nil
+}
+
+// insertRecordMetadata attaches the metadata of the configuration and the request to the record,
+// where the pairs of the request take precedence.
+func (dms *DataManagerService) insertRecordMetadata(ctx context.Context, g *graph.Graph, req *requests.DNSRequest, rtype, name, data string) error {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	bus := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if cfg == nil || bus == nil || (len(cfg.RecordMetadata) == 0 && len(req.Metadata) == 0) {
+		return nil
+	}
+
+	metadata := make(map[string]string, len(cfg.RecordMetadata)+len(req.Metadata))
+	for k, v := range cfg.RecordMetadata {
+		metadata[k] = v
+	}
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+
+	if err := g.InsertRecordMetadata(name, rtype, data, metadata, req.Source, req.Tag, eventID(ctx)); err != nil {
+		dms.health.failed()
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the record metadata: %v", g, err).With("graph", g))
+		return err
+	}
+	return nil
+}
+
+// streamRecord writes the stored record to the record stream, when the configuration enables it.
+func (dms *DataManagerService) streamRecord(ctx context.Context, req *requests.DNSRequest, rtype, name, data string) {
+	cfg := ctx.Value(requests.ContextConfig).(*config.Config)
+	if cfg == nil {
+		return
+	}
+
+	rs := dms.getRecordStream(cfg)
+	if rs == nil {
+		return
+	}
+
+	err := rs.write(&StreamedRecord{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Type:      rtype,
+		Name:      name,
+		Data:      data,
+		Domain:    req.Domain,
+		Tag:       req.Tag,
+		Source:    req.Source,
+	})
+	if err != nil {
+		cfg.Log.Printf("%s: Failed to write the record to the stream: %v", dms.String(), err)
+	}
+}
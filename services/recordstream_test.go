@@ -0,0 +1,81 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+func TestStreamRecords(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.StreamRecords = true
+	cfg.RecordStream = buf
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+
+	dms := services.NewDataManagerService(h.Sys)
+	for _, req := range []*requests.DNSRequest{
+		{
+			Name:   "www.owasp.org",
+			Domain: "owasp.org",
+			Records: []requests.DNSAnswer{
+				{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "192.0.2.10"},
+				{Name: "www.owasp.org", Type: int(dns.TypeAAAA), Data: "2001:db8::10"},
+			},
+			Tag:    requests.DNS,
+			Source: "DNS",
+		},
+		{
+			Name:    "static.owasp.org",
+			Domain:  "owasp.org",
+			Records: []requests.DNSAnswer{{Name: "static.owasp.org", Type: int(dns.TypeCNAME), Data: "www.owasp.org."}},
+			Tag:     requests.DNS,
+			Source:  "DNS",
+		},
+	} {
+		h.Process(t, dms, req)
+	}
+
+	expected := []services.StreamedRecord{
+		{Type: graph.RecordA, Name: "www.owasp.org", Data: "192.0.2.10"},
+		{Type: graph.RecordAAAA, Name: "www.owasp.org", Data: "2001:db8::10"},
+		{Type: graph.RecordCNAME, Name: "static.owasp.org", Data: "www.owasp.org"},
+	}
+
+	var num int
+	scanner := bufio.NewScanner(buf)
+	for ; scanner.Scan(); num++ {
+		var rec services.StreamedRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("Line %d is not valid JSON: %v", num+1, err)
+		}
+		if num >= len(expected) {
+			continue
+		}
+
+		exp := expected[num]
+		if rec.Type != exp.Type || rec.Name != exp.Name || rec.Data != exp.Data {
+			t.Errorf("Line %d: Expected the %s record %s -> %s, got %+v", num+1, exp.Type, exp.Name, exp.Data, rec)
+		}
+		if rec.Timestamp == "" || rec.Domain != "owasp.org" || rec.Source != "DNS" {
+			t.Errorf("Line %d: The record was missing the attribution: %+v", num+1, rec)
+		}
+	}
+	if num != len(expected) {
+		t.Errorf("Expected %d streamed records, got %d", len(expected), num)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/graph"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/semaphore"
+	"github.com/OWASP/Amass/v3/stringset"
+)
+
+// ReputationChecker provides the verdicts of a threat intelligence or reputation service for the
+// CNAME, NS and MX targets discovered by the DataManagerService.
+type ReputationChecker interface {
+	String() string
+
+	// Check returns the verdict for the name (e.g. malicious), or an empty string when the
+	// service has no verdict for the name
+	Check(ctx context.Context, name string) (string, error)
+}
+
+// The maximum number of reputation checks performed concurrently, and the time allowed for each
+const (
+	maxReputationChecks    = 10
+	reputationCheckTimeout = 30 * time.Second
+)
+
+// SetReputationChecker provides the ReputationChecker used to tag the CNAME, NS and MX targets
+// with verdicts. The checks are performed asynchronously, so the enumeration is not held up,
+// and the verdicts are stored once the checks return.
+func (dms *DataManagerService) SetReputationChecker(c ReputationChecker) {
+	dms.reputationLock.Lock()
+	defer dms.reputationLock.Unlock()
+
+	dms.reputation = c
+	dms.reputationChecked = stringset.NewStringFilter()
+	dms.reputationSem = semaphore.NewWeightedSemaphore(maxReputationChecks)
+}
+
+// checkReputation sends the target to the ReputationChecker, when provided. Each target is
+// only checked once.
+func (dms *DataManagerService) checkReputation(ctx context.Context, target string) {
+	dms.reputationLock.Lock()
+	c := dms.reputation
+	checked := dms.reputationChecked
+	sem := dms.reputationSem
+	dms.reputationLock.Unlock()
+
+	target = amassdns.Canonical(target)
+	if c == nil || target == "" || checked.Duplicate(target) {
+		return
+	}
+
+	// The check outlives the request, so the writes cannot use the graph writer of the request
+	ctx = context.WithValue(ctx, graphWritesKey{}, (*graphWriter)(nil))
+	go func() {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			return
+		}
+		cctx, cancel := context.WithTimeout(ctx, reputationCheckTimeout)
+		verdict, err := c.Check(cctx, target)
+		cancel()
+		sem.Release(1)
+
+		if err != nil {
+			dms.publish(ctx, requests.LogTopic, eventbus.PriorityLow,
+				requests.NewLogEntry(requests.LogWarn, dms.String(), "%s failed to check %s: %v", c, target, err))
+			return
+		}
+		if verdict == "" {
+			return
+		}
+
+		dms.writeGraphs(ctx, func(g *graph.Graph) error {
+			if err := g.InsertReputation(target, c.String(), verdict, c.String(), requests.EXTERNAL, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the reputation verdict: %v", g, err).With("graph", g))
+				return err
+			}
+			return nil
+		})
+	}()
+}
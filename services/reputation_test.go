@@ -0,0 +1,71 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+// stubReputation flags the names provided, and counts the checks performed.
+type stubReputation struct {
+	flagged map[string]string
+	checks  int32
+}
+
+func (s *stubReputation) String() string { return "Stub Intel" }
+
+func (s *stubReputation) Check(ctx context.Context, name string) (string, error) {
+	atomic.AddInt32(&s.checks, 1)
+	return s.flagged[name], nil
+}
+
+func TestReputationChecker(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+
+	checker := &stubReputation{flagged: map[string]string{"cdn.evil.net": "malicious"}}
+	dms := services.NewDataManagerService(h.Sys)
+	dms.SetReputationChecker(checker)
+
+	for _, target := range []string{"cdn.evil.net", "mail.example.net", "cdn.evil.net"} {
+		h.Process(t, dms, &requests.DNSRequest{
+			Name:   "www.owasp.org",
+			Domain: "owasp.org",
+			Records: []requests.DNSAnswer{
+				{Name: "www.owasp.org", Type: int(dns.TypeCNAME), Data: target},
+			},
+			Tag:    requests.DNS,
+			Source: "DNS",
+		})
+	}
+
+	g := h.Sys.Graph()
+	deadline := time.Now().Add(5 * time.Second)
+	for (g.ReadReputation("cdn.evil.net")["Stub Intel"] == "" || atomic.LoadInt32(&checker.checks) < 2) &&
+		time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if verdict := g.ReadReputation("cdn.evil.net")["Stub Intel"]; verdict != "malicious" {
+		t.Errorf("Expected the flagged target to have the malicious verdict, got %q", verdict)
+	}
+	if verdicts := g.ReadReputation("mail.example.net"); len(verdicts) != 0 {
+		t.Errorf("The target without a verdict was tagged: %v", verdicts)
+	}
+	if n := atomic.LoadInt32(&checker.checks); n != 2 {
+		t.Errorf("Expected each target to be checked once, got %d checks", n)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
-	"fmt"
 	"net"
 	"strconv"
 	"strings"
@@ -16,7 +15,6 @@ import (
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
 	amassnet "github.com/OWASP/Amass/v3/net"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -93,12 +91,12 @@ func (r *Robtex) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	r.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, r.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", r.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, r.String(), "Querying for %s subdomains", req.Domain))
 
 	url := "https://freeapi.robtex.com/pdns/forward/" + req.Domain
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := r.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -107,7 +105,7 @@ func (r *Robtex) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 		if line.Type == "A" {
 			ips.Insert(line.Data)
 		} else if line.Type == "NS" || line.Type == "MX" {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   strings.Trim(line.Data, "."),
 				Domain: req.Domain,
 				Tag:    r.Type(),
@@ -126,10 +124,10 @@ loop:
 			bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, r.String())
 
 			url = "https://freeapi.robtex.com/pdns/reverse/" + ip
-			pdns, err := http.RequestWebPage(url, nil, nil, "", "")
+			pdns, err := r.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 			if err != nil {
 				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-					fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+					requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 				continue loop
 			}
 
@@ -137,7 +135,7 @@ loop:
 				n := strings.Trim(line.Name, ".")
 
 				if d := cfg.WhichDomain(n); d != "" {
-					bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+					PublishName(ctx, &requests.DNSRequest{
 						Name:   n,
 						Domain: d,
 						Tag:    r.Type(),
@@ -230,9 +228,9 @@ func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, r.String())
 
 	url := "https://freeapi.robtex.com/ipquery/" + addr
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := r.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return nil
 	}
 	// Extract the network information
@@ -262,7 +260,7 @@ func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
 
 	for _, n := range ipinfo.ActiveDNS {
 		if cfg.IsDomainInScope(n.Name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   n.Name,
 				Domain: r.System().Pool().SubdomainToDomain(n.Name),
 				Tag:    r.Type(),
@@ -273,7 +271,7 @@ func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
 
 	for _, n := range ipinfo.ActiveDNSHistory {
 		if cfg.IsDomainInScope(n.Name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   n.Name,
 				Domain: r.System().Pool().SubdomainToDomain(n.Name),
 				Tag:    r.Type(),
@@ -284,7 +282,7 @@ func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
 
 	for _, n := range ipinfo.PassiveDNS {
 		if cfg.IsDomainInScope(n.Name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   n.Name,
 				Domain: r.System().Pool().SubdomainToDomain(n.Name),
 				Tag:    r.Type(),
@@ -295,7 +293,7 @@ func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
 
 	for _, n := range ipinfo.PassiveDNSHistory {
 		if cfg.IsDomainInScope(n.Name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   n.Name,
 				Domain: r.System().Pool().SubdomainToDomain(n.Name),
 				Tag:    r.Type(),
@@ -306,7 +304,7 @@ func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
 
 	if ipinfo.ASN == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the origin response: %v", r.String(), url, err),
+			requests.NewLogEntry(requests.LogError, r.String(), "%s: Failed to parse the origin response: %v", url, err),
 		)
 		return nil
 	}
@@ -342,9 +340,9 @@ func (r *Robtex) netblocks(ctx context.Context, asn int) stringset.Set {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, r.String())
 
 	url := "https://freeapi.robtex.com/asquery/" + strconv.Itoa(asn)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := r.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, r.String(), "%s: %v", url, err))
 		return netblocks
 	}
 	// Extract the network information
@@ -364,7 +362,7 @@ func (r *Robtex) netblocks(ctx context.Context, asn int) stringset.Set {
 
 	if len(netblocks) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: Failed to acquire netblocks for ASN %d", r.String(), asn),
+			requests.NewLogEntry(requests.LogError, r.String(), "Failed to acquire netblocks for ASN %d", asn),
 		)
 	}
 	return netblocks
@@ -12,7 +12,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -68,7 +67,7 @@ func (st *SecurityTrails) OnDNSRequest(ctx context.Context, req *requests.DNSReq
 	st.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, st.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", st.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, st.String(), "Querying for %s subdomains", req.Domain))
 
 	url := st.restDNSURL(req.Domain)
 	headers := map[string]string{
@@ -76,9 +75,9 @@ func (st *SecurityTrails) OnDNSRequest(ctx context.Context, req *requests.DNSReq
 		"Content-Type": "application/json",
 	}
 
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := st.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", st.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, st.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the subdomain names from the REST API results
@@ -92,7 +91,7 @@ func (st *SecurityTrails) OnDNSRequest(ctx context.Context, req *requests.DNSReq
 	for _, s := range subs.Subdomains {
 		name := strings.ToLower(s) + "." + req.Domain
 		if re.MatchString(name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   name,
 				Domain: req.Domain,
 				Tag:    st.SourceType,
@@ -127,9 +126,9 @@ func (st *SecurityTrails) OnWhoisRequest(ctx context.Context, req *requests.Whoi
 		"Content-Type": "application/json",
 	}
 
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := st.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", st.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, st.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the whois information from the REST API results
@@ -0,0 +1,69 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/miekg/dns"
+)
+
+// The policies for the records whose data points to the owner name.
+const (
+	// SelfReferenceFlag stores the records and flags them in the graph
+	SelfReferenceFlag = "flag"
+	// SelfReferenceDrop drops the records
+	SelfReferenceDrop = "drop"
+)
+
+// SelfReferences returns the number of records found with data pointing to the owner name.
+func (dms *DataManagerService) SelfReferences() uint64 {
+	return atomic.LoadUint64(&dms.selfReferences)
+}
+
+// selfReferencedRecords returns the records of the request, leaving out the records whose
+// normalized data equals the normalized owner name when the config policy drops them.
+// Otherwise, those records are flagged in the graph databases.
+func (dms *DataManagerService) selfReferencedRecords(ctx context.Context, cfg *config.Config, req *requests.DNSRequest) []requests.DNSAnswer {
+	drop := strings.EqualFold(cfg.SelfReferencePolicy, SelfReferenceDrop)
+
+	var records []requests.DNSAnswer
+	for _, r := range req.Records {
+		owner := r.Name
+		if owner == "" {
+			owner = req.Name
+		}
+
+		rrtype := dns.TypeToString[uint16(r.Type)]
+		if rrtype == "" || owner == "" || r.Data != owner {
+			records = append(records, r)
+			continue
+		}
+
+		atomic.AddUint64(&dms.selfReferences, 1)
+		if drop {
+			continue
+		}
+
+		records = append(records, r)
+		// The data equals the owner name
+		name := owner
+		dms.writeGraphs(ctx, func(g *graph.Graph) error {
+			if err := g.InsertSelfReference(name, rrtype, name, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to flag the self-referencing record: %v", g, err).With("graph", g))
+				return err
+			}
+			return nil
+		})
+	}
+	return records
+}
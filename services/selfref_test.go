@@ -0,0 +1,66 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+func TestSelfReferencedRecords(t *testing.T) {
+	for _, test := range []struct {
+		policy  string
+		flagged bool
+		stored  bool
+	}{
+		{"", true, true},
+		{services.SelfReferenceFlag, true, true},
+		{services.SelfReferenceDrop, false, false},
+	} {
+		cfg := config.NewConfig()
+		cfg.AddDomain("owasp.org")
+		cfg.SelfReferencePolicy = test.policy
+
+		h := servicetest.NewHarness(cfg)
+
+		dms := services.NewDataManagerService(h.Sys)
+		h.Process(t, dms, &requests.DNSRequest{
+			Name:   "self.owasp.org",
+			Domain: "owasp.org",
+			Records: []requests.DNSAnswer{
+				{Name: "self.owasp.org", Type: int(dns.TypeA), Data: "Self.OWASP.org."},
+				{Name: "self.owasp.org", Type: int(dns.TypeA), Data: "192.0.2.1"},
+			},
+			Tag:    requests.DNS,
+			Source: "DNS",
+		})
+		h.Close()
+
+		g := h.Sys.Graph()
+		if got := g.IsSelfReference("self.owasp.org", "A", "self.owasp.org"); got != test.flagged {
+			t.Errorf("The %q policy flagged the self-referencing record: %t, expected %t", test.policy, got, test.flagged)
+		}
+		if got := dms.SelfReferences(); got != 1 {
+			t.Errorf("The %q policy counted %d self-referencing records, expected 1", test.policy, got)
+		}
+
+		var self, addr bool
+		for _, r := range g.NameRecords("self.owasp.org") {
+			switch r.Data {
+			case "self.owasp.org":
+				self = true
+			case "192.0.2.1":
+				addr = true
+			}
+		}
+		if self != test.stored || !addr {
+			t.Errorf("The %q policy stored the self-referencing record: %t, and the address: %t", test.policy, self, addr)
+		}
+	}
+}
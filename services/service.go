@@ -8,12 +8,17 @@ import (
 	"errors"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/OWASP/Amass/v3/eventbus"
 	"github.com/OWASP/Amass/v3/queue"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
+// ActivityInterval is the minimum time between the activity publications of a service.
+const ActivityInterval = 500 * time.Millisecond
+
 // Possible values for the AmassService.APIKeyRequired field.
 const (
 	APIKeyRequired int = iota
@@ -45,6 +50,9 @@ type Service interface {
 	// RequestLen returns the current length of the request queue
 	RequestLen() int
 
+	// InFlight returns the number of requests being handled
+	InFlight() int
+
 	// Methods to support processing of DNSRequests
 	DNSRequest(ctx context.Context, req *requests.DNSRequest)
 	OnDNSRequest(ctx context.Context, req *requests.DNSRequest)
@@ -96,6 +104,9 @@ type BaseService struct {
 	// The queue for all incoming request types
 	queue *queue.Queue
 
+	// The number of queued requests being handled
+	inflight int32
+
 	// The broadcast channel closed when the service is stopped
 	quit chan struct{}
 
@@ -109,6 +120,10 @@ type BaseService struct {
 	rateLimit time.Duration
 	lastLock  sync.Mutex
 	last      time.Time
+
+	// The time of the last activity publication
+	activeLock sync.Mutex
+	activeAt   time.Time
 }
 
 // NewBaseService returns an initialized BaseService object.
@@ -170,6 +185,11 @@ func (bas *BaseService) RequestLen() int {
 	return bas.queue.Len()
 }
 
+// InFlight returns the number of queued requests being handled.
+func (bas *BaseService) InFlight() int {
+	return int(atomic.LoadInt32(&bas.inflight))
+}
+
 // DNSRequest adds the request provided by the parameter to the service request channel.
 func (bas *BaseService) DNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	bas.queueRequest(bas.service.OnDNSRequest, ctx, req)
@@ -240,6 +260,46 @@ func (bas *BaseService) Stats() *ServiceStats {
 	return new(ServiceStats)
 }
 
+// SetActive publishes the activity of the service on the SetActiveTopic, at most once each
+// ActivityInterval, so the frequent calls do not load the event bus. Returns true when the
+// activity was published.
+func (bas *BaseService) SetActive(ctx context.Context) bool {
+	bus, ok := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if !ok || bus == nil || !bas.activityDue() {
+		return false
+	}
+
+	// Services that observe their publications send the activity through their own publish
+	if p, ok := bas.service.(publisher); ok {
+		p.publish(ctx, requests.SetActiveTopic, eventbus.PriorityCritical, bas.String())
+		return true
+	}
+
+	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, bas.String())
+	return true
+}
+
+// publisher is implemented by the services that send their event bus publications through
+// their own publish method.
+type publisher interface {
+	publish(ctx context.Context, topic string, priority int, args ...interface{})
+}
+
+// activityDue returns true when the ActivityInterval has passed since the last activity
+// publication, and records the time of the new publication.
+func (bas *BaseService) activityDue() bool {
+	bas.activeLock.Lock()
+	defer bas.activeLock.Unlock()
+
+	now := time.Now()
+	if now.Sub(bas.activeAt) < ActivityInterval {
+		return false
+	}
+
+	bas.activeAt = now
+	return true
+}
+
 // SetRateLimit sets the minimum wait between checks.
 func (bas *BaseService) SetRateLimit(min time.Duration) {
 	bas.rateLimit = min
@@ -304,8 +364,40 @@ loop:
 				continue loop
 			default:
 				// Call the queued function or method
+				atomic.AddInt32(&bas.inflight, 1)
 				e.Func.Call(e.Args)
+				atomic.AddInt32(&bas.inflight, -1)
 			}
 		}
 	}
 }
+
+// StopServices stops the services once the requests being handled have returned, or once the
+// grace period has elapsed. The services still handling requests after the grace period are
+// stopped regardless and returned.
+func StopServices(srvs []Service, grace time.Duration) []Service {
+	deadline := time.Now().Add(grace)
+
+	for {
+		var busy bool
+		for _, srv := range srvs {
+			if srv.InFlight() > 0 {
+				busy = true
+				break
+			}
+		}
+		if !busy || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	var forced []Service
+	for _, srv := range srvs {
+		if srv.InFlight() > 0 {
+			forced = append(forced, srv)
+		}
+		srv.Stop()
+	}
+	return forced
+}
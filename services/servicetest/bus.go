@@ -0,0 +1,136 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package servicetest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// Capture collects the messages published on a topic of the EventBus.
+type Capture struct {
+	sync.Mutex
+	msgs []interface{}
+}
+
+// CaptureTopic subscribes to the topic and returns the Capture collecting the messages.
+// Each message is the single published argument, or the slice of arguments otherwise.
+func CaptureTopic(bus *eventbus.EventBus, topic string) *Capture {
+	c := new(Capture)
+
+	bus.Subscribe(topic, func(args ...interface{}) {
+		c.Lock()
+		defer c.Unlock()
+
+		if len(args) == 1 {
+			c.msgs = append(c.msgs, args[0])
+			return
+		}
+		c.msgs = append(c.msgs, args)
+	})
+	return c
+}
+
+// Messages returns the messages collected so far.
+func (c *Capture) Messages() []interface{} {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]interface{}(nil), c.msgs...)
+}
+
+// Len returns the number of messages collected so far.
+func (c *Capture) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return len(c.msgs)
+}
+
+// Wait blocks until at least num messages have been collected, and returns false when the
+// timeout expires first.
+func (c *Capture) Wait(num int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	for c.Len() < num {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// DNSRequests returns the collected messages that are DNS requests.
+func (c *Capture) DNSRequests() []*requests.DNSRequest {
+	var reqs []*requests.DNSRequest
+
+	for _, msg := range c.Messages() {
+		if req, ok := msg.(*requests.DNSRequest); ok {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// AddrRequests returns the collected messages that are address requests.
+func (c *Capture) AddrRequests() []*requests.AddrRequest {
+	var reqs []*requests.AddrRequest
+
+	for _, msg := range c.Messages() {
+		if req, ok := msg.(*requests.AddrRequest); ok {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// LogEntries returns the collected messages that are log entries.
+func (c *Capture) LogEntries() []*requests.LogEntry {
+	var entries []*requests.LogEntry
+
+	for _, msg := range c.Messages() {
+		if le, ok := msg.(*requests.LogEntry); ok {
+			entries = append(entries, le)
+		}
+	}
+	return entries
+}
+
+// Drain blocks until the events published on the bus so far have been handed to the
+// subscribers, and returns false when the timeout expires first.
+func Drain(bus *eventbus.EventBus, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	// The callbacks run in their own goroutines, so the second marker gives the callbacks
+	// started along with the first marker the time to complete
+	for i := 0; i < 2; i++ {
+		if !drainMarker(bus, time.Until(deadline)) {
+			return false
+		}
+	}
+	return true
+}
+
+func drainMarker(bus *eventbus.EventBus, timeout time.Duration) bool {
+	done := make(chan struct{})
+	topic := fmt.Sprintf("servicetest:drain:%p", done)
+	fn := func() { close(done) }
+
+	bus.Subscribe(topic, fn)
+	defer bus.Unsubscribe(topic, fn)
+	// The low priority events are only dispatched once the other queues are empty
+	bus.Publish(topic, eventbus.PriorityLow)
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
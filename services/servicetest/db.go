@@ -0,0 +1,129 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package servicetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph/db"
+)
+
+// The operations recorded by the RecordingDB.
+const (
+	InsertNodeOp     = "node"
+	InsertEdgeOp     = "edge"
+	InsertPropertyOp = "property"
+)
+
+// Insert is a single insert call made on the RecordingDB. The subject is the node identifier,
+// the predicate is the node type, edge predicate or property predicate, and the object is the
+// identifier of the edge target or the property value.
+type Insert struct {
+	Op        string
+	Subject   string
+	Predicate string
+	Object    string
+}
+
+// RecordingDB is an in-memory GraphDatabase that records all the insert calls made on it.
+type RecordingDB struct {
+	db.GraphDatabase
+
+	sync.Mutex
+	inserts []Insert
+}
+
+// NewRecordingDB returns a RecordingDB backed by an in-memory Cayley graph.
+func NewRecordingDB() *RecordingDB {
+	return &RecordingDB{GraphDatabase: db.NewCayleyGraphMemory()}
+}
+
+func (r *RecordingDB) record(ins Insert) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.inserts = append(r.inserts, ins)
+}
+
+// InsertNode implements the GraphDatabase interface.
+func (r *RecordingDB) InsertNode(id, ntype string) (db.Node, error) {
+	r.record(Insert{
+		Op:        InsertNodeOp,
+		Subject:   id,
+		Predicate: ntype,
+	})
+
+	return r.GraphDatabase.InsertNode(id, ntype)
+}
+
+// InsertEdge implements the GraphDatabase interface. The edge is recorded once it was
+// inserted, so the tests waiting on the edge can read it from the graph.
+func (r *RecordingDB) InsertEdge(edge *db.Edge) error {
+	err := r.GraphDatabase.InsertEdge(edge)
+
+	if edge != nil {
+		r.record(Insert{
+			Op:        InsertEdgeOp,
+			Subject:   r.NodeToID(edge.From),
+			Predicate: edge.Predicate,
+			Object:    r.NodeToID(edge.To),
+		})
+	}
+	return err
+}
+
+// InsertProperty implements the GraphDatabase interface.
+func (r *RecordingDB) InsertProperty(node db.Node, predicate, value string) error {
+	r.record(Insert{
+		Op:        InsertPropertyOp,
+		Subject:   r.NodeToID(node),
+		Predicate: predicate,
+		Object:    value,
+	})
+
+	return r.GraphDatabase.InsertProperty(node, predicate, value)
+}
+
+// Inserts returns the insert calls recorded in the order they were made.
+func (r *RecordingDB) Inserts() []Insert {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]Insert(nil), r.inserts...)
+}
+
+// Edges returns the recorded edge inserts using the predicate.
+func (r *RecordingDB) Edges(predicate string) []Insert {
+	var edges []Insert
+
+	for _, ins := range r.Inserts() {
+		if ins.Op == InsertEdgeOp && ins.Predicate == predicate {
+			edges = append(edges, ins)
+		}
+	}
+	return edges
+}
+
+// WaitEdges blocks until at least num edge inserts using the predicate have been recorded,
+// and returns false when the timeout expires first.
+func (r *RecordingDB) WaitEdges(predicate string, num int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	for len(r.Edges(predicate)) < num {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// Reset discards the insert calls recorded so far, while keeping the stored graph.
+func (r *RecordingDB) Reset() {
+	r.Lock()
+	defer r.Unlock()
+
+	r.inserts = nil
+}
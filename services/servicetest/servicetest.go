@@ -0,0 +1,83 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package servicetest provides a System, graph database and event bus helpers for testing
+// the services without standing up a full enumeration.
+package servicetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+)
+
+// WaitTimeout is the maximum time the helpers wait for a service to handle a request.
+var WaitTimeout = 10 * time.Second
+
+// NewContext returns a context carrying the configuration and event bus, the way the
+// services expect them during an enumeration.
+func NewContext(cfg *config.Config, bus *eventbus.EventBus) context.Context {
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, cfg)
+	return context.WithValue(ctx, requests.ContextEventBus, bus)
+}
+
+// Harness bundles the System, event bus and context used to test a service.
+type Harness struct {
+	Sys *System
+	Bus *eventbus.EventBus
+	Ctx context.Context
+}
+
+// NewHarness returns a Harness using the configuration, or a default configuration when nil.
+func NewHarness(cfg *config.Config) *Harness {
+	sys := NewSystem(cfg)
+	bus := eventbus.NewEventBus(1000)
+
+	return &Harness{
+		Sys: sys,
+		Bus: bus,
+		Ctx: NewContext(sys.Config(), bus),
+	}
+}
+
+// Close stops the event bus and shuts down the System.
+func (h *Harness) Close() {
+	h.Bus.Stop()
+	h.Sys.Shutdown()
+}
+
+// ProcessDNSRequest hands the request to the DataManagerService and returns once the records
+// of the request have been handled.
+func ProcessDNSRequest(ctx context.Context, dms *services.DataManagerService, req *requests.DNSRequest) error {
+	start := time.Now()
+	dms.OnDNSRequest(ctx, req)
+
+	// The service only processes one request at a time, so the request has been handled once
+	// the service became active after the start and is no longer processing
+	deadline := start.Add(WaitTimeout)
+	for time.Now().Before(deadline) {
+		if hs := dms.Health(); !hs.LastActive.Before(start) && hs.Processing == 0 {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return errors.New("ProcessDNSRequest: The request was not handled before the timeout")
+}
+
+// Process hands the requests to the DataManagerService using the context of the Harness, and
+// fails the test when a request is not handled.
+func (h *Harness) Process(t testing.TB, dms *services.DataManagerService, reqs ...*requests.DNSRequest) {
+	t.Helper()
+
+	for _, req := range reqs {
+		if err := ProcessDNSRequest(h.Ctx, dms, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package servicetest
+
+import (
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/services"
+)
+
+// System is a services.System providing a configuration, a graph database backed by a
+// RecordingDB, optional additional graph databases and an optional resolver.
+type System struct {
+	sync.Mutex
+	cfg         *config.Config
+	pool        resolvers.Resolver
+	limiter     *resolvers.QueryLimiter
+	dialer      *amassnet.RateLimitedDialer
+	checker     *amassnet.PortChecker
+	client      *amasshttp.Client
+	suffixes    *amassdns.SuffixList
+	db          *RecordingDB
+	graph       *graph.Graph
+	graphs      []*graph.Graph
+	dataSources []services.Service
+	coreSrvs    []services.Service
+}
+
+// NewSystem returns a System using the configuration, or a default configuration when nil.
+func NewSystem(cfg *config.Config) *System {
+	if cfg == nil {
+		cfg = config.NewConfig()
+	}
+
+	client, _ := amasshttp.NewClient(amasshttp.ClientSettings{})
+	rdb := NewRecordingDB()
+	return &System{
+		cfg:      cfg,
+		limiter:  resolvers.NewQueryLimiter(0),
+		dialer:   amassnet.NewRateLimitedDialer(0, 0),
+		checker:  amassnet.NewPortChecker(nil, 0, 0),
+		client:   client,
+		suffixes: cfg.Suffixes(),
+		db:       rdb,
+		graph:    graph.NewGraph(rdb),
+	}
+}
+
+// SetPool sets the resolver returned by the Pool method.
+func (s *System) SetPool(pool resolvers.Resolver) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.pool = pool
+}
+
+// AddCoreService appends the service to the slice returned by the CoreServices method.
+func (s *System) AddCoreService(srv services.Service) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.coreSrvs = append(s.coreSrvs, srv)
+}
+
+// AddGraph appends the graph database to the slice returned by the GraphDatabases method,
+// after the graph database backed by the RecordingDB.
+func (s *System) AddGraph(g *graph.Graph) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.graphs = append(s.graphs, g)
+}
+
+// DB returns the RecordingDB used by the graph database of the System.
+func (s *System) DB() *RecordingDB {
+	return s.db
+}
+
+// Graph returns the graph database of the System.
+func (s *System) Graph() *graph.Graph {
+	return s.graph
+}
+
+// Config implements the services.System interface.
+func (s *System) Config() *config.Config {
+	return s.cfg
+}
+
+// Pool implements the services.System interface.
+func (s *System) Pool() resolvers.Resolver {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.pool
+}
+
+// QueryLimiter implements the services.System interface.
+func (s *System) QueryLimiter() *resolvers.QueryLimiter {
+	return s.limiter
+}
+
+// Dialer implements the services.System interface.
+func (s *System) Dialer() *amassnet.RateLimitedDialer {
+	return s.dialer
+}
+
+// PortChecker implements the services.System interface.
+func (s *System) PortChecker() *amassnet.PortChecker {
+	return s.checker
+}
+
+// HTTPClient implements the services.System interface.
+func (s *System) HTTPClient() *amasshttp.Client {
+	return s.client
+}
+
+// Suffixes implements the services.System interface.
+func (s *System) Suffixes() *amassdns.SuffixList {
+	return s.suffixes
+}
+
+// AddSource implements the services.System interface.
+func (s *System) AddSource(srv services.Service) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.dataSources = append(s.dataSources, srv)
+	return nil
+}
+
+// AddAndStart implements the services.System interface.
+func (s *System) AddAndStart(srv services.Service) error {
+	if err := srv.Start(); err != nil {
+		return err
+	}
+
+	return s.AddSource(srv)
+}
+
+// DataSources implements the services.System interface.
+func (s *System) DataSources() []services.Service {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]services.Service(nil), s.dataSources...)
+}
+
+// CoreServices implements the services.System interface.
+func (s *System) CoreServices() []services.Service {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]services.Service(nil), s.coreSrvs...)
+}
+
+// GraphDatabases implements the services.System interface.
+func (s *System) GraphDatabases() []*graph.Graph {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]*graph.Graph{s.graph}, s.graphs...)
+}
+
+// Shutdown implements the services.System interface.
+func (s *System) Shutdown() error {
+	for _, srv := range append(s.CoreServices(), s.DataSources()...) {
+		srv.Stop()
+	}
+
+	for _, g := range s.GraphDatabases() {
+		g.Close()
+	}
+	return nil
+}
@@ -139,7 +139,7 @@ func (s *ShadowServer) origin(ctx context.Context, addr string) *requests.ASNReq
 	answers, _, err := s.System().Pool().Resolve(ctx, name, "TXT", resolvers.PriorityCritical)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: DNS TXT record query error: %v", s.String(), name, err),
+			requests.NewLogEntry(requests.LogError, s.String(), "%s: DNS TXT record query error: %v", name, err),
 		)
 		return nil
 	}
@@ -147,7 +147,7 @@ func (s *ShadowServer) origin(ctx context.Context, addr string) *requests.ASNReq
 	fields := strings.Split(strings.Trim(answers[0].Data, "\""), " | ")
 	if len(fields) < 5 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the origin response", s.String(), name),
+			requests.NewLogEntry(requests.LogError, s.String(), "%s: Failed to parse the origin response", name),
 		)
 		return nil
 	}
@@ -155,7 +155,7 @@ func (s *ShadowServer) origin(ctx context.Context, addr string) *requests.ASNReq
 	asn, err := strconv.Atoi(strings.TrimSpace(fields[0]))
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the origin response: %v", s.String(), name, err),
+			requests.NewLogEntry(requests.LogError, s.String(), "%s: Failed to parse the origin response: %v", name, err),
 		)
 		return nil
 	}
@@ -184,14 +184,14 @@ func (s *ShadowServer) netblocks(ctx context.Context, asn int) stringset.Set {
 		answers, _, err := s.System().Pool().Resolve(ctx, ShadowServerWhoisURL, "A", resolvers.PriorityCritical)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: %s: %v", s.String(), ShadowServerWhoisURL, err))
+				requests.NewLogEntry(requests.LogError, s.String(), "%s: %v", ShadowServerWhoisURL, err))
 			return netblocks
 		}
 
 		ip := answers[0].Data
 		if ip == "" {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-				fmt.Sprintf("%s: Failed to resolve %s", s.String(), ShadowServerWhoisURL),
+				requests.NewLogEntry(requests.LogError, s.String(), "Failed to resolve %s", ShadowServerWhoisURL),
 			)
 			return netblocks
 		}
@@ -204,7 +204,7 @@ func (s *ShadowServer) netblocks(ctx context.Context, asn int) stringset.Set {
 	d := net.Dialer{}
 	conn, err := d.DialContext(ctx, "tcp", s.addr+":43")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", s.String(), err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, s.String(), "%v", err))
 		return netblocks
 	}
 	defer conn.Close()
@@ -222,7 +222,7 @@ func (s *ShadowServer) netblocks(ctx context.Context, asn int) stringset.Set {
 
 	if len(netblocks) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: Failed to acquire netblocks for ASN %d", s.String(), asn))
+			requests.NewLogEntry(requests.LogError, s.String(), "Failed to acquire netblocks for ASN %d", asn))
 	}
 	return netblocks
 }
@@ -11,7 +11,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -65,13 +64,13 @@ func (s *Shodan) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	s.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, s.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", s.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, s.String(), "Querying for %s subdomains", req.Domain))
 
 	url := s.restURL(req.Domain)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := s.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", s.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, s.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the subdomain names from the REST API results
@@ -86,7 +85,7 @@ func (s *Shodan) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 		name := sub + "." + req.Domain
 
 		if re.MatchString(name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   name,
 				Domain: req.Domain,
 				Tag:    s.SourceType,
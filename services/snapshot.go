@@ -0,0 +1,119 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"time"
+)
+
+// StatsSnapshot holds the cumulative counters of the DataManagerService at a point in time.
+type StatsSnapshot struct {
+	Time time.Time `json:"time"`
+
+	// The records stored for each record type
+	Records map[string]uint64 `json:"records"`
+
+	// The graph database writes and failures
+	Writes       uint64 `json:"writes"`
+	InsertErrors uint64 `json:"insert_errors"`
+
+	// The names and records that were dropped
+	Denied      uint64 `json:"denied"`
+	EmptyDomain uint64 `json:"empty_domain"`
+	FanOut      uint64 `json:"fanout_dropped"`
+}
+
+// InsertErrorRate returns the share of the graph database writes that failed.
+func (s *StatsSnapshot) InsertErrorRate() float64 {
+	if s.Writes == 0 {
+		return 0
+	}
+	return float64(s.InsertErrors) / float64(s.Writes)
+}
+
+// StatsDiff holds the change of the DataManagerService counters between two snapshots.
+type StatsDiff struct {
+	Elapsed time.Duration `json:"elapsed"`
+
+	// The records stored for each record type, and the rate per second
+	Records       map[string]uint64  `json:"records"`
+	RecordsPerSec map[string]float64 `json:"records_per_sec"`
+
+	// The graph database writes and failures, and the share of those writes that failed
+	Writes          uint64  `json:"writes"`
+	InsertErrors    uint64  `json:"insert_errors"`
+	InsertErrorRate float64 `json:"insert_error_rate"`
+
+	// The change of the cumulative error rate from the first snapshot to the second
+	ErrorRateDelta float64 `json:"error_rate_delta"`
+}
+
+// Snapshot returns the timestamped cumulative counters of the service.
+func (dms *DataManagerService) Snapshot() *StatsSnapshot {
+	s := &StatsSnapshot{
+		Time:        time.Now(),
+		Records:     make(map[string]uint64),
+		Denied:      dms.Denied(),
+		EmptyDomain: dms.EmptyDomain(),
+		FanOut:      dms.FanOutDropped(),
+	}
+	s.Writes, s.InsertErrors = dms.health.totals()
+
+	dms.statsLock.Lock()
+	for rtype, num := range dms.storedRecords {
+		s.Records[rtype] = num
+	}
+	dms.statsLock.Unlock()
+
+	return s
+}
+
+// DiffSnapshots returns the change of the counters from the earlier snapshot to the later one,
+// which turns the cumulative counters into rates.
+func DiffSnapshots(earlier, later *StatsSnapshot) *StatsDiff {
+	d := &StatsDiff{
+		Elapsed:       later.Time.Sub(earlier.Time),
+		Records:       make(map[string]uint64),
+		RecordsPerSec: make(map[string]float64),
+		Writes:        counterDelta(earlier.Writes, later.Writes),
+		InsertErrors:  counterDelta(earlier.InsertErrors, later.InsertErrors),
+	}
+
+	for rtype, num := range later.Records {
+		n := counterDelta(earlier.Records[rtype], num)
+		if n == 0 {
+			continue
+		}
+
+		d.Records[rtype] = n
+		if d.Elapsed > 0 {
+			d.RecordsPerSec[rtype] = float64(n) / d.Elapsed.Seconds()
+		}
+	}
+
+	if d.Writes > 0 {
+		d.InsertErrorRate = float64(d.InsertErrors) / float64(d.Writes)
+	}
+	d.ErrorRateDelta = later.InsertErrorRate() - earlier.InsertErrorRate()
+	return d
+}
+
+// counterDelta returns the increase of the counter, where a counter lower than before counts as zero.
+func counterDelta(before, after uint64) uint64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
+
+// countRecord increments the number of records stored for the record type.
+func (dms *DataManagerService) countRecord(rtype string) {
+	dms.statsLock.Lock()
+	defer dms.statsLock.Unlock()
+
+	if dms.storedRecords == nil {
+		dms.storedRecords = make(map[string]uint64)
+	}
+	dms.storedRecords[rtype]++
+}
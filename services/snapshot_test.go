@@ -0,0 +1,96 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+func TestStatsSnapshots(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+
+	dms := services.NewDataManagerService(h.Sys)
+	process := func(name string, rrtype uint16, data ...string) {
+		var records []requests.DNSAnswer
+		for _, d := range data {
+			records = append(records, requests.DNSAnswer{Name: name, Type: int(rrtype), Data: d})
+		}
+
+		h.Process(t, dms, &requests.DNSRequest{
+			Name:    name,
+			Domain:  "owasp.org",
+			Records: records,
+			Tag:     requests.DNS,
+			Source:  "DNS",
+		})
+	}
+
+	process("www.owasp.org", dns.TypeA, "192.0.2.1")
+	first := dms.Snapshot()
+
+	time.Sleep(10 * time.Millisecond)
+	process("api.owasp.org", dns.TypeA, "192.0.2.2", "192.0.2.3")
+	process("docs.owasp.org", dns.TypeCNAME, "www.owasp.org")
+	second := dms.Snapshot()
+
+	if first.Records["A"] != 1 || second.Records["A"] != 3 || second.Records["CNAME"] != 1 {
+		t.Errorf("Unexpected cumulative record counts: %v and %v", first.Records, second.Records)
+	}
+
+	diff := services.DiffSnapshots(first, second)
+	if diff.Elapsed <= 0 {
+		t.Errorf("Expected the snapshots to be apart in time, got %s", diff.Elapsed)
+	}
+	if diff.Records["A"] != 2 || diff.Records["CNAME"] != 1 || len(diff.Records) != 2 {
+		t.Errorf("Unexpected record deltas: %v", diff.Records)
+	}
+	if rate := diff.RecordsPerSec["A"]; rate <= 0 || rate != 2/diff.Elapsed.Seconds() {
+		t.Errorf("Unexpected rate of the A records: %f", rate)
+	}
+	if diff.Writes == 0 || diff.InsertErrors != 0 || diff.InsertErrorRate != 0 || diff.ErrorRateDelta != 0 {
+		t.Errorf("Unexpected write deltas: %d writes, %d errors, %f rate, %f rate delta",
+			diff.Writes, diff.InsertErrors, diff.InsertErrorRate, diff.ErrorRateDelta)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	now := time.Now()
+	earlier := &services.StatsSnapshot{
+		Time:         now,
+		Records:      map[string]uint64{"A": 10, "MX": 2},
+		Writes:       100,
+		InsertErrors: 0,
+	}
+	later := &services.StatsSnapshot{
+		Time:         now.Add(2 * time.Second),
+		Records:      map[string]uint64{"A": 30, "MX": 2, "NS": 4},
+		Writes:       200,
+		InsertErrors: 20,
+	}
+
+	diff := services.DiffSnapshots(earlier, later)
+	if diff.RecordsPerSec["A"] != 10 || diff.RecordsPerSec["NS"] != 2 {
+		t.Errorf("Unexpected record rates: %v", diff.RecordsPerSec)
+	}
+	if _, found := diff.Records["MX"]; found {
+		t.Errorf("The unchanged MX counter was included in the diff")
+	}
+	if diff.Writes != 100 || diff.InsertErrors != 20 || diff.InsertErrorRate != 0.2 {
+		t.Errorf("Unexpected write deltas: %d writes, %d errors, %f rate", diff.Writes, diff.InsertErrors, diff.InsertErrorRate)
+	}
+	if diff.ErrorRateDelta != 0.1 {
+		t.Errorf("Expected the error rate delta 0.1, got %f", diff.ErrorRateDelta)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/semaphore"
@@ -24,7 +26,7 @@ import (
 
 var (
 	nameStripRE = regexp.MustCompile("^((20)|(25)|(2b)|(2f)|(3d)|(3a)|(40))+")
-	maxCrawlSem = semaphore.NewSimpleSemaphore(50)
+	maxCrawlSem = semaphore.NewWeightedSemaphore(50)
 )
 
 // GetAllSources returns a slice of all data source services, initialized and ready.
@@ -112,6 +114,54 @@ func shouldEnable(srvName string, cfg *config.Config) bool {
 	return include
 }
 
+// PublishName sends the name out on the NewNameTopic once it has been validated as a DNS
+// hostname. The invalid names are sent out on the InvalidNameTopic, so they are counted for
+// the event, and logged when verbose output was requested.
+func PublishName(ctx context.Context, req *requests.DNSRequest) {
+	if !validName(ctx, req) {
+		return
+	}
+
+	if bus, ok := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus); ok && bus != nil {
+		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, req)
+	}
+}
+
+// validName normalizes the name and domain of the request, and returns true when the name
+// is a valid DNS hostname.
+func validName(ctx context.Context, req *requests.DNSRequest) bool {
+	bus, ok := ctx.Value(requests.ContextEventBus).(*eventbus.EventBus)
+	if !ok || bus == nil || req == nil {
+		return false
+	}
+
+	// Internationalized names are published in the punycode form, and asterisk labels
+	// are removed before the name is validated
+	name, err := amassdns.NormalizeName(req.Name)
+	if err == nil {
+		err = amassdns.ValidateHostname(amassdns.RemoveAsteriskLabel(name))
+	}
+	if err != nil {
+		bus.Publish(requests.InvalidNameTopic, eventbus.PriorityLow, req)
+
+		if cfg, ok := ctx.Value(requests.ContextConfig).(*config.Config); ok && cfg != nil && cfg.Verbose {
+			bus.Publish(requests.LogTopic, eventbus.PriorityLow,
+				requests.NewLogEntry(requests.LogWarn, req.Source, "Dropped the invalid name %q: %v", req.Name, err))
+		}
+		return false
+	}
+
+	req.Name = name
+	if domain, err := amassdns.NormalizeName(req.Domain); err == nil {
+		req.Domain = domain
+	}
+	// The request is attributed to the event publishing it
+	if cfg, ok := ctx.Value(requests.ContextConfig).(*config.Config); ok && cfg != nil && req.EventID == "" {
+		req.EventID = cfg.UUID.String()
+	}
+	return true
+}
+
 // Clean up the names scraped from the web.
 func cleanName(name string) string {
 	name = strings.TrimSpace(strings.ToLower(name))
@@ -140,7 +190,9 @@ func crawl(ctx context.Context, baseURL, baseDomain, subdomain, domain string) (
 		return results.Slice(), errors.New("crawler error: Failed to obtain the config from Context")
 	}
 
-	maxCrawlSem.Acquire(1)
+	if err := maxCrawlSem.Acquire(ctx, 1); err != nil {
+		return results.Slice(), fmt.Errorf("crawler error: %v", err)
+	}
 	defer maxCrawlSem.Release(1)
 
 	re := cfg.DomainRegex(domain)
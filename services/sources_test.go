@@ -64,6 +64,56 @@ func TestCleanName(t *testing.T) {
 	}
 }
 
+func TestPublishName(t *testing.T) {
+	bus := eb.NewEventBus(1000)
+	defer bus.Stop()
+
+	cfg := config.NewConfig()
+	cfg.Verbose = true
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, cfg)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
+
+	names := make(chan string, 10)
+	bus.Subscribe(requests.NewNameTopic, func(req *requests.DNSRequest) {
+		names <- req.Name
+	})
+	logs := make(chan *requests.LogEntry, 10)
+	bus.Subscribe(requests.LogTopic, func(entry *requests.LogEntry) {
+		logs <- entry
+	})
+	nf := NewNameFilter(cfg, nil)
+	bus.Subscribe(requests.InvalidNameTopic, nf.OnInvalidName)
+
+	for _, name := range []string{"sub..owasp.org", "-www.owasp.org", strings.Repeat("a", 64) + ".owasp.org", "_sip._tcp.owasp.org"} {
+		PublishName(ctx, &requests.DNSRequest{
+			Name:   name,
+			Domain: domainTest,
+			Source: "Test",
+		})
+	}
+
+	select {
+	case name := <-names:
+		if name != "_sip._tcp.owasp.org" {
+			t.Errorf("The invalid name %s was published", name)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("The valid service name was not published")
+	}
+	select {
+	case name := <-names:
+		t.Errorf("The invalid name %s was published", name)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if invalid := nf.Invalid(); invalid != 3 {
+		t.Errorf("Expected 3 invalid names to be counted, got %d", invalid)
+	}
+	if len(logs) != 3 {
+		t.Errorf("Expected the 3 invalid names to be logged, got %d messages", len(logs))
+	}
+}
+
 func setupConfig(domain string) *config.Config {
 	cfg := config.NewConfig()
 
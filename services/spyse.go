@@ -15,7 +15,6 @@ import (
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
 	"github.com/OWASP/Amass/v3/net/dns"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -67,7 +66,7 @@ func (s *Spyse) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", s.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, s.String(), "Querying for %s subdomains", req.Domain))
 
 	if s.API == nil || s.API.Key == "" {
 		s.executeSubdomainQuery(ctx, req.Domain)
@@ -102,9 +101,9 @@ func (s *Spyse) subdomainQueryAPI(ctx context.Context, domain string, page int)
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, s.String())
 
 	u := s.getAPIURL(domain, page)
-	response, err := http.RequestWebPage(u, nil, nil, "", "")
+	response, err := s.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", s.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, s.String(), "%s: %v", u, err))
 		return 0, err
 	}
 
@@ -117,7 +116,7 @@ func (s *Spyse) subdomainQueryAPI(ctx context.Context, domain string, page int)
 
 	if err := json.Unmarshal([]byte(response), &results); err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: Failed to unmarshal JSON: %v", s.String(), err),
+			requests.NewLogEntry(requests.LogError, s.String(), "Failed to unmarshal JSON: %v", err),
 		)
 		return 0, err
 	}
@@ -129,7 +128,7 @@ func (s *Spyse) subdomainQueryAPI(ctx context.Context, domain string, page int)
 			continue
 		}
 
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   cleanName(n),
 			Domain: record.Domain,
 			Tag:    s.SourceType,
@@ -157,9 +156,9 @@ func (s *Spyse) executeSubdomainQuery(ctx context.Context, domain string) {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, s.String())
 
 	url := s.getURL(domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := s.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", s.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, s.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -174,7 +173,7 @@ func (s *Spyse) executeSubdomainQuery(ctx context.Context, domain string) {
 	}
 
 	for sd := range matches {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		PublishName(ctx, &requests.DNSRequest{
 			Name:   cleanName(sd),
 			Domain: domain,
 			Tag:    s.SourceType,
@@ -194,9 +193,9 @@ func (s *Spyse) certQueryAPI(ctx context.Context, domain string) error {
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, s.String())
 
 	u := s.getCertAPIURL(domain)
-	response, err := http.RequestWebPage(u, nil, nil, "", "")
+	response, err := s.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", s.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, s.String(), "%s: %v", u, err))
 		return err
 	}
 
@@ -208,7 +207,7 @@ func (s *Spyse) certQueryAPI(ctx context.Context, domain string) error {
 
 	if err := json.Unmarshal([]byte(response), &results); err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: Failed to unmarshal JSON: %v", s.String(), err),
+			requests.NewLogEntry(requests.LogError, s.String(), "Failed to unmarshal JSON: %v", err),
 		)
 		return err
 	}
@@ -223,7 +222,7 @@ func (s *Spyse) certQueryAPI(ctx context.Context, domain string) error {
 				continue
 			}
 
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   cleanName(n),
 				Domain: record.Domain,
 				Tag:    s.SourceType,
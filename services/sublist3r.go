@@ -11,7 +11,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -58,30 +57,30 @@ func (s *Sublist3rAPI) OnDNSRequest(ctx context.Context, req *requests.DNSReques
 	s.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, s.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", s.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, s.String(), "Querying for %s subdomains", req.Domain))
 
 	url := s.restURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := s.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", s.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, s.String(), "%s: %v", url, err))
 		return
 	}
 
 	// Extract the subdomain names from the REST API results
 	var subs []string
 	if err := json.Unmarshal([]byte(page), &subs); err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", s.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, s.String(), "%s: %v", url, err))
 		return
 	} else if len(subs) == 0 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: The request returned zero results", s.String(), url),
+			requests.NewLogEntry(requests.LogInfo, s.String(), "%s: The request returned zero results", url),
 		)
 		return
 	}
 
 	for _, sub := range subs {
 		if cfg.IsDomainInScope(sub) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   sub,
 				Domain: req.Domain,
 				Tag:    s.SourceType,
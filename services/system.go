@@ -6,6 +6,9 @@ package services
 import (
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/graph"
+	amassnet "github.com/OWASP/Amass/v3/net"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/resolvers"
 )
 
@@ -17,6 +20,22 @@ type System interface {
 	// Returns the resolver pool that handles DNS requests
 	Pool() resolvers.Resolver
 
+	// QueryLimiter returns the budget of DNS queries per second shared by the resolver pool,
+	// the wildcard tests and the zone walking
+	QueryLimiter() *resolvers.QueryLimiter
+
+	// Dialer returns the budget of connections shared by the active techniques
+	Dialer() *amassnet.RateLimitedDialer
+
+	// PortChecker returns the coordinator of the connections made by the active techniques
+	PortChecker() *amassnet.PortChecker
+
+	// HTTPClient returns the client pooling the connections made by the data sources
+	HTTPClient() *amasshttp.Client
+
+	// Suffixes returns the public suffix settings used to derive the registered domains
+	Suffixes() *amassdns.SuffixList
+
 	// AddSource appends the provided data source to the slice of sources managed by the System
 	AddSource(srv Service) error
 
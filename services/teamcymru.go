@@ -5,7 +5,6 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"strconv"
 	"strings"
@@ -94,7 +93,7 @@ func (t *TeamCymru) origin(ctx context.Context, addr string) *requests.ASNReques
 		name = amassdns.IPv6NibbleFormat(ip.String()) + ".origin6.asn.cymru.com"
 	} else {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the IP address", t.String(), addr),
+			requests.NewLogEntry(requests.LogError, t.String(), "%s: Failed to parse the IP address", addr),
 		)
 		return nil
 	}
@@ -102,7 +101,7 @@ func (t *TeamCymru) origin(ctx context.Context, addr string) *requests.ASNReques
 	answers, _, err = t.System().Pool().Resolve(ctx, name, "TXT", resolvers.PriorityCritical)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: DNS TXT record query error: %v", t.String(), name, err),
+			requests.NewLogEntry(requests.LogError, t.String(), "%s: DNS TXT record query error: %v", name, err),
 		)
 		return nil
 	}
@@ -110,7 +109,7 @@ func (t *TeamCymru) origin(ctx context.Context, addr string) *requests.ASNReques
 	fields := strings.Split(answers[0].Data, " | ")
 	if len(fields) < 5 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the origin response", t.String(), name),
+			requests.NewLogEntry(requests.LogError, t.String(), "%s: Failed to parse the origin response", name),
 		)
 		return nil
 	}
@@ -118,7 +117,7 @@ func (t *TeamCymru) origin(ctx context.Context, addr string) *requests.ASNReques
 	asn, err := strconv.Atoi(strings.TrimSpace(fields[0]))
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the origin response: %v", t.String(), name, err),
+			requests.NewLogEntry(requests.LogError, t.String(), "%s: Failed to parse the origin response: %v", name, err),
 		)
 		return nil
 	}
@@ -154,7 +153,7 @@ func (t *TeamCymru) asnLookup(ctx context.Context, asn int) *requests.ASNRequest
 	answers, _, err = t.System().Pool().Resolve(ctx, name, "TXT", resolvers.PriorityCritical)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: DNS TXT record query error: %v", t.String(), name, err),
+			requests.NewLogEntry(requests.LogError, t.String(), "%s: DNS TXT record query error: %v", name, err),
 		)
 		return nil
 	}
@@ -162,7 +161,7 @@ func (t *TeamCymru) asnLookup(ctx context.Context, asn int) *requests.ASNRequest
 	fields := strings.Split(answers[0].Data, " | ")
 	if len(fields) < 5 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the origin response", t.String(), name),
+			requests.NewLogEntry(requests.LogError, t.String(), "%s: Failed to parse the origin response", name),
 		)
 		return nil
 	}
@@ -170,7 +169,7 @@ func (t *TeamCymru) asnLookup(ctx context.Context, asn int) *requests.ASNRequest
 	pASN, err := strconv.Atoi(strings.TrimSpace(fields[0]))
 	if err != nil || asn != pASN {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to parse the origin response: %v", t.String(), name, err),
+			requests.NewLogEntry(requests.LogError, t.String(), "%s: Failed to parse the origin response: %v", name, err),
 		)
 		return nil
 	}
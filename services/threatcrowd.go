@@ -12,7 +12,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -60,13 +59,13 @@ func (t *ThreatCrowd) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 	t.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, t.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", t.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, t.String(), "Querying for %s subdomains", req.Domain))
 
 	url := t.getURL(req.Domain)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := t.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", t.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, t.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -84,7 +83,7 @@ func (t *ThreatCrowd) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 
 	if m.ResponseCode != "1" {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Response code %s", t.String(), url, m.ResponseCode),
+			requests.NewLogEntry(requests.LogWarn, t.String(), "%s: Response code %s", url, m.ResponseCode),
 		)
 		return
 	}
@@ -93,7 +92,7 @@ func (t *ThreatCrowd) OnDNSRequest(ctx context.Context, req *requests.DNSRequest
 		s := strings.ToLower(sub)
 
 		if s != "" && re.MatchString(s) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   s,
 				Domain: req.Domain,
 				Tag:    t.SourceType,
@@ -0,0 +1,93 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/miekg/dns"
+)
+
+// Tracer starts the spans for the requests processed by the DataManagerService. The interface
+// allows an OpenTelemetry tracer to be adapted without the dependency being required, and the
+// trace context carried by the parent context is expected to be propagated to the span.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a unit of work started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// The name of the span started for each DNS request processed by the data manager.
+const dnsRequestSpan = "amass.datamanager.process_dns_request"
+
+type spanKey struct{}
+
+// SetTracer provides the Tracer used to start a span for each DNS request processed.
+func (dms *DataManagerService) SetTracer(t Tracer) {
+	dms.tracerLock.Lock()
+	defer dms.tracerLock.Unlock()
+
+	dms.tracer = t
+}
+
+// startSpan returns a context carrying the span started for the request, or a nil span
+// when no Tracer has been provided.
+func (dms *DataManagerService) startSpan(ctx context.Context, req *requests.DNSRequest) (context.Context, Span) {
+	dms.tracerLock.Lock()
+	t := dms.tracer
+	dms.tracerLock.Unlock()
+
+	if t == nil {
+		return ctx, nil
+	}
+
+	ctx, span := t.Start(ctx, dnsRequestSpan)
+	if span == nil {
+		return ctx, nil
+	}
+
+	span.SetAttribute("amass.dns.name", req.Name)
+	span.SetAttribute("amass.dns.domain", req.Domain)
+	span.SetAttribute("amass.dns.source", req.Source)
+	span.SetAttribute("amass.dns.tag", req.Tag)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// setRecordAttributes records the number of records, and the number of each type, on the span.
+func setRecordAttributes(span Span, records []requests.DNSAnswer) {
+	if span == nil {
+		return
+	}
+
+	types := make(map[string]int)
+	for _, r := range records {
+		rtype, found := dns.TypeToString[uint16(r.Type)]
+		if !found {
+			rtype = "UNKNOWN"
+		}
+		types[rtype]++
+	}
+
+	span.SetAttribute("amass.dns.record_count", len(records))
+	for rtype, num := range types {
+		span.SetAttribute("amass.dns.records."+rtype, num)
+	}
+}
+
+// spanError marks the span of the request as failed when the log entry reports an error.
+func spanError(ctx context.Context, entry *requests.LogEntry) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok || span == nil || entry == nil || entry.Level != requests.LogError {
+		return
+	}
+
+	span.RecordError(errors.New(entry.Message))
+}
@@ -0,0 +1,161 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/graph/db"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+type stubSpan struct {
+	sync.Mutex
+	name   string
+	parent string
+	attrs  map[string]interface{}
+	errs   []error
+	ended  bool
+}
+
+func (s *stubSpan) SetAttribute(key string, value interface{}) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.attrs[key] = value
+}
+
+func (s *stubSpan) RecordError(err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.errs = append(s.errs, err)
+}
+
+func (s *stubSpan) End() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.ended = true
+}
+
+type traceKey struct{}
+
+// failingEdgeDatabase fails every edge insertion.
+type failingEdgeDatabase struct {
+	db.GraphDatabase
+}
+
+func (f *failingEdgeDatabase) InsertEdge(edge *db.Edge) error {
+	return errors.New("edge insertion failed")
+}
+
+type stubTracer struct {
+	sync.Mutex
+	spans []*stubSpan
+}
+
+func (t *stubTracer) Start(ctx context.Context, name string) (context.Context, services.Span) {
+	t.Lock()
+	defer t.Unlock()
+
+	parent, _ := ctx.Value(traceKey{}).(string)
+	span := &stubSpan{name: name, parent: parent, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestDataManagerTracing(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+	h.Ctx = context.WithValue(h.Ctx, traceKey{}, "parent-trace")
+
+	tracer := new(stubTracer)
+	dms := services.NewDataManagerService(h.Sys)
+	dms.SetTracer(tracer)
+
+	h.Process(t, dms, &requests.DNSRequest{
+		Name:   "www.owasp.org",
+		Domain: "owasp.org",
+		Records: []requests.DNSAnswer{
+			{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "192.0.2.1"},
+			{Name: "www.owasp.org", Type: int(dns.TypeA), Data: "192.0.2.2"},
+			{Name: "www.owasp.org", Type: int(dns.TypeAAAA), Data: "2001:db8::1"},
+		},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	})
+
+	tracer.Lock()
+	spans := tracer.spans
+	tracer.Unlock()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	span.Lock()
+	if span.name != "amass.datamanager.process_dns_request" || span.parent != "parent-trace" || !span.ended {
+		t.Errorf("The span %q with parent %q was not started from the request context and ended", span.name, span.parent)
+	}
+	for key, value := range map[string]interface{}{
+		"amass.dns.name":         "www.owasp.org",
+		"amass.dns.domain":       "owasp.org",
+		"amass.dns.source":       "DNS",
+		"amass.dns.record_count": 3,
+		"amass.dns.records.A":    2,
+		"amass.dns.records.AAAA": 1,
+	} {
+		if got := span.attrs[key]; got != value {
+			t.Errorf("The span attribute %s was %v, expected %v", key, got, value)
+		}
+	}
+	if len(span.errs) != 0 {
+		t.Errorf("The span recorded unexpected errors: %v", span.errs)
+	}
+	span.Unlock()
+
+	// The errors logged while processing the request mark the span
+	h.Sys.AddGraph(graph.NewGraph(&failingEdgeDatabase{GraphDatabase: db.NewCayleyGraphMemory()}))
+	h.Process(t, dms, &requests.DNSRequest{
+		Name:    "api.owasp.org",
+		Domain:  "owasp.org",
+		Records: []requests.DNSAnswer{{Name: "api.owasp.org", Type: int(dns.TypeA), Data: "192.0.2.3"}},
+		Tag:     requests.DNS,
+		Source:  "DNS",
+	})
+
+	tracer.Lock()
+	spans = tracer.spans
+	tracer.Unlock()
+	if len(spans) != 2 {
+		t.Fatalf("Expected two spans, got %d", len(spans))
+	}
+
+	span = spans[1]
+	span.Lock()
+	defer span.Unlock()
+
+	var found bool
+	for _, err := range span.errs {
+		if strings.Contains(err.Error(), "failed to insert A record") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the span to record the logged insert error, got %v", span.errs)
+	}
+}
@@ -13,7 +13,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
 	"github.com/OWASP/Amass/v3/stringset"
@@ -72,13 +71,13 @@ func (u *Umbrella) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	u.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, u.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", u.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, u.String(), "Querying for %s subdomains", req.Domain))
 
 	headers := u.restHeaders()
 	url := u.restDNSURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := u.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the subdomain names from the REST API results
@@ -93,7 +92,7 @@ func (u *Umbrella) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	for _, m := range subs.Matches {
 		if d := cfg.WhichDomain(m.Name); d != "" {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   m.Name,
 				Domain: d,
 				Tag:    u.SourceType,
@@ -124,9 +123,9 @@ func (u *Umbrella) OnAddrRequest(ctx context.Context, req *requests.AddrRequest)
 
 	headers := u.restHeaders()
 	url := u.restAddrURL(req.Address)
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := u.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the subdomain names from the REST API results
@@ -142,7 +141,7 @@ func (u *Umbrella) OnAddrRequest(ctx context.Context, req *requests.AddrRequest)
 	for _, record := range ip.Records {
 		if name := resolvers.RemoveLastDot(record.Data); name != "" {
 			if domain := cfg.WhichDomain(name); domain != "" {
-				bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+				PublishName(ctx, &requests.DNSRequest{
 					Name:   name,
 					Domain: req.Domain,
 					Tag:    u.SourceType,
@@ -187,9 +186,9 @@ func (u *Umbrella) executeASNAddrQuery(ctx context.Context, req *requests.ASNReq
 
 	headers := u.restHeaders()
 	url := u.restAddrToASNURL(req.Address)
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := u.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the AS information from the REST API results
@@ -252,9 +251,9 @@ func (u *Umbrella) executeASNQuery(ctx context.Context, req *requests.ASNRequest
 
 	headers := u.restHeaders()
 	url := u.restASNToCIDRsURL(req.ASN)
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := u.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the netblock information from the REST API results
@@ -362,15 +361,15 @@ func (u *Umbrella) queryWhois(ctx context.Context, domain string) *whoisRecord {
 	u.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, u.String())
 
-	record, err := http.RequestWebPage(whoisURL, nil, headers, "", "")
+	record, err := u.System().HTTPClient().RequestWebPage(ctx, whoisURL, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), whoisURL, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", whoisURL, err))
 		return nil
 	}
 
 	err = json.Unmarshal([]byte(record), &whois)
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), whoisURL, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", whoisURL, err))
 		return nil
 	}
 	return &whois
@@ -392,9 +391,9 @@ func (u *Umbrella) queryReverseWhois(ctx context.Context, apiURL string) []strin
 		bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, u.String())
 
 		fullAPIURL := fmt.Sprintf("%s&offset=%d", apiURL, count)
-		record, err := http.RequestWebPage(fullAPIURL, nil, headers, "", "")
+		record, err := u.System().HTTPClient().RequestWebPage(ctx, fullAPIURL, nil, headers, "", "")
 		if err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), apiURL, err))
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", apiURL, err))
 			return domains.Slice()
 		}
 		err = json.Unmarshal([]byte(record), &whois)
@@ -67,12 +67,12 @@ func (u *URLScan) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	u.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, u.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", u.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, u.String(), "Querying for %s subdomains", req.Domain))
 
 	url := u.searchURL(req.Domain)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := u.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", url, err))
 		return
 	}
 	// Extract the subdomain names from the REST API results
@@ -104,7 +104,7 @@ func (u *URLScan) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 
 	for name := range subs {
 		if re.MatchString(name) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   name,
 				Domain: req.Domain,
 				Tag:    u.SourceType,
@@ -126,9 +126,9 @@ func (u *URLScan) getSubsFromResult(ctx context.Context, id string) stringset.Se
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, u.String())
 
 	url := u.resultURL(id)
-	page, err := http.RequestWebPage(url, nil, nil, "", "")
+	page, err := u.System().HTTPClient().RequestWebPage(ctx, url, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", url, err))
 		return subs
 	}
 	// Extract the subdomain names from the REST API results
@@ -163,9 +163,9 @@ func (u *URLScan) attemptSubmission(ctx context.Context, domain string) string {
 	}
 	url := "https://urlscan.io/api/v1/scan/"
 	body := strings.NewReader(u.submitBody(domain))
-	page, err := http.RequestWebPage(url, body, headers, "", "")
+	page, err := u.System().HTTPClient().RequestWebPage(ctx, url, body, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, u.String(), "%s: %v", url, err))
 		return ""
 	}
 
@@ -184,7 +184,7 @@ func (u *URLScan) attemptSubmission(ctx context.Context, domain string) string {
 
 	// Keep this data source active while waiting for the scan to complete
 	for {
-		_, err = http.RequestWebPage(result.API, nil, nil, "", "")
+		_, err = u.System().HTTPClient().RequestWebPage(ctx, result.API, nil, nil, "", "")
 		if err == nil || err.Error() != "404 Not Found" {
 			break
 		}
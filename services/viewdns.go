@@ -13,7 +13,6 @@ import (
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
 	"github.com/OWASP/Amass/v3/net"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringset"
 )
@@ -61,21 +60,20 @@ func (v *ViewDNS) OnDNSRequest(ctx context.Context, req *requests.DNSRequest) {
 	v.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, v.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", v.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, v.String(), "Querying for %s subdomains", req.Domain))
 
 	var unique []string
 	u := v.getIPHistoryURL(req.Domain)
 	// The ViewDNS IP History lookup sometimes reveals interesting results
-	page, err := http.RequestWebPage(u, nil, nil, "", "")
+	page, err := v.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", v.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, v.String(), "%s: %v", u, err))
 		return
 	}
 
 	// Look for IP addresses in the web page returned
-	re := regexp.MustCompile(net.IPv4RE)
-	for _, sd := range re.FindAllString(page, -1) {
-		addr := NewUniqueElements(unique, sd)
+	for _, ip := range net.FindAllIPs(page) {
+		addr := NewUniqueElements(unique, ip.String())
 
 		if len(addr) > 0 {
 			bus.Publish(requests.NewAddrTopic, eventbus.PriorityHigh, &requests.AddrRequest{
@@ -104,16 +102,16 @@ func (v *ViewDNS) OnWhoisRequest(ctx context.Context, req *requests.WhoisRequest
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, v.String())
 
 	u := v.getReverseWhoisURL(req.Domain)
-	page, err := http.RequestWebPage(u, nil, nil, "", "")
+	page, err := v.System().HTTPClient().RequestWebPage(ctx, u, nil, nil, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", v.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, v.String(), "%s: %v", u, err))
 		return
 	}
 	// Pull the table we need from the page content
 	table := getViewDNSTable(page)
 	if table == "" {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Failed to discover the table of results", v.String(), u),
+			requests.NewLogEntry(requests.LogError, v.String(), "%s: Failed to discover the table of results", u),
 		)
 		return
 	}
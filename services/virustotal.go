@@ -13,7 +13,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -71,7 +70,7 @@ func (v *VirusTotal) OnDNSRequest(ctx context.Context, req *requests.DNSRequest)
 	v.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, v.String())
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", v.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, v.String(), "Querying for %s subdomains", req.Domain))
 
 	if v.haveAPIKey {
 		v.apiQuery(ctx, req.Domain)
@@ -95,9 +94,9 @@ func (v *VirusTotal) apiQuery(ctx context.Context, domain string) {
 
 	url := v.apiURL(domain)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := v.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", v.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, v.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -116,7 +115,7 @@ func (v *VirusTotal) apiQuery(ctx context.Context, domain string) {
 
 	if m.ResponseCode != 1 {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("%s: %s: Response code %d: %s", v.String(), url, m.ResponseCode, m.Message),
+			requests.NewLogEntry(requests.LogWarn, v.String(), "%s: Response code %d: %s", url, m.ResponseCode, m.Message),
 		)
 		return
 	}
@@ -125,7 +124,7 @@ func (v *VirusTotal) apiQuery(ctx context.Context, domain string) {
 		s := strings.ToLower(sub)
 
 		if re.MatchString(s) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   s,
 				Domain: domain,
 				Tag:    v.SourceType,
@@ -165,9 +164,9 @@ func (v *VirusTotal) regularQuery(ctx context.Context, domain string) {
 
 	url := v.getURL(domain)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(url, nil, headers, "", "")
+	page, err := v.System().HTTPClient().RequestWebPage(ctx, url, nil, headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", v.String(), url, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, v.String(), "%s: %v", url, err))
 		return
 	}
 
@@ -184,7 +183,7 @@ func (v *VirusTotal) regularQuery(ctx context.Context, domain string) {
 
 	for _, data := range m.Data {
 		if data.Type == "domain" && re.MatchString(data.ID) {
-			bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+			PublishName(ctx, &requests.DNSRequest{
 				Name:   data.ID,
 				Domain: domain,
 				Tag:    v.SourceType,
@@ -13,7 +13,6 @@ import (
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/eventbus"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 )
 
@@ -93,7 +92,7 @@ func (w *WhoisXML) OnWhoisRequest(ctx context.Context, req *requests.WhoisReques
 		return
 	}
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-		fmt.Sprintf("Querying %s for %s subdomains", w.String(), req.Domain))
+		requests.NewLogEntry(requests.LogInfo, w.String(), "Querying for %s subdomains", req.Domain))
 
 	w.CheckRateLimit()
 	bus.Publish(requests.SetActiveTopic, eventbus.PriorityCritical, w.String())
@@ -108,9 +107,9 @@ func (w *WhoisXML) OnWhoisRequest(ctx context.Context, req *requests.WhoisReques
 	r.SearchTerms.Include = append(r.SearchTerms.Include, req.Domain)
 	jr, _ := json.Marshal(r)
 
-	page, err := http.RequestWebPage(u, bytes.NewReader(jr), headers, "", "")
+	page, err := w.System().HTTPClient().RequestWebPage(ctx, u, bytes.NewReader(jr), headers, "", "")
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", w.String(), u, err))
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, requests.NewLogEntry(requests.LogError, w.String(), "%s: %v", u, err))
 		return
 	}
 
@@ -119,7 +118,7 @@ func (w *WhoisXML) OnWhoisRequest(ctx context.Context, req *requests.WhoisReques
 	err = json.NewDecoder(strings.NewReader(page)).Decode(&q)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("Failed to decode json in WhoisXML.\nErr:%s", err))
+			requests.NewLogEntry(requests.LogError, w.String(), "Failed to decode the JSON: %v", err))
 		return
 	}
 
@@ -0,0 +1,105 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/eventbus"
+	"github.com/OWASP/Amass/v3/graph"
+	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/stringset"
+	"github.com/miekg/dns"
+)
+
+// wildcardCNAME holds the distinct labels below a zone answered with the same CNAME target.
+type wildcardCNAME struct {
+	labels    stringset.Set
+	collapsed bool
+}
+
+// WildcardCNAMECollapsed returns the number of names that were not stored, since the names were
+// answered by a wildcard CNAME collapsed by the config policy.
+func (dms *DataManagerService) WildcardCNAMECollapsed() uint64 {
+	return atomic.LoadUint64(&dms.wildcardCollapsed)
+}
+
+// collapseWildcardCNAME returns true when the request is answered by a wildcard CNAME and the
+// config policy collapses those names. The wildcard is detected once the config threshold of
+// distinct labels below the zone share the CNAME target, and the single node representing the
+// pattern is stored at that time. The names listed by the config are never collapsed.
+func (dms *DataManagerService) collapseWildcardCNAME(ctx context.Context, cfg *config.Config, req *requests.DNSRequest) bool {
+	if !strings.EqualFold(cfg.WildcardCNAMEPolicy, "collapse") || cfg.WildcardCNAMEThreshold <= 0 {
+		return false
+	}
+
+	var target string
+	for _, r := range req.Records {
+		if uint16(r.Type) == dns.TypeCNAME {
+			target = r.Data
+			break
+		}
+	}
+	if target == "" {
+		return false
+	}
+
+	i := strings.IndexByte(req.Name, '.')
+	if i <= 0 || i == len(req.Name)-1 {
+		return false
+	}
+	label, zone := req.Name[:i], req.Name[i+1:]
+
+	for _, n := range cfg.WildcardCNAMENames {
+		if amassdns.Canonical(n) == req.Name {
+			return false
+		}
+	}
+
+	key := eventKey(ctx, zone+" "+target)
+	dms.wildcardLock.Lock()
+	if dms.wildcardCNAMEs == nil {
+		dms.wildcardCNAMEs = make(map[string]*wildcardCNAME)
+	}
+	w, found := dms.wildcardCNAMEs[key]
+	if !found {
+		w = &wildcardCNAME{labels: stringset.New()}
+		dms.wildcardCNAMEs[key] = w
+	}
+
+	var detected bool
+	if !w.collapsed {
+		w.labels.Insert(label)
+		if w.labels.Len() >= cfg.WildcardCNAMEThreshold {
+			// The labels are no longer needed once the wildcard has been detected
+			w.collapsed = true
+			w.labels = nil
+			detected = true
+		}
+	}
+	collapsed := w.collapsed
+	dms.wildcardLock.Unlock()
+
+	if detected {
+		dms.writeGraphs(ctx, func(g *graph.Graph) error {
+			if err := g.InsertWildcardCNAME(zone, target, req.Source, req.Tag, eventID(ctx)); err != nil {
+				dms.health.failed()
+				dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+					requests.NewLogEntry(requests.LogError, dms.String(), "%s failed to insert the wildcard CNAME: %v", g, err).With("graph", g))
+				return err
+			}
+			return nil
+		})
+		dms.publish(ctx, requests.LogTopic, eventbus.PriorityHigh,
+			requests.NewLogEntry(requests.LogInfo, dms.String(), "Wildcard CNAME detected: *.%s -> %s", zone, target))
+	}
+	if collapsed {
+		atomic.AddUint64(&dms.wildcardCollapsed, 1)
+	}
+	return collapsed
+}
@@ -0,0 +1,82 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package services_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/services"
+	"github.com/OWASP/Amass/v3/services/servicetest"
+	"github.com/miekg/dns"
+)
+
+func TestWildcardCNAMECollapse(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.WildcardCNAMEPolicy = "collapse"
+	cfg.WildcardCNAMEThreshold = 3
+	cfg.WildcardCNAMENames = []string{"portal.apps.owasp.org"}
+
+	h := servicetest.NewHarness(cfg)
+	defer h.Close()
+
+	dms := services.NewDataManagerService(h.Sys)
+	process := func(name string) {
+		h.Process(t, dms, &requests.DNSRequest{
+			Name:    name,
+			Domain:  "owasp.org",
+			Records: []requests.DNSAnswer{{Name: name, Type: int(dns.TypeCNAME), Data: "tenant.saas.example.com"}},
+			Tag:     requests.DNS,
+			Source:  "DNS",
+		})
+	}
+
+	// The labels observed by another enumeration sharing the data manager do not count
+	for i := 1; i <= 2; i++ {
+		name := fmt.Sprintf("other%d.apps.owasp.org", i)
+
+		h.Process(t, dms, &requests.DNSRequest{
+			Name:    name,
+			Domain:  "owasp.org",
+			Records: []requests.DNSAnswer{{Name: name, Type: int(dns.TypeCNAME), Data: "tenant.saas.example.com"}},
+			Tag:     requests.DNS,
+			Source:  "DNS",
+			EventID: "other-event",
+		})
+	}
+
+	// Every random label below the zone is answered with the same CNAME target
+	for i := 1; i <= 10; i++ {
+		process(fmt.Sprintf("random%d.apps.owasp.org", i))
+	}
+	process("portal.apps.owasp.org")
+
+	g := h.Sys.Graph()
+	if !g.IsWildcardCNAME("*.apps.owasp.org") {
+		t.Fatalf("The wildcard CNAME was not collapsed into the pattern node")
+	}
+	if !g.IsCNAMENode("*.apps.owasp.org") {
+		t.Errorf("The pattern node did not receive the CNAME record")
+	}
+
+	var stored int
+	for i := 1; i <= 10; i++ {
+		if g.IsCNAMENode(fmt.Sprintf("random%d.apps.owasp.org", i)) {
+			stored++
+		}
+	}
+	// Only the labels seen before the wildcard was detected are stored
+	if stored != 2 {
+		t.Errorf("Expected 2 names stored before the detection, got %d", stored)
+	}
+	if n := dms.WildcardCNAMECollapsed(); n != 8 {
+		t.Errorf("Expected 8 collapsed names, got %d", n)
+	}
+	if !g.IsCNAMENode("portal.apps.owasp.org") {
+		t.Errorf("The explicitly listed name was collapsed")
+	}
+}
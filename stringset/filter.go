@@ -3,39 +3,86 @@
 
 package stringset
 
-import "sync"
+import (
+	"strings"
+	"sync"
+)
+
+// The number of shards of a StringFilter, each protected by its own lock.
+const numFilterShards = 32
 
 // StringFilter implements an object that performs filtering of strings
-// to ensure that only unique items get through the filter.
+// to ensure that only unique items get through the filter. The strings are
+// spread across shards, so concurrent callers rarely contend for the same lock.
 type StringFilter struct {
+	shards [numFilterShards]filterShard
+}
+
+type filterShard struct {
 	sync.Mutex
 	filter Set
 }
 
 // NewStringFilter returns an initialized StringFilter.
 func NewStringFilter() *StringFilter {
-	s := New()
+	sf := new(StringFilter)
 
-	return &StringFilter{filter: s}
+	for i := range sf.shards {
+		sf.shards[i].filter = New()
+	}
+	return sf
 }
 
-// Duplicate checks if the name provided has been seen before by this filter.
-func (sf *StringFilter) Duplicate(s string) bool {
-	sf.Lock()
-	defer sf.Unlock()
+// The shard is selected by the lowercase string, since the Set is case insensitive.
+func (sf *StringFilter) shard(s string) (*filterShard, string) {
+	s = strings.ToLower(s)
 
-	if sf.filter.Has(s) {
-		return true
+	// FNV-1a, computed inline to avoid allocating on the hot path
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
 	}
+	return &sf.shards[h%numFilterShards], s
+}
+
+// InsertIfAbsent adds the string to the filter and returns true if it was not already present.
+func (sf *StringFilter) InsertIfAbsent(s string) bool {
+	shard, s := sf.shard(s)
 
-	sf.filter.Insert(s)
-	return false
+	shard.Lock()
+	defer shard.Unlock()
+
+	if shard.filter.Has(s) {
+		return false
+	}
+	shard.filter.Insert(s)
+	return true
+}
+
+// Duplicate checks if the name provided has been seen before by this filter.
+func (sf *StringFilter) Duplicate(s string) bool {
+	return !sf.InsertIfAbsent(s)
 }
 
 // Has returns true if the receiver StringFilter already contains the string argument.
 func (sf *StringFilter) Has(s string) bool {
-	sf.Lock()
-	defer sf.Unlock()
+	shard, s := sf.shard(s)
+
+	shard.Lock()
+	defer shard.Unlock()
 
-	return sf.filter.Has(s)
+	return shard.filter.Has(s)
+}
+
+// Len returns the number of strings in the receiver StringFilter.
+func (sf *StringFilter) Len() int {
+	var num int
+
+	for i := range sf.shards {
+		sf.shards[i].Lock()
+		num += sf.shards[i].filter.Len()
+		sf.shards[i].Unlock()
+	}
+	return num
 }
@@ -1,6 +1,9 @@
 package stringset
 
 import (
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -15,3 +18,94 @@ func TestStringFilterDuplicate(t *testing.T) {
 		t.Errorf("StringFilter failed duplicate check")
 	}
 }
+
+func TestStringFilterInsertIfAbsent(t *testing.T) {
+	sf := NewStringFilter()
+
+	if !sf.InsertIfAbsent("www.owasp.org") {
+		t.Errorf("StringFilter reported the new string as present")
+	}
+	if sf.InsertIfAbsent("WWW.OWASP.ORG") {
+		t.Errorf("StringFilter reported the string in a different case as new")
+	}
+	if !sf.Has("www.OWASP.org") || sf.Has("owasp.org") {
+		t.Errorf("StringFilter failed the membership check")
+	}
+	if l := sf.Len(); l != 1 {
+		t.Errorf("Got %d strings, expected 1", l)
+	}
+}
+
+func TestStringFilterConcurrent(t *testing.T) {
+	sf := NewStringFilter()
+
+	var wg sync.WaitGroup
+	var inserted int64
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				if sf.InsertIfAbsent("name" + strconv.Itoa(j)) {
+					atomic.AddInt64(&inserted, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if inserted != 1000 || sf.Len() != 1000 {
+		t.Errorf("Expected each of the 1000 strings to be inserted once, got %d inserts and %d strings", inserted, sf.Len())
+	}
+}
+
+// mutexFilter is the filter guarding a single Set with one lock, for comparison.
+type mutexFilter struct {
+	sync.Mutex
+	filter Set
+}
+
+func (mf *mutexFilter) InsertIfAbsent(s string) bool {
+	mf.Lock()
+	defer mf.Unlock()
+
+	if mf.filter.Has(s) {
+		return false
+	}
+	mf.filter.Insert(s)
+	return true
+}
+
+func benchmarkFilter(b *testing.B, insert func(string) bool) {
+	const routines = 32
+
+	names := make([]string, 4096)
+	for i := range names {
+		names[i] = "name" + strconv.Itoa(i) + ".owasp.org"
+	}
+
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	for r := 0; r < routines; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+
+			for i := r; i < b.N; i += routines {
+				insert(names[i%len(names)])
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+func BenchmarkStringFilterSharded(b *testing.B) {
+	benchmarkFilter(b, NewStringFilter().InsertIfAbsent)
+}
+
+func BenchmarkStringFilterSingleMutex(b *testing.B) {
+	mf := &mutexFilter{filter: New()}
+
+	benchmarkFilter(b, mf.InsertIfAbsent)
+}
@@ -4,10 +4,15 @@
 package viz
 
 import (
+	"encoding/json"
 	"io"
 	"text/template"
+	"time"
 )
 
+// Nodes with more edges than the threshold are collapsed until selected in the visualization.
+const d3ExpandThreshold = 50
+
 const d3Template = `
 <!DOCTYPE html>
 <html lang="en">
@@ -16,11 +21,16 @@ const d3Template = `
     <title>OWASP Amass Network Mapping</title>
     <script src="https://d3js.org/d3.v4.min.js"></script>
     <style>
+        body {
+            margin: 0;
+            overflow: hidden;
+            font-family: 'Open Sans', sans-serif;
+            font-size: 12px;
+        }
         div#tooltip {
-            position: absolute;        
+            position: absolute;
             display: inline-block;
             padding: 10px;
-            font-family: 'Open Sans' sans-serif;
             color: #000;
             background-color: #fff;
             border: 1px solid #999;
@@ -29,27 +39,78 @@ const d3Template = `
             opacity: 0;
             z-index: 1;
         }
+        div#controls, div#details {
+            position: absolute;
+            top: 10px;
+            padding: 10px;
+            background-color: rgba(255, 255, 255, 0.9);
+            border: 1px solid #999;
+            border-radius: 2px;
+            z-index: 2;
+        }
+        div#controls {
+            left: 10px;
+            max-height: 90%;
+            overflow-y: auto;
+        }
+        div#details {
+            right: 10px;
+            display: none;
+            min-width: 250px;
+        }
+        div#controls fieldset {
+            margin: 8px 0 0 0;
+        }
+        div#details td {
+            padding: 2px 6px 2px 0;
+            vertical-align: top;
+        }
+        span.swatch {
+            display: inline-block;
+            width: 10px;
+            height: 10px;
+            margin-right: 4px;
+            border: 1px solid #333;
+        }
     </style>
 </head>
 <body>
     <div id="graphDiv"></div>
     <div id="tooltip"></div>
-
+    <div id="controls">
+        <input id="search" type="search" placeholder="Search names" size="30">
+        <span id="matches"></span>
+        <fieldset id="typeFilters"><legend>Node types</legend></fieldset>
+        <fieldset id="tagFilters"><legend>Tags</legend></fieldset>
+    </div>
+    <div id="details"></div>
+
+<script id="graphData" type="application/json">{{ .Data }}</script>
 <script>
 /* global d3 */
 
-var graph = {
-    nodes: [
-    {{ range .Nodes }}
-        {id: {{.ID }}, num: {{ .Num }}, label: "{{ .Label }}", color: "{{ .Color }}" },
-    {{ end }}
-    ],
-    edges: [
-    {{ range .Edges }}
-        {source: {{ .Source }}, target: {{ .Destination }}, label: "{{ .Label }}" },
-    {{ end }}
-    ]
-};
+var graph = JSON.parse(document.getElementById('graphData').textContent),
+    expandThreshold = {{ .ExpandThreshold }},
+    typeGroups = {
+        "Names": ["domain", "subdomain", "ptr", "ns", "mx"],
+        "Addresses": ["address"],
+        "Netblocks": ["netblock"],
+        "ASNs": ["as"]
+    },
+    hiddenTypes = {},
+    hiddenTags = {},
+    expanded = {},
+    matches = [],
+    selected = null,
+    visibleNodes = [],
+    visibleEdges = [];
+
+graph.edges = graph.edges || [];
+graph.nodes.forEach(function(n) { n.neighbors = []; });
+graph.edges.forEach(function(e) {
+    graph.nodes[e.source].neighbors.push(e.target);
+    graph.nodes[e.target].neighbors.push(e.source);
+});
 
 var graphWidth = window.innerWidth,
     graphHeight = window.innerHeight;
@@ -63,16 +124,10 @@ var graphCanvas = d3.select('#graphDiv')
 
 var ctx = graphCanvas.getContext('2d');
 
-var div = d3.select("body").append("div")
-    .attr("class", "tooltip")
-    .style("opacity", 0);
-
 var r = 5,
-    max = {{ .MaxNum }},
+    max = graph.max || 1,
     simulation = d3.forceSimulation()
-        .nodes(graph.nodes)
         .force("link", d3.forceLink()
-            .links(graph.edges)
             .distance(nodeLinkDistance)
             .strength(nodeLinkStrength)
             .id(function(d) { return d.id; }))
@@ -83,7 +138,8 @@ var r = 5,
             .radius(nodeCollideRadius))
         .force("center", d3.forceCenter(graphWidth / 2, graphHeight / 2))
         .on("tick", update),
-    transform = d3.zoomIdentity;
+    transform = d3.zoomIdentity,
+    zoom = d3.zoom().scaleExtent([1 / 10, 8]).on("zoom", zoomed);
 
 d3.select(graphCanvas)
     .call(d3.drag()
@@ -92,7 +148,8 @@ d3.select(graphCanvas)
         .on("start", dragstarted)
         .on("drag", dragged)
         .on("end", dragended))
-    .call(d3.zoom().scaleExtent([1 / 10, 8]).on("zoom", zoomed));
+    .call(zoom)
+    .on("click", clicked);
 
 function nodePercent(n) {
     return n.num / max;
@@ -107,19 +164,13 @@ function nodeCollideRadius(n) {
 }
 
 function nodeLinkDistance(e) {
-    var n1 = graph.nodes[e.source.id],
-        n2 = graph.nodes[e.target.id];
+    var avg = (nodePercent(e.source) + nodePercent(e.target)) / 2;
 
-    var avg = (nodePercent(n1) + nodePercent(n2)) / 2;
-    
     return 60 * avg;
 }
 
 function nodeLinkStrength(e) {
-    var n1 = graph.nodes[e.source.id],
-        n2 = graph.nodes[e.target.id];
-
-    var avg = (nodePercent(n1) + nodePercent(n2)) / 2;
+    var avg = (nodePercent(e.source) + nodePercent(e.target)) / 2;
 
     return 1 - (1 * avg);
 }
@@ -128,6 +179,57 @@ function nodeChargeStrength(n) {
     return -100 + (-300 * nodePercent(n));
 }
 
+function isHub(n) {
+    return n.num > expandThreshold;
+}
+
+function isCollapsed(n) {
+    return isHub(n) && !expanded[n.id];
+}
+
+function passesFilters(n) {
+    return !hiddenTypes[n.type] && !hiddenTags[n.tag || ""];
+}
+
+// Nodes only connected to collapsed high-degree nodes are hidden until one is expanded
+function isVisible(n) {
+    if (!passesFilters(n)) {
+        return false;
+    }
+    if (isHub(n) || n.neighbors.length === 0) {
+        return true;
+    }
+
+    return n.neighbors.some(function(id) {
+        var other = graph.nodes[id];
+
+        return !isCollapsed(other) || matches.indexOf(n) !== -1;
+    });
+}
+
+function applyFilters() {
+    var shown = {};
+
+    visibleNodes = graph.nodes.filter(function(n) {
+        shown[n.id] = isVisible(n);
+        return shown[n.id];
+    });
+    visibleEdges = graph.edges.filter(function(e) {
+        var s = typeof e.source === "object" ? e.source.id : e.source,
+            t = typeof e.target === "object" ? e.target.id : e.target;
+
+        return shown[s] && shown[t];
+    });
+
+    if (selected && !shown[selected.id]) {
+        showDetails(null);
+    }
+
+    simulation.nodes(visibleNodes);
+    simulation.force("link").links(visibleEdges);
+    simulation.alpha(0.3).restart();
+}
+
 function zoomed() {
     transform = d3.event.transform;
     update()
@@ -139,15 +241,15 @@ function update() {
     ctx.translate(transform.x, transform.y);
     ctx.scale(transform.k, transform.k);
 
-    graph.edges.forEach(drawEdge);
-    graph.nodes.forEach(drawNode);
+    visibleEdges.forEach(drawEdge);
+    visibleNodes.forEach(drawNode);
 
     if (closeNode) {
         d3.select('#tooltip')
             .style('opacity', 0.8)
             .style('top', transform.applyY(closeNode.y) + 5 + 'px')
             .style('left', transform.applyX(closeNode.x) + 5 + 'px')
-            .html(closeNode.label);
+            .text(closeNode.title);
     }  else {
         d3.select('#tooltip')
             .style('opacity', 0);
@@ -159,6 +261,13 @@ function update() {
 function drawNode(d) {
     var size = nodeRadius(d);
 
+    if (matches.indexOf(d) !== -1 || d === selected) {
+        ctx.beginPath();
+        ctx.arc(d.x, d.y, size + 4, 0, 2 * Math.PI);
+        ctx.fillStyle = d === selected ? "#000" : "#f00";
+        ctx.fill();
+    }
+
     ctx.beginPath();
     ctx.fillStyle = d.color;
     ctx.moveTo(d.x, d.y);
@@ -166,6 +275,13 @@ function drawNode(d) {
     ctx.strokeStyle = "#333333";
     ctx.stroke();
     ctx.fill();
+
+    if (isCollapsed(d)) {
+        ctx.fillStyle = "#000";
+        ctx.textAlign = "center";
+        ctx.textBaseline = "middle";
+        ctx.fillText("+", d.x, d.y);
+    }
 }
 
 function drawEdge(e) {
@@ -206,14 +322,15 @@ d3.select("canvas").on("mousemove", function(d) {
 
 function findNode(x, y) {
     var i,
+        node,
         newx = transform.invertX(x),
         newy = transform.invertY(y),
         dx,
         dy,
         radius;
 
-    for (i = graph.nodes.length - 1; i >= 0; --i) {
-        node = graph.nodes[i];
+    for (i = visibleNodes.length - 1; i >= 0; --i) {
+        node = visibleNodes[i];
         dx = newx - node.x;
         dy = newy - node.y;
         radius = nodeRadius(node);
@@ -224,9 +341,130 @@ function findNode(x, y) {
     }
 }
 
+function clicked() {
+    if (d3.event.defaultPrevented) {
+        return;
+    }
+
+    var p = d3.mouse(this),
+        node = findNode(p[0], p[1]);
+
+    if (node && isHub(node) && node === selected) {
+        expanded[node.id] = !expanded[node.id];
+        applyFilters();
+    }
+    showDetails(node || null);
+    update();
+}
+
+function showDetails(node) {
+    var panel = d3.select('#details');
+
+    selected = node;
+    panel.html('');
+    if (!node) {
+        panel.style('display', 'none');
+        return;
+    }
+
+    var rows = [
+        ["Name", node.name],
+        ["Type", node.type],
+        ["Tag", node.tag],
+        ["Source", node.source],
+        ["First seen", node.first_seen],
+        ["Edges", node.num]
+    ];
+    if (node.title !== node.type + ": " + node.name) {
+        rows.push(["Details", node.title]);
+    }
+
+    var table = panel.append('table');
+    rows.forEach(function(row) {
+        if (row[1] === undefined || row[1] === "") {
+            return;
+        }
+
+        var tr = table.append('tr');
+        tr.append('td').append('b').text(row[0]);
+        tr.append('td').text(row[1]);
+    });
+
+    if (isHub(node)) {
+        panel.append('p').text(expanded[node.id] ?
+            "Click the node again to collapse its neighbors" :
+            "Click the node again to expand its neighbors");
+    }
+    panel.style('display', 'block');
+}
+
+function search(term) {
+    term = term.trim().toLowerCase();
+
+    matches = term === "" ? [] : graph.nodes.filter(function(n) {
+        return passesFilters(n) && n.name.toLowerCase().indexOf(term) !== -1;
+    });
+    d3.select('#matches').text(term === "" ? "" : matches.length + " matches");
+
+    applyFilters();
+    if (matches.length > 0) {
+        // Wait for the simulation to place nodes that were previously hidden
+        setTimeout(function() { centerOn(matches[0]); }, 500);
+    }
+}
+
+function centerOn(node) {
+    var k = Math.max(transform.k, 1);
+
+    d3.select(graphCanvas).transition().duration(750).call(zoom.transform,
+        d3.zoomIdentity.translate(graphWidth / 2 - node.x * k, graphHeight / 2 - node.y * k).scale(k));
+    showDetails(node);
+}
+
+function addFilter(container, label, color, onChange) {
+    var lbl = d3.select(container).append('div').append('label');
+
+    lbl.append('input')
+        .attr('type', 'checkbox')
+        .property('checked', true)
+        .on('change', function() { onChange(this.checked); });
+    if (color) {
+        lbl.append('span').attr('class', 'swatch').style('background-color', color);
+    }
+    lbl.append('span').text(label);
+}
+
+Object.keys(typeGroups).forEach(function(group) {
+    var types = typeGroups[group].filter(function(t) {
+        return graph.nodes.some(function(n) { return n.type === t; });
+    });
+    if (types.length === 0) {
+        return;
+    }
+
+    addFilter('#typeFilters', group, null, function(checked) {
+        types.forEach(function(t) { hiddenTypes[t] = !checked; });
+        search(document.getElementById('search').value);
+    });
+});
+
+d3.set(graph.nodes.map(function(n) { return n.tag || ""; })).values().sort().forEach(function(tag) {
+    addFilter('#tagFilters', tag === "" ? "none" : tag, null, function(checked) {
+        hiddenTags[tag] = !checked;
+        search(document.getElementById('search').value);
+    });
+});
+
+d3.select('#search').on('input', function() {
+    search(this.value);
+});
+
 function dragsubject() {
     var node = findNode(d3.event.x, d3.event.y);
 
+    if (!node) {
+        return null;
+    }
     node.x = transform.applyX(node.x);
     node.y = transform.applyY(node.y);
     return node
@@ -249,6 +487,7 @@ function dragended() {
     d3.event.subject.fy = null;
 }
 
+applyFilters();
 update();
 
 </script>
@@ -257,26 +496,33 @@ update();
 `
 
 type d3Edge struct {
-	Source      int
-	Destination int
-	Label       string
+	Source      int    `json:"source"`
+	Destination int    `json:"target"`
+	Label       string `json:"label"`
 }
 
 type d3Node struct {
-	ID    int
-	Num   int
-	Label string
-	Color string
+	ID        int    `json:"id"`
+	Num       int    `json:"num"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Title     string `json:"title"`
+	Source    string `json:"source,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	FirstSeen string `json:"first_seen,omitempty"`
+	Color     string `json:"color"`
 }
 
 type d3Graph struct {
-	Name   string
-	MaxNum int
-	Nodes  []d3Node
-	Edges  []d3Edge
+	Name   string   `json:"name"`
+	MaxNum int      `json:"max"`
+	Nodes  []d3Node `json:"nodes"`
+	Edges  []d3Edge `json:"edges"`
 }
 
 // WriteD3Data generates a HTML file that displays the Amass graph using D3.
+// The graph is embedded as JSON, and the page allows the nodes to be searched
+// and filtered by type and tag.
 func WriteD3Data(output io.Writer, nodes []Node, edges []Edge) {
 	colors := map[string]string{
 		"subdomain": "green",
@@ -289,18 +535,27 @@ func WriteD3Data(output io.Writer, nodes []Node, edges []Edge) {
 		"as":        "blue",
 	}
 
-	graph := &d3Graph{Name: "OWASP Amass - Attack Surface Mapping"}
+	graph := &d3Graph{
+		Name:  "OWASP Amass - Attack Surface Mapping",
+		Nodes: []d3Node{},
+		Edges: []d3Edge{},
+	}
 
 	for idx, node := range nodes {
-		label := node.Title
-		if node.Source != "" {
-			label += ", Source: " + node.Source
+		var first string
+		if !node.FirstSeen.IsZero() {
+			first = node.FirstSeen.UTC().Format(time.RFC3339)
 		}
 
 		graph.Nodes = append(graph.Nodes, d3Node{
-			ID:    idx,
-			Label: label,
-			Color: colors[node.Type],
+			ID:        idx,
+			Type:      node.Type,
+			Name:      node.Label,
+			Title:     node.Title,
+			Source:    node.Source,
+			Tag:       node.Tag,
+			FirstSeen: first,
+			Color:     colors[node.Type],
 		})
 	}
 
@@ -320,6 +575,18 @@ func WriteD3Data(output io.Writer, nodes []Node, edges []Edge) {
 		}
 	}
 
+	// The JSON encoder escapes the HTML characters, so the data cannot close the script element
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return
+	}
+
 	t := template.Must(template.New("graph").Parse(d3Template))
-	t.Execute(output, graph)
+	t.Execute(output, struct {
+		Data            string
+		ExpandThreshold int
+	}{
+		Data:            string(data),
+		ExpandThreshold: d3ExpandThreshold,
+	})
 }
@@ -3,6 +3,8 @@
 
 package viz
 
+import "time"
+
 // Edge represents an Amass graph edge throughout the viz package.
 type Edge struct {
 	From, To int
@@ -12,9 +14,11 @@ type Edge struct {
 
 // Node represents an Amass graph node throughout the viz package.
 type Node struct {
-	ID     int
-	Type   string
-	Label  string
-	Title  string
-	Source string
+	ID        int
+	Type      string
+	Label     string
+	Title     string
+	Source    string
+	Tag       string
+	FirstSeen time.Time
 }